@@ -3,7 +3,69 @@ package apperror
 import "errors"
 
 var (
-	ErrEmailAlreadyExists = errors.New("email already exists")
-	ErrInvalidCredentials = errors.New("invalid credentials")
-	ErrNotFound = errors.New("not found")
-)
\ No newline at end of file
+	ErrEmailAlreadyExists          = errors.New("email already exists")
+	ErrInvalidCredentials          = errors.New("invalid credentials")
+	ErrNotFound                    = errors.New("not found")
+	ErrSKUAlreadyExists            = errors.New("sku already exists")
+	ErrInvalidProduct              = errors.New("invalid product")
+	ErrInvalidSaleOrderItem        = errors.New("invalid sale order item")
+	ErrInvalidPromotion            = errors.New("invalid promotion")
+	ErrPromotionUsageLimitReached  = errors.New("promotion usage limit reached")
+	ErrStoreCodeAlreadyExists      = errors.New("store code already exists")
+	ErrInvalidStore                = errors.New("invalid store")
+	ErrTaxRateAlreadyExists        = errors.New("tax rate already exists")
+	ErrInvalidTaxRate              = errors.New("invalid tax rate")
+	ErrInvalidOrderNumberFormat    = errors.New("invalid order number format")
+	ErrOrderNumberConflict         = errors.New("order number already exists")
+	ErrInvalidFilter               = errors.New("invalid filter")
+	ErrInvalidCursor               = errors.New("invalid cursor")
+	ErrForbidden                   = errors.New("forbidden")
+	ErrPreconditionRequired        = errors.New("precondition required")
+	ErrVersionMismatch             = errors.New("version mismatch")
+	ErrIdempotencyKeyMismatch      = errors.New("idempotency key reused with a different request")
+	ErrInvalidIdempotencyKey       = errors.New("invalid idempotency key")
+	ErrSaleOrderAlreadyExists      = errors.New("sale order already exists")
+	ErrInvalidSyncBatch            = errors.New("invalid sync batch")
+	ErrSaleOrderNotDraft           = errors.New("sale order is not a draft")
+	ErrSaleOrderNotHeld            = errors.New("sale order is not held")
+	ErrSaleOrderHeld               = errors.New("sale order is held")
+	ErrInvalidSaleOrderStatus      = errors.New("invalid sale order status")
+	ErrInvalidHold                 = errors.New("invalid hold request")
+	ErrInvalidQuotation            = errors.New("invalid quotation")
+	ErrInvalidQuotationStatus      = errors.New("invalid quotation status change")
+	ErrQuotationNotAccepted        = errors.New("quotation is not accepted")
+	ErrQuotationAlreadyConverted   = errors.New("quotation already converted")
+	ErrQuotationPriceChanged       = errors.New("quoted prices changed")
+	ErrQuotationProductUnavailable = errors.New("quoted product is no longer available")
+	ErrInvalidInvoice              = errors.New("invalid invoice")
+	ErrInvoiceAlreadyExists        = errors.New("invoice already exists")
+	ErrSaleOrderNotCompleted       = errors.New("sale order is not completed")
+	ErrInvalidPayment              = errors.New("invalid payment")
+	ErrPaymentExceedsBalance       = errors.New("payment exceeds balance")
+	ErrInvalidCustomerAccount      = errors.New("invalid customer account")
+	ErrInvalidCharge               = errors.New("invalid charge")
+	ErrCreditLimitExceeded         = errors.New("credit limit exceeded")
+	ErrSaleOrderAlreadyCharged     = errors.New("sale order already charged to an account")
+	ErrRepaymentExceedsBalance     = errors.New("repayment exceeds balance")
+	ErrInvalidCustomer             = errors.New("invalid customer")
+	ErrInvalidCustomerMerge        = errors.New("invalid customer merge")
+	ErrInvalidLoyaltySettings      = errors.New("invalid loyalty settings")
+	ErrLoyaltyDisabled             = errors.New("loyalty program is disabled")
+	ErrInvalidRedemption           = errors.New("invalid points redemption")
+	ErrInsufficientPoints          = errors.New("insufficient points")
+	ErrInvalidGiftCard             = errors.New("invalid gift card")
+	ErrGiftCardCodeConflict        = errors.New("gift card code conflict")
+	ErrGiftCardExpired             = errors.New("gift card expired")
+	ErrInvalidGiftCardRedemption   = errors.New("invalid gift card redemption")
+	ErrInsufficientGiftCardBalance = errors.New("insufficient gift card balance")
+	ErrRedemptionsExceedTotal      = errors.New("redemptions exceed sale order total")
+	ErrInvalidVoucherCampaign      = errors.New("invalid voucher campaign")
+	ErrVoucherCodeConflict         = errors.New("voucher code conflict")
+	ErrVoucherNotApplicable        = errors.New("voucher not applicable")
+	ErrInvalidCashTendered         = errors.New("invalid cash tendered")
+	ErrInvalidReceiptFormat        = errors.New("invalid receipt format")
+	ErrInvalidEmailRecipient       = errors.New("invalid email recipient")
+	ErrEmailNotRetryable           = errors.New("email not retryable")
+	ErrInvalidWebhookEndpoint      = errors.New("invalid webhook endpoint")
+	ErrWebhookEndpointInactive     = errors.New("webhook endpoint inactive")
+)
@@ -2,13 +2,15 @@ package config
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/hafiztri123/kki-be/internal/constants"
 	"github.com/hafiztri123/kki-be/internal/handler"
 	"github.com/hafiztri123/kki-be/internal/middleware"
+	"github.com/hafiztri123/kki-be/internal/service"
 )
 
-func NewRouter(handlers *handler.Handlers) *http.ServeMux {
+func NewRouter(handlers *handler.Handlers, services *service.Services) *http.ServeMux {
 	mux := http.NewServeMux()
 
 	mux.HandleFunc("POST /api/v1/auth/register", handlers.UserHandler.RegisterHandler)
@@ -19,13 +21,19 @@ func NewRouter(handlers *handler.Handlers) *http.ServeMux {
 		middleware.JWTMiddleware(
 			middleware.RBACMiddleware(handlers.SaleOrderHandler.GetSaleOrdersHandler, constants.RoleCashier, constants.RoleOwner)))
 
+	mux.HandleFunc("GET /api/v1/sale-orders/held",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.SaleOrderHandler.GetHeldSaleOrdersHandler, constants.RoleCashier, constants.RoleOwner)))
+
 	mux.HandleFunc("GET /api/v1/sale-orders/{id}",
 		middleware.JWTMiddleware(
 			middleware.RBACMiddleware(handlers.SaleOrderHandler.GetSaleOrderByIDHandler, constants.RoleCashier, constants.RoleOwner)))
 
 	mux.HandleFunc("POST /api/v1/sale-orders",
 		middleware.JWTMiddleware(
-			middleware.RBACMiddleware(handlers.SaleOrderHandler.CreateSaleOrderHandler, constants.RoleCashier, constants.RoleOwner)))
+			middleware.RBACMiddleware(
+				middleware.IdempotencyMiddleware(handlers.SaleOrderHandler.CreateSaleOrderHandler, services.IdempotencyService),
+				constants.RoleCashier, constants.RoleOwner)))
 
 	mux.HandleFunc("PUT /api/v1/sale-orders/{id}",
 		middleware.JWTMiddleware(
@@ -35,6 +43,34 @@ func NewRouter(handlers *handler.Handlers) *http.ServeMux {
 		middleware.JWTMiddleware(
 			middleware.RBACMiddleware(handlers.SaleOrderHandler.DeleteSaleOrderHandler, constants.RoleCashier, constants.RoleOwner)))
 
+	mux.HandleFunc("POST /api/v1/sale-orders/{id}/hold",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.SaleOrderHandler.HoldSaleOrderHandler, constants.RoleCashier, constants.RoleOwner)))
+
+	mux.HandleFunc("POST /api/v1/sale-orders/{id}/resume",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.SaleOrderHandler.ResumeSaleOrderHandler, constants.RoleCashier, constants.RoleOwner)))
+
+	mux.HandleFunc("POST /api/v1/sale-orders/{id}/take-over",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.SaleOrderHandler.TakeOverSaleOrderHandler, constants.RoleCashier, constants.RoleOwner)))
+
+	mux.HandleFunc("GET /api/v1/sale-orders/{id}/hold-events",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.SaleOrderHandler.GetSaleOrderHoldEventsHandler, constants.RoleCashier, constants.RoleOwner)))
+
+	mux.HandleFunc("GET /api/v1/sale-orders/{id}/revisions",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.SaleOrderHandler.GetSaleOrderRevisionsHandler, constants.RoleCashier, constants.RoleOwner)))
+
+	mux.HandleFunc("GET /api/v1/sale-orders/{id}/revisions/{n}/diff",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.SaleOrderHandler.DiffSaleOrderRevisionHandler, constants.RoleCashier, constants.RoleOwner)))
+
+	mux.HandleFunc("POST /api/v1/sale-orders/{id}/revisions/{n}/restore",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.SaleOrderHandler.RestoreSaleOrderRevisionHandler, constants.RoleOwner)))
+
 	// Cashier
 	mux.HandleFunc("GET /api/v1/users/cashier",
 		middleware.JWTMiddleware(
@@ -56,5 +92,356 @@ func NewRouter(handlers *handler.Handlers) *http.ServeMux {
 		middleware.JWTMiddleware(
 			middleware.RBACMiddleware(handlers.UserHandler.DeleteCashierHandler, constants.RoleOwner)))
 
+	// Product
+	mux.HandleFunc("GET /api/v1/products",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.ProductHandler.GetProductsHandler, constants.RoleCashier, constants.RoleOwner)))
+
+	mux.HandleFunc("GET /api/v1/products/{id}",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.ProductHandler.GetProductByIDHandler, constants.RoleCashier, constants.RoleOwner)))
+
+	mux.HandleFunc("POST /api/v1/products",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.ProductHandler.CreateProductHandler, constants.RoleOwner)))
+
+	mux.HandleFunc("PUT /api/v1/products/{id}",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.ProductHandler.UpdateProductHandler, constants.RoleOwner)))
+
+	mux.HandleFunc("DELETE /api/v1/products/{id}",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.ProductHandler.DeleteProductHandler, constants.RoleOwner)))
+
+	// Promotion
+	mux.HandleFunc("GET /api/v1/promotions",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.PromotionHandler.GetPromotionsHandler, constants.RoleCashier, constants.RoleOwner)))
+
+	mux.HandleFunc("GET /api/v1/promotions/{id}",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.PromotionHandler.GetPromotionByIDHandler, constants.RoleCashier, constants.RoleOwner)))
+
+	mux.HandleFunc("POST /api/v1/promotions",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.PromotionHandler.CreatePromotionHandler, constants.RoleOwner)))
+
+	mux.HandleFunc("PUT /api/v1/promotions/{id}",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.PromotionHandler.UpdatePromotionHandler, constants.RoleOwner)))
+
+	mux.HandleFunc("DELETE /api/v1/promotions/{id}",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.PromotionHandler.DeletePromotionHandler, constants.RoleOwner)))
+
+	// Store
+	mux.HandleFunc("GET /api/v1/stores",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.StoreHandler.GetStoresHandler, constants.RoleCashier, constants.RoleOwner)))
+
+	mux.HandleFunc("GET /api/v1/stores/{id}",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.StoreHandler.GetStoreByIDHandler, constants.RoleCashier, constants.RoleOwner)))
+
+	mux.HandleFunc("POST /api/v1/stores",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.StoreHandler.CreateStoreHandler, constants.RoleOwner)))
+
+	mux.HandleFunc("PUT /api/v1/stores/{id}",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.StoreHandler.UpdateStoreHandler, constants.RoleOwner)))
+
+	mux.HandleFunc("DELETE /api/v1/stores/{id}",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.StoreHandler.DeleteStoreHandler, constants.RoleOwner)))
+
+	// Tax rate
+	mux.HandleFunc("GET /api/v1/tax-rates",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.StoreHandler.GetTaxRatesHandler, constants.RoleCashier, constants.RoleOwner)))
+
+	mux.HandleFunc("GET /api/v1/tax-rates/{id}",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.StoreHandler.GetTaxRateByIDHandler, constants.RoleCashier, constants.RoleOwner)))
+
+	mux.HandleFunc("POST /api/v1/tax-rates",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.StoreHandler.CreateTaxRateHandler, constants.RoleOwner)))
+
+	mux.HandleFunc("PUT /api/v1/tax-rates/{id}",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.StoreHandler.UpdateTaxRateHandler, constants.RoleOwner)))
+
+	mux.HandleFunc("DELETE /api/v1/tax-rates/{id}",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.StoreHandler.DeleteTaxRateHandler, constants.RoleOwner)))
+
+	// Offline sync
+	mux.HandleFunc("POST /api/v1/sync/orders",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.SyncHandler.PushOrdersHandler, constants.RoleCashier, constants.RoleOwner)))
+
+	mux.HandleFunc("GET /api/v1/sync/changes",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.SyncHandler.GetChangesHandler, constants.RoleCashier, constants.RoleOwner)))
+
+	// Quotations
+	mux.HandleFunc("POST /api/v1/quotations",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.QuotationHandler.CreateQuotationHandler, constants.RoleCashier, constants.RoleOwner)))
+
+	mux.HandleFunc("GET /api/v1/quotations",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.QuotationHandler.GetQuotationsHandler, constants.RoleCashier, constants.RoleOwner)))
+
+	mux.HandleFunc("GET /api/v1/quotations/{id}",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.QuotationHandler.GetQuotationByIDHandler, constants.RoleCashier, constants.RoleOwner)))
+
+	mux.HandleFunc("PUT /api/v1/quotations/{id}/status",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.QuotationHandler.UpdateQuotationStatusHandler, constants.RoleCashier, constants.RoleOwner)))
+
+	mux.HandleFunc("POST /api/v1/quotations/{id}/convert",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.QuotationHandler.ConvertQuotationHandler, constants.RoleCashier, constants.RoleOwner)))
+
+	// Invoices and receivables
+	mux.HandleFunc("POST /api/v1/sale-orders/{id}/invoice",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.InvoiceHandler.CreateInvoiceHandler, constants.RoleCashier, constants.RoleOwner)))
+
+	mux.HandleFunc("GET /api/v1/invoices",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.InvoiceHandler.GetInvoicesHandler, constants.RoleCashier, constants.RoleOwner)))
+
+	mux.HandleFunc("GET /api/v1/invoices/{id}",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.InvoiceHandler.GetInvoiceByIDHandler, constants.RoleCashier, constants.RoleOwner)))
+
+	mux.HandleFunc("POST /api/v1/invoices/{id}/payments",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(
+				middleware.IdempotencyMiddleware(handlers.InvoiceHandler.RecordPaymentHandler, services.IdempotencyService),
+				constants.RoleCashier, constants.RoleOwner)))
+
+	mux.HandleFunc("GET /api/v1/reports/receivables-aging",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.InvoiceHandler.GetReceivablesAgingHandler, constants.RoleOwner)))
+
+	// Customer credit accounts
+	mux.HandleFunc("POST /api/v1/customer-accounts",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.CustomerAccountHandler.CreateCustomerAccountHandler, constants.RoleOwner)))
+
+	mux.HandleFunc("GET /api/v1/customer-accounts",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.CustomerAccountHandler.GetCustomerAccountsHandler, constants.RoleCashier, constants.RoleOwner)))
+
+	mux.HandleFunc("GET /api/v1/customer-accounts/{id}",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.CustomerAccountHandler.GetCustomerAccountByIDHandler, constants.RoleCashier, constants.RoleOwner)))
+
+	mux.HandleFunc("PUT /api/v1/customer-accounts/{id}",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.CustomerAccountHandler.UpdateCustomerAccountHandler, constants.RoleOwner)))
+
+	mux.HandleFunc("POST /api/v1/customer-accounts/{id}/repayments",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(
+				middleware.IdempotencyMiddleware(handlers.CustomerAccountHandler.RecordRepaymentHandler, services.IdempotencyService),
+				constants.RoleCashier, constants.RoleOwner)))
+
+	mux.HandleFunc("GET /api/v1/customer-accounts/{id}/statement",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.CustomerAccountHandler.GetStatementHandler, constants.RoleCashier, constants.RoleOwner)))
+
+	mux.HandleFunc("POST /api/v1/sale-orders/{id}/charge-to-account",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(
+				middleware.IdempotencyMiddleware(handlers.CustomerAccountHandler.ChargeToAccountHandler, services.IdempotencyService),
+				constants.RoleCashier, constants.RoleOwner)))
+
+	// Customers
+	mux.HandleFunc("POST /api/v1/customers",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.CustomerHandler.CreateCustomerHandler, constants.RoleCashier, constants.RoleOwner)))
+
+	mux.HandleFunc("GET /api/v1/customers",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.CustomerHandler.GetCustomersHandler, constants.RoleCashier, constants.RoleOwner)))
+
+	mux.HandleFunc("GET /api/v1/customers/{id}",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.CustomerHandler.GetCustomerByIDHandler, constants.RoleCashier, constants.RoleOwner)))
+
+	mux.HandleFunc("PUT /api/v1/customers/{id}",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.CustomerHandler.UpdateCustomerHandler, constants.RoleCashier, constants.RoleOwner)))
+
+	mux.HandleFunc("DELETE /api/v1/customers/{id}",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.CustomerHandler.DeleteCustomerHandler, constants.RoleOwner)))
+
+	mux.HandleFunc("GET /api/v1/customers/{id}/sale-orders",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.CustomerHandler.GetCustomerSaleOrdersHandler, constants.RoleCashier, constants.RoleOwner)))
+
+	mux.HandleFunc("POST /api/v1/customers/{id}/merge",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.CustomerHandler.MergeCustomersHandler, constants.RoleOwner)))
+
+	// Loyalty
+	mux.HandleFunc("GET /api/v1/loyalty/settings",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.LoyaltyHandler.GetLoyaltySettingsHandler, constants.RoleOwner)))
+
+	mux.HandleFunc("PUT /api/v1/loyalty/settings",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.LoyaltyHandler.UpdateLoyaltySettingsHandler, constants.RoleOwner)))
+
+	mux.HandleFunc("GET /api/v1/customers/{id}/loyalty",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.LoyaltyHandler.GetCustomerLoyaltyHandler, constants.RoleCashier, constants.RoleOwner)))
+
+	mux.HandleFunc("GET /api/v1/customers/{id}/loyalty/history",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.LoyaltyHandler.GetCustomerLoyaltyHistoryHandler, constants.RoleCashier, constants.RoleOwner)))
+
+	mux.HandleFunc("POST /api/v1/sale-orders/{id}/loyalty-redemption",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(
+				middleware.IdempotencyMiddleware(handlers.SaleOrderHandler.RedeemPointsHandler, services.IdempotencyService),
+				constants.RoleCashier, constants.RoleOwner)))
+
+	mux.HandleFunc("DELETE /api/v1/sale-orders/{id}/loyalty-redemption",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.SaleOrderHandler.ReleasePointsRedemptionHandler, constants.RoleCashier, constants.RoleOwner)))
+
+	// Gift cards and store credit
+	mux.HandleFunc("POST /api/v1/gift-cards",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(
+				middleware.IdempotencyMiddleware(handlers.GiftCardHandler.IssueGiftCardHandler, services.IdempotencyService),
+				constants.RoleOwner)))
+
+	mux.HandleFunc("GET /api/v1/gift-cards",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.GiftCardHandler.GetGiftCardsHandler, constants.RoleOwner)))
+
+	mux.HandleFunc("GET /api/v1/gift-cards/{code}",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.GiftCardHandler.GetGiftCardByCodeHandler, constants.RoleCashier, constants.RoleOwner)))
+
+	mux.HandleFunc("POST /api/v1/gift-cards/{code}/top-ups",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(
+				middleware.IdempotencyMiddleware(handlers.GiftCardHandler.TopUpGiftCardHandler, services.IdempotencyService),
+				constants.RoleCashier, constants.RoleOwner)))
+
+	mux.HandleFunc("GET /api/v1/gift-cards/{code}/transactions",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.GiftCardHandler.GetGiftCardTransactionsHandler, constants.RoleCashier, constants.RoleOwner)))
+
+	mux.HandleFunc("POST /api/v1/sale-orders/{id}/gift-card-redemptions",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(
+				middleware.IdempotencyMiddleware(handlers.SaleOrderHandler.RedeemGiftCardHandler, services.IdempotencyService),
+				constants.RoleCashier, constants.RoleOwner)))
+
+	mux.HandleFunc("DELETE /api/v1/sale-orders/{id}/gift-card-redemptions",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.SaleOrderHandler.ReleaseGiftCardRedemptionsHandler, constants.RoleCashier, constants.RoleOwner)))
+
+	// Vouchers
+	mux.HandleFunc("POST /api/v1/voucher-campaigns",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.VoucherHandler.CreateVoucherCampaignHandler, constants.RoleOwner)))
+
+	mux.HandleFunc("GET /api/v1/voucher-campaigns",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.VoucherHandler.GetVoucherCampaignsHandler, constants.RoleOwner)))
+
+	mux.HandleFunc("GET /api/v1/voucher-campaigns/{id}",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.VoucherHandler.GetVoucherCampaignHandler, constants.RoleOwner)))
+
+	mux.HandleFunc("GET /api/v1/voucher-campaigns/{id}/codes",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.VoucherHandler.GetVoucherCodesHandler, constants.RoleOwner)))
+
+	mux.HandleFunc("POST /api/v1/vouchers/validate",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.VoucherHandler.ValidateVoucherHandler, constants.RoleCashier, constants.RoleOwner)))
+
+	mux.HandleFunc("POST /api/v1/sale-orders/{id}/voucher",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.SaleOrderHandler.ApplyVoucherHandler, constants.RoleCashier, constants.RoleOwner)))
+
+	mux.HandleFunc("DELETE /api/v1/sale-orders/{id}/voucher",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.SaleOrderHandler.RemoveVoucherHandler, constants.RoleCashier, constants.RoleOwner)))
+
+	// Receipts
+	mux.HandleFunc("GET /api/v1/sale-orders/{id}/receipt",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.ReceiptHandler.GetReceiptHandler, constants.RoleCashier, constants.RoleOwner)))
+
+	// Public receipt check, opened from the QR code on a receipt
+	receiptLimiter := middleware.NewRateLimiter(middleware.RateLimitFromEnv("RECEIPT_VERIFY_RATE_LIMIT", 30), time.Minute)
+
+	mux.HandleFunc("GET /r/{token}", middleware.RateLimitMiddleware(handlers.ReceiptHandler.VerifyReceiptHandler, receiptLimiter))
+
+	// Emails
+	mux.HandleFunc("POST /api/v1/sale-orders/{id}/receipt/email",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.EmailHandler.SendReceiptEmailHandler, constants.RoleCashier, constants.RoleOwner)))
+
+	mux.HandleFunc("GET /api/v1/sale-orders/{id}/emails",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.EmailHandler.GetSaleOrderEmailMessagesHandler, constants.RoleCashier, constants.RoleOwner)))
+
+	mux.HandleFunc("GET /api/v1/emails",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.EmailHandler.GetEmailMessagesHandler, constants.RoleOwner)))
+
+	mux.HandleFunc("POST /api/v1/emails/{id}/retry",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.EmailHandler.RetryEmailMessageHandler, constants.RoleOwner)))
+
+	// Webhooks
+	mux.HandleFunc("POST /api/v1/webhooks",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.WebhookHandler.CreateWebhookEndpointHandler, constants.RoleOwner)))
+
+	mux.HandleFunc("GET /api/v1/webhooks",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.WebhookHandler.GetWebhookEndpointsHandler, constants.RoleOwner)))
+
+	mux.HandleFunc("GET /api/v1/webhooks/{id}",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.WebhookHandler.GetWebhookEndpointHandler, constants.RoleOwner)))
+
+	mux.HandleFunc("PUT /api/v1/webhooks/{id}",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.WebhookHandler.UpdateWebhookEndpointHandler, constants.RoleOwner)))
+
+	mux.HandleFunc("DELETE /api/v1/webhooks/{id}",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.WebhookHandler.DeleteWebhookEndpointHandler, constants.RoleOwner)))
+
+	mux.HandleFunc("GET /api/v1/webhooks/{id}/deliveries",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.WebhookHandler.GetWebhookDeliveriesHandler, constants.RoleOwner)))
+
+	mux.HandleFunc("POST /api/v1/webhook-deliveries/{id}/redeliver",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.WebhookHandler.RedeliverWebhookDeliveryHandler, constants.RoleOwner)))
+
+	// Live feeds
+	mux.HandleFunc("GET /api/v1/stream/sale-orders",
+		middleware.JWTMiddleware(
+			middleware.RBACMiddleware(handlers.StreamHandler.StreamSaleOrdersHandler, constants.RoleCashier, constants.RoleOwner)))
+
 	return mux
 }
\ No newline at end of file
@@ -0,0 +1,8 @@
+package constants
+
+// Types of customer account ledger entries.
+const (
+	CustomerAccountEntryCharge    = "charge"
+	CustomerAccountEntryRepayment = "repayment"
+	CustomerAccountEntryReversal  = "reversal"
+)
@@ -0,0 +1,14 @@
+package constants
+
+// Kinds of emails sent about a sale order.
+const (
+	EmailKindReceipt = "receipt"
+	EmailKindRefund  = "refund"
+)
+
+// Delivery statuses of an email in the outbox.
+const (
+	EmailStatusPending = "pending"
+	EmailStatusSent    = "sent"
+	EmailStatusFailed  = "failed"
+)
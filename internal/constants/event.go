@@ -0,0 +1,32 @@
+package constants
+
+// Domain events raised by changes to sale orders and cashiers. Webhook
+// endpoints subscribe to them by the same names.
+const (
+	EventSaleOrderCreated       = "sale_order.created"
+	EventSaleOrderUpdated       = "sale_order.updated"
+	EventSaleOrderStatusChanged = "sale_order.status_changed"
+	EventSaleOrderDeleted       = "sale_order.deleted"
+	EventCashierCreated         = "cashier.created"
+	EventCashierUpdated         = "cashier.updated"
+	EventCashierDeleted         = "cashier.deleted"
+)
+
+// Dispatch statuses of a domain event. Failed events kept failing in a
+// handler and are no longer retried.
+const (
+	EventStatusPending    = "pending"
+	EventStatusDispatched = "dispatched"
+	EventStatusFailed     = "failed"
+)
+
+// StreamEventReset tells a client of a live feed that it missed more than can
+// be replayed and has to fetch the current state again.
+const StreamEventReset = "reset"
+
+// Reasons given with a reset: the client missed more events than are
+// replayed, or some of them were already purged.
+const (
+	StreamResetReplayLimit = "replay_limit"
+	StreamResetPurged      = "purged"
+)
@@ -0,0 +1,15 @@
+package constants
+
+// Types of gift cards.
+const (
+	GiftCardTypeGiftCard    = "gift_card"
+	GiftCardTypeStoreCredit = "store_credit"
+)
+
+// Types of gift card ledger transactions.
+const (
+	GiftCardTransactionIssue  = "issue"
+	GiftCardTransactionTopUp  = "top_up"
+	GiftCardTransactionRedeem = "redeem"
+	GiftCardTransactionRefund = "refund"
+)
@@ -0,0 +1,16 @@
+package constants
+
+const (
+	InvoiceStatusOpen          = "open"
+	InvoiceStatusPartiallyPaid = "partially_paid"
+	InvoiceStatusPaid          = "paid"
+	InvoiceStatusOverdue       = "overdue"
+)
+
+// Payment methods accepted on invoice payments.
+const (
+	InvoicePaymentMethodCash         = "cash"
+	InvoicePaymentMethodBankTransfer = "bank_transfer"
+	InvoicePaymentMethodCard         = "card"
+	InvoicePaymentMethodOther        = "other"
+)
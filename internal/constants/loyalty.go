@@ -0,0 +1,10 @@
+package constants
+
+// Types of loyalty points ledger entries.
+const (
+	LoyaltyEntryEarn    = "earn"
+	LoyaltyEntryRedeem  = "redeem"
+	LoyaltyEntryReverse = "reverse"
+	LoyaltyEntryRestore = "restore"
+	LoyaltyEntryExpire  = "expire"
+)
@@ -23,6 +23,11 @@ const (
 	MsgSuccessUpdate   = "updated successfully"
 	MsgSuccessDelete   = "deleted successfully"
 	MsgSuccessRetrieve = "retrieved successfully"
+	MsgSuccessSync     = "sync processed"
+	MsgSuccessRestore  = "restored successfully"
+	MsgSuccessConvert  = "converted successfully"
+	MsgSuccessMerge    = "merged successfully"
+	MsgSuccessQueued   = "queued successfully"
 )
 
 const (
@@ -35,3 +40,66 @@ const (
 	MsgAccessDenied      = "access denied"
 	MsgInvalidPagination = "invalid pagination parameters"
 )
+
+const (
+	MsgSKUAlreadyExists            = "sku already exists"
+	MsgInvalidProduct              = "invalid product"
+	MsgInvalidSaleOrderItem        = "invalid sale order item"
+	MsgInvalidPromotion            = "invalid promotion"
+	MsgPromotionUsageLimitReached  = "promotion usage limit reached, please retry"
+	MsgStoreCodeAlreadyExists      = "store code already exists"
+	MsgInvalidStore                = "invalid store"
+	MsgTaxRateAlreadyExists        = "tax rate already exists for this store and category"
+	MsgInvalidTaxRate              = "invalid tax rate"
+	MsgInvalidOrderNumberFormat    = "invalid order number format"
+	MsgOrderNumberConflict         = "could not assign an order number, please retry"
+	MsgInvalidFilter               = "invalid filter or sort parameters"
+	MsgPreconditionRequired        = "If-Match header is required"
+	MsgVersionMismatch             = "resource was modified by another request, reload and retry"
+	MsgIdempotencyKeyMismatch      = "idempotency key was already used with a different request"
+	MsgInvalidIdempotencyKey       = "invalid idempotency key"
+	MsgRequestBodyTooLarge         = "request body is too large"
+	MsgInvalidSyncBatch            = "invalid sync batch"
+	MsgSaleOrderNotDraft           = "sale order is not a draft"
+	MsgSaleOrderNotHeld            = "sale order is not held"
+	MsgSaleOrderHeld               = "sale order is held, resume it first"
+	MsgInvalidSaleOrderStatus      = "status must be draft, completed or cancelled, orders are held with /hold"
+	MsgInvalidHold                 = "invalid hold request"
+	MsgInvalidQuotation            = "invalid quotation"
+	MsgInvalidQuotationStatus      = "quotation status cannot be changed this way"
+	MsgQuotationNotAccepted        = "only accepted quotations can be converted"
+	MsgQuotationAlreadyConverted   = "quotation was already converted into a sale order"
+	MsgQuotationPriceChanged       = "prices changed since the quotation was sent, confirm with accept_price_changes"
+	MsgQuotationProductUnavailable = "a quoted product is no longer available"
+	MsgInvalidInvoice              = "invalid invoice, payment terms must be NET 7, 14 or 30"
+	MsgInvoiceAlreadyExists        = "sale order is already invoiced"
+	MsgSaleOrderNotCompleted       = "only completed sale orders can be invoiced"
+	MsgInvalidPayment              = "invalid payment"
+	MsgPaymentExceedsBalance       = "payment exceeds the invoice balance"
+	MsgInvalidCustomerAccount      = "invalid customer account"
+	MsgInvalidCharge               = "sale order cannot be charged to this account"
+	MsgCreditLimitExceeded         = "charge exceeds the available credit, an owner must approve it"
+	MsgSaleOrderAlreadyCharged     = "sale order is already charged to an account"
+	MsgRepaymentExceedsBalance     = "repayment exceeds the account balance"
+	MsgInvalidCustomer             = "invalid customer"
+	MsgInvalidCustomerMerge        = "invalid merge, list existing duplicates other than the surviving customer"
+	MsgInvalidLoyaltySettings      = "invalid loyalty settings"
+	MsgLoyaltyDisabled             = "loyalty program is disabled"
+	MsgInvalidRedemption           = "points cannot be redeemed on this sale order"
+	MsgInsufficientPoints          = "customer does not have enough points"
+	MsgInvalidGiftCard             = "invalid gift card"
+	MsgGiftCardExpired             = "gift card has expired"
+	MsgInvalidGiftCardRedemption   = "gift card cannot be redeemed on this sale order"
+	MsgInsufficientGiftCardBalance = "gift card balance is too low"
+	MsgRedemptionsExceedTotal      = "points and gift cards pay more than the sale order total, release them first"
+	MsgInvalidVoucherCampaign      = "invalid voucher campaign"
+	MsgVoucherCodeConflict         = "voucher code already exists"
+	MsgVoucherNotApplicable        = "voucher code cannot be used on this sale order"
+	MsgInvalidCashTendered         = "cash tendered does not cover the amount due"
+	MsgInvalidReceiptFormat        = "receipt format must be text, escpos or pdf and paper 58mm or 80mm"
+	MsgTooManyRequests             = "too many requests, try again later"
+	MsgInvalidEmailRecipient       = "a valid email address is required when the customer has none"
+	MsgEmailNotRetryable           = "only emails that failed can be retried"
+	MsgInvalidWebhookEndpoint      = "webhook url must be an absolute http or https url and events must be known"
+	MsgWebhookEndpointInactive     = "webhook endpoint is inactive"
+)
@@ -4,4 +4,10 @@ package constants
 
 const (
 	UniqueConstraintViolationErrorCode = "23505"
+	SaleOrderOrderNumberConstraint     = "sale_orders_order_number_key"
+	SaleOrderPrimaryKeyConstraint      = "sale_orders_pkey"
+	InvoiceSaleOrderConstraint         = "invoices_sale_order_id_key"
+	CustomerAccountChargeConstraint    = "customer_account_entries_sale_order_id_key"
+	GiftCardCodeConstraint             = "gift_cards_code_key"
+	VoucherCodeConstraint              = "vouchers_code_key"
 )
\ No newline at end of file
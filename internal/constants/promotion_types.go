@@ -0,0 +1,7 @@
+package constants
+
+const (
+	PromotionTypeBuyXGetY    = "buy_x_get_y"
+	PromotionTypePercentage  = "percentage"
+	PromotionTypeFixedAmount = "fixed_amount"
+)
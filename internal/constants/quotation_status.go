@@ -0,0 +1,8 @@
+package constants
+
+const (
+	QuotationStatusSent     = "sent"
+	QuotationStatusAccepted = "accepted"
+	QuotationStatusRejected = "rejected"
+	QuotationStatusExpired  = "expired"
+)
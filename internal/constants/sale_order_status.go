@@ -0,0 +1,22 @@
+package constants
+
+const (
+	SaleOrderStatusDraft     = "draft"
+	SaleOrderStatusCompleted = "completed"
+	SaleOrderStatusCancelled = "cancelled"
+	SaleOrderStatusHeld      = "held"
+)
+
+// Actions recorded in the hold audit trail of a sale order.
+const (
+	HoldActionHold     = "hold"
+	HoldActionResume   = "resume"
+	HoldActionTakeOver = "take_over"
+	HoldActionExpire   = "expire"
+)
+
+// Visibility of sale orders for cashiers, set with CASHIER_ORDER_SCOPE.
+const (
+	CashierOrderScopeOwn   = "own"
+	CashierOrderScopeStore = "store"
+)
@@ -0,0 +1,9 @@
+package constants
+
+// Outcomes of an order uploaded through the offline sync endpoint.
+const (
+	SyncResultCreated   = "created"
+	SyncResultDuplicate = "duplicate"
+	SyncResultConflict  = "conflict"
+	SyncResultRejected  = "rejected"
+)
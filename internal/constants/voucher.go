@@ -0,0 +1,21 @@
+package constants
+
+// Types of voucher discounts.
+const (
+	VoucherDiscountPercentage  = "percentage"
+	VoucherDiscountFixedAmount = "fixed_amount"
+)
+
+// Reasons a voucher code cannot be used, reported by the validate endpoint
+// and when applying a code.
+const (
+	VoucherRejectNotFound         = "not_found"
+	VoucherRejectNotStarted       = "not_started"
+	VoucherRejectExpired          = "expired"
+	VoucherRejectCurrency         = "currency_mismatch"
+	VoucherRejectUsedUp           = "usage_limit_reached"
+	VoucherRejectCustomerRequired = "customer_required"
+	VoucherRejectCustomerLimit    = "customer_limit_reached"
+	VoucherRejectMinSpend         = "min_spend_not_met"
+	VoucherRejectNoItems          = "no_items"
+)
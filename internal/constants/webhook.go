@@ -0,0 +1,9 @@
+package constants
+
+// Delivery statuses of a webhook event to an endpoint. Dead deliveries failed
+// too often to be retried and wait to be redelivered by hand.
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryDead      = "dead"
+)
@@ -0,0 +1,89 @@
+package dto
+
+import (
+	"github.com/google/uuid"
+	"github.com/hafiztri123/kki-be/internal/money"
+)
+
+// CreateCustomerAccountRequest opens a credit account. An empty Currency
+// means the default currency. With CustomerID set, an empty CustomerName
+// takes the name of the customer.
+type CreateCustomerAccountRequest struct {
+	CustomerID   *uuid.UUID   `json:"customer_id"`
+	CustomerName string       `json:"customer_name"`
+	Phone        string       `json:"phone"`
+	Currency     string       `json:"currency"`
+	CreditLimit  money.Amount `json:"credit_limit"`
+}
+
+type UpdateCustomerAccountRequest struct {
+	CustomerID   *uuid.UUID   `json:"customer_id"`
+	CustomerName string       `json:"customer_name"`
+	Phone        string       `json:"phone"`
+	CreditLimit  money.Amount `json:"credit_limit"`
+}
+
+// ChargeToAccountRequest settles a draft sale order on a customer's account.
+// ApproveOverLimit lets an owner charge past the credit limit; it is refused
+// for cashiers.
+type ChargeToAccountRequest struct {
+	AccountID        uuid.UUID `json:"account_id"`
+	ApproveOverLimit bool      `json:"approve_over_limit"`
+}
+
+type CreateRepaymentRequest struct {
+	Amount    money.Amount `json:"amount"`
+	Method    string       `json:"method"`
+	Reference string       `json:"reference"`
+}
+
+type CustomerAccountResponse struct {
+	ID              uuid.UUID      `json:"id"`
+	CustomerID      *uuid.UUID     `json:"customer_id,omitempty"`
+	CustomerName    string         `json:"customer_name"`
+	Phone           string         `json:"phone,omitempty"`
+	Currency        money.Currency `json:"currency"`
+	CreditLimit     money.Amount   `json:"credit_limit"`
+	Balance         money.Amount   `json:"balance"`
+	AvailableCredit money.Amount   `json:"available_credit"`
+	CreatedBy       uuid.UUID      `json:"created_by"`
+	CreatedAt       string         `json:"created_at"`
+	UpdatedAt       string         `json:"updated_at"`
+}
+
+type CustomerAccountEntryResponse struct {
+	ID           uuid.UUID    `json:"id"`
+	EntryType    string       `json:"entry_type"`
+	Amount       money.Amount `json:"amount"`
+	BalanceAfter money.Amount `json:"balance_after"`
+	SaleOrderID  *uuid.UUID   `json:"sale_order_id,omitempty"`
+	Method       string       `json:"method,omitempty"`
+	Reference    string       `json:"reference,omitempty"`
+	ApprovedBy   *uuid.UUID   `json:"approved_by,omitempty"`
+	RecordedBy   *uuid.UUID   `json:"recorded_by,omitempty"`
+	CreatedAt    string       `json:"created_at"`
+}
+
+// CustomerAccountStatementResponse is the ledger of an account over a period,
+// with the balance owed before and after it. Empty From and To mean the
+// period is open on that side.
+type CustomerAccountStatementResponse struct {
+	Account         CustomerAccountResponse        `json:"account"`
+	From            string                         `json:"from,omitempty"`
+	To              string                         `json:"to,omitempty"`
+	OpeningBalance  money.Amount                   `json:"opening_balance"`
+	TotalCharges    money.Amount                   `json:"total_charges"`
+	TotalRepayments money.Amount                   `json:"total_repayments"`
+	TotalReversals  money.Amount                   `json:"total_reversals"`
+	ClosingBalance  money.Amount                   `json:"closing_balance"`
+	Entries         []CustomerAccountEntryResponse `json:"entries"`
+}
+
+// CreditLimitExceededResponse is returned with a refused charge so the
+// cashier can tell the customer how much credit is left.
+type CreditLimitExceededResponse struct {
+	CreditLimit     money.Amount `json:"credit_limit"`
+	Balance         money.Amount `json:"balance"`
+	AvailableCredit money.Amount `json:"available_credit"`
+	ChargeAmount    money.Amount `json:"charge_amount"`
+}
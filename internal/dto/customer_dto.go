@@ -0,0 +1,51 @@
+package dto
+
+import (
+	"github.com/google/uuid"
+	"github.com/hafiztri123/kki-be/internal/money"
+)
+
+type CustomerRequest struct {
+	Name    string   `json:"name"`
+	Phone   string   `json:"phone"`
+	Email   string   `json:"email"`
+	Address string   `json:"address"`
+	Tags    []string `json:"tags"`
+	Notes   string   `json:"notes"`
+}
+
+// MergeCustomersRequest lists the duplicates folded into the customer named
+// in the path.
+type MergeCustomersRequest struct {
+	DuplicateIDs []uuid.UUID `json:"duplicate_ids"`
+}
+
+// CustomerValueResponse is the lifetime value of a customer in one currency,
+// counting completed orders only.
+type CustomerValueResponse struct {
+	Currency      money.Currency `json:"currency"`
+	OrderCount    int64          `json:"order_count"`
+	LifetimeValue money.Amount   `json:"lifetime_value"`
+	FirstOrderAt  string         `json:"first_order_at"`
+	LastOrderAt   string         `json:"last_order_at"`
+}
+
+type CustomerResponse struct {
+	ID        uuid.UUID               `json:"id"`
+	Name      string                  `json:"name"`
+	Phone     string                  `json:"phone,omitempty"`
+	Email     string                  `json:"email,omitempty"`
+	Address   string                  `json:"address,omitempty"`
+	Tags      []string                `json:"tags"`
+	Notes     string                  `json:"notes,omitempty"`
+	CreatedBy uuid.UUID               `json:"created_by"`
+	CreatedAt string                  `json:"created_at"`
+	UpdatedAt string                  `json:"updated_at"`
+	Values    []CustomerValueResponse `json:"lifetime_values,omitempty"`
+}
+
+type MergeCustomersResponse struct {
+	Customer        CustomerResponse `json:"customer"`
+	MergedIDs       []uuid.UUID      `json:"merged_ids"`
+	MovedSaleOrders int64            `json:"moved_sale_orders"`
+}
@@ -0,0 +1,24 @@
+package dto
+
+import "github.com/google/uuid"
+
+// SendReceiptEmailRequest emails the receipt of a sale order. An empty Email
+// sends it to the email of the order's customer.
+type SendReceiptEmailRequest struct {
+	Email string `json:"email"`
+}
+
+type EmailMessageResponse struct {
+	ID            uuid.UUID  `json:"id"`
+	Kind          string     `json:"kind"`
+	SaleOrderID   uuid.UUID  `json:"sale_order_id"`
+	Recipient     string     `json:"recipient"`
+	Subject       string     `json:"subject,omitempty"`
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	LastError     string     `json:"last_error,omitempty"`
+	NextAttemptAt string     `json:"next_attempt_at,omitempty"`
+	SentAt        string     `json:"sent_at,omitempty"`
+	CreatedBy     *uuid.UUID `json:"created_by,omitempty"`
+	CreatedAt     string     `json:"created_at"`
+}
@@ -0,0 +1,71 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hafiztri123/kki-be/internal/money"
+)
+
+// IssueGiftCardRequest issues a gift card or a store credit balance. Store
+// credit belongs to a customer. An empty Currency means the default currency
+// and a nil ExpiresAt means the card does not expire.
+type IssueGiftCardRequest struct {
+	CardType   string       `json:"card_type"`
+	Amount     money.Amount `json:"amount"`
+	Currency   string       `json:"currency"`
+	ExpiresAt  *time.Time   `json:"expires_at"`
+	CustomerID *uuid.UUID   `json:"customer_id"`
+}
+
+type TopUpGiftCardRequest struct {
+	Amount    money.Amount `json:"amount"`
+	Method    string       `json:"method"`
+	Reference string       `json:"reference"`
+}
+
+type GiftCardResponse struct {
+	ID            uuid.UUID      `json:"id"`
+	Code          string         `json:"code"`
+	CardType      string         `json:"card_type"`
+	Currency      money.Currency `json:"currency"`
+	InitialAmount money.Amount   `json:"initial_amount"`
+	Balance       money.Amount   `json:"balance"`
+	ExpiresAt     string         `json:"expires_at,omitempty"`
+	Expired       bool           `json:"expired"`
+	CustomerID    *uuid.UUID     `json:"customer_id,omitempty"`
+	IssuedBy      uuid.UUID      `json:"issued_by"`
+	CreatedAt     string         `json:"created_at"`
+	UpdatedAt     string         `json:"updated_at"`
+}
+
+type GiftCardTransactionResponse struct {
+	ID              uuid.UUID    `json:"id"`
+	TransactionType string       `json:"transaction_type"`
+	Amount          money.Amount `json:"amount"`
+	BalanceAfter    money.Amount `json:"balance_after"`
+	SaleOrderID     *uuid.UUID   `json:"sale_order_id,omitempty"`
+	Method          string       `json:"method,omitempty"`
+	Reference       string       `json:"reference,omitempty"`
+	RecordedBy      *uuid.UUID   `json:"recorded_by,omitempty"`
+	CreatedAt       string       `json:"created_at"`
+}
+
+// RedeemGiftCardRequest pays part of a sale order with a card. A zero Amount
+// takes as much as the card and the amount due allow.
+type RedeemGiftCardRequest struct {
+	Code   string       `json:"code"`
+	Amount money.Amount `json:"amount"`
+}
+
+// GiftCardRedemptionResponse shows what a card paid on a sale order. Like
+// points, cards are a tender: the order total is unchanged and AmountDue is
+// what is left to pay by other means.
+type GiftCardRedemptionResponse struct {
+	SaleOrderID    uuid.UUID    `json:"sale_order_id"`
+	Code           string       `json:"code"`
+	RedeemedAmount money.Amount `json:"redeemed_amount"`
+	CardBalance    money.Amount `json:"card_balance"`
+	TotalAmount    money.Amount `json:"total_amount"`
+	AmountDue      money.Amount `json:"amount_due"`
+}
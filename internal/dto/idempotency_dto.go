@@ -0,0 +1,9 @@
+package dto
+
+// IdempotentResponse is a response stored under an Idempotency-Key and
+// replayed to retries of the same request.
+type IdempotentResponse struct {
+	Status      int
+	ContentType string
+	Body        []byte
+}
@@ -0,0 +1,66 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hafiztri123/kki-be/internal/money"
+)
+
+// CreateInvoiceRequest bills a completed sale order on NET terms, in days.
+type CreateInvoiceRequest struct {
+	PaymentTermDays int `json:"payment_term_days"`
+}
+
+// CreateInvoicePaymentRequest records money received against an invoice. A
+// nil PaidAt means now.
+type CreateInvoicePaymentRequest struct {
+	Amount    money.Amount `json:"amount"`
+	Method    string       `json:"method"`
+	Reference string       `json:"reference"`
+	PaidAt    *time.Time   `json:"paid_at"`
+}
+
+type InvoicePaymentResponse struct {
+	ID         uuid.UUID    `json:"id"`
+	Amount     money.Amount `json:"amount"`
+	Method     string       `json:"method"`
+	Reference  string       `json:"reference,omitempty"`
+	PaidAt     string       `json:"paid_at"`
+	RecordedBy uuid.UUID    `json:"recorded_by"`
+}
+
+type InvoiceResponse struct {
+	ID              uuid.UUID                `json:"id"`
+	InvoiceNumber   string                   `json:"invoice_number"`
+	SaleOrderID     uuid.UUID                `json:"sale_order_id"`
+	CustomerName    string                   `json:"customer_name"`
+	StoreID         *uuid.UUID               `json:"store_id,omitempty"`
+	Currency        money.Currency           `json:"currency"`
+	TotalAmount     money.Amount             `json:"total_amount"`
+	PaidAmount      money.Amount             `json:"paid_amount"`
+	BalanceAmount   money.Amount             `json:"balance_amount"`
+	PaymentTermDays int                      `json:"payment_term_days"`
+	IssuedAt        string                   `json:"issued_at"`
+	DueAt           string                   `json:"due_at"`
+	Status          string                   `json:"status"`
+	CreatedBy       uuid.UUID                `json:"created_by"`
+	CreatedAt       string                   `json:"created_at"`
+	UpdatedAt       string                   `json:"updated_at"`
+	Payments        []InvoicePaymentResponse `json:"payments,omitempty"`
+}
+
+type ReceivablesAgingRow struct {
+	CustomerName string         `json:"customer_name"`
+	Currency     money.Currency `json:"currency"`
+	Days0To30    money.Amount   `json:"days_0_30"`
+	Days31To60   money.Amount   `json:"days_31_60"`
+	Days61To90   money.Amount   `json:"days_61_90"`
+	Days90Plus   money.Amount   `json:"days_90_plus"`
+	Total        money.Amount   `json:"total"`
+}
+
+type ReceivablesAgingResponse struct {
+	AsOf      string                `json:"as_of"`
+	Customers []ReceivablesAgingRow `json:"customers"`
+}
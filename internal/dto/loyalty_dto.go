@@ -0,0 +1,64 @@
+package dto
+
+import (
+	"github.com/google/uuid"
+	"github.com/hafiztri123/kki-be/internal/money"
+)
+
+// LoyaltySettingsRequest replaces the earn and redeem rule. Categories left
+// out of CategoryMultipliers earn at 1x.
+type LoyaltySettingsRequest struct {
+	Enabled             bool               `json:"enabled"`
+	SpendPerPoint       money.Amount       `json:"spend_per_point"`
+	PointValue          money.Amount       `json:"point_value"`
+	ExpiryDays          int                `json:"expiry_days"`
+	MinRedeemPoints     int64              `json:"min_redeem_points"`
+	CategoryMultipliers map[string]float64 `json:"category_multipliers"`
+}
+
+type LoyaltySettingsResponse struct {
+	Enabled             bool               `json:"enabled"`
+	SpendPerPoint       money.Amount       `json:"spend_per_point"`
+	PointValue          money.Amount       `json:"point_value"`
+	ExpiryDays          int                `json:"expiry_days"`
+	MinRedeemPoints     int64              `json:"min_redeem_points"`
+	CategoryMultipliers map[string]float64 `json:"category_multipliers"`
+	UpdatedBy           *uuid.UUID         `json:"updated_by,omitempty"`
+	UpdatedAt           string             `json:"updated_at"`
+}
+
+type LoyaltyBalanceResponse struct {
+	CustomerID       uuid.UUID    `json:"customer_id"`
+	Points           int64        `json:"points"`
+	PointsValue      money.Amount `json:"points_value"`
+	NextExpiryPoints int64        `json:"next_expiry_points,omitempty"`
+	NextExpiryAt     string       `json:"next_expiry_at,omitempty"`
+}
+
+type LoyaltyEntryResponse struct {
+	ID              uuid.UUID    `json:"id"`
+	EntryType       string       `json:"entry_type"`
+	Points          int64        `json:"points"`
+	RemainingPoints int64        `json:"remaining_points,omitempty"`
+	ExpiresAt       string       `json:"expires_at,omitempty"`
+	SaleOrderID     *uuid.UUID   `json:"sale_order_id,omitempty"`
+	Amount          money.Amount `json:"amount,omitempty"`
+	CreatedBy       *uuid.UUID   `json:"created_by,omitempty"`
+	CreatedAt       string       `json:"created_at"`
+}
+
+type RedeemPointsRequest struct {
+	Points int64 `json:"points"`
+}
+
+// LoyaltyRedemptionResponse shows what points pay on a sale order. Points are
+// a tender: the order total is unchanged and AmountDue is what is left to
+// pay by other means.
+type LoyaltyRedemptionResponse struct {
+	SaleOrderID    uuid.UUID    `json:"sale_order_id"`
+	PointsRedeemed int64        `json:"points_redeemed"`
+	RedeemedAmount money.Amount `json:"redeemed_amount"`
+	TotalAmount    money.Amount `json:"total_amount"`
+	AmountDue      money.Amount `json:"amount_due"`
+	PointsBalance  int64        `json:"points_balance"`
+}
@@ -0,0 +1,25 @@
+package dto
+
+import (
+	"github.com/google/uuid"
+	"github.com/hafiztri123/kki-be/internal/money"
+)
+
+type CreateProductRequest struct {
+	SKU       string       `json:"sku"`
+	Name      string       `json:"name"`
+	Category  string       `json:"category"`
+	UnitPrice money.Amount `json:"unit_price"`
+}
+
+type UpdateProductRequest = CreateProductRequest
+
+type ProductResponse struct {
+	ID        uuid.UUID    `json:"id"`
+	SKU       string       `json:"sku"`
+	Name      string       `json:"name"`
+	Category  string       `json:"category"`
+	UnitPrice money.Amount `json:"unit_price"`
+	CreatedAt string       `json:"created_at"`
+	UpdatedAt string       `json:"updated_at"`
+}
@@ -0,0 +1,56 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hafiztri123/kki-be/internal/money"
+)
+
+type CreatePromotionRequest struct {
+	Name        string        `json:"name"`
+	Type        string        `json:"type"`
+	ProductID   *uuid.UUID    `json:"product_id"`
+	Category    *string       `json:"category"`
+	BuyQuantity *int          `json:"buy_quantity"`
+	GetQuantity *int          `json:"get_quantity"`
+	Percentage  *float64      `json:"percentage"`
+	Amount      *money.Amount `json:"amount"`
+	MinSpend    money.Amount  `json:"min_spend"`
+	StartsAt    time.Time     `json:"starts_at"`
+	EndsAt      time.Time     `json:"ends_at"`
+	Priority    int           `json:"priority"`
+	Stackable   *bool         `json:"stackable"`
+	UsageLimit  *int          `json:"usage_limit"`
+	IsActive    *bool         `json:"is_active"`
+}
+
+type UpdatePromotionRequest = CreatePromotionRequest
+
+type PromotionResponse struct {
+	ID          uuid.UUID     `json:"id"`
+	Name        string        `json:"name"`
+	Type        string        `json:"type"`
+	ProductID   *uuid.UUID    `json:"product_id,omitempty"`
+	Category    *string       `json:"category,omitempty"`
+	BuyQuantity *int          `json:"buy_quantity,omitempty"`
+	GetQuantity *int          `json:"get_quantity,omitempty"`
+	Percentage  *float64      `json:"percentage,omitempty"`
+	Amount      *money.Amount `json:"amount,omitempty"`
+	MinSpend    money.Amount  `json:"min_spend"`
+	StartsAt    string        `json:"starts_at"`
+	EndsAt      string        `json:"ends_at"`
+	Priority    int           `json:"priority"`
+	Stackable   bool          `json:"stackable"`
+	UsageLimit  *int          `json:"usage_limit,omitempty"`
+	UsageCount  int           `json:"usage_count"`
+	IsActive    bool          `json:"is_active"`
+	CreatedAt   string        `json:"created_at"`
+	UpdatedAt   string        `json:"updated_at"`
+}
+
+type AppliedPromotionResponse struct {
+	PromotionID    uuid.UUID    `json:"promotion_id"`
+	PromotionName  string       `json:"promotion_name"`
+	DiscountAmount money.Amount `json:"discount_amount"`
+}
@@ -0,0 +1,85 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hafiztri123/kki-be/internal/money"
+)
+
+// CreateQuotationRequest prices a quote the way a sale order would be priced
+// now. A nil ValidUntil gives the quote the default validity.
+type CreateQuotationRequest struct {
+	CustomerName          string                 `json:"customer_name"`
+	StoreID               *uuid.UUID             `json:"store_id"`
+	TaxExempt             bool                   `json:"tax_exempt"`
+	TaxExemptionReference string                 `json:"tax_exemption_reference"`
+	ValidUntil            *time.Time             `json:"valid_until"`
+	Items                 []SaleOrderItemRequest `json:"items"`
+}
+
+// UpdateQuotationStatusRequest records the customer's answer to a sent quote,
+// either "accepted" or "rejected".
+type UpdateQuotationStatusRequest struct {
+	Status string `json:"status"`
+}
+
+// ConvertQuotationRequest confirms a conversion whose prices changed since the
+// quote was sent.
+type ConvertQuotationRequest struct {
+	AcceptPriceChanges bool `json:"accept_price_changes"`
+}
+
+type QuotationItemResponse struct {
+	ProductID      uuid.UUID    `json:"product_id"`
+	ProductName    string       `json:"product_name"`
+	Category       string       `json:"category"`
+	Quantity       int          `json:"quantity"`
+	UnitPrice      money.Amount `json:"unit_price"`
+	SubtotalAmount money.Amount `json:"subtotal_amount"`
+	DiscountAmount money.Amount `json:"discount_amount"`
+	TotalAmount    money.Amount `json:"total_amount"`
+	TaxRate        float64      `json:"tax_rate"`
+	TaxableAmount  money.Amount `json:"taxable_amount"`
+	TaxAmount      money.Amount `json:"tax_amount"`
+}
+
+type QuotationResponse struct {
+	ID                    uuid.UUID               `json:"id"`
+	CustomerName          string                  `json:"customer_name"`
+	StoreID               *uuid.UUID              `json:"store_id,omitempty"`
+	Currency              money.Currency          `json:"currency"`
+	PriceIncludesTax      bool                    `json:"price_includes_tax"`
+	TaxExempt             bool                    `json:"tax_exempt"`
+	TaxExemptionReference string                  `json:"tax_exemption_reference,omitempty"`
+	SubtotalAmount        money.Amount            `json:"subtotal_amount"`
+	DiscountAmount        money.Amount            `json:"discount_amount"`
+	TaxAmount             money.Amount            `json:"tax_amount"`
+	TotalAmount           money.Amount            `json:"total_amount"`
+	Status                string                  `json:"status"`
+	ValidUntil            string                  `json:"valid_until"`
+	SaleOrderID           *uuid.UUID              `json:"sale_order_id,omitempty"`
+	CreatedBy             uuid.UUID               `json:"created_by"`
+	CreatedAt             string                  `json:"created_at"`
+	UpdatedAt             string                  `json:"updated_at"`
+	Items                 []QuotationItemResponse `json:"items,omitempty"`
+}
+
+// QuotationPriceChange is a quoted line whose current price differs from the
+// quote.
+type QuotationPriceChange struct {
+	ProductID        uuid.UUID    `json:"product_id"`
+	ProductName      string       `json:"product_name"`
+	QuotedUnitPrice  money.Amount `json:"quoted_unit_price"`
+	CurrentUnitPrice money.Amount `json:"current_unit_price"`
+}
+
+// ConvertQuotationResponse describes the order created from a quote, or, when
+// the conversion was refused because prices changed, what it would cost now.
+type ConvertQuotationResponse struct {
+	SaleOrderID       *uuid.UUID             `json:"sale_order_id,omitempty"`
+	OrderNumber       string                 `json:"order_number,omitempty"`
+	QuotedTotalAmount money.Amount           `json:"quoted_total_amount"`
+	TotalAmount       money.Amount           `json:"total_amount"`
+	PriceChanges      []QuotationPriceChange `json:"price_changes"`
+}
@@ -0,0 +1,13 @@
+package dto
+
+import "github.com/hafiztri123/kki-be/internal/money"
+
+// ReceiptVerificationResponse is what the public receipt check shows: enough
+// to tell a genuine receipt from a fake one and nothing about the customer.
+type ReceiptVerificationResponse struct {
+	OrderNumber string         `json:"order_number"`
+	Date        string         `json:"date"`
+	Currency    money.Currency `json:"currency"`
+	TotalAmount money.Amount   `json:"total_amount"`
+	Status      string         `json:"status"`
+}
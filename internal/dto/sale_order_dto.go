@@ -1,39 +1,193 @@
 package dto
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hafiztri123/kki-be/internal/money"
+)
+
+type SaleOrderItemRequest struct {
+	ProductID uuid.UUID `json:"product_id"`
+	Quantity  int       `json:"quantity"`
+}
 
 type CreateSaleOrderRequest struct {
-	CustomerName string  `json:"customer_name"`
-	TotalAmount  float64 `json:"total_amount"`
-	Status       string  `json:"status"`
+	CustomerName          string                 `json:"customer_name"`
+	CustomerID            *uuid.UUID             `json:"customer_id"`
+	TotalAmount           money.Amount           `json:"total_amount"`
+	Status                string                 `json:"status"`
+	StoreID               *uuid.UUID             `json:"store_id"`
+	TaxExempt             bool                   `json:"tax_exempt"`
+	TaxExemptionReference string                 `json:"tax_exemption_reference"`
+	Items                 []SaleOrderItemRequest `json:"items"`
+	CashTendered          *money.Amount          `json:"cash_tendered"`
 }
 
 type UpdateSaleOrderRequest struct {
-	CustomerName string  `json:"customer_name"`
-	TotalAmount  float64 `json:"total_amount"`
-	Status       string  `json:"status"`
+	CustomerName          string                 `json:"customer_name"`
+	CustomerID            *uuid.UUID             `json:"customer_id"`
+	TotalAmount           money.Amount           `json:"total_amount"`
+	Status                string                 `json:"status"`
+	TaxExempt             bool                   `json:"tax_exempt"`
+	TaxExemptionReference string                 `json:"tax_exemption_reference"`
+	Items                 []SaleOrderItemRequest `json:"items"`
+	CashTendered          *money.Amount          `json:"cash_tendered"`
+}
+
+type SaleOrderItemResponse struct {
+	ID             uuid.UUID    `json:"id"`
+	ProductID      uuid.UUID    `json:"product_id"`
+	ProductName    string       `json:"product_name"`
+	Category       string       `json:"category"`
+	Quantity       int          `json:"quantity"`
+	UnitPrice      money.Amount `json:"unit_price"`
+	SubtotalAmount money.Amount `json:"subtotal_amount"`
+	DiscountAmount money.Amount `json:"discount_amount"`
+	TotalAmount    money.Amount `json:"total_amount"`
+	TaxRate        float64      `json:"tax_rate"`
+	TaxableAmount  money.Amount `json:"taxable_amount"`
+	TaxAmount      money.Amount `json:"tax_amount"`
+}
+
+type TaxBreakdownResponse struct {
+	Rate          float64      `json:"rate"`
+	TaxableAmount money.Amount `json:"taxable_amount"`
+	TaxAmount     money.Amount `json:"tax_amount"`
 }
 
 type SaleOrderResponse struct {
-	ID           uuid.UUID `json:"id"`
-	OrderNumber  string    `json:"order_number"`
-	CustomerName string    `json:"customer_name"`
-	TotalAmount  float64   `json:"total_amount"`
-	Status       string    `json:"status"`
-	CreatedBy    uuid.UUID `json:"created_by"`
-	CreatedAt    string    `json:"created_at"`
-	UpdatedAt    string    `json:"updated_at"`
+	ID                    uuid.UUID                  `json:"id"`
+	OrderNumber           string                     `json:"order_number"`
+	CustomerName          string                     `json:"customer_name"`
+	CustomerID            *uuid.UUID                 `json:"customer_id,omitempty"`
+	StoreID               *uuid.UUID                 `json:"store_id,omitempty"`
+	Currency              money.Currency             `json:"currency"`
+	PriceIncludesTax      bool                       `json:"price_includes_tax"`
+	TaxExempt             bool                       `json:"tax_exempt"`
+	TaxExemptionReference string                     `json:"tax_exemption_reference,omitempty"`
+	SubtotalAmount        money.Amount               `json:"subtotal_amount"`
+	DiscountAmount        money.Amount               `json:"discount_amount"`
+	TaxAmount             money.Amount               `json:"tax_amount"`
+	TotalAmount           money.Amount               `json:"total_amount"`
+	CashTotalAmount       money.Amount               `json:"cash_total_amount"`
+	CashTendered          *money.Amount              `json:"cash_tendered,omitempty"`
+	Status                string                     `json:"status"`
+	CreatedBy             uuid.UUID                  `json:"created_by"`
+	CreatedAt             string                     `json:"created_at"`
+	UpdatedAt             string                     `json:"updated_at"`
+	Version               int64                      `json:"version"`
+	DeviceID              string                     `json:"device_id,omitempty"`
+	SyncedAt              string                     `json:"synced_at,omitempty"`
+	HoldLabel             string                     `json:"hold_label,omitempty"`
+	RegisterID            string                     `json:"register_id,omitempty"`
+	HeldAt                string                     `json:"held_at,omitempty"`
+	QuotationID           *uuid.UUID                 `json:"quotation_id,omitempty"`
+	Items                 []SaleOrderItemResponse    `json:"items,omitempty"`
+	Taxes                 []TaxBreakdownResponse     `json:"taxes,omitempty"`
+	Promotions            []AppliedPromotionResponse `json:"promotions,omitempty"`
+	Voucher               *AppliedVoucherResponse    `json:"voucher,omitempty"`
+}
+
+type SortField struct {
+	Field      string
+	Descending bool
+}
+
+// SaleOrderFilterRequest holds the parsed query parameters of the sale order
+// list. Nil pointers and empty values are not applied.
+type SaleOrderFilterRequest struct {
+	Statuses     []string
+	CreatedFrom  *time.Time
+	CreatedTo    *time.Time
+	CustomerName string
+	CustomerID   *uuid.UUID
+	CreatedBy    *uuid.UUID
+	StoreID      *uuid.UUID
+	MinAmount    *money.Amount
+	MaxAmount    *money.Amount
+	Query        string
+	Sort         []SortField
 }
 
 type PaginationRequest struct {
 	Limit int `json:"limit"`
 	Page  int `json:"page"`
+	// UseCursor is set when the request carries a cursor parameter, which may
+	// be empty to ask for the first page.
+	UseCursor    bool   `json:"-"`
+	Cursor       string `json:"-"`
+	IncludeTotal bool   `json:"-"`
 }
 
+// Cursor is the decoded form of an opaque next_cursor or prev_cursor. It
+// points at the (created_at, id) of the row the page starts after.
+type Cursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+	Backward  bool
+}
+
+// CursorPageInfo describes where a cursor page sits in the full list.
+type CursorPageInfo struct {
+	NextCursor string
+	PrevCursor string
+	TotalItems *int64
+}
+
+// PaginatedResponse is returned by list endpoints. Page based requests fill
+// page, total_pages and total_items; cursor based requests fill next_cursor
+// and prev_cursor, and total_items only when include_total is set.
 type PaginatedResponse struct {
-	Data       any   `json:"data"`
-	TotalItems int64 `json:"total_items"`
-	TotalPages int   `json:"total_pages"`
-	Page       int   `json:"page"`
-	Limit      int   `json:"limit"`
+	Data       any    `json:"data"`
+	TotalItems *int64 `json:"total_items,omitempty"`
+	TotalPages *int   `json:"total_pages,omitempty"`
+	Page       *int   `json:"page,omitempty"`
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+}
+
+type SaleOrderRevisionResponse struct {
+	RevisionNumber int64     `json:"revision_number"`
+	ChangedBy      uuid.UUID `json:"changed_by"`
+	CreatedAt      string    `json:"created_at"`
+}
+
+// FieldChange is one changed field of a revision diff. Field is a dotted path
+// into the sale order, with items and promotions keyed by product and
+// promotion ID. A nil value means the field was absent on that side.
+type FieldChange struct {
+	Field    string `json:"field"`
+	OldValue any    `json:"old_value"`
+	NewValue any    `json:"new_value"`
+}
+
+// SaleOrderRevisionDiffResponse compares a revision with the one taken after
+// it, or with the current order when ToRevision is the current version.
+type SaleOrderRevisionDiffResponse struct {
+	FromRevision int64         `json:"from_revision"`
+	ToRevision   int64         `json:"to_revision"`
+	Changes      []FieldChange `json:"changes"`
+}
+
+// HoldSaleOrderRequest parks a draft at a register. Label is a free text tag,
+// such as the customer's name, shown in the held order list.
+type HoldSaleOrderRequest struct {
+	RegisterID string `json:"register_id"`
+	Label      string `json:"label"`
+}
+
+// ResumeSaleOrderRequest brings a held order back as a draft. An empty
+// RegisterID keeps the register the order was held at.
+type ResumeSaleOrderRequest struct {
+	RegisterID string `json:"register_id"`
+}
+
+type SaleOrderHoldEventResponse struct {
+	Action            string     `json:"action"`
+	ActorID           *uuid.UUID `json:"actor_id,omitempty"`
+	PreviousCashierID *uuid.UUID `json:"previous_cashier_id,omitempty"`
+	RegisterID        string     `json:"register_id,omitempty"`
+	CreatedAt         string     `json:"created_at"`
 }
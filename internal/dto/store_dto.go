@@ -0,0 +1,51 @@
+package dto
+
+import "github.com/google/uuid"
+
+type CreateStoreRequest struct {
+	Code              string  `json:"code"`
+	Name              string  `json:"name"`
+	Address           string  `json:"address"`
+	Currency          string  `json:"currency"`
+	PriceIncludesTax  bool    `json:"price_includes_tax"`
+	DefaultTaxRate    float64 `json:"default_tax_rate"`
+	OrderNumberFormat string  `json:"order_number_format"`
+	ReceiptHeader     string  `json:"receipt_header"`
+	ReceiptFooter     string  `json:"receipt_footer"`
+}
+
+type UpdateStoreRequest = CreateStoreRequest
+
+type StoreResponse struct {
+	ID                uuid.UUID `json:"id"`
+	Code              string    `json:"code"`
+	Name              string    `json:"name"`
+	Address           string    `json:"address"`
+	Currency          string    `json:"currency"`
+	PriceIncludesTax  bool      `json:"price_includes_tax"`
+	DefaultTaxRate    float64   `json:"default_tax_rate"`
+	OrderNumberFormat string    `json:"order_number_format,omitempty"`
+	ReceiptHeader     string    `json:"receipt_header,omitempty"`
+	ReceiptFooter     string    `json:"receipt_footer,omitempty"`
+	CreatedAt         string    `json:"created_at"`
+	UpdatedAt         string    `json:"updated_at"`
+}
+
+type CreateTaxRateRequest struct {
+	StoreID  *uuid.UUID `json:"store_id"`
+	Category string     `json:"category"`
+	Name     string     `json:"name"`
+	Rate     float64    `json:"rate"`
+}
+
+type UpdateTaxRateRequest = CreateTaxRateRequest
+
+type TaxRateResponse struct {
+	ID        uuid.UUID  `json:"id"`
+	StoreID   *uuid.UUID `json:"store_id,omitempty"`
+	Category  string     `json:"category"`
+	Name      string     `json:"name"`
+	Rate      float64    `json:"rate"`
+	CreatedAt string     `json:"created_at"`
+	UpdatedAt string     `json:"updated_at"`
+}
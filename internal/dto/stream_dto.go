@@ -0,0 +1,16 @@
+package dto
+
+import "encoding/json"
+
+// StreamEvent is an event pushed to a live feed. ID is the sequence of the
+// domain event, which clients send back as Last-Event-ID to resume.
+type StreamEvent struct {
+	ID   int64
+	Type string
+	Data json.RawMessage
+}
+
+// StreamResetData is the data of a reset event.
+type StreamResetData struct {
+	Reason string `json:"reason"`
+}
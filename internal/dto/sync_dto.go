@@ -0,0 +1,58 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hafiztri123/kki-be/internal/money"
+)
+
+// SyncOrderRequest is an order a terminal created while offline. ClientID is
+// generated on the device and becomes the order ID, so uploading the same
+// order twice is harmless. CreatedAt is the device's local time of the sale.
+type SyncOrderRequest struct {
+	ClientID  uuid.UUID `json:"client_id"`
+	CreatedAt time.Time `json:"created_at"`
+	CreateSaleOrderRequest
+}
+
+type SyncOrdersRequest struct {
+	DeviceID string             `json:"device_id"`
+	Orders   []SyncOrderRequest `json:"orders"`
+}
+
+// SyncConflict reports a value the server decided differently than the
+// device, for example a total priced with a promotion that had ended.
+type SyncConflict struct {
+	Field       string `json:"field"`
+	ClientValue string `json:"client_value"`
+	ServerValue string `json:"server_value"`
+}
+
+type SyncOrderResult struct {
+	ClientID    uuid.UUID      `json:"client_id"`
+	Status      string         `json:"status"`
+	OrderNumber string         `json:"order_number,omitempty"`
+	TotalAmount *money.Amount  `json:"total_amount,omitempty"`
+	Message     string         `json:"message,omitempty"`
+	Conflicts   []SyncConflict `json:"conflicts,omitempty"`
+}
+
+type SyncOrdersResponse struct {
+	ServerTime string            `json:"server_time"`
+	Results    []SyncOrderResult `json:"results"`
+}
+
+// SyncChangesResponse lists everything that changed after the requested
+// since. ServerTime is the since to send on the next call.
+type SyncChangesResponse struct {
+	ServerTime          string              `json:"server_time"`
+	Products            []ProductResponse   `json:"products"`
+	DeletedProductIDs   []uuid.UUID         `json:"deleted_product_ids"`
+	Promotions          []PromotionResponse `json:"promotions"`
+	DeletedPromotionIDs []uuid.UUID         `json:"deleted_promotion_ids"`
+	TaxRates            []TaxRateResponse   `json:"tax_rates"`
+	DeletedTaxRateIDs   []uuid.UUID         `json:"deleted_tax_rate_ids"`
+	SaleOrders          []SaleOrderResponse `json:"sale_orders"`
+	DeletedSaleOrderIDs []uuid.UUID         `json:"deleted_sale_order_ids"`
+}
@@ -20,19 +20,28 @@ type LoginResponse struct {
 }
 
 type CreateCashierRequest struct {
-	Username string `json:"username"`
-	Email    string `json:"email"`
-	Password string `json:"password"`
-	Name     string `json:"name"`
+	Username string     `json:"username"`
+	Email    string     `json:"email"`
+	Password string     `json:"password"`
+	Name     string     `json:"name"`
+	StoreID  *uuid.UUID `json:"store_id"`
 }
 
 type UpdateCashierRequest = CreateCashierRequest
 type UserResponse struct {
-	ID        uuid.UUID `json:"id"`
-	Username  string    `json:"username"`
-	Email     string    `json:"email"`
-	Role      string    `json:"role"`
-	Name      string    `json:"name"`
-	CreatedAt string    `json:"created_at"`
-	UpdatedAt string    `json:"updated_at"`
-}
\ No newline at end of file
+	ID        uuid.UUID  `json:"id"`
+	Username  string     `json:"username"`
+	Email     string     `json:"email"`
+	Role      string     `json:"role"`
+	Name      string     `json:"name"`
+	StoreID   *uuid.UUID `json:"store_id,omitempty"`
+	CreatedAt string     `json:"created_at"`
+	UpdatedAt string     `json:"updated_at"`
+	Version   int64      `json:"version"`
+}
+
+// Caller is the authenticated user behind a request, taken from the JWT claims.
+type Caller struct {
+	ID   uuid.UUID
+	Role string
+}
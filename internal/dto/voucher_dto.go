@@ -0,0 +1,93 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hafiztri123/kki-be/internal/money"
+)
+
+// CreateVoucherCampaignRequest creates a campaign and its codes. Quantity codes
+// are generated, each starting with Prefix, unless Code is given, which makes
+// a single code chosen by the owner. A nil MaxUsesPerCode makes codes usable
+// any number of times and a nil StartsAt starts the campaign now.
+type CreateVoucherCampaignRequest struct {
+	Name               string        `json:"name"`
+	DiscountType       string        `json:"discount_type"`
+	Percentage         *float64      `json:"percentage"`
+	Amount             *money.Amount `json:"amount"`
+	MaxDiscount        *money.Amount `json:"max_discount"`
+	MinSpend           money.Amount  `json:"min_spend"`
+	Currency           string        `json:"currency"`
+	MaxUsesPerCode     *int          `json:"max_uses_per_code"`
+	MaxUsesPerCustomer *int          `json:"max_uses_per_customer"`
+	StartsAt           *time.Time    `json:"starts_at"`
+	ExpiresAt          *time.Time    `json:"expires_at"`
+	Quantity           int           `json:"quantity"`
+	Prefix             string        `json:"prefix"`
+	Code               string        `json:"code"`
+}
+
+type VoucherCampaignResponse struct {
+	ID                 uuid.UUID      `json:"id"`
+	Name               string         `json:"name"`
+	DiscountType       string         `json:"discount_type"`
+	Percentage         *float64       `json:"percentage,omitempty"`
+	Amount             *money.Amount  `json:"amount,omitempty"`
+	MaxDiscount        *money.Amount  `json:"max_discount,omitempty"`
+	MinSpend           money.Amount   `json:"min_spend"`
+	Currency           money.Currency `json:"currency"`
+	MaxUsesPerCode     *int           `json:"max_uses_per_code,omitempty"`
+	MaxUsesPerCustomer *int           `json:"max_uses_per_customer,omitempty"`
+	StartsAt           string         `json:"starts_at"`
+	ExpiresAt          string         `json:"expires_at,omitempty"`
+	CreatedBy          uuid.UUID      `json:"created_by"`
+	CreatedAt          string         `json:"created_at"`
+	UpdatedAt          string         `json:"updated_at"`
+	Stats              *VoucherStats  `json:"stats,omitempty"`
+}
+
+// VoucherStats counts the codes of a campaign and the paid orders that used
+// them. Refunded orders are not counted.
+type VoucherStats struct {
+	Codes          int          `json:"codes"`
+	CodesUsed      int          `json:"codes_used"`
+	Redemptions    int          `json:"redemptions"`
+	DiscountAmount money.Amount `json:"discount_amount"`
+}
+
+// ValidateVoucherRequest asks whether a code can be used by a customer on an
+// order of Amount, before it is applied. CustomerID is optional, but codes
+// limited per customer need one.
+type ValidateVoucherRequest struct {
+	Code       string       `json:"code"`
+	CustomerID *uuid.UUID   `json:"customer_id"`
+	Amount     money.Amount `json:"amount"`
+	Currency   string       `json:"currency"`
+}
+
+// ValidateVoucherResponse reports whether a code can be used and, if not,
+// why. DiscountAmount is an estimate against the whole amount; the discount
+// applied to an order is worked out after its promotions.
+type ValidateVoucherResponse struct {
+	Code           string       `json:"code"`
+	Valid          bool         `json:"valid"`
+	Reason         string       `json:"reason,omitempty"`
+	DiscountAmount money.Amount `json:"discount_amount"`
+}
+
+type ApplyVoucherRequest struct {
+	Code string `json:"code"`
+}
+
+// VoucherRejectionResponse is the data of the error returned when a code
+// cannot be applied to a sale order.
+type VoucherRejectionResponse struct {
+	Code   string `json:"code"`
+	Reason string `json:"reason"`
+}
+
+type AppliedVoucherResponse struct {
+	Code           string       `json:"code"`
+	DiscountAmount money.Amount `json:"discount_amount"`
+}
@@ -0,0 +1,53 @@
+// Package events routes domain events read from the outbox to the handlers
+// of the features that react to them.
+package events
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hafiztri123/kki-be/internal/models"
+)
+
+// Handler reacts to an event. It runs inside the transaction that marks the
+// event dispatched, so its database writes commit with it. An error undoes
+// what every handler of the event wrote and the event is retried later, so
+// handlers must not have side effects outside the database.
+type Handler func(ctx context.Context, event *models.DomainEvent) error
+
+type subscription struct {
+	name    string
+	handler Handler
+}
+
+// Bus holds the handlers subscribed to each event type. Subscriptions are
+// made while the services are built, before events are dispatched.
+type Bus struct {
+	subscriptions map[string][]subscription
+}
+
+func NewBus() *Bus {
+	return &Bus{
+		subscriptions: make(map[string][]subscription),
+	}
+}
+
+// Subscribe registers handler for the given event types. name identifies the
+// handler in errors.
+func (b *Bus) Subscribe(name string, handler Handler, eventTypes ...string) {
+	for _, eventType := range eventTypes {
+		b.subscriptions[eventType] = append(b.subscriptions[eventType], subscription{name: name, handler: handler})
+	}
+}
+
+// Dispatch runs the handlers of an event in the order they subscribed and
+// stops at the first that fails. Events nobody subscribed to are dropped.
+func (b *Bus) Dispatch(ctx context.Context, event *models.DomainEvent) error {
+	for _, s := range b.subscriptions[event.EventType] {
+		if err := s.handler(ctx, event); err != nil {
+			return fmt.Errorf("%s: %w", s.name, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,308 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/google/uuid"
+	apperror "github.com/hafiztri123/kki-be/internal/app_error"
+	"github.com/hafiztri123/kki-be/internal/constants"
+	"github.com/hafiztri123/kki-be/internal/dto"
+	"github.com/hafiztri123/kki-be/internal/service"
+	"github.com/hafiztri123/kki-be/internal/utils"
+)
+
+type CustomerAccountHandler struct {
+	customerAccountService *service.CustomerAccountService
+}
+
+func NewCustomerAccountHandler(customerAccountService *service.CustomerAccountService) *CustomerAccountHandler {
+	return &CustomerAccountHandler{
+		customerAccountService: customerAccountService,
+	}
+}
+
+func (h *CustomerAccountHandler) CreateCustomerAccountHandler(w http.ResponseWriter, r *http.Request) {
+	var req dto.CreateCustomerAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.NewSlogFailToDecode(r, err)
+		utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgBadRequest, nil)
+		return
+	}
+
+	caller, ok := utils.GetCaller(r.Context())
+	if !ok {
+		slog.ErrorContext(r.Context(), constants.MsgFailedToExtractValueFromJWT, "path", r.URL.Path)
+		utils.NewJSONResponse(w, http.StatusUnauthorized, constants.MsgStatusError, constants.MsgUnauthorized, nil)
+		return
+	}
+
+	account, err := h.customerAccountService.CreateCustomerAccount(r.Context(), caller, &req)
+	if err != nil {
+		if errors.Is(err, apperror.ErrInvalidCustomerAccount) {
+			utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgInvalidCustomerAccount, nil)
+			return
+		}
+
+		utils.NewSlogInternalServerError(r, err)
+		utils.NewJSONResponse(w, http.StatusInternalServerError, constants.MsgStatusError, constants.MsgInternalServerError, nil)
+		return
+	}
+
+	utils.NewJSONResponse(w, http.StatusCreated, constants.MsgStatusSuccess, constants.MsgSuccessCreate, account)
+}
+
+// GetCustomerAccountsHandler lists accounts, narrowed by customer_name and
+// customer_id.
+func (h *CustomerAccountHandler) GetCustomerAccountsHandler(w http.ResponseWriter, r *http.Request) {
+	pagination := utils.ParsePaginationParams(r)
+	offset := utils.CalculateOffset(pagination.Page, pagination.Limit)
+
+	customerID, err := utils.ParseUUIDParam(r, "customer_id")
+	if err != nil {
+		utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgInvalidFilter, nil)
+		return
+	}
+
+	accounts, totalCount, err := h.customerAccountService.GetCustomerAccounts(r.Context(), r.URL.Query().Get("customer_name"), customerID, pagination.Limit, offset)
+	if err != nil {
+		utils.NewSlogInternalServerError(r, err)
+		utils.NewJSONResponse(w, http.StatusInternalServerError, constants.MsgStatusError, constants.MsgInternalServerError, nil)
+		return
+	}
+
+	paginatedResponse := utils.NewPaginatedResponse(accounts, totalCount, pagination.Page, pagination.Limit)
+	utils.SetPageLinks(w, r, pagination, totalCount)
+
+	utils.NewJSONResponse(w, http.StatusOK, constants.MsgStatusSuccess, constants.MsgSuccessRetrieve, paginatedResponse)
+}
+
+func (h *CustomerAccountHandler) GetCustomerAccountByIDHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgBadRequest, nil)
+		return
+	}
+
+	account, err := h.customerAccountService.GetCustomerAccountByID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			utils.NewJSONResponse(w, http.StatusNotFound, constants.MsgStatusError, constants.MsgNotFound, nil)
+			return
+		}
+
+		utils.NewSlogInternalServerError(r, err)
+		utils.NewJSONResponse(w, http.StatusInternalServerError, constants.MsgStatusError, constants.MsgInternalServerError, nil)
+		return
+	}
+
+	utils.NewJSONResponse(w, http.StatusOK, constants.MsgStatusSuccess, constants.MsgSuccessRetrieve, account)
+}
+
+func (h *CustomerAccountHandler) UpdateCustomerAccountHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgBadRequest, nil)
+		return
+	}
+
+	var req dto.UpdateCustomerAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.NewSlogFailToDecode(r, err)
+		utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgBadRequest, nil)
+		return
+	}
+
+	account, err := h.customerAccountService.UpdateCustomerAccount(r.Context(), id, &req)
+	if err != nil {
+		if errors.Is(err, apperror.ErrInvalidCustomerAccount) {
+			utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgInvalidCustomerAccount, nil)
+			return
+		}
+
+		if errors.Is(err, apperror.ErrNotFound) {
+			utils.NewJSONResponse(w, http.StatusNotFound, constants.MsgStatusError, constants.MsgNotFound, nil)
+			return
+		}
+
+		utils.NewSlogInternalServerError(r, err)
+		utils.NewJSONResponse(w, http.StatusInternalServerError, constants.MsgStatusError, constants.MsgInternalServerError, nil)
+		return
+	}
+
+	utils.NewJSONResponse(w, http.StatusOK, constants.MsgStatusSuccess, constants.MsgSuccessUpdate, account)
+}
+
+// ChargeToAccountHandler pays a draft sale order with the customer's credit
+// account. Like other sale order changes it needs If-Match.
+func (h *CustomerAccountHandler) ChargeToAccountHandler(w http.ResponseWriter, r *http.Request) {
+	saleOrderID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgBadRequest, nil)
+		return
+	}
+
+	var req dto.ChargeToAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.NewSlogFailToDecode(r, err)
+		utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgBadRequest, nil)
+		return
+	}
+
+	caller, ok := utils.GetCaller(r.Context())
+	if !ok {
+		slog.ErrorContext(r.Context(), constants.MsgFailedToExtractValueFromJWT, "path", r.URL.Path)
+		utils.NewJSONResponse(w, http.StatusUnauthorized, constants.MsgStatusError, constants.MsgUnauthorized, nil)
+		return
+	}
+
+	version, err := utils.ParseIfMatch(r)
+	if err != nil {
+		if errors.Is(err, apperror.ErrPreconditionRequired) {
+			utils.NewJSONResponse(w, http.StatusPreconditionRequired, constants.MsgStatusError, constants.MsgPreconditionRequired, nil)
+			return
+		}
+
+		utils.NewJSONResponse(w, http.StatusPreconditionFailed, constants.MsgStatusError, constants.MsgVersionMismatch, nil)
+		return
+	}
+
+	account, exceeded, err := h.customerAccountService.ChargeToAccount(r.Context(), caller, saleOrderID, version, &req)
+	if err != nil {
+		if errors.Is(err, apperror.ErrInvalidCharge) {
+			utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgInvalidCharge, nil)
+			return
+		}
+
+		if errors.Is(err, apperror.ErrVersionMismatch) {
+			utils.NewJSONResponse(w, http.StatusPreconditionFailed, constants.MsgStatusError, constants.MsgVersionMismatch, nil)
+			return
+		}
+
+		if errors.Is(err, apperror.ErrNotFound) {
+			utils.NewJSONResponse(w, http.StatusNotFound, constants.MsgStatusError, constants.MsgNotFound, nil)
+			return
+		}
+
+		if errors.Is(err, apperror.ErrForbidden) {
+			utils.NewJSONResponse(w, http.StatusForbidden, constants.MsgStatusError, constants.MsgAccessDenied, nil)
+			return
+		}
+
+		if errors.Is(err, apperror.ErrSaleOrderNotDraft) {
+			utils.NewJSONResponse(w, http.StatusConflict, constants.MsgStatusError, constants.MsgSaleOrderNotDraft, nil)
+			return
+		}
+
+		if errors.Is(err, apperror.ErrCreditLimitExceeded) {
+			utils.NewJSONResponse(w, http.StatusConflict, constants.MsgStatusError, constants.MsgCreditLimitExceeded, exceeded)
+			return
+		}
+
+		if errors.Is(err, apperror.ErrSaleOrderAlreadyCharged) {
+			utils.NewJSONResponse(w, http.StatusConflict, constants.MsgStatusError, constants.MsgSaleOrderAlreadyCharged, nil)
+			return
+		}
+
+		if errors.Is(err, apperror.ErrVoucherNotApplicable) {
+			utils.NewJSONResponse(w, http.StatusConflict, constants.MsgStatusError, constants.MsgVoucherNotApplicable, nil)
+			return
+		}
+
+		utils.NewSlogInternalServerError(r, err)
+		utils.NewJSONResponse(w, http.StatusInternalServerError, constants.MsgStatusError, constants.MsgInternalServerError, nil)
+		return
+	}
+
+	utils.NewJSONResponse(w, http.StatusOK, constants.MsgStatusSuccess, constants.MsgSuccessUpdate, account)
+}
+
+func (h *CustomerAccountHandler) RecordRepaymentHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgBadRequest, nil)
+		return
+	}
+
+	var req dto.CreateRepaymentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.NewSlogFailToDecode(r, err)
+		utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgBadRequest, nil)
+		return
+	}
+
+	caller, ok := utils.GetCaller(r.Context())
+	if !ok {
+		slog.ErrorContext(r.Context(), constants.MsgFailedToExtractValueFromJWT, "path", r.URL.Path)
+		utils.NewJSONResponse(w, http.StatusUnauthorized, constants.MsgStatusError, constants.MsgUnauthorized, nil)
+		return
+	}
+
+	account, err := h.customerAccountService.RecordRepayment(r.Context(), caller, id, &req)
+	if err != nil {
+		if errors.Is(err, apperror.ErrInvalidPayment) {
+			utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgInvalidPayment, nil)
+			return
+		}
+
+		if errors.Is(err, apperror.ErrNotFound) {
+			utils.NewJSONResponse(w, http.StatusNotFound, constants.MsgStatusError, constants.MsgNotFound, nil)
+			return
+		}
+
+		if errors.Is(err, apperror.ErrRepaymentExceedsBalance) {
+			utils.NewJSONResponse(w, http.StatusConflict, constants.MsgStatusError, constants.MsgRepaymentExceedsBalance, nil)
+			return
+		}
+
+		utils.NewSlogInternalServerError(r, err)
+		utils.NewJSONResponse(w, http.StatusInternalServerError, constants.MsgStatusError, constants.MsgInternalServerError, nil)
+		return
+	}
+
+	utils.NewJSONResponse(w, http.StatusCreated, constants.MsgStatusSuccess, constants.MsgSuccessCreate, account)
+}
+
+// GetStatementHandler returns the ledger of an account between the optional
+// from and to query parameters, dates or timestamps. A plain to date covers
+// the whole day.
+func (h *CustomerAccountHandler) GetStatementHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgBadRequest, nil)
+		return
+	}
+
+	from, err := utils.ParseTimeParam(r, "from", false)
+	if err != nil {
+		utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgInvalidFilter, nil)
+		return
+	}
+
+	to, err := utils.ParseTimeParam(r, "to", true)
+	if err != nil {
+		utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgInvalidFilter, nil)
+		return
+	}
+
+	statement, err := h.customerAccountService.GetStatement(r.Context(), id, from, to)
+	if err != nil {
+		if errors.Is(err, apperror.ErrInvalidFilter) {
+			utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgInvalidFilter, nil)
+			return
+		}
+
+		if errors.Is(err, apperror.ErrNotFound) {
+			utils.NewJSONResponse(w, http.StatusNotFound, constants.MsgStatusError, constants.MsgNotFound, nil)
+			return
+		}
+
+		utils.NewSlogInternalServerError(r, err)
+		utils.NewJSONResponse(w, http.StatusInternalServerError, constants.MsgStatusError, constants.MsgInternalServerError, nil)
+		return
+	}
+
+	utils.NewJSONResponse(w, http.StatusOK, constants.MsgStatusSuccess, constants.MsgSuccessRetrieve, statement)
+}
@@ -0,0 +1,222 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/google/uuid"
+	apperror "github.com/hafiztri123/kki-be/internal/app_error"
+	"github.com/hafiztri123/kki-be/internal/constants"
+	"github.com/hafiztri123/kki-be/internal/dto"
+	"github.com/hafiztri123/kki-be/internal/service"
+	"github.com/hafiztri123/kki-be/internal/utils"
+)
+
+type CustomerHandler struct {
+	customerService *service.CustomerService
+}
+
+func NewCustomerHandler(customerService *service.CustomerService) *CustomerHandler {
+	return &CustomerHandler{
+		customerService: customerService,
+	}
+}
+
+func (h *CustomerHandler) CreateCustomerHandler(w http.ResponseWriter, r *http.Request) {
+	var req dto.CustomerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.NewSlogFailToDecode(r, err)
+		utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgBadRequest, nil)
+		return
+	}
+
+	caller, ok := utils.GetCaller(r.Context())
+	if !ok {
+		slog.ErrorContext(r.Context(), constants.MsgFailedToExtractValueFromJWT, "path", r.URL.Path)
+		utils.NewJSONResponse(w, http.StatusUnauthorized, constants.MsgStatusError, constants.MsgUnauthorized, nil)
+		return
+	}
+
+	customer, err := h.customerService.CreateCustomer(r.Context(), caller, &req)
+	if err != nil {
+		if errors.Is(err, apperror.ErrInvalidCustomer) {
+			utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgInvalidCustomer, nil)
+			return
+		}
+
+		utils.NewSlogInternalServerError(r, err)
+		utils.NewJSONResponse(w, http.StatusInternalServerError, constants.MsgStatusError, constants.MsgInternalServerError, nil)
+		return
+	}
+
+	utils.NewJSONResponse(w, http.StatusCreated, constants.MsgStatusSuccess, constants.MsgSuccessCreate, customer)
+}
+
+// GetCustomersHandler searches customers by name, phone or email with q and
+// narrows them to one tag with tag.
+func (h *CustomerHandler) GetCustomersHandler(w http.ResponseWriter, r *http.Request) {
+	pagination := utils.ParsePaginationParams(r)
+	offset := utils.CalculateOffset(pagination.Page, pagination.Limit)
+	query := r.URL.Query()
+
+	customers, totalCount, err := h.customerService.GetCustomers(r.Context(), query.Get("q"), query.Get("tag"), pagination.Limit, offset)
+	if err != nil {
+		utils.NewSlogInternalServerError(r, err)
+		utils.NewJSONResponse(w, http.StatusInternalServerError, constants.MsgStatusError, constants.MsgInternalServerError, nil)
+		return
+	}
+
+	paginatedResponse := utils.NewPaginatedResponse(customers, totalCount, pagination.Page, pagination.Limit)
+	utils.SetPageLinks(w, r, pagination, totalCount)
+
+	utils.NewJSONResponse(w, http.StatusOK, constants.MsgStatusSuccess, constants.MsgSuccessRetrieve, paginatedResponse)
+}
+
+func (h *CustomerHandler) GetCustomerByIDHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgBadRequest, nil)
+		return
+	}
+
+	customer, err := h.customerService.GetCustomerByID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			utils.NewJSONResponse(w, http.StatusNotFound, constants.MsgStatusError, constants.MsgNotFound, nil)
+			return
+		}
+
+		utils.NewSlogInternalServerError(r, err)
+		utils.NewJSONResponse(w, http.StatusInternalServerError, constants.MsgStatusError, constants.MsgInternalServerError, nil)
+		return
+	}
+
+	utils.NewJSONResponse(w, http.StatusOK, constants.MsgStatusSuccess, constants.MsgSuccessRetrieve, customer)
+}
+
+func (h *CustomerHandler) UpdateCustomerHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgBadRequest, nil)
+		return
+	}
+
+	var req dto.CustomerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.NewSlogFailToDecode(r, err)
+		utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgBadRequest, nil)
+		return
+	}
+
+	customer, err := h.customerService.UpdateCustomer(r.Context(), id, &req)
+	if err != nil {
+		if errors.Is(err, apperror.ErrInvalidCustomer) {
+			utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgInvalidCustomer, nil)
+			return
+		}
+
+		if errors.Is(err, apperror.ErrNotFound) {
+			utils.NewJSONResponse(w, http.StatusNotFound, constants.MsgStatusError, constants.MsgNotFound, nil)
+			return
+		}
+
+		utils.NewSlogInternalServerError(r, err)
+		utils.NewJSONResponse(w, http.StatusInternalServerError, constants.MsgStatusError, constants.MsgInternalServerError, nil)
+		return
+	}
+
+	utils.NewJSONResponse(w, http.StatusOK, constants.MsgStatusSuccess, constants.MsgSuccessUpdate, customer)
+}
+
+func (h *CustomerHandler) DeleteCustomerHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgBadRequest, nil)
+		return
+	}
+
+	err = h.customerService.DeleteCustomer(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			utils.NewJSONResponse(w, http.StatusNotFound, constants.MsgStatusError, constants.MsgNotFound, nil)
+			return
+		}
+
+		utils.NewSlogInternalServerError(r, err)
+		utils.NewJSONResponse(w, http.StatusInternalServerError, constants.MsgStatusError, constants.MsgInternalServerError, nil)
+		return
+	}
+
+	utils.NewJSONResponse(w, http.StatusOK, constants.MsgStatusSuccess, constants.MsgSuccessDelete, nil)
+}
+
+func (h *CustomerHandler) GetCustomerSaleOrdersHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgBadRequest, nil)
+		return
+	}
+
+	caller, ok := utils.GetCaller(r.Context())
+	if !ok {
+		slog.ErrorContext(r.Context(), constants.MsgFailedToExtractValueFromJWT, "path", r.URL.Path)
+		utils.NewJSONResponse(w, http.StatusUnauthorized, constants.MsgStatusError, constants.MsgUnauthorized, nil)
+		return
+	}
+
+	pagination := utils.ParsePaginationParams(r)
+	offset := utils.CalculateOffset(pagination.Page, pagination.Limit)
+
+	saleOrders, totalCount, err := h.customerService.GetCustomerSaleOrders(r.Context(), caller, id, pagination.Limit, offset)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			utils.NewJSONResponse(w, http.StatusNotFound, constants.MsgStatusError, constants.MsgNotFound, nil)
+			return
+		}
+
+		utils.NewSlogInternalServerError(r, err)
+		utils.NewJSONResponse(w, http.StatusInternalServerError, constants.MsgStatusError, constants.MsgInternalServerError, nil)
+		return
+	}
+
+	paginatedResponse := utils.NewPaginatedResponse(saleOrders, totalCount, pagination.Page, pagination.Limit)
+	utils.SetPageLinks(w, r, pagination, totalCount)
+
+	utils.NewJSONResponse(w, http.StatusOK, constants.MsgStatusSuccess, constants.MsgSuccessRetrieve, paginatedResponse)
+}
+
+func (h *CustomerHandler) MergeCustomersHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgBadRequest, nil)
+		return
+	}
+
+	var req dto.MergeCustomersRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.NewSlogFailToDecode(r, err)
+		utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgBadRequest, nil)
+		return
+	}
+
+	result, err := h.customerService.MergeCustomers(r.Context(), id, &req)
+	if err != nil {
+		if errors.Is(err, apperror.ErrInvalidCustomerMerge) {
+			utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgInvalidCustomerMerge, nil)
+			return
+		}
+
+		if errors.Is(err, apperror.ErrNotFound) {
+			utils.NewJSONResponse(w, http.StatusNotFound, constants.MsgStatusError, constants.MsgNotFound, nil)
+			return
+		}
+
+		utils.NewSlogInternalServerError(r, err)
+		utils.NewJSONResponse(w, http.StatusInternalServerError, constants.MsgStatusError, constants.MsgInternalServerError, nil)
+		return
+	}
+
+	utils.NewJSONResponse(w, http.StatusOK, constants.MsgStatusSuccess, constants.MsgSuccessMerge, result)
+}
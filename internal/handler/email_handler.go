@@ -0,0 +1,154 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/google/uuid"
+	apperror "github.com/hafiztri123/kki-be/internal/app_error"
+	"github.com/hafiztri123/kki-be/internal/constants"
+	"github.com/hafiztri123/kki-be/internal/dto"
+	"github.com/hafiztri123/kki-be/internal/service"
+	"github.com/hafiztri123/kki-be/internal/utils"
+)
+
+type EmailHandler struct {
+	emailService *service.EmailService
+}
+
+func NewEmailHandler(emailService *service.EmailService) *EmailHandler {
+	return &EmailHandler{
+		emailService: emailService,
+	}
+}
+
+// SendReceiptEmailHandler queues the receipt of a sale order for email. It
+// answers 202 as the email is sent in the background; its delivery status is
+// on the email.
+func (h *EmailHandler) SendReceiptEmailHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgBadRequest, nil)
+		return
+	}
+
+	var req dto.SendReceiptEmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.NewSlogFailToDecode(r, err)
+		utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgBadRequest, nil)
+		return
+	}
+
+	caller, ok := utils.GetCaller(r.Context())
+	if !ok {
+		slog.ErrorContext(r.Context(), constants.MsgFailedToExtractValueFromJWT, "path", r.URL.Path)
+		utils.NewJSONResponse(w, http.StatusUnauthorized, constants.MsgStatusError, constants.MsgUnauthorized, nil)
+		return
+	}
+
+	message, err := h.emailService.SendReceiptEmail(r.Context(), caller, id, &req)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			utils.NewJSONResponse(w, http.StatusNotFound, constants.MsgStatusError, constants.MsgNotFound, nil)
+			return
+		}
+
+		if errors.Is(err, apperror.ErrInvalidEmailRecipient) {
+			utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgInvalidEmailRecipient, nil)
+			return
+		}
+
+		utils.NewSlogInternalServerError(r, err)
+		utils.NewJSONResponse(w, http.StatusInternalServerError, constants.MsgStatusError, constants.MsgInternalServerError, nil)
+		return
+	}
+
+	utils.NewJSONResponse(w, http.StatusAccepted, constants.MsgStatusSuccess, constants.MsgSuccessQueued, message)
+}
+
+func (h *EmailHandler) GetEmailMessagesHandler(w http.ResponseWriter, r *http.Request) {
+	pagination := utils.ParsePaginationParams(r)
+	offset := utils.CalculateOffset(pagination.Page, pagination.Limit)
+
+	saleOrderID, err := utils.ParseUUIDParam(r, "sale_order_id")
+	if err != nil {
+		utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgInvalidFilter, nil)
+		return
+	}
+
+	messages, totalCount, err := h.emailService.GetEmailMessages(r.Context(), r.URL.Query().Get("status"), saleOrderID, pagination.Limit, offset)
+	if err != nil {
+		utils.NewSlogInternalServerError(r, err)
+		utils.NewJSONResponse(w, http.StatusInternalServerError, constants.MsgStatusError, constants.MsgInternalServerError, nil)
+		return
+	}
+
+	paginatedResponse := utils.NewPaginatedResponse(messages, totalCount, pagination.Page, pagination.Limit)
+	utils.SetPageLinks(w, r, pagination, totalCount)
+
+	utils.NewJSONResponse(w, http.StatusOK, constants.MsgStatusSuccess, constants.MsgSuccessRetrieve, paginatedResponse)
+}
+
+func (h *EmailHandler) GetSaleOrderEmailMessagesHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgBadRequest, nil)
+		return
+	}
+
+	caller, ok := utils.GetCaller(r.Context())
+	if !ok {
+		slog.ErrorContext(r.Context(), constants.MsgFailedToExtractValueFromJWT, "path", r.URL.Path)
+		utils.NewJSONResponse(w, http.StatusUnauthorized, constants.MsgStatusError, constants.MsgUnauthorized, nil)
+		return
+	}
+
+	pagination := utils.ParsePaginationParams(r)
+	offset := utils.CalculateOffset(pagination.Page, pagination.Limit)
+
+	messages, totalCount, err := h.emailService.GetSaleOrderEmailMessages(r.Context(), caller, id, pagination.Limit, offset)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			utils.NewJSONResponse(w, http.StatusNotFound, constants.MsgStatusError, constants.MsgNotFound, nil)
+			return
+		}
+
+		utils.NewSlogInternalServerError(r, err)
+		utils.NewJSONResponse(w, http.StatusInternalServerError, constants.MsgStatusError, constants.MsgInternalServerError, nil)
+		return
+	}
+
+	paginatedResponse := utils.NewPaginatedResponse(messages, totalCount, pagination.Page, pagination.Limit)
+	utils.SetPageLinks(w, r, pagination, totalCount)
+
+	utils.NewJSONResponse(w, http.StatusOK, constants.MsgStatusSuccess, constants.MsgSuccessRetrieve, paginatedResponse)
+}
+
+func (h *EmailHandler) RetryEmailMessageHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgBadRequest, nil)
+		return
+	}
+
+	message, err := h.emailService.RetryEmailMessage(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			utils.NewJSONResponse(w, http.StatusNotFound, constants.MsgStatusError, constants.MsgNotFound, nil)
+			return
+		}
+
+		if errors.Is(err, apperror.ErrEmailNotRetryable) {
+			utils.NewJSONResponse(w, http.StatusConflict, constants.MsgStatusError, constants.MsgEmailNotRetryable, nil)
+			return
+		}
+
+		utils.NewSlogInternalServerError(r, err)
+		utils.NewJSONResponse(w, http.StatusInternalServerError, constants.MsgStatusError, constants.MsgInternalServerError, nil)
+		return
+	}
+
+	utils.NewJSONResponse(w, http.StatusAccepted, constants.MsgStatusSuccess, constants.MsgSuccessQueued, message)
+}
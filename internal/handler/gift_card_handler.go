@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	apperror "github.com/hafiztri123/kki-be/internal/app_error"
+	"github.com/hafiztri123/kki-be/internal/constants"
+	"github.com/hafiztri123/kki-be/internal/dto"
+	"github.com/hafiztri123/kki-be/internal/service"
+	"github.com/hafiztri123/kki-be/internal/utils"
+)
+
+type GiftCardHandler struct {
+	giftCardService *service.GiftCardService
+}
+
+func NewGiftCardHandler(giftCardService *service.GiftCardService) *GiftCardHandler {
+	return &GiftCardHandler{
+		giftCardService: giftCardService,
+	}
+}
+
+func (h *GiftCardHandler) IssueGiftCardHandler(w http.ResponseWriter, r *http.Request) {
+	var req dto.IssueGiftCardRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.NewSlogFailToDecode(r, err)
+		utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgBadRequest, nil)
+		return
+	}
+
+	caller, ok := utils.GetCaller(r.Context())
+	if !ok {
+		slog.ErrorContext(r.Context(), constants.MsgFailedToExtractValueFromJWT, "path", r.URL.Path)
+		utils.NewJSONResponse(w, http.StatusUnauthorized, constants.MsgStatusError, constants.MsgUnauthorized, nil)
+		return
+	}
+
+	card, err := h.giftCardService.IssueGiftCard(r.Context(), caller, &req)
+	if err != nil {
+		if errors.Is(err, apperror.ErrInvalidGiftCard) {
+			utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgInvalidGiftCard, nil)
+			return
+		}
+
+		utils.NewSlogInternalServerError(r, err)
+		utils.NewJSONResponse(w, http.StatusInternalServerError, constants.MsgStatusError, constants.MsgInternalServerError, nil)
+		return
+	}
+
+	utils.NewJSONResponse(w, http.StatusCreated, constants.MsgStatusSuccess, constants.MsgSuccessCreate, card)
+}
+
+// GetGiftCardsHandler lists issued cards, narrowed by card_type and
+// customer_id.
+func (h *GiftCardHandler) GetGiftCardsHandler(w http.ResponseWriter, r *http.Request) {
+	pagination := utils.ParsePaginationParams(r)
+	offset := utils.CalculateOffset(pagination.Page, pagination.Limit)
+
+	customerID, err := utils.ParseUUIDParam(r, "customer_id")
+	if err != nil {
+		utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgInvalidFilter, nil)
+		return
+	}
+
+	cards, totalCount, err := h.giftCardService.GetGiftCards(r.Context(), r.URL.Query().Get("card_type"), customerID, pagination.Limit, offset)
+	if err != nil {
+		utils.NewSlogInternalServerError(r, err)
+		utils.NewJSONResponse(w, http.StatusInternalServerError, constants.MsgStatusError, constants.MsgInternalServerError, nil)
+		return
+	}
+
+	paginatedResponse := utils.NewPaginatedResponse(cards, totalCount, pagination.Page, pagination.Limit)
+	utils.SetPageLinks(w, r, pagination, totalCount)
+
+	utils.NewJSONResponse(w, http.StatusOK, constants.MsgStatusSuccess, constants.MsgSuccessRetrieve, paginatedResponse)
+}
+
+func (h *GiftCardHandler) GetGiftCardByCodeHandler(w http.ResponseWriter, r *http.Request) {
+	card, err := h.giftCardService.GetGiftCardByCode(r.Context(), r.PathValue("code"))
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			utils.NewJSONResponse(w, http.StatusNotFound, constants.MsgStatusError, constants.MsgNotFound, nil)
+			return
+		}
+
+		utils.NewSlogInternalServerError(r, err)
+		utils.NewJSONResponse(w, http.StatusInternalServerError, constants.MsgStatusError, constants.MsgInternalServerError, nil)
+		return
+	}
+
+	utils.NewJSONResponse(w, http.StatusOK, constants.MsgStatusSuccess, constants.MsgSuccessRetrieve, card)
+}
+
+func (h *GiftCardHandler) TopUpGiftCardHandler(w http.ResponseWriter, r *http.Request) {
+	var req dto.TopUpGiftCardRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.NewSlogFailToDecode(r, err)
+		utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgBadRequest, nil)
+		return
+	}
+
+	caller, ok := utils.GetCaller(r.Context())
+	if !ok {
+		slog.ErrorContext(r.Context(), constants.MsgFailedToExtractValueFromJWT, "path", r.URL.Path)
+		utils.NewJSONResponse(w, http.StatusUnauthorized, constants.MsgStatusError, constants.MsgUnauthorized, nil)
+		return
+	}
+
+	card, err := h.giftCardService.TopUpGiftCard(r.Context(), caller, r.PathValue("code"), &req)
+	if err != nil {
+		if errors.Is(err, apperror.ErrInvalidPayment) {
+			utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgInvalidPayment, nil)
+			return
+		}
+
+		if errors.Is(err, apperror.ErrNotFound) {
+			utils.NewJSONResponse(w, http.StatusNotFound, constants.MsgStatusError, constants.MsgNotFound, nil)
+			return
+		}
+
+		if errors.Is(err, apperror.ErrGiftCardExpired) {
+			utils.NewJSONResponse(w, http.StatusConflict, constants.MsgStatusError, constants.MsgGiftCardExpired, nil)
+			return
+		}
+
+		utils.NewSlogInternalServerError(r, err)
+		utils.NewJSONResponse(w, http.StatusInternalServerError, constants.MsgStatusError, constants.MsgInternalServerError, nil)
+		return
+	}
+
+	utils.NewJSONResponse(w, http.StatusOK, constants.MsgStatusSuccess, constants.MsgSuccessUpdate, card)
+}
+
+// GetGiftCardTransactionsHandler lists the ledger of a card, newest first.
+func (h *GiftCardHandler) GetGiftCardTransactionsHandler(w http.ResponseWriter, r *http.Request) {
+	pagination := utils.ParsePaginationParams(r)
+	offset := utils.CalculateOffset(pagination.Page, pagination.Limit)
+
+	transactions, totalCount, err := h.giftCardService.GetTransactions(r.Context(), r.PathValue("code"), pagination.Limit, offset)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			utils.NewJSONResponse(w, http.StatusNotFound, constants.MsgStatusError, constants.MsgNotFound, nil)
+			return
+		}
+
+		utils.NewSlogInternalServerError(r, err)
+		utils.NewJSONResponse(w, http.StatusInternalServerError, constants.MsgStatusError, constants.MsgInternalServerError, nil)
+		return
+	}
+
+	paginatedResponse := utils.NewPaginatedResponse(transactions, totalCount, pagination.Page, pagination.Limit)
+	utils.SetPageLinks(w, r, pagination, totalCount)
+
+	utils.NewJSONResponse(w, http.StatusOK, constants.MsgStatusSuccess, constants.MsgSuccessRetrieve, paginatedResponse)
+}
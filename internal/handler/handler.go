@@ -3,13 +3,43 @@ package handler
 import "github.com/hafiztri123/kki-be/internal/service"
 
 type Handlers struct {
-	UserHandler      *UserHandler
-	SaleOrderHandler *SaleOrderHandler
+	UserHandler            *UserHandler
+	SaleOrderHandler       *SaleOrderHandler
+	ProductHandler         *ProductHandler
+	PromotionHandler       *PromotionHandler
+	StoreHandler           *StoreHandler
+	SyncHandler            *SyncHandler
+	QuotationHandler       *QuotationHandler
+	InvoiceHandler         *InvoiceHandler
+	CustomerAccountHandler *CustomerAccountHandler
+	CustomerHandler        *CustomerHandler
+	LoyaltyHandler         *LoyaltyHandler
+	GiftCardHandler        *GiftCardHandler
+	VoucherHandler         *VoucherHandler
+	ReceiptHandler         *ReceiptHandler
+	EmailHandler           *EmailHandler
+	WebhookHandler         *WebhookHandler
+	StreamHandler          *StreamHandler
 }
 
 func NewHandlers(services *service.Services) *Handlers {
 	return &Handlers{
-		UserHandler:      NewUserHandler(services.UserService),
-		SaleOrderHandler: NewSaleOrderHandler(services.SaleOrderService),
+		UserHandler:            NewUserHandler(services.UserService),
+		SaleOrderHandler:       NewSaleOrderHandler(services.SaleOrderService),
+		ProductHandler:         NewProductHandler(services.ProductService),
+		PromotionHandler:       NewPromotionHandler(services.PromotionService),
+		StoreHandler:           NewStoreHandler(services.StoreService),
+		SyncHandler:            NewSyncHandler(services.SyncService),
+		QuotationHandler:       NewQuotationHandler(services.QuotationService),
+		InvoiceHandler:         NewInvoiceHandler(services.InvoiceService),
+		CustomerAccountHandler: NewCustomerAccountHandler(services.CustomerAccountService),
+		CustomerHandler:        NewCustomerHandler(services.CustomerService),
+		LoyaltyHandler:         NewLoyaltyHandler(services.LoyaltyService),
+		GiftCardHandler:        NewGiftCardHandler(services.GiftCardService),
+		VoucherHandler:         NewVoucherHandler(services.VoucherService),
+		ReceiptHandler:         NewReceiptHandler(services.ReceiptService),
+		EmailHandler:           NewEmailHandler(services.EmailService),
+		WebhookHandler:         NewWebhookHandler(services.WebhookService),
+		StreamHandler:          NewStreamHandler(services.SaleOrderStreamService),
 	}
 }
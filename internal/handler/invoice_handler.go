@@ -0,0 +1,202 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	apperror "github.com/hafiztri123/kki-be/internal/app_error"
+	"github.com/hafiztri123/kki-be/internal/constants"
+	"github.com/hafiztri123/kki-be/internal/dto"
+	"github.com/hafiztri123/kki-be/internal/service"
+	"github.com/hafiztri123/kki-be/internal/utils"
+)
+
+type InvoiceHandler struct {
+	invoiceService *service.InvoiceService
+}
+
+func NewInvoiceHandler(invoiceService *service.InvoiceService) *InvoiceHandler {
+	return &InvoiceHandler{
+		invoiceService: invoiceService,
+	}
+}
+
+func (h *InvoiceHandler) CreateInvoiceHandler(w http.ResponseWriter, r *http.Request) {
+	saleOrderID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgBadRequest, nil)
+		return
+	}
+
+	var req dto.CreateInvoiceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.NewSlogFailToDecode(r, err)
+		utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgBadRequest, nil)
+		return
+	}
+
+	caller, ok := utils.GetCaller(r.Context())
+	if !ok {
+		slog.ErrorContext(r.Context(), constants.MsgFailedToExtractValueFromJWT, "path", r.URL.Path)
+		utils.NewJSONResponse(w, http.StatusUnauthorized, constants.MsgStatusError, constants.MsgUnauthorized, nil)
+		return
+	}
+
+	invoice, err := h.invoiceService.CreateInvoice(r.Context(), caller, saleOrderID, &req)
+	if err != nil {
+		if errors.Is(err, apperror.ErrInvalidInvoice) {
+			utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgInvalidInvoice, nil)
+			return
+		}
+
+		if errors.Is(err, apperror.ErrNotFound) {
+			utils.NewJSONResponse(w, http.StatusNotFound, constants.MsgStatusError, constants.MsgNotFound, nil)
+			return
+		}
+
+		if errors.Is(err, apperror.ErrSaleOrderNotCompleted) {
+			utils.NewJSONResponse(w, http.StatusConflict, constants.MsgStatusError, constants.MsgSaleOrderNotCompleted, nil)
+			return
+		}
+
+		if errors.Is(err, apperror.ErrInvoiceAlreadyExists) {
+			utils.NewJSONResponse(w, http.StatusConflict, constants.MsgStatusError, constants.MsgInvoiceAlreadyExists, nil)
+			return
+		}
+
+		utils.NewSlogInternalServerError(r, err)
+		utils.NewJSONResponse(w, http.StatusInternalServerError, constants.MsgStatusError, constants.MsgInternalServerError, nil)
+		return
+	}
+
+	utils.NewJSONResponse(w, http.StatusCreated, constants.MsgStatusSuccess, constants.MsgSuccessCreate, invoice)
+}
+
+func (h *InvoiceHandler) GetInvoicesHandler(w http.ResponseWriter, r *http.Request) {
+	caller, ok := utils.GetCaller(r.Context())
+	if !ok {
+		slog.ErrorContext(r.Context(), constants.MsgFailedToExtractValueFromJWT, "path", r.URL.Path)
+		utils.NewJSONResponse(w, http.StatusUnauthorized, constants.MsgStatusError, constants.MsgUnauthorized, nil)
+		return
+	}
+
+	pagination := utils.ParsePaginationParams(r)
+	offset := utils.CalculateOffset(pagination.Page, pagination.Limit)
+	query := r.URL.Query()
+
+	invoices, totalCount, err := h.invoiceService.GetInvoices(r.Context(), caller, query.Get("status"), query.Get("customer_name"), pagination.Limit, offset)
+	if err != nil {
+		utils.NewSlogInternalServerError(r, err)
+		utils.NewJSONResponse(w, http.StatusInternalServerError, constants.MsgStatusError, constants.MsgInternalServerError, nil)
+		return
+	}
+
+	paginatedResponse := utils.NewPaginatedResponse(invoices, totalCount, pagination.Page, pagination.Limit)
+	utils.SetPageLinks(w, r, pagination, totalCount)
+
+	utils.NewJSONResponse(w, http.StatusOK, constants.MsgStatusSuccess, constants.MsgSuccessRetrieve, paginatedResponse)
+}
+
+func (h *InvoiceHandler) GetInvoiceByIDHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgBadRequest, nil)
+		return
+	}
+
+	caller, ok := utils.GetCaller(r.Context())
+	if !ok {
+		slog.ErrorContext(r.Context(), constants.MsgFailedToExtractValueFromJWT, "path", r.URL.Path)
+		utils.NewJSONResponse(w, http.StatusUnauthorized, constants.MsgStatusError, constants.MsgUnauthorized, nil)
+		return
+	}
+
+	invoice, err := h.invoiceService.GetInvoiceByID(r.Context(), caller, id)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			utils.NewJSONResponse(w, http.StatusNotFound, constants.MsgStatusError, constants.MsgNotFound, nil)
+			return
+		}
+
+		utils.NewSlogInternalServerError(r, err)
+		utils.NewJSONResponse(w, http.StatusInternalServerError, constants.MsgStatusError, constants.MsgInternalServerError, nil)
+		return
+	}
+
+	utils.NewJSONResponse(w, http.StatusOK, constants.MsgStatusSuccess, constants.MsgSuccessRetrieve, invoice)
+}
+
+func (h *InvoiceHandler) RecordPaymentHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgBadRequest, nil)
+		return
+	}
+
+	var req dto.CreateInvoicePaymentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.NewSlogFailToDecode(r, err)
+		utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgBadRequest, nil)
+		return
+	}
+
+	caller, ok := utils.GetCaller(r.Context())
+	if !ok {
+		slog.ErrorContext(r.Context(), constants.MsgFailedToExtractValueFromJWT, "path", r.URL.Path)
+		utils.NewJSONResponse(w, http.StatusUnauthorized, constants.MsgStatusError, constants.MsgUnauthorized, nil)
+		return
+	}
+
+	invoice, err := h.invoiceService.RecordPayment(r.Context(), caller, id, &req)
+	if err != nil {
+		if errors.Is(err, apperror.ErrInvalidPayment) {
+			utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgInvalidPayment, nil)
+			return
+		}
+
+		if errors.Is(err, apperror.ErrNotFound) {
+			utils.NewJSONResponse(w, http.StatusNotFound, constants.MsgStatusError, constants.MsgNotFound, nil)
+			return
+		}
+
+		if errors.Is(err, apperror.ErrPaymentExceedsBalance) {
+			utils.NewJSONResponse(w, http.StatusConflict, constants.MsgStatusError, constants.MsgPaymentExceedsBalance, nil)
+			return
+		}
+
+		utils.NewSlogInternalServerError(r, err)
+		utils.NewJSONResponse(w, http.StatusInternalServerError, constants.MsgStatusError, constants.MsgInternalServerError, nil)
+		return
+	}
+
+	utils.NewJSONResponse(w, http.StatusCreated, constants.MsgStatusSuccess, constants.MsgSuccessCreate, invoice)
+}
+
+// GetReceivablesAgingHandler reports receivables as of the as_of query
+// parameter, a date or timestamp, defaulting to now. A plain date covers the
+// whole day.
+func (h *InvoiceHandler) GetReceivablesAgingHandler(w http.ResponseWriter, r *http.Request) {
+	asOf, err := utils.ParseTimeParam(r, "as_of", true)
+	if err != nil {
+		utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgInvalidFilter, nil)
+		return
+	}
+
+	at := time.Now()
+	if asOf != nil {
+		at = *asOf
+	}
+
+	report, err := h.invoiceService.GetReceivablesAging(r.Context(), at)
+	if err != nil {
+		utils.NewSlogInternalServerError(r, err)
+		utils.NewJSONResponse(w, http.StatusInternalServerError, constants.MsgStatusError, constants.MsgInternalServerError, nil)
+		return
+	}
+
+	utils.NewJSONResponse(w, http.StatusOK, constants.MsgStatusSuccess, constants.MsgSuccessRetrieve, report)
+}
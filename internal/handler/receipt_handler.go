@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/google/uuid"
+	apperror "github.com/hafiztri123/kki-be/internal/app_error"
+	"github.com/hafiztri123/kki-be/internal/constants"
+	"github.com/hafiztri123/kki-be/internal/receipt"
+	"github.com/hafiztri123/kki-be/internal/service"
+	"github.com/hafiztri123/kki-be/internal/utils"
+)
+
+type ReceiptHandler struct {
+	receiptService *service.ReceiptService
+}
+
+func NewReceiptHandler(receiptService *service.ReceiptService) *ReceiptHandler {
+	return &ReceiptHandler{
+		receiptService: receiptService,
+	}
+}
+
+// GetReceiptHandler prints a sale order receipt. format is text, escpos for
+// raw thermal printer bytes, or pdf; paper is 58mm or 80mm.
+func (h *ReceiptHandler) GetReceiptHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgBadRequest, nil)
+		return
+	}
+
+	format, ok := receipt.ParseFormat(r.URL.Query().Get("format"))
+	if !ok {
+		utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgInvalidReceiptFormat, nil)
+		return
+	}
+
+	paper, ok := receipt.ParsePaper(r.URL.Query().Get("paper"))
+	if !ok {
+		utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgInvalidReceiptFormat, nil)
+		return
+	}
+
+	caller, ok := utils.GetCaller(r.Context())
+	if !ok {
+		slog.ErrorContext(r.Context(), constants.MsgFailedToExtractValueFromJWT, "path", r.URL.Path)
+		utils.NewJSONResponse(w, http.StatusUnauthorized, constants.MsgStatusError, constants.MsgUnauthorized, nil)
+		return
+	}
+
+	rec, err := h.receiptService.GetReceipt(r.Context(), caller, id)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			utils.NewJSONResponse(w, http.StatusNotFound, constants.MsgStatusError, constants.MsgNotFound, nil)
+			return
+		}
+
+		utils.NewSlogInternalServerError(r, err)
+		utils.NewJSONResponse(w, http.StatusInternalServerError, constants.MsgStatusError, constants.MsgInternalServerError, nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", format.ContentType())
+	switch format {
+	case receipt.FormatPDF:
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`inline; filename="receipt-%s.pdf"`, id))
+	case receipt.FormatESCPOS:
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="receipt-%s.bin"`, id))
+	}
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := w.Write(receipt.Render(rec, format, paper)); err != nil {
+		utils.NewSlogInternalServerError(r, err)
+	}
+}
+
+// VerifyReceiptHandler is the public page the QR code on a receipt opens. It
+// needs no login, so it only tells whether the receipt is genuine.
+func (h *ReceiptHandler) VerifyReceiptHandler(w http.ResponseWriter, r *http.Request) {
+	verification, err := h.receiptService.VerifyReceipt(r.Context(), r.PathValue("token"))
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			utils.NewJSONResponse(w, http.StatusNotFound, constants.MsgStatusError, constants.MsgNotFound, nil)
+			return
+		}
+
+		utils.NewSlogInternalServerError(r, err)
+		utils.NewJSONResponse(w, http.StatusInternalServerError, constants.MsgStatusError, constants.MsgInternalServerError, nil)
+		return
+	}
+
+	utils.NewJSONResponse(w, http.StatusOK, constants.MsgStatusSuccess, constants.MsgSuccessRetrieve, verification)
+}
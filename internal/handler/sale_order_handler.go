@@ -5,6 +5,8 @@ import (
 	"errors"
 	"log/slog"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/google/uuid"
 	apperror "github.com/hafiztri123/kki-be/internal/app_error"
@@ -33,15 +35,61 @@ func (h *SaleOrderHandler) CreateSaleOrderHandler(w http.ResponseWriter, r *http
 		return
 	}
 
-	createdBy, ok := r.Context().Value(constants.ClaimsKeyID).(uuid.UUID)
+	caller, ok := utils.GetCaller(r.Context())
 	if !ok {
-		slog.ErrorContext(r.Context(), constants.MsgFailedToExtractValueFromJWT, "path", r.URL.Path )
+		slog.ErrorContext(r.Context(), constants.MsgFailedToExtractValueFromJWT, "path", r.URL.Path)
 		utils.NewJSONResponse(w, http.StatusUnauthorized, constants.MsgStatusError, constants.MsgUnauthorized, nil)
 		return
 	}
 
-	err := h.saleOrderService.CreateSaleOrder(r.Context(), &req, createdBy)
+	err := h.saleOrderService.CreateSaleOrder(r.Context(), caller, &req)
 	if err != nil {
+		if errors.Is(err, apperror.ErrForbidden) {
+			utils.NewJSONResponse(w, http.StatusForbidden, constants.MsgStatusError, constants.MsgAccessDenied, nil)
+			return
+		}
+
+		if errors.Is(err, apperror.ErrInvalidSaleOrderItem) {
+			utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgInvalidSaleOrderItem, nil)
+			return
+		}
+
+		if errors.Is(err, apperror.ErrInvalidSaleOrderStatus) {
+			utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgInvalidSaleOrderStatus, nil)
+			return
+		}
+
+		if errors.Is(err, apperror.ErrInvalidCustomer) {
+			utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgInvalidCustomer, nil)
+			return
+		}
+
+		if errors.Is(err, apperror.ErrInvalidCashTendered) {
+			utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgInvalidCashTendered, nil)
+			return
+		}
+
+		if errors.Is(err, apperror.ErrInvalidStore) {
+			utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgInvalidStore, nil)
+			return
+		}
+
+		if errors.Is(err, apperror.ErrPromotionUsageLimitReached) {
+			utils.NewJSONResponse(w, http.StatusConflict, constants.MsgStatusError, constants.MsgPromotionUsageLimitReached, nil)
+			return
+		}
+
+		if errors.Is(err, apperror.ErrOrderNumberConflict) {
+			utils.NewJSONResponse(w, http.StatusConflict, constants.MsgStatusError, constants.MsgOrderNumberConflict, nil)
+			return
+		}
+
+		if errors.Is(err, apperror.ErrInvalidOrderNumberFormat) {
+			utils.NewSlogInternalServerError(r, err)
+			utils.NewJSONResponse(w, http.StatusInternalServerError, constants.MsgStatusError, constants.MsgInvalidOrderNumberFormat, nil)
+			return
+		}
+
 		utils.NewSlogInternalServerError(r, err)
 		utils.NewJSONResponse(w, http.StatusInternalServerError, constants.MsgStatusError, constants.MsgInternalServerError, nil)
 		return
@@ -50,11 +98,92 @@ func (h *SaleOrderHandler) CreateSaleOrderHandler(w http.ResponseWriter, r *http
 	utils.NewJSONResponse(w, http.StatusCreated, constants.MsgStatusSuccess, constants.MsgSuccessCreate, nil)
 }
 
+// parseSaleOrderFilter reads the list filters: status (comma separated),
+// created_from, created_to, customer_name, customer_id, created_by, store_id,
+// min_amount, max_amount, q and sort.
+func parseSaleOrderFilter(r *http.Request) (dto.SaleOrderFilterRequest, error) {
+	query := r.URL.Query()
+	filter := dto.SaleOrderFilterRequest{
+		Statuses:     utils.ParseListParam(r, "status"),
+		CustomerName: strings.TrimSpace(query.Get("customer_name")),
+		Query:        strings.TrimSpace(query.Get("q")),
+	}
+
+	var err error
+	if filter.CreatedFrom, err = utils.ParseTimeParam(r, "created_from", false); err != nil {
+		return filter, err
+	}
+	if filter.CreatedTo, err = utils.ParseTimeParam(r, "created_to", true); err != nil {
+		return filter, err
+	}
+	if filter.CustomerID, err = utils.ParseUUIDParam(r, "customer_id"); err != nil {
+		return filter, err
+	}
+	if filter.CreatedBy, err = utils.ParseUUIDParam(r, "created_by"); err != nil {
+		return filter, err
+	}
+	if filter.StoreID, err = utils.ParseUUIDParam(r, "store_id"); err != nil {
+		return filter, err
+	}
+	if filter.MinAmount, err = utils.ParseAmountParam(r, "min_amount"); err != nil {
+		return filter, err
+	}
+	if filter.MaxAmount, err = utils.ParseAmountParam(r, "max_amount"); err != nil {
+		return filter, err
+	}
+	if filter.Sort, err = utils.ParseSortParams(r, "created_at", "updated_at", "order_number", "customer_name", "total_amount", "status"); err != nil {
+		return filter, err
+	}
+
+	if filter.CreatedFrom != nil && filter.CreatedTo != nil && !filter.CreatedFrom.Before(*filter.CreatedTo) {
+		return filter, apperror.ErrInvalidFilter
+	}
+	if filter.MinAmount != nil && filter.MaxAmount != nil && filter.MinAmount.MinorUnits() > filter.MaxAmount.MinorUnits() {
+		return filter, apperror.ErrInvalidFilter
+	}
+
+	return filter, nil
+}
+
 func (h *SaleOrderHandler) GetSaleOrdersHandler(w http.ResponseWriter, r *http.Request) {
+	caller, ok := utils.GetCaller(r.Context())
+	if !ok {
+		slog.ErrorContext(r.Context(), constants.MsgFailedToExtractValueFromJWT, "path", r.URL.Path)
+		utils.NewJSONResponse(w, http.StatusUnauthorized, constants.MsgStatusError, constants.MsgUnauthorized, nil)
+		return
+	}
+
 	pagination := utils.ParsePaginationParams(r)
+
+	filter, err := parseSaleOrderFilter(r)
+	if err != nil {
+		utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgInvalidFilter, nil)
+		return
+	}
+
+	if pagination.UseCursor {
+		// Cursors follow (created_at, id), so a custom sort cannot be combined with them.
+		cursor, err := utils.DecodeCursor(pagination.Cursor)
+		if err != nil || len(filter.Sort) > 0 {
+			utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgInvalidPagination, nil)
+			return
+		}
+
+		saleOrders, info, err := h.saleOrderService.GetSaleOrdersPage(r.Context(), caller, &filter, cursor, pagination.Limit, pagination.IncludeTotal)
+		if err != nil {
+			utils.NewSlogInternalServerError(r, err)
+			utils.NewJSONResponse(w, http.StatusInternalServerError, constants.MsgStatusError, constants.MsgInternalServerError, nil)
+			return
+		}
+
+		utils.SetCursorLinks(w, r, pagination, info)
+		utils.NewJSONResponse(w, http.StatusOK, constants.MsgStatusSuccess, constants.MsgSuccessRetrieve, utils.NewCursorPaginatedResponse(saleOrders, info, pagination.Limit))
+		return
+	}
+
 	offset := utils.CalculateOffset(pagination.Page, pagination.Limit)
 
-	saleOrders, totalCount, err := h.saleOrderService.GetSaleOrders(r.Context(), pagination.Limit, offset)
+	saleOrders, totalCount, err := h.saleOrderService.GetSaleOrders(r.Context(), caller, &filter, pagination.Limit, offset)
 	if err != nil {
 		utils.NewSlogInternalServerError(r, err)
 		utils.NewJSONResponse(w, http.StatusInternalServerError, constants.MsgStatusError, constants.MsgInternalServerError, nil)
@@ -62,6 +191,7 @@ func (h *SaleOrderHandler) GetSaleOrdersHandler(w http.ResponseWriter, r *http.R
 	}
 
 	paginatedResponse := utils.NewPaginatedResponse(saleOrders, totalCount, pagination.Page, pagination.Limit)
+	utils.SetPageLinks(w, r, pagination, totalCount)
 
 	utils.NewJSONResponse(w, http.StatusOK, constants.MsgStatusSuccess, constants.MsgSuccessRetrieve, paginatedResponse)
 }
@@ -74,7 +204,14 @@ func (h *SaleOrderHandler) GetSaleOrderByIDHandler(w http.ResponseWriter, r *htt
 		return
 	}
 
-	saleOrder, err := h.saleOrderService.GetSaleOrderByID(r.Context(), id)
+	caller, ok := utils.GetCaller(r.Context())
+	if !ok {
+		slog.ErrorContext(r.Context(), constants.MsgFailedToExtractValueFromJWT, "path", r.URL.Path)
+		utils.NewJSONResponse(w, http.StatusUnauthorized, constants.MsgStatusError, constants.MsgUnauthorized, nil)
+		return
+	}
+
+	saleOrder, err := h.saleOrderService.GetSaleOrderByID(r.Context(), caller, id)
 	if err != nil {
 		if errors.Is(err, apperror.ErrNotFound) {
 			utils.NewJSONResponse(w, http.StatusNotFound, constants.MsgStatusError, constants.MsgNotFound, nil)
@@ -86,6 +223,7 @@ func (h *SaleOrderHandler) GetSaleOrderByIDHandler(w http.ResponseWriter, r *htt
 		return
 	}
 
+	utils.SetETag(w, saleOrder.Version)
 	utils.NewJSONResponse(w, http.StatusOK, constants.MsgStatusSuccess, constants.MsgSuccessRetrieve, saleOrder)
 }
 
@@ -104,13 +242,81 @@ func (h *SaleOrderHandler) UpdateSaleOrderHandler(w http.ResponseWriter, r *http
 		return
 	}
 
-	err = h.saleOrderService.UpdateSaleOrder(r.Context(), id, &req)
+	caller, ok := utils.GetCaller(r.Context())
+	if !ok {
+		slog.ErrorContext(r.Context(), constants.MsgFailedToExtractValueFromJWT, "path", r.URL.Path)
+		utils.NewJSONResponse(w, http.StatusUnauthorized, constants.MsgStatusError, constants.MsgUnauthorized, nil)
+		return
+	}
+
+	version, err := utils.ParseIfMatch(r)
 	if err != nil {
+		if errors.Is(err, apperror.ErrPreconditionRequired) {
+			utils.NewJSONResponse(w, http.StatusPreconditionRequired, constants.MsgStatusError, constants.MsgPreconditionRequired, nil)
+			return
+		}
+
+		utils.NewJSONResponse(w, http.StatusPreconditionFailed, constants.MsgStatusError, constants.MsgVersionMismatch, nil)
+		return
+	}
+
+	err = h.saleOrderService.UpdateSaleOrder(r.Context(), caller, id, version, &req)
+	if err != nil {
+		if errors.Is(err, apperror.ErrVersionMismatch) {
+			utils.NewJSONResponse(w, http.StatusPreconditionFailed, constants.MsgStatusError, constants.MsgVersionMismatch, nil)
+			return
+		}
+
 		if errors.Is(err, apperror.ErrNotFound) {
 			utils.NewJSONResponse(w, http.StatusNotFound, constants.MsgStatusError, constants.MsgNotFound, nil)
 			return
 		}
 
+		if errors.Is(err, apperror.ErrForbidden) {
+			utils.NewJSONResponse(w, http.StatusForbidden, constants.MsgStatusError, constants.MsgAccessDenied, nil)
+			return
+		}
+
+		if errors.Is(err, apperror.ErrInvalidSaleOrderItem) {
+			utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgInvalidSaleOrderItem, nil)
+			return
+		}
+
+		if errors.Is(err, apperror.ErrInvalidSaleOrderStatus) {
+			utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgInvalidSaleOrderStatus, nil)
+			return
+		}
+
+		if errors.Is(err, apperror.ErrSaleOrderHeld) {
+			utils.NewJSONResponse(w, http.StatusConflict, constants.MsgStatusError, constants.MsgSaleOrderHeld, nil)
+			return
+		}
+
+		if errors.Is(err, apperror.ErrInvalidCustomer) {
+			utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgInvalidCustomer, nil)
+			return
+		}
+
+		if errors.Is(err, apperror.ErrInvalidCashTendered) {
+			utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgInvalidCashTendered, nil)
+			return
+		}
+
+		if errors.Is(err, apperror.ErrPromotionUsageLimitReached) {
+			utils.NewJSONResponse(w, http.StatusConflict, constants.MsgStatusError, constants.MsgPromotionUsageLimitReached, nil)
+			return
+		}
+
+		if errors.Is(err, apperror.ErrVoucherNotApplicable) {
+			utils.NewJSONResponse(w, http.StatusConflict, constants.MsgStatusError, constants.MsgVoucherNotApplicable, nil)
+			return
+		}
+
+		if errors.Is(err, apperror.ErrRedemptionsExceedTotal) {
+			utils.NewJSONResponse(w, http.StatusConflict, constants.MsgStatusError, constants.MsgRedemptionsExceedTotal, nil)
+			return
+		}
+
 		utils.NewSlogInternalServerError(r, err)
 		utils.NewJSONResponse(w, http.StatusInternalServerError, constants.MsgStatusError, constants.MsgInternalServerError, nil)
 		return
@@ -127,13 +333,41 @@ func (h *SaleOrderHandler) DeleteSaleOrderHandler(w http.ResponseWriter, r *http
 		return
 	}
 
-	err = h.saleOrderService.DeleteSaleOrder(r.Context(), id)
+	caller, ok := utils.GetCaller(r.Context())
+	if !ok {
+		slog.ErrorContext(r.Context(), constants.MsgFailedToExtractValueFromJWT, "path", r.URL.Path)
+		utils.NewJSONResponse(w, http.StatusUnauthorized, constants.MsgStatusError, constants.MsgUnauthorized, nil)
+		return
+	}
+
+	version, err := utils.ParseIfMatch(r)
 	if err != nil {
+		if errors.Is(err, apperror.ErrPreconditionRequired) {
+			utils.NewJSONResponse(w, http.StatusPreconditionRequired, constants.MsgStatusError, constants.MsgPreconditionRequired, nil)
+			return
+		}
+
+		utils.NewJSONResponse(w, http.StatusPreconditionFailed, constants.MsgStatusError, constants.MsgVersionMismatch, nil)
+		return
+	}
+
+	err = h.saleOrderService.DeleteSaleOrder(r.Context(), caller, id, version)
+	if err != nil {
+		if errors.Is(err, apperror.ErrVersionMismatch) {
+			utils.NewJSONResponse(w, http.StatusPreconditionFailed, constants.MsgStatusError, constants.MsgVersionMismatch, nil)
+			return
+		}
+
 		if errors.Is(err, apperror.ErrNotFound) {
 			utils.NewJSONResponse(w, http.StatusNotFound, constants.MsgStatusError, constants.MsgNotFound, nil)
 			return
 		}
 
+		if errors.Is(err, apperror.ErrForbidden) {
+			utils.NewJSONResponse(w, http.StatusForbidden, constants.MsgStatusError, constants.MsgAccessDenied, nil)
+			return
+		}
+
 		utils.NewSlogInternalServerError(r, err)
 		utils.NewJSONResponse(w, http.StatusInternalServerError, constants.MsgStatusError, constants.MsgInternalServerError, nil)
 		return
@@ -141,3 +375,142 @@ func (h *SaleOrderHandler) DeleteSaleOrderHandler(w http.ResponseWriter, r *http
 
 	utils.NewJSONResponse(w, http.StatusOK, constants.MsgStatusSuccess, constants.MsgSuccessDelete, nil)
 }
+
+func (h *SaleOrderHandler) GetSaleOrderRevisionsHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgBadRequest, nil)
+		return
+	}
+
+	caller, ok := utils.GetCaller(r.Context())
+	if !ok {
+		slog.ErrorContext(r.Context(), constants.MsgFailedToExtractValueFromJWT, "path", r.URL.Path)
+		utils.NewJSONResponse(w, http.StatusUnauthorized, constants.MsgStatusError, constants.MsgUnauthorized, nil)
+		return
+	}
+
+	revisions, err := h.saleOrderService.GetSaleOrderRevisions(r.Context(), caller, id)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			utils.NewJSONResponse(w, http.StatusNotFound, constants.MsgStatusError, constants.MsgNotFound, nil)
+			return
+		}
+
+		utils.NewSlogInternalServerError(r, err)
+		utils.NewJSONResponse(w, http.StatusInternalServerError, constants.MsgStatusError, constants.MsgInternalServerError, nil)
+		return
+	}
+
+	utils.NewJSONResponse(w, http.StatusOK, constants.MsgStatusSuccess, constants.MsgSuccessRetrieve, revisions)
+}
+
+// parseRevisionPath reads the {id} and {n} path values of the revision routes.
+func parseRevisionPath(r *http.Request) (uuid.UUID, int64, bool) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		return uuid.Nil, 0, false
+	}
+
+	n, err := strconv.ParseInt(r.PathValue("n"), 10, 64)
+	if err != nil || n < 1 {
+		return uuid.Nil, 0, false
+	}
+
+	return id, n, true
+}
+
+func (h *SaleOrderHandler) DiffSaleOrderRevisionHandler(w http.ResponseWriter, r *http.Request) {
+	id, n, ok := parseRevisionPath(r)
+	if !ok {
+		utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgBadRequest, nil)
+		return
+	}
+
+	caller, ok := utils.GetCaller(r.Context())
+	if !ok {
+		slog.ErrorContext(r.Context(), constants.MsgFailedToExtractValueFromJWT, "path", r.URL.Path)
+		utils.NewJSONResponse(w, http.StatusUnauthorized, constants.MsgStatusError, constants.MsgUnauthorized, nil)
+		return
+	}
+
+	diff, err := h.saleOrderService.DiffSaleOrderRevision(r.Context(), caller, id, n)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			utils.NewJSONResponse(w, http.StatusNotFound, constants.MsgStatusError, constants.MsgNotFound, nil)
+			return
+		}
+
+		utils.NewSlogInternalServerError(r, err)
+		utils.NewJSONResponse(w, http.StatusInternalServerError, constants.MsgStatusError, constants.MsgInternalServerError, nil)
+		return
+	}
+
+	utils.NewJSONResponse(w, http.StatusOK, constants.MsgStatusSuccess, constants.MsgSuccessRetrieve, diff)
+}
+
+func (h *SaleOrderHandler) RestoreSaleOrderRevisionHandler(w http.ResponseWriter, r *http.Request) {
+	id, n, ok := parseRevisionPath(r)
+	if !ok {
+		utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgBadRequest, nil)
+		return
+	}
+
+	caller, ok := utils.GetCaller(r.Context())
+	if !ok {
+		slog.ErrorContext(r.Context(), constants.MsgFailedToExtractValueFromJWT, "path", r.URL.Path)
+		utils.NewJSONResponse(w, http.StatusUnauthorized, constants.MsgStatusError, constants.MsgUnauthorized, nil)
+		return
+	}
+
+	version, err := utils.ParseIfMatch(r)
+	if err != nil {
+		if errors.Is(err, apperror.ErrPreconditionRequired) {
+			utils.NewJSONResponse(w, http.StatusPreconditionRequired, constants.MsgStatusError, constants.MsgPreconditionRequired, nil)
+			return
+		}
+
+		utils.NewJSONResponse(w, http.StatusPreconditionFailed, constants.MsgStatusError, constants.MsgVersionMismatch, nil)
+		return
+	}
+
+	err = h.saleOrderService.RestoreSaleOrderRevision(r.Context(), caller, id, n, version)
+	if err != nil {
+		if errors.Is(err, apperror.ErrVersionMismatch) {
+			utils.NewJSONResponse(w, http.StatusPreconditionFailed, constants.MsgStatusError, constants.MsgVersionMismatch, nil)
+			return
+		}
+
+		if errors.Is(err, apperror.ErrNotFound) {
+			utils.NewJSONResponse(w, http.StatusNotFound, constants.MsgStatusError, constants.MsgNotFound, nil)
+			return
+		}
+
+		if errors.Is(err, apperror.ErrForbidden) {
+			utils.NewJSONResponse(w, http.StatusForbidden, constants.MsgStatusError, constants.MsgAccessDenied, nil)
+			return
+		}
+
+		if errors.Is(err, apperror.ErrSaleOrderNotDraft) {
+			utils.NewJSONResponse(w, http.StatusConflict, constants.MsgStatusError, constants.MsgSaleOrderNotDraft, nil)
+			return
+		}
+
+		if errors.Is(err, apperror.ErrPromotionUsageLimitReached) {
+			utils.NewJSONResponse(w, http.StatusConflict, constants.MsgStatusError, constants.MsgPromotionUsageLimitReached, nil)
+			return
+		}
+
+		if errors.Is(err, apperror.ErrRedemptionsExceedTotal) {
+			utils.NewJSONResponse(w, http.StatusConflict, constants.MsgStatusError, constants.MsgRedemptionsExceedTotal, nil)
+			return
+		}
+
+		utils.NewSlogInternalServerError(r, err)
+		utils.NewJSONResponse(w, http.StatusInternalServerError, constants.MsgStatusError, constants.MsgInternalServerError, nil)
+		return
+	}
+
+	utils.NewJSONResponse(w, http.StatusOK, constants.MsgStatusSuccess, constants.MsgSuccessRestore, nil)
+}
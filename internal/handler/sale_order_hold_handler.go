@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/google/uuid"
+	apperror "github.com/hafiztri123/kki-be/internal/app_error"
+	"github.com/hafiztri123/kki-be/internal/constants"
+	"github.com/hafiztri123/kki-be/internal/dto"
+	"github.com/hafiztri123/kki-be/internal/utils"
+)
+
+func (h *SaleOrderHandler) HoldSaleOrderHandler(w http.ResponseWriter, r *http.Request) {
+	var req dto.HoldSaleOrderRequest
+	h.changeHold(w, r, &req, func(caller dto.Caller, id uuid.UUID, version int64) error {
+		return h.saleOrderService.HoldSaleOrder(r.Context(), caller, id, version, &req)
+	})
+}
+
+func (h *SaleOrderHandler) ResumeSaleOrderHandler(w http.ResponseWriter, r *http.Request) {
+	var req dto.ResumeSaleOrderRequest
+	h.changeHold(w, r, &req, func(caller dto.Caller, id uuid.UUID, version int64) error {
+		return h.saleOrderService.ResumeSaleOrder(r.Context(), caller, id, version, &req)
+	})
+}
+
+func (h *SaleOrderHandler) TakeOverSaleOrderHandler(w http.ResponseWriter, r *http.Request) {
+	var req dto.ResumeSaleOrderRequest
+	h.changeHold(w, r, &req, func(caller dto.Caller, id uuid.UUID, version int64) error {
+		return h.saleOrderService.TakeOverSaleOrder(r.Context(), caller, id, version, &req)
+	})
+}
+
+// changeHold decodes req and runs one of the hold transitions. Hold, resume
+// and take-over take the same path parameter and headers and fail the same
+// ways.
+func (h *SaleOrderHandler) changeHold(w http.ResponseWriter, r *http.Request, req any, change func(caller dto.Caller, id uuid.UUID, version int64) error) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgBadRequest, nil)
+		return
+	}
+
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			utils.NewSlogFailToDecode(r, err)
+			utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgBadRequest, nil)
+			return
+		}
+	}
+
+	caller, ok := utils.GetCaller(r.Context())
+	if !ok {
+		slog.ErrorContext(r.Context(), constants.MsgFailedToExtractValueFromJWT, "path", r.URL.Path)
+		utils.NewJSONResponse(w, http.StatusUnauthorized, constants.MsgStatusError, constants.MsgUnauthorized, nil)
+		return
+	}
+
+	version, err := utils.ParseIfMatch(r)
+	if err != nil {
+		if errors.Is(err, apperror.ErrPreconditionRequired) {
+			utils.NewJSONResponse(w, http.StatusPreconditionRequired, constants.MsgStatusError, constants.MsgPreconditionRequired, nil)
+			return
+		}
+
+		utils.NewJSONResponse(w, http.StatusPreconditionFailed, constants.MsgStatusError, constants.MsgVersionMismatch, nil)
+		return
+	}
+
+	err = change(caller, id, version)
+	if err != nil {
+		if errors.Is(err, apperror.ErrInvalidHold) {
+			utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgInvalidHold, nil)
+			return
+		}
+
+		if errors.Is(err, apperror.ErrVersionMismatch) {
+			utils.NewJSONResponse(w, http.StatusPreconditionFailed, constants.MsgStatusError, constants.MsgVersionMismatch, nil)
+			return
+		}
+
+		if errors.Is(err, apperror.ErrNotFound) {
+			utils.NewJSONResponse(w, http.StatusNotFound, constants.MsgStatusError, constants.MsgNotFound, nil)
+			return
+		}
+
+		if errors.Is(err, apperror.ErrForbidden) {
+			utils.NewJSONResponse(w, http.StatusForbidden, constants.MsgStatusError, constants.MsgAccessDenied, nil)
+			return
+		}
+
+		if errors.Is(err, apperror.ErrSaleOrderNotDraft) {
+			utils.NewJSONResponse(w, http.StatusConflict, constants.MsgStatusError, constants.MsgSaleOrderNotDraft, nil)
+			return
+		}
+
+		if errors.Is(err, apperror.ErrSaleOrderNotHeld) {
+			utils.NewJSONResponse(w, http.StatusConflict, constants.MsgStatusError, constants.MsgSaleOrderNotHeld, nil)
+			return
+		}
+
+		utils.NewSlogInternalServerError(r, err)
+		utils.NewJSONResponse(w, http.StatusInternalServerError, constants.MsgStatusError, constants.MsgInternalServerError, nil)
+		return
+	}
+
+	utils.NewJSONResponse(w, http.StatusOK, constants.MsgStatusSuccess, constants.MsgSuccessUpdate, nil)
+}
+
+func (h *SaleOrderHandler) GetHeldSaleOrdersHandler(w http.ResponseWriter, r *http.Request) {
+	caller, ok := utils.GetCaller(r.Context())
+	if !ok {
+		slog.ErrorContext(r.Context(), constants.MsgFailedToExtractValueFromJWT, "path", r.URL.Path)
+		utils.NewJSONResponse(w, http.StatusUnauthorized, constants.MsgStatusError, constants.MsgUnauthorized, nil)
+		return
+	}
+
+	storeID, err := utils.ParseUUIDParam(r, "store_id")
+	if err != nil {
+		utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgInvalidFilter, nil)
+		return
+	}
+
+	saleOrders, err := h.saleOrderService.GetHeldSaleOrders(r.Context(), caller, r.URL.Query().Get("register_id"), storeID)
+	if err != nil {
+		utils.NewSlogInternalServerError(r, err)
+		utils.NewJSONResponse(w, http.StatusInternalServerError, constants.MsgStatusError, constants.MsgInternalServerError, nil)
+		return
+	}
+
+	utils.NewJSONResponse(w, http.StatusOK, constants.MsgStatusSuccess, constants.MsgSuccessRetrieve, saleOrders)
+}
+
+func (h *SaleOrderHandler) GetSaleOrderHoldEventsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgBadRequest, nil)
+		return
+	}
+
+	caller, ok := utils.GetCaller(r.Context())
+	if !ok {
+		slog.ErrorContext(r.Context(), constants.MsgFailedToExtractValueFromJWT, "path", r.URL.Path)
+		utils.NewJSONResponse(w, http.StatusUnauthorized, constants.MsgStatusError, constants.MsgUnauthorized, nil)
+		return
+	}
+
+	events, err := h.saleOrderService.GetSaleOrderHoldEvents(r.Context(), caller, id)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			utils.NewJSONResponse(w, http.StatusNotFound, constants.MsgStatusError, constants.MsgNotFound, nil)
+			return
+		}
+
+		utils.NewSlogInternalServerError(r, err)
+		utils.NewJSONResponse(w, http.StatusInternalServerError, constants.MsgStatusError, constants.MsgInternalServerError, nil)
+		return
+	}
+
+	utils.NewJSONResponse(w, http.StatusOK, constants.MsgStatusSuccess, constants.MsgSuccessRetrieve, events)
+}
@@ -0,0 +1,25 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/hafiztri123/kki-be/internal/dto"
+)
+
+// RedeemPointsHandler pays part of a draft with loyalty points. Like other
+// sale order changes it needs If-Match.
+func (h *SaleOrderHandler) RedeemPointsHandler(w http.ResponseWriter, r *http.Request) {
+	var req dto.RedeemPointsRequest
+	var redemption *dto.LoyaltyRedemptionResponse
+	h.changeRedemption(w, r, &req, func(caller dto.Caller, id uuid.UUID, version int64) (err error) {
+		redemption, err = h.saleOrderService.RedeemPoints(r.Context(), caller, id, version, &req)
+		return err
+	}, func() any { return redemption })
+}
+
+func (h *SaleOrderHandler) ReleasePointsRedemptionHandler(w http.ResponseWriter, r *http.Request) {
+	h.changeRedemption(w, r, nil, func(caller dto.Caller, id uuid.UUID, version int64) error {
+		return h.saleOrderService.ReleasePointsRedemption(r.Context(), caller, id, version)
+	}, func() any { return nil })
+}
@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/google/uuid"
+	apperror "github.com/hafiztri123/kki-be/internal/app_error"
+	"github.com/hafiztri123/kki-be/internal/constants"
+	"github.com/hafiztri123/kki-be/internal/dto"
+	"github.com/hafiztri123/kki-be/internal/utils"
+)
+
+// RedeemGiftCardHandler pays part of a draft with a gift card or store
+// credit. Like other sale order changes it needs If-Match.
+func (h *SaleOrderHandler) RedeemGiftCardHandler(w http.ResponseWriter, r *http.Request) {
+	var req dto.RedeemGiftCardRequest
+	var redemption *dto.GiftCardRedemptionResponse
+	h.changeRedemption(w, r, &req, func(caller dto.Caller, id uuid.UUID, version int64) (err error) {
+		redemption, err = h.saleOrderService.RedeemGiftCard(r.Context(), caller, id, version, &req)
+		return err
+	}, func() any { return redemption })
+}
+
+func (h *SaleOrderHandler) ReleaseGiftCardRedemptionsHandler(w http.ResponseWriter, r *http.Request) {
+	h.changeRedemption(w, r, nil, func(caller dto.Caller, id uuid.UUID, version int64) error {
+		return h.saleOrderService.ReleaseGiftCardRedemptions(r.Context(), caller, id, version)
+	}, func() any { return nil })
+}
+
+// changeRedemption decodes req, when given, and runs a points, gift card or
+// voucher change. result is called after the change for the response data; a
+// rejected voucher sends it along with the error.
+func (h *SaleOrderHandler) changeRedemption(w http.ResponseWriter, r *http.Request, req any, change func(caller dto.Caller, id uuid.UUID, version int64) error, result func() any) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgBadRequest, nil)
+		return
+	}
+
+	if req != nil {
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			utils.NewSlogFailToDecode(r, err)
+			utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgBadRequest, nil)
+			return
+		}
+	}
+
+	caller, ok := utils.GetCaller(r.Context())
+	if !ok {
+		slog.ErrorContext(r.Context(), constants.MsgFailedToExtractValueFromJWT, "path", r.URL.Path)
+		utils.NewJSONResponse(w, http.StatusUnauthorized, constants.MsgStatusError, constants.MsgUnauthorized, nil)
+		return
+	}
+
+	version, err := utils.ParseIfMatch(r)
+	if err != nil {
+		if errors.Is(err, apperror.ErrPreconditionRequired) {
+			utils.NewJSONResponse(w, http.StatusPreconditionRequired, constants.MsgStatusError, constants.MsgPreconditionRequired, nil)
+			return
+		}
+
+		utils.NewJSONResponse(w, http.StatusPreconditionFailed, constants.MsgStatusError, constants.MsgVersionMismatch, nil)
+		return
+	}
+
+	err = change(caller, id, version)
+	if err != nil {
+		if errors.Is(err, apperror.ErrInvalidRedemption) {
+			utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgInvalidRedemption, nil)
+			return
+		}
+
+		if errors.Is(err, apperror.ErrVersionMismatch) {
+			utils.NewJSONResponse(w, http.StatusPreconditionFailed, constants.MsgStatusError, constants.MsgVersionMismatch, nil)
+			return
+		}
+
+		if errors.Is(err, apperror.ErrNotFound) {
+			utils.NewJSONResponse(w, http.StatusNotFound, constants.MsgStatusError, constants.MsgNotFound, nil)
+			return
+		}
+
+		if errors.Is(err, apperror.ErrForbidden) {
+			utils.NewJSONResponse(w, http.StatusForbidden, constants.MsgStatusError, constants.MsgAccessDenied, nil)
+			return
+		}
+
+		if errors.Is(err, apperror.ErrSaleOrderNotDraft) {
+			utils.NewJSONResponse(w, http.StatusConflict, constants.MsgStatusError, constants.MsgSaleOrderNotDraft, nil)
+			return
+		}
+
+		if errors.Is(err, apperror.ErrLoyaltyDisabled) {
+			utils.NewJSONResponse(w, http.StatusConflict, constants.MsgStatusError, constants.MsgLoyaltyDisabled, nil)
+			return
+		}
+
+		if errors.Is(err, apperror.ErrInsufficientPoints) {
+			utils.NewJSONResponse(w, http.StatusConflict, constants.MsgStatusError, constants.MsgInsufficientPoints, nil)
+			return
+		}
+
+		if errors.Is(err, apperror.ErrInvalidGiftCardRedemption) {
+			utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgInvalidGiftCardRedemption, nil)
+			return
+		}
+
+		if errors.Is(err, apperror.ErrGiftCardExpired) {
+			utils.NewJSONResponse(w, http.StatusConflict, constants.MsgStatusError, constants.MsgGiftCardExpired, nil)
+			return
+		}
+
+		if errors.Is(err, apperror.ErrInsufficientGiftCardBalance) {
+			utils.NewJSONResponse(w, http.StatusConflict, constants.MsgStatusError, constants.MsgInsufficientGiftCardBalance, nil)
+			return
+		}
+
+		if errors.Is(err, apperror.ErrInvalidSaleOrderItem) {
+			utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgInvalidSaleOrderItem, nil)
+			return
+		}
+
+		if errors.Is(err, apperror.ErrPromotionUsageLimitReached) {
+			utils.NewJSONResponse(w, http.StatusConflict, constants.MsgStatusError, constants.MsgPromotionUsageLimitReached, nil)
+			return
+		}
+
+		if errors.Is(err, apperror.ErrVoucherNotApplicable) {
+			utils.NewJSONResponse(w, http.StatusConflict, constants.MsgStatusError, constants.MsgVoucherNotApplicable, result())
+			return
+		}
+
+		if errors.Is(err, apperror.ErrRedemptionsExceedTotal) {
+			utils.NewJSONResponse(w, http.StatusConflict, constants.MsgStatusError, constants.MsgRedemptionsExceedTotal, nil)
+			return
+		}
+
+		utils.NewSlogInternalServerError(r, err)
+		utils.NewJSONResponse(w, http.StatusInternalServerError, constants.MsgStatusError, constants.MsgInternalServerError, nil)
+		return
+	}
+
+	utils.NewJSONResponse(w, http.StatusOK, constants.MsgStatusSuccess, constants.MsgSuccessUpdate, result())
+}
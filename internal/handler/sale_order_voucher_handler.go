@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/hafiztri123/kki-be/internal/dto"
+)
+
+// ApplyVoucherHandler applies a voucher code to a draft and returns the
+// repriced order. A code that cannot be used gets a 409 with the reason.
+func (h *SaleOrderHandler) ApplyVoucherHandler(w http.ResponseWriter, r *http.Request) {
+	var req dto.ApplyVoucherRequest
+	var saleOrder *dto.SaleOrderResponse
+	var rejection *dto.VoucherRejectionResponse
+	h.changeRedemption(w, r, &req, func(caller dto.Caller, id uuid.UUID, version int64) (err error) {
+		saleOrder, rejection, err = h.saleOrderService.ApplyVoucher(r.Context(), caller, id, version, &req)
+		return err
+	}, func() any {
+		if rejection != nil {
+			return rejection
+		}
+		return saleOrder
+	})
+}
+
+func (h *SaleOrderHandler) RemoveVoucherHandler(w http.ResponseWriter, r *http.Request) {
+	var saleOrder *dto.SaleOrderResponse
+	h.changeRedemption(w, r, nil, func(caller dto.Caller, id uuid.UUID, version int64) (err error) {
+		saleOrder, err = h.saleOrderService.RemoveVoucher(r.Context(), caller, id, version)
+		return err
+	}, func() any { return saleOrder })
+}
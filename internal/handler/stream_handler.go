@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/hafiztri123/kki-be/internal/constants"
+	"github.com/hafiztri123/kki-be/internal/dto"
+	"github.com/hafiztri123/kki-be/internal/service"
+	"github.com/hafiztri123/kki-be/internal/utils"
+)
+
+const (
+	// streamHeartbeat is how often an idle feed sends a comment, so proxies
+	// keep the connection open and clients notice when it drops.
+	streamHeartbeat = 15 * time.Second
+	// streamRetry is how long clients wait before reconnecting, in
+	// milliseconds.
+	streamRetry = 3000
+)
+
+type StreamHandler struct {
+	saleOrderStreamService *service.SaleOrderStreamService
+}
+
+func NewStreamHandler(saleOrderStreamService *service.SaleOrderStreamService) *StreamHandler {
+	return &StreamHandler{
+		saleOrderStreamService: saleOrderStreamService,
+	}
+}
+
+// StreamSaleOrdersHandler pushes changes to the sale orders the caller may
+// read as Server-Sent Events. A client reconnecting with Last-Event-ID first
+// gets the events it missed, or a reset event telling it to refetch when it
+// missed too many.
+func (h *StreamHandler) StreamSaleOrdersHandler(w http.ResponseWriter, r *http.Request) {
+	var lastEventID *int64
+	if value := r.Header.Get("Last-Event-ID"); value != "" {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || id < 0 {
+			utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgBadRequest, nil)
+			return
+		}
+		lastEventID = &id
+	}
+
+	caller, ok := utils.GetCaller(r.Context())
+	if !ok {
+		slog.ErrorContext(r.Context(), constants.MsgFailedToExtractValueFromJWT, "path", r.URL.Path)
+		utils.NewJSONResponse(w, http.StatusUnauthorized, constants.MsgStatusError, constants.MsgUnauthorized, nil)
+		return
+	}
+
+	stream, err := h.saleOrderStreamService.SubscribeSaleOrders(r.Context(), caller, lastEventID)
+	if err != nil {
+		utils.NewSlogInternalServerError(r, err)
+		utils.NewJSONResponse(w, http.StatusInternalServerError, constants.MsgStatusError, constants.MsgInternalServerError, nil)
+		return
+	}
+	defer stream.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	rc := http.NewResponseController(w)
+	rc.SetWriteDeadline(time.Time{})
+
+	fmt.Fprintf(w, "retry: %d\n\n", streamRetry)
+
+	replayed := make(map[int64]struct{}, len(stream.Replay))
+	for _, event := range stream.Replay {
+		writeStreamEvent(w, event)
+		replayed[event.ID] = struct{}{}
+	}
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+		case event, ok := <-stream.Events:
+			if !ok {
+				return
+			}
+			if _, ok := replayed[event.ID]; ok {
+				continue
+			}
+			writeStreamEvent(w, event)
+		}
+
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// writeStreamEvent writes an event in the text/event-stream format. The data
+// is compact JSON, so it fits on one data line.
+func writeStreamEvent(w http.ResponseWriter, event dto.StreamEvent) {
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, event.Data)
+}
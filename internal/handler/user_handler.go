@@ -145,6 +145,11 @@ func (u *UserHandler) CreateCashierHandler(w http.ResponseWriter, r *http.Reques
 			return
 		}
 
+		if errors.Is(err, apperror.ErrInvalidStore) {
+			utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgInvalidStore, nil)
+			return
+		}
+
 		utils.NewSlogInternalServerError(r, err)
 		utils.NewJSONResponse(
 			w,
@@ -167,6 +172,26 @@ func (u *UserHandler) CreateCashierHandler(w http.ResponseWriter, r *http.Reques
 
 func (u *UserHandler) GetCashiersHandler(w http.ResponseWriter, r *http.Request) {
 	pagination := utils.ParsePaginationParams(r)
+
+	if pagination.UseCursor {
+		cursor, err := utils.DecodeCursor(pagination.Cursor)
+		if err != nil {
+			utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgInvalidPagination, nil)
+			return
+		}
+
+		cashiers, info, err := u.userService.GetCashiersPage(r.Context(), cursor, pagination.Limit, pagination.IncludeTotal)
+		if err != nil {
+			utils.NewSlogInternalServerError(r, err)
+			utils.NewJSONResponse(w, http.StatusInternalServerError, constants.MsgStatusError, constants.MsgInternalServerError, nil)
+			return
+		}
+
+		utils.SetCursorLinks(w, r, pagination, info)
+		utils.NewJSONResponse(w, http.StatusOK, constants.MsgStatusSuccess, constants.MsgSuccessRetrieve, utils.NewCursorPaginatedResponse(cashiers, info, pagination.Limit))
+		return
+	}
+
 	offset := utils.CalculateOffset(pagination.Page, pagination.Limit)
 
 	cashiers, totalCount, err := u.userService.GetCashiers(r.Context(), pagination.Limit, offset)
@@ -177,6 +202,7 @@ func (u *UserHandler) GetCashiersHandler(w http.ResponseWriter, r *http.Request)
 	}
 
 	paginatedResponse := utils.NewPaginatedResponse(cashiers, totalCount, pagination.Page, pagination.Limit)
+	utils.SetPageLinks(w, r, pagination, totalCount)
 
 	utils.NewJSONResponse(w, http.StatusOK, constants.MsgStatusSuccess, constants.MsgSuccessRetrieve, paginatedResponse)
 }
@@ -196,6 +222,7 @@ func (u *UserHandler) GetCashierByIDHandler(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	utils.SetETag(w, cashier.Version)
 	utils.NewJSONResponse(w, http.StatusOK, constants.MsgStatusSuccess, constants.MsgSuccessRetrieve, cashier)
 }
 
@@ -209,13 +236,29 @@ func (u *UserHandler) UpdateCashierHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	err := u.userService.UpdateCashier(r.Context(), id, &req)
+	version, err := utils.ParseIfMatch(r)
+	if err != nil {
+		if errors.Is(err, apperror.ErrPreconditionRequired) {
+			utils.NewJSONResponse(w, http.StatusPreconditionRequired, constants.MsgStatusError, constants.MsgPreconditionRequired, nil)
+			return
+		}
+
+		utils.NewJSONResponse(w, http.StatusPreconditionFailed, constants.MsgStatusError, constants.MsgVersionMismatch, nil)
+		return
+	}
+
+	err = u.userService.UpdateCashier(r.Context(), id, version, &req)
 	if err != nil {
 		if errors.Is(err, apperror.ErrNotFound) {
 			utils.NewJSONResponse(w, http.StatusNotFound, constants.MsgStatusError, constants.MsgNotFound, nil)
 			return
 		}
 
+		if errors.Is(err, apperror.ErrVersionMismatch) {
+			utils.NewJSONResponse(w, http.StatusPreconditionFailed, constants.MsgStatusError, constants.MsgVersionMismatch, nil)
+			return
+		}
+
 		if errors.Is(err, apperror.ErrEmailAlreadyExists) {
 			utils.NewJSONResponse(
 				w,
@@ -227,6 +270,11 @@ func (u *UserHandler) UpdateCashierHandler(w http.ResponseWriter, r *http.Reques
 			return
 		}
 
+		if errors.Is(err, apperror.ErrInvalidStore) {
+			utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgInvalidStore, nil)
+			return
+		}
+
 		utils.NewSlogInternalServerError(r, err)
 		utils.NewJSONResponse(w, http.StatusInternalServerError, constants.MsgStatusError, constants.MsgInternalServerError, nil)
 		return
@@ -238,13 +286,29 @@ func (u *UserHandler) UpdateCashierHandler(w http.ResponseWriter, r *http.Reques
 func (u *UserHandler) DeleteCashierHandler(w http.ResponseWriter, r *http.Request) {
 	id := r.PathValue("id")
 
-	err := u.userService.DeleteCashier(r.Context(), id)
+	version, err := utils.ParseIfMatch(r)
+	if err != nil {
+		if errors.Is(err, apperror.ErrPreconditionRequired) {
+			utils.NewJSONResponse(w, http.StatusPreconditionRequired, constants.MsgStatusError, constants.MsgPreconditionRequired, nil)
+			return
+		}
+
+		utils.NewJSONResponse(w, http.StatusPreconditionFailed, constants.MsgStatusError, constants.MsgVersionMismatch, nil)
+		return
+	}
+
+	err = u.userService.DeleteCashier(r.Context(), id, version)
 	if err != nil {
 		if errors.Is(err, apperror.ErrNotFound) {
 			utils.NewJSONResponse(w, http.StatusNotFound, constants.MsgStatusError, constants.MsgNotFound, nil)
 			return
 		}
 
+		if errors.Is(err, apperror.ErrVersionMismatch) {
+			utils.NewJSONResponse(w, http.StatusPreconditionFailed, constants.MsgStatusError, constants.MsgVersionMismatch, nil)
+			return
+		}
+
 		utils.NewSlogInternalServerError(r, err)
 		utils.NewJSONResponse(w, http.StatusInternalServerError, constants.MsgStatusError, constants.MsgInternalServerError, nil)
 		return
@@ -0,0 +1,33 @@
+package mailer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FileTransport writes each message as an .eml file in a directory instead of
+// sending it, so emails can be opened in a mail client during development.
+type FileTransport struct {
+	dir string
+}
+
+func NewFileTransport(dir string) *FileTransport {
+	return &FileTransport{dir: dir}
+}
+
+func (t *FileTransport) Send(ctx context.Context, m *Message) error {
+	now := time.Now()
+	msg, err := m.Bytes(now)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(t.dir, os.ModePerm); err != nil {
+		return err
+	}
+
+	name := now.Format("20060102-150405") + "-" + randomID()[:8] + ".eml"
+	return os.WriteFile(filepath.Join(t.dir, name), msg, 0644)
+}
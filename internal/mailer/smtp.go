@@ -0,0 +1,48 @@
+package mailer
+
+import (
+	"context"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"time"
+)
+
+// SMTPTransport sends through an SMTP server. The connection is upgraded with
+// STARTTLS when the server offers it, and the credentials are only sent over
+// TLS or to localhost.
+type SMTPTransport struct {
+	addr string
+	auth smtp.Auth
+}
+
+// NewSMTPTransport sends through host:port, authenticating when username is
+// not empty.
+func NewSMTPTransport(host, port, username, password string) *SMTPTransport {
+	t := &SMTPTransport{addr: net.JoinHostPort(host, port)}
+	if username != "" {
+		t.auth = smtp.PlainAuth("", username, password, host)
+	}
+	return t
+}
+
+func (t *SMTPTransport) Send(ctx context.Context, m *Message) error {
+	from, err := mail.ParseAddress(m.From)
+	if err != nil {
+		return err
+	}
+
+	to, err := mail.ParseAddress(m.To)
+	if err != nil {
+		return err
+	}
+
+	msg, err := m.Bytes(time.Now())
+	if err != nil {
+		return err
+	}
+
+	// net/smtp does not take a context; a stuck server is cut off by the
+	// dial and I/O timeouts of the operating system instead.
+	return smtp.SendMail(t.addr, t.auth, from.Address, []string{to.Address}, msg)
+}
@@ -0,0 +1,127 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	apperror "github.com/hafiztri123/kki-be/internal/app_error"
+	"github.com/hafiztri123/kki-be/internal/constants"
+	"github.com/hafiztri123/kki-be/internal/dto"
+	"github.com/hafiztri123/kki-be/internal/service"
+	"github.com/hafiztri123/kki-be/internal/utils"
+)
+
+const (
+	maxIdempotencyKeyLength = 255
+	maxIdempotentBodyBytes  = 1 << 20
+)
+
+// responseRecorder buffers a response so it can be stored before anything is
+// sent to the client.
+type responseRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func newResponseRecorder() *responseRecorder {
+	return &responseRecorder{header: make(http.Header), status: http.StatusOK}
+}
+
+func (rec *responseRecorder) Header() http.Header         { return rec.header }
+func (rec *responseRecorder) Write(b []byte) (int, error) { return rec.body.Write(b) }
+func (rec *responseRecorder) WriteHeader(status int)      { rec.status = status }
+
+func requestFingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + r.URL.Path + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// IdempotencyMiddleware makes a handler safe to retry when the client sends an
+// Idempotency-Key header. The first request runs and its response is stored;
+// retries with the same key and body get the stored response, and a retry with
+// a different body is rejected with 422. Bodies over 1MB are rejected with
+// 413. Requests without the header pass through unchanged. It must run after
+// JWTMiddleware.
+func IdempotencyMiddleware(next http.HandlerFunc, idempotencyService *service.IdempotencyService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimSpace(r.Header.Get("Idempotency-Key"))
+		if key == "" {
+			next(w, r)
+			return
+		}
+
+		if len(key) > maxIdempotencyKeyLength {
+			utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgInvalidIdempotencyKey, nil)
+			return
+		}
+
+		caller, ok := utils.GetCaller(r.Context())
+		if !ok {
+			slog.ErrorContext(r.Context(), constants.MsgFailedToExtractValueFromJWT, "path", r.URL.Path)
+			utils.NewJSONResponse(w, http.StatusUnauthorized, constants.MsgStatusError, constants.MsgUnauthorized, nil)
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxIdempotentBodyBytes))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				utils.NewJSONResponse(w, http.StatusRequestEntityTooLarge, constants.MsgStatusError, constants.MsgRequestBodyTooLarge, nil)
+				return
+			}
+
+			utils.NewSlogFailToDecode(r, err)
+			utils.NewJSONResponse(w, http.StatusBadRequest, constants.MsgStatusError, constants.MsgBadRequest, nil)
+			return
+		}
+
+		var header http.Header
+		response, replayed, err := idempotencyService.Execute(r.Context(), caller.ID, key, requestFingerprint(r, body),
+			func(ctx context.Context) *dto.IdempotentResponse {
+				rec := newResponseRecorder()
+				req := r.WithContext(ctx)
+				req.Body = io.NopCloser(bytes.NewReader(body))
+
+				next(rec, req)
+
+				header = rec.header
+				return &dto.IdempotentResponse{
+					Status:      rec.status,
+					ContentType: rec.header.Get("Content-Type"),
+					Body:        rec.body.Bytes(),
+				}
+			})
+		if err != nil {
+			if errors.Is(err, apperror.ErrIdempotencyKeyMismatch) {
+				utils.NewJSONResponse(w, http.StatusUnprocessableEntity, constants.MsgStatusError, constants.MsgIdempotencyKeyMismatch, nil)
+				return
+			}
+
+			utils.NewSlogInternalServerError(r, err)
+			utils.NewJSONResponse(w, http.StatusInternalServerError, constants.MsgStatusError, constants.MsgInternalServerError, nil)
+			return
+		}
+
+		if replayed {
+			w.Header().Set("Content-Type", response.ContentType)
+			w.Header().Set("Idempotent-Replayed", "true")
+		} else {
+			for k, v := range header {
+				w.Header()[k] = v
+			}
+		}
+
+		w.WriteHeader(response.Status)
+		_, _ = w.Write(response.Body)
+	}
+}
@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hafiztri123/kki-be/internal/constants"
+	"github.com/hafiztri123/kki-be/internal/utils"
+)
+
+// RateLimiter allows each client a number of requests per window. It counts
+// in memory, so every instance of the API limits on its own.
+type RateLimiter struct {
+	mu        sync.Mutex
+	limit     int
+	window    time.Duration
+	clients   map[string]*rateWindow
+	nextSweep time.Time
+}
+
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+func NewRateLimiter(limit int, window time.Duration) *RateLimiter {
+	return &RateLimiter{
+		limit:   limit,
+		window:  window,
+		clients: make(map[string]*rateWindow),
+	}
+}
+
+// RateLimitFromEnv reads a per-minute limit from key, falling back to
+// defaultLimit when it is unset or not a positive number.
+func RateLimitFromEnv(key string, defaultLimit int) int {
+	limit, err := strconv.Atoi(utils.GetEnvOrDefault(key, ""))
+	if err != nil || limit <= 0 {
+		return defaultLimit
+	}
+	return limit
+}
+
+// Allow counts a request of client at now. When the client is over the limit
+// it returns false and how long until its window ends.
+func (l *RateLimiter) Allow(client string, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Forget clients whose window has ended, so the map does not grow with
+	// every address ever seen.
+	if now.After(l.nextSweep) {
+		for key, w := range l.clients {
+			if now.Sub(w.start) >= l.window {
+				delete(l.clients, key)
+			}
+		}
+		l.nextSweep = now.Add(l.window)
+	}
+
+	w, ok := l.clients[client]
+	if !ok || now.Sub(w.start) >= l.window {
+		w = &rateWindow{start: now}
+		l.clients[client] = w
+	}
+
+	if w.count >= l.limit {
+		return false, w.start.Add(l.window).Sub(now)
+	}
+
+	w.count++
+	return true, 0
+}
+
+// RateLimitMiddleware rejects requests over the limit of their client address
+// with 429 and a Retry-After header.
+func RateLimitMiddleware(next http.HandlerFunc, limiter *RateLimiter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		client, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			client = r.RemoteAddr
+		}
+
+		if ok, retryAfter := limiter.Allow(client, time.Now()); !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(max(int(math.Ceil(retryAfter.Seconds())), 1)))
+			utils.NewJSONResponse(w, http.StatusTooManyRequests, constants.MsgStatusError, constants.MsgTooManyRequests, nil)
+			return
+		}
+
+		next(w, r)
+	}
+}
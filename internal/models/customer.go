@@ -0,0 +1,35 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hafiztri123/kki-be/internal/money"
+)
+
+// Customer is the master record of a buyer. A customer merged into another
+// is soft deleted and keeps a pointer to the surviving record.
+type Customer struct {
+	ID           uuid.UUID
+	Name         string
+	Phone        sql.NullString
+	Email        sql.NullString
+	Address      sql.NullString
+	Tags         []string
+	Notes        sql.NullString
+	MergedIntoID uuid.NullUUID
+	CreatedBy    uuid.UUID
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	DeletedAt    sql.NullTime
+}
+
+// CustomerValue sums the completed orders of a customer in one currency.
+type CustomerValue struct {
+	Currency      money.Currency
+	OrderCount    int64
+	LifetimeValue money.Amount
+	FirstOrderAt  time.Time
+	LastOrderAt   time.Time
+}
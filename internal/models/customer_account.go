@@ -0,0 +1,46 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hafiztri123/kki-be/internal/money"
+)
+
+// CustomerAccount is a customer allowed to buy on credit up to CreditLimit.
+// Balance is what the customer owes. CustomerID links it to a customer record.
+type CustomerAccount struct {
+	ID           uuid.UUID
+	CustomerID   uuid.NullUUID
+	CustomerName string
+	Phone        sql.NullString
+	Currency     money.Currency
+	CreditLimit  money.Amount
+	Balance      money.Amount
+	CreatedBy    uuid.UUID
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// AvailableCredit is how much more can be charged before the limit is hit.
+func (a *CustomerAccount) AvailableCredit() money.Amount {
+	return money.Max(a.CreditLimit-a.Balance, 0)
+}
+
+// CustomerAccountEntry is one line of an account ledger. Amount is always
+// positive; EntryType tells whether it raised or lowered the balance.
+// RecordedBy is not set on reversals made by the system.
+type CustomerAccountEntry struct {
+	ID           uuid.UUID
+	AccountID    uuid.UUID
+	EntryType    string
+	Amount       money.Amount
+	BalanceAfter money.Amount
+	SaleOrderID  uuid.NullUUID
+	Method       sql.NullString
+	Reference    sql.NullString
+	ApprovedBy   uuid.NullUUID
+	RecordedBy   uuid.NullUUID
+	CreatedAt    time.Time
+}
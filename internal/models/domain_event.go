@@ -0,0 +1,27 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DomainEvent is a change other features react to, such as an order changing
+// status. AggregateID is the order or user that changed and Payload the JSON
+// of it as of the change.
+type DomainEvent struct {
+	ID            uuid.UUID
+	Sequence      int64
+	EventType     string
+	AggregateID   uuid.UUID
+	Payload       json.RawMessage
+	Status        string
+	Attempts      int
+	NextAttemptAt time.Time
+	LastError     sql.NullString
+	DispatchedAt  sql.NullTime
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
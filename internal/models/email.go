@@ -0,0 +1,27 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EmailMessage is an email in the outbox. It is rendered when it is sent, so
+// Subject is only set once it has been. CreatedBy is NULL for emails queued
+// by a sale order status change rather than asked for by a user.
+type EmailMessage struct {
+	ID            uuid.UUID
+	Kind          string
+	SaleOrderID   uuid.UUID
+	Recipient     string
+	Subject       sql.NullString
+	Status        string
+	Attempts      int
+	LastError     sql.NullString
+	NextAttemptAt time.Time
+	SentAt        sql.NullTime
+	CreatedBy     uuid.NullUUID
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
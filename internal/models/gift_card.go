@@ -0,0 +1,51 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hafiztri123/kki-be/internal/money"
+)
+
+// GiftCard is a prepaid gift card or a store credit balance, identified by
+// Code. Balance is what is left to spend.
+type GiftCard struct {
+	ID            uuid.UUID
+	Code          string
+	CardType      string
+	Currency      money.Currency
+	InitialAmount money.Amount
+	Balance       money.Amount
+	ExpiresAt     sql.NullTime
+	CustomerID    uuid.NullUUID
+	IssuedBy      uuid.UUID
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// Expired reports whether the card can no longer be used at now.
+func (g *GiftCard) Expired(now time.Time) bool {
+	return g.ExpiresAt.Valid && !now.Before(g.ExpiresAt.Time)
+}
+
+// GiftCardTransaction is one line of a card ledger. Amount is always
+// positive; TransactionType tells whether it raised or lowered the balance.
+type GiftCardTransaction struct {
+	ID              uuid.UUID
+	GiftCardID      uuid.UUID
+	TransactionType string
+	Amount          money.Amount
+	BalanceAfter    money.Amount
+	SaleOrderID     uuid.NullUUID
+	Method          sql.NullString
+	Reference       sql.NullString
+	RecordedBy      uuid.NullUUID
+	CreatedAt       time.Time
+}
+
+// GiftCardTender is what one card currently pays on a sale order.
+type GiftCardTender struct {
+	GiftCardID uuid.UUID
+	Amount     money.Amount
+}
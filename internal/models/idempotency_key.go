@@ -0,0 +1,21 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IdempotencyKey remembers the response to a request sent with an
+// Idempotency-Key header so retries can be answered without running it again.
+type IdempotencyKey struct {
+	UserID              uuid.UUID
+	Key                 string
+	RequestFingerprint  string
+	ResponseStatus      sql.NullInt32
+	ResponseContentType sql.NullString
+	ResponseBody        []byte
+	CreatedAt           time.Time
+	ExpiresAt           time.Time
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hafiztri123/kki-be/internal/money"
+)
+
+type Invoice struct {
+	ID              uuid.UUID
+	InvoiceNumber   string
+	SaleOrderID     uuid.UUID
+	CustomerName    string
+	StoreID         uuid.NullUUID
+	Currency        money.Currency
+	TotalAmount     money.Amount
+	PaidAmount      money.Amount
+	PaymentTermDays int
+	IssuedAt        time.Time
+	DueAt           time.Time
+	Status          string
+	CreatedBy       uuid.UUID
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+// Balance is the amount still owed on the invoice.
+func (i *Invoice) Balance() money.Amount {
+	return i.TotalAmount - i.PaidAmount
+}
+
+type InvoicePayment struct {
+	ID         uuid.UUID
+	InvoiceID  uuid.UUID
+	Amount     money.Amount
+	Method     string
+	Reference  sql.NullString
+	PaidAt     time.Time
+	RecordedBy uuid.UUID
+	CreatedAt  time.Time
+}
+
+// ReceivablesAging is the unpaid balance of one customer in one currency,
+// split by how many days its invoices are past due.
+type ReceivablesAging struct {
+	CustomerName string
+	Currency     money.Currency
+	Days0To30    money.Amount
+	Days31To60   money.Amount
+	Days61To90   money.Amount
+	Days90Plus   money.Amount
+	Total        money.Amount
+}
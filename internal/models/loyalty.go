@@ -0,0 +1,70 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hafiztri123/kki-be/internal/money"
+)
+
+// LoyaltySettings is the earn and redeem rule. A customer earns one point for
+// every SpendPerPoint spent and a point pays PointValue when redeemed. Points
+// expire ExpiryDays after they are earned; zero means they never expire.
+type LoyaltySettings struct {
+	Enabled         bool
+	SpendPerPoint   money.Amount
+	PointValue      money.Amount
+	ExpiryDays      int
+	MinRedeemPoints int64
+	UpdatedBy       uuid.NullUUID
+	UpdatedAt       time.Time
+}
+
+// LoyaltyMultiplier scales the points earned on lines of one category.
+type LoyaltyMultiplier struct {
+	Category   string
+	Multiplier float64
+}
+
+// LoyaltyPointEntry is one line of a customer's points ledger. Points is
+// positive for earned and restored points and negative for redeemed,
+// reversed and expired ones. Positive entries are lots and RemainingPoints is
+// what redemptions have left of them.
+type LoyaltyPointEntry struct {
+	ID              uuid.UUID
+	CustomerID      uuid.UUID
+	EntryType       string
+	Points          int64
+	RemainingPoints int64
+	ExpiresAt       sql.NullTime
+	SaleOrderID     uuid.NullUUID
+	Amount          money.Amount
+	CreatedBy       uuid.NullUUID
+	CreatedAt       time.Time
+}
+
+// LoyaltyOrderPoints sums the ledger entries of one sale order by type.
+// Points are positive; amounts are the money value of the redemptions.
+// EarnCustomerID and RedeemCustomerID are whose points were earned and
+// redeemed, which may differ when the order changed customer in between.
+type LoyaltyOrderPoints struct {
+	EarnCustomerID   uuid.NullUUID
+	RedeemCustomerID uuid.NullUUID
+	Earned           int64
+	Reversed         int64
+	Redeemed         int64
+	Restored         int64
+	RedeemedAmount   money.Amount
+	RestoredAmount   money.Amount
+}
+
+// NetEarned is what the order earned and still holds.
+func (p *LoyaltyOrderPoints) NetEarned() int64 {
+	return p.Earned - p.Reversed
+}
+
+// NetRedeemed is what is still redeemed against the order.
+func (p *LoyaltyOrderPoints) NetRedeemed() (int64, money.Amount) {
+	return p.Redeemed - p.Restored, p.RedeemedAmount - p.RestoredAmount
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hafiztri123/kki-be/internal/money"
+)
+
+type Product struct {
+	ID        uuid.UUID
+	SKU       string
+	Name      string
+	Category  string
+	UnitPrice money.Amount
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt sql.NullTime
+}
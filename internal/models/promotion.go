@@ -0,0 +1,32 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hafiztri123/kki-be/internal/money"
+)
+
+type Promotion struct {
+	ID          uuid.UUID
+	Name        string
+	Type        string
+	ProductID   uuid.NullUUID
+	Category    sql.NullString
+	BuyQuantity sql.NullInt32
+	GetQuantity sql.NullInt32
+	Percentage  sql.NullFloat64
+	Amount      money.NullAmount
+	MinSpend    money.Amount
+	StartsAt    time.Time
+	EndsAt      time.Time
+	Priority    int
+	Stackable   bool
+	UsageLimit  sql.NullInt32
+	UsageCount  int
+	IsActive    bool
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	DeletedAt   sql.NullTime
+}
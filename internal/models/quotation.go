@@ -0,0 +1,48 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hafiztri123/kki-be/internal/money"
+)
+
+type Quotation struct {
+	ID                    uuid.UUID
+	CustomerName          string
+	StoreID               uuid.NullUUID
+	Currency              money.Currency
+	PriceIncludesTax      bool
+	TaxExempt             bool
+	TaxExemptionReference sql.NullString
+	SubtotalAmount        money.Amount
+	DiscountAmount        money.Amount
+	TaxAmount             money.Amount
+	TotalAmount           money.Amount
+	Status                string
+	ValidUntil            time.Time
+	SaleOrderID           uuid.NullUUID
+	CreatedBy             uuid.UUID
+	CreatedAt             time.Time
+	UpdatedAt             time.Time
+	DeletedAt             sql.NullTime
+}
+
+type QuotationItem struct {
+	ID             uuid.UUID
+	QuotationID    uuid.UUID
+	LineNumber     int
+	ProductID      uuid.UUID
+	ProductName    string
+	Category       string
+	Quantity       int
+	UnitPrice      money.Amount
+	SubtotalAmount money.Amount
+	DiscountAmount money.Amount
+	TotalAmount    money.Amount
+	TaxRate        float64
+	TaxableAmount  money.Amount
+	TaxAmount      money.Amount
+	CreatedAt      time.Time
+}
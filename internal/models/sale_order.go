@@ -5,16 +5,34 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/hafiztri123/kki-be/internal/money"
 )
 
 type SaleOrder struct {
-	ID          uuid.UUID    `json:"id"`
-	OrderNumber string       `json:"order_number"`
-	CustomerName string      `json:"customer_name"`
-	TotalAmount float64      `json:"total_amount"`
-	Status      string       `json:"status"`
-	CreatedBy   uuid.UUID    `json:"created_by"`
-	CreatedAt   time.Time    `json:"created_at"`
-	UpdatedAt   time.Time    `json:"updated_at"`
-	DeletedAt   sql.NullTime `json:"deleted_at,omitempty"`
+	ID                    uuid.UUID        `json:"id"`
+	OrderNumber           string           `json:"order_number"`
+	CustomerName          string           `json:"customer_name"`
+	StoreID               uuid.NullUUID    `json:"store_id"`
+	Currency              money.Currency   `json:"currency"`
+	PriceIncludesTax      bool             `json:"price_includes_tax"`
+	TaxExempt             bool             `json:"tax_exempt"`
+	TaxExemptionReference sql.NullString   `json:"tax_exemption_reference"`
+	SubtotalAmount        money.Amount     `json:"subtotal_amount"`
+	DiscountAmount        money.Amount     `json:"discount_amount"`
+	TaxAmount             money.Amount     `json:"tax_amount"`
+	TotalAmount           money.Amount     `json:"total_amount"`
+	Status                string           `json:"status"`
+	CreatedBy             uuid.UUID        `json:"created_by"`
+	DeviceID              sql.NullString   `json:"device_id"`
+	SyncedAt              sql.NullTime     `json:"synced_at"`
+	HoldLabel             sql.NullString   `json:"hold_label"`
+	RegisterID            sql.NullString   `json:"register_id"`
+	HeldAt                sql.NullTime     `json:"held_at"`
+	QuotationID           uuid.NullUUID    `json:"quotation_id"`
+	CustomerID            uuid.NullUUID    `json:"customer_id"`
+	CashTendered          money.NullAmount `json:"cash_tendered"`
+	CreatedAt             time.Time        `json:"created_at"`
+	UpdatedAt             time.Time        `json:"updated_at"`
+	Version               int64            `json:"version"`
+	DeletedAt             sql.NullTime     `json:"deleted_at,omitempty"`
 }
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hafiztri123/kki-be/internal/money"
+)
+
+// SortField is one entry of a multi-field sort. Field is the public name of
+// the field and is mapped to a column by the repository.
+type SortField struct {
+	Field      string
+	Descending bool
+}
+
+// SaleOrderFilter narrows a sale order listing. Zero values mean "no filter".
+type SaleOrderFilter struct {
+	Statuses     []string
+	CreatedFrom  *time.Time
+	CreatedTo    *time.Time
+	CustomerName string
+	CustomerID   *uuid.UUID
+	CreatedBy    *uuid.UUID
+	StoreID      *uuid.UUID
+	RegisterID   string
+	MinAmount    *money.Amount
+	MaxAmount    *money.Amount
+	Query        string
+	Sort         []SortField
+}
+
+// Keyset is the position of a row in a list ordered by (created_at, id).
+type Keyset struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+// KeysetPage asks for up to Limit rows after Cursor, or before it when
+// Backward is set. A nil Cursor is the first page.
+type KeysetPage struct {
+	Cursor   *Keyset
+	Backward bool
+	Limit    int
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SaleOrderHoldEvent records a hold, resume, take-over or expiry of an order.
+// ActorID is empty for expiries done by the background job.
+type SaleOrderHoldEvent struct {
+	ID                uuid.UUID
+	SaleOrderID       uuid.UUID
+	Action            string
+	ActorID           uuid.NullUUID
+	PreviousCashierID uuid.NullUUID
+	RegisterID        sql.NullString
+	CreatedAt         time.Time
+}
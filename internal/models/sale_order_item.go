@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hafiztri123/kki-be/internal/money"
+)
+
+type SaleOrderItem struct {
+	ID             uuid.UUID
+	SaleOrderID    uuid.UUID
+	LineNumber     int
+	ProductID      uuid.UUID
+	ProductName    string
+	Category       string
+	Quantity       int
+	UnitPrice      money.Amount
+	SubtotalAmount money.Amount
+	DiscountAmount money.Amount
+	TotalAmount    money.Amount
+	TaxRate        float64
+	TaxableAmount  money.Amount
+	TaxAmount      money.Amount
+	CreatedAt      time.Time
+}
+
+type SaleOrderPromotion struct {
+	ID             uuid.UUID
+	SaleOrderID    uuid.UUID
+	PromotionID    uuid.UUID
+	PromotionName  string
+	DiscountAmount money.Amount
+	CreatedAt      time.Time
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SaleOrderSnapshot is the full state of an order at one version: the order
+// row, its lines and the promotions and voucher applied to it.
+type SaleOrderSnapshot struct {
+	Order      SaleOrder            `json:"order"`
+	Items      []SaleOrderItem      `json:"items"`
+	Promotions []SaleOrderPromotion `json:"promotions"`
+	Voucher    *SaleOrderVoucher    `json:"voucher,omitempty"`
+}
+
+// SaleOrderRevision keeps the state an order had before an update. Its
+// RevisionNumber is the order version the snapshot was taken at.
+type SaleOrderRevision struct {
+	ID             uuid.UUID
+	SaleOrderID    uuid.UUID
+	RevisionNumber int64
+	Snapshot       SaleOrderSnapshot
+	ChangedBy      uuid.UUID
+	CreatedAt      time.Time
+}
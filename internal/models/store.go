@@ -0,0 +1,25 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hafiztri123/kki-be/internal/money"
+)
+
+type Store struct {
+	ID                uuid.UUID
+	Code              string
+	Name              string
+	Address           string
+	Currency          money.Currency
+	PriceIncludesTax  bool
+	DefaultTaxRate    float64
+	OrderNumberFormat sql.NullString
+	ReceiptHeader     sql.NullString
+	ReceiptFooter     sql.NullString
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+	DeletedAt         sql.NullTime
+}
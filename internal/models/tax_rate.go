@@ -0,0 +1,19 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type TaxRate struct {
+	ID        uuid.UUID
+	StoreID   uuid.NullUUID
+	Category  string
+	Name      string
+	Rate      float64
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt sql.NullTime
+}
@@ -15,7 +15,9 @@ type User struct {
 	Password string 
 	Role string 
 	Name string 
+	StoreID uuid.NullUUID
 	CreatedAt time.Time 
 	UpdatedAt time.Time
+	Version int64
 	DeletedAt sql.NullTime
 }
\ No newline at end of file
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hafiztri123/kki-be/internal/money"
+)
+
+// VoucherCampaign holds the rules shared by a batch of voucher codes. A code
+// can be used MaxUsesPerCode times, once for single-use codes; null limits are
+// not applied.
+type VoucherCampaign struct {
+	ID                 uuid.UUID
+	Name               string
+	DiscountType       string
+	Percentage         sql.NullFloat64
+	Amount             money.NullAmount
+	MaxDiscount        money.NullAmount
+	MinSpend           money.Amount
+	Currency           money.Currency
+	MaxUsesPerCode     sql.NullInt32
+	MaxUsesPerCustomer sql.NullInt32
+	StartsAt           time.Time
+	ExpiresAt          sql.NullTime
+	CreatedBy          uuid.UUID
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+}
+
+// Voucher is one code of a campaign, loaded together with the campaign.
+type Voucher struct {
+	ID         uuid.UUID
+	CampaignID uuid.UUID
+	Code       string
+	UseCount   int
+	CreatedAt  time.Time
+	Campaign   VoucherCampaign
+}
+
+// SaleOrderVoucher is the code applied to a sale order. Like promotions it is
+// part of the order pricing and is replaced whenever the order is repriced.
+type SaleOrderVoucher struct {
+	ID             uuid.UUID
+	SaleOrderID    uuid.UUID
+	VoucherID      uuid.UUID
+	Code           string
+	DiscountAmount money.Amount
+	CreatedAt      time.Time
+}
+
+// VoucherRedemption records a code used by a paid sale order.
+type VoucherRedemption struct {
+	ID             uuid.UUID
+	VoucherID      uuid.UUID
+	CampaignID     uuid.UUID
+	SaleOrderID    uuid.UUID
+	CustomerID     uuid.NullUUID
+	DiscountAmount money.Amount
+	RedeemedAt     time.Time
+	ReversedAt     sql.NullTime
+}
+
+// VoucherCampaignStats counts a campaign's codes and how they were used.
+type VoucherCampaignStats struct {
+	Codes          int
+	CodesUsed      int
+	Redemptions    int
+	DiscountAmount money.Amount
+}
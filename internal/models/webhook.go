@@ -0,0 +1,41 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WebhookEndpoint is a URL told about the events it subscribes to.
+type WebhookEndpoint struct {
+	ID          uuid.UUID
+	URL         string
+	Description sql.NullString
+	Secret      string
+	Events      []string
+	Active      bool
+	CreatedBy   uuid.UUID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	DeletedAt   sql.NullTime
+}
+
+// WebhookDelivery is one event sent, or to be sent, to one endpoint. Payload
+// is the request body, fixed when the event is raised.
+type WebhookDelivery struct {
+	ID             uuid.UUID
+	EndpointID     uuid.UUID
+	EventID        uuid.UUID
+	EventType      string
+	Payload        json.RawMessage
+	Status         string
+	Attempts       int
+	NextAttemptAt  time.Time
+	LastStatusCode sql.NullInt32
+	LastError      sql.NullString
+	DeliveredAt    sql.NullTime
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
@@ -0,0 +1,286 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Scale is the number of decimal places stored for every amount, matching the
+// DECIMAL(15, 2) columns.
+const Scale = 2
+
+const minorUnitsPerUnit = 100
+
+var ErrInvalidAmount = errors.New("invalid amount")
+
+// Amount is an exact monetary amount in minor units (hundredths of the
+// currency unit). It encodes to JSON as a decimal string such as "12500.00".
+type Amount int64
+
+func FromUnits(units int64) Amount {
+	return Amount(units * minorUnitsPerUnit)
+}
+
+// Parse reads a plain decimal such as "12500", "-3.5" or "0.25". More than two
+// decimal places is rejected rather than silently rounded.
+func Parse(s string) (Amount, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, ErrInvalidAmount
+	}
+
+	negative := false
+	switch s[0] {
+	case '-':
+		negative = true
+		s = s[1:]
+	case '+':
+		s = s[1:]
+	}
+
+	whole, fraction, hasFraction := strings.Cut(s, ".")
+	if whole == "" && (!hasFraction || fraction == "") {
+		return 0, ErrInvalidAmount
+	}
+	if len(fraction) > Scale {
+		return 0, ErrInvalidAmount
+	}
+	if whole == "" {
+		whole = "0"
+	}
+
+	for _, part := range []string{whole, fraction} {
+		for _, c := range part {
+			if c < '0' || c > '9' {
+				return 0, ErrInvalidAmount
+			}
+		}
+	}
+
+	fraction += strings.Repeat("0", Scale-len(fraction))
+
+	units, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil {
+		return 0, ErrInvalidAmount
+	}
+	minor, err := strconv.ParseInt(fraction, 10, 64)
+	if err != nil {
+		return 0, ErrInvalidAmount
+	}
+
+	if units > (1<<63-1)/minorUnitsPerUnit-1 {
+		return 0, ErrInvalidAmount
+	}
+
+	value := Amount(units*minorUnitsPerUnit + minor)
+	if negative {
+		value = -value
+	}
+
+	return value, nil
+}
+
+func MustParse(s string) Amount {
+	a, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
+
+func (a Amount) String() string {
+	sign := ""
+	v := int64(a)
+	if v < 0 {
+		sign = "-"
+		v = -v
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, v/minorUnitsPerUnit, v%minorUnitsPerUnit)
+}
+
+func (a Amount) MinorUnits() int64 {
+	return int64(a)
+}
+
+func (a Amount) IsZero() bool {
+	return a == 0
+}
+
+func (a Amount) IsNegative() bool {
+	return a < 0
+}
+
+func (a Amount) Mul(quantity int) Amount {
+	return a * Amount(quantity)
+}
+
+// MulDiv returns a * num / den rounded with the given mode. It is computed with
+// arbitrary precision so intermediate products cannot overflow.
+func (a Amount) MulDiv(num, den int64, mode RoundingMode) Amount {
+	if den == 0 {
+		panic("money: division by zero")
+	}
+
+	product := new(big.Int).Mul(big.NewInt(int64(a)), big.NewInt(num))
+	divisor := big.NewInt(den)
+	if divisor.Sign() < 0 {
+		product.Neg(product)
+		divisor.Neg(divisor)
+	}
+
+	quotient, remainder := new(big.Int).QuoRem(product, divisor, new(big.Int))
+	q := quotient.Int64()
+
+	if remainder.Sign() == 0 {
+		return Amount(q)
+	}
+
+	// Compare 2*|remainder| with the divisor to decide which way a tie falls.
+	twice := new(big.Int).Abs(remainder)
+	twice.Lsh(twice, 1)
+	cmp := twice.Cmp(divisor)
+	negative := product.Sign() < 0
+
+	return Amount(roundQuotient(q, negative, cmp, mode))
+}
+
+// Percent returns pct percent of a. Percentages are taken to two decimal
+// places, which is the precision the rate columns are stored with.
+func (a Amount) Percent(pct float64, mode RoundingMode) Amount {
+	return a.MulDiv(percentBasisPoints(pct), 100*100, mode)
+}
+
+// IncludedPercent returns the part of a gross amount that a percentage charged
+// on top of the net amount accounts for, e.g. the 11% PPN inside a tax
+// inclusive price: a * pct / (100 + pct).
+func (a Amount) IncludedPercent(pct float64, mode RoundingMode) Amount {
+	bp := percentBasisPoints(pct)
+	return a.MulDiv(bp, 100*100+bp, mode)
+}
+
+func percentBasisPoints(pct float64) int64 {
+	return int64(pct*100 + copySign(0.5, pct))
+}
+
+func copySign(v, sign float64) float64 {
+	if sign < 0 {
+		return -v
+	}
+	return v
+}
+
+func Min(a, b Amount) Amount {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func Max(a, b Amount) Amount {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func Sum(amounts ...Amount) Amount {
+	var total Amount
+	for _, a := range amounts {
+		total += a
+	}
+	return total
+}
+
+// Allocate splits total over the given weights in proportion to them. Every
+// share is rounded down and the leftover minor units go to the largest
+// remainders, so the shares always add up to total exactly.
+func Allocate(total Amount, weights []Amount) []Amount {
+	shares := make([]Amount, len(weights))
+
+	var weightSum Amount
+	for _, w := range weights {
+		if w > 0 {
+			weightSum += w
+		}
+	}
+	if weightSum == 0 || total == 0 {
+		return shares
+	}
+
+	remainders := make([]*big.Int, len(weights))
+	var allocated Amount
+	for i, w := range weights {
+		if w <= 0 {
+			remainders[i] = big.NewInt(-1)
+			continue
+		}
+
+		product := new(big.Int).Mul(big.NewInt(int64(total)), big.NewInt(int64(w)))
+		quotient, remainder := new(big.Int).QuoRem(product, big.NewInt(int64(weightSum)), new(big.Int))
+		shares[i] = Amount(quotient.Int64())
+		remainders[i] = remainder.Abs(remainder)
+		allocated += shares[i]
+	}
+
+	step := Amount(1)
+	if total < 0 {
+		step = -1
+	}
+
+	for allocated != total {
+		best := -1
+		for i, r := range remainders {
+			if r.Sign() < 0 {
+				continue
+			}
+			if best == -1 || r.Cmp(remainders[best]) > 0 {
+				best = i
+			}
+		}
+		if best == -1 {
+			break
+		}
+
+		shares[best] += step
+		allocated += step
+		remainders[best] = big.NewInt(-1)
+	}
+
+	return shares
+}
+
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.String())
+}
+
+// UnmarshalJSON accepts both a decimal string and a bare JSON number, so
+// existing clients sending numbers keep working. Numbers are parsed from their
+// literal text and never go through float64.
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	text := string(data)
+	if len(data) > 0 && data[0] == '"' {
+		if err := json.Unmarshal(data, &text); err != nil {
+			return err
+		}
+	}
+
+	if strings.ContainsAny(text, "eE") {
+		return ErrInvalidAmount
+	}
+
+	parsed, err := Parse(text)
+	if err != nil {
+		return err
+	}
+
+	*a = parsed
+	return nil
+}
@@ -0,0 +1,277 @@
+package money
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Amount
+		wantErr bool
+	}{
+		{in: "12500", want: 1250000},
+		{in: "-3.5", want: -350},
+		{in: "0.25", want: 25},
+		{in: "+1.05", want: 105},
+		{in: ".5", want: 50},
+		{in: "5.", want: 500},
+		{in: " 7.10 ", want: 710},
+		{in: "-0.01", want: -1},
+		{in: "0", want: 0},
+		{in: "92233720368547757.99", want: 9223372036854775799},
+		{in: "-92233720368547757.99", want: -9223372036854775799},
+		{in: "", wantErr: true},
+		{in: "-", wantErr: true},
+		{in: ".", wantErr: true},
+		{in: "1.234", wantErr: true},
+		{in: "1e3", wantErr: true},
+		{in: "1,000", wantErr: true},
+		{in: "--1", wantErr: true},
+		{in: "1.-5", wantErr: true},
+		{in: "92233720368547758", wantErr: true},
+		{in: "99999999999999999999", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := Parse(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("Parse(%q) = %d, want error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Parse(%q) returned error %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Parse(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		in   Amount
+		want string
+	}{
+		{in: 0, want: "0.00"},
+		{in: 5, want: "0.05"},
+		{in: -5, want: "-0.05"},
+		{in: -350, want: "-3.50"},
+		{in: 1250000, want: "12500.00"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.in.String(); got != tt.want {
+			t.Errorf("Amount(%d).String() = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestMulDiv(t *testing.T) {
+	const maxAmount = Amount(1<<63 - 1)
+
+	tests := []struct {
+		a        Amount
+		num, den int64
+		mode     RoundingMode
+		want     Amount
+	}{
+		// Exactly half.
+		{a: 5, num: 1, den: 2, mode: RoundHalfUp, want: 3},
+		{a: 5, num: 1, den: 2, mode: RoundHalfEven, want: 2},
+		{a: 15, num: 1, den: 2, mode: RoundHalfEven, want: 8},
+		{a: 5, num: 1, den: 2, mode: RoundDown, want: 2},
+		{a: 5, num: 1, den: 2, mode: RoundUp, want: 3},
+		{a: -5, num: 1, den: 2, mode: RoundHalfUp, want: -3},
+		{a: -5, num: 1, den: 2, mode: RoundHalfEven, want: -2},
+		{a: -15, num: 1, den: 2, mode: RoundHalfEven, want: -8},
+		{a: -5, num: 1, den: 2, mode: RoundDown, want: -2},
+		{a: -5, num: 1, den: 2, mode: RoundUp, want: -3},
+		{a: 5, num: 1, den: -2, mode: RoundHalfUp, want: -3},
+		// Either side of half.
+		{a: 10, num: 1, den: 3, mode: RoundHalfUp, want: 3},
+		{a: 10, num: 1, den: 3, mode: RoundHalfEven, want: 3},
+		{a: 10, num: 1, den: 3, mode: RoundUp, want: 4},
+		{a: 20, num: 1, den: 3, mode: RoundHalfUp, want: 7},
+		{a: 20, num: 1, den: 3, mode: RoundHalfEven, want: 7},
+		{a: 20, num: 1, den: 3, mode: RoundDown, want: 6},
+		{a: -20, num: 1, den: 3, mode: RoundHalfUp, want: -7},
+		// Exact results are not rounded.
+		{a: 300, num: 2, den: 3, mode: RoundUp, want: 200},
+		// The intermediate product overflows int64.
+		{a: maxAmount, num: 3, den: 3, mode: RoundHalfUp, want: maxAmount},
+		{a: 1 << 62, num: 4, den: 8, mode: RoundHalfUp, want: 1 << 61},
+	}
+
+	for _, tt := range tests {
+		if got := tt.a.MulDiv(tt.num, tt.den, tt.mode); got != tt.want {
+			t.Errorf("Amount(%d).MulDiv(%d, %d, %d) = %d, want %d", tt.a, tt.num, tt.den, tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestMulDivByZero(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("MulDiv by zero did not panic")
+		}
+	}()
+
+	Amount(100).MulDiv(1, 0, RoundHalfUp)
+}
+
+func TestPercent(t *testing.T) {
+	tests := []struct {
+		a        Amount
+		pct      float64
+		included bool
+		want     Amount
+	}{
+		{a: FromUnits(100), pct: 11, want: FromUnits(11)},
+		{a: FromUnits(111), pct: 11, included: true, want: FromUnits(11)},
+		{a: 999, pct: 12.5, want: 125},
+		{a: -999, pct: 12.5, want: -125},
+		{a: 1000, pct: 0.07, want: 1},
+	}
+
+	for _, tt := range tests {
+		var got Amount
+		if tt.included {
+			got = tt.a.IncludedPercent(tt.pct, RoundHalfUp)
+		} else {
+			got = tt.a.Percent(tt.pct, RoundHalfUp)
+		}
+		if got != tt.want {
+			t.Errorf("Amount(%d) %v%% (included %v) = %d, want %d", tt.a, tt.pct, tt.included, got, tt.want)
+		}
+	}
+}
+
+func TestAllocate(t *testing.T) {
+	tests := []struct {
+		name    string
+		total   Amount
+		weights []Amount
+		want    []Amount
+	}{
+		{name: "even split remainder to first", total: 100, weights: []Amount{1, 1, 1}, want: []Amount{34, 33, 33}},
+		{name: "negative total", total: -100, weights: []Amount{1, 1, 1}, want: []Amount{-34, -33, -33}},
+		{name: "largest remainder", total: 100, weights: []Amount{1, 2}, want: []Amount{33, 67}},
+		{name: "remainder over several shares", total: 5, weights: []Amount{1, 1, 1, 1, 1, 1, 1}, want: []Amount{1, 1, 1, 1, 1, 0, 0}},
+		{name: "exact", total: 1000, weights: []Amount{1, 2, 3, 4}, want: []Amount{100, 200, 300, 400}},
+		{name: "zero and negative weights", total: 1000, weights: []Amount{3, 0, -5, 1}, want: []Amount{750, 0, 0, 250}},
+		{name: "no positive weights", total: 1000, weights: []Amount{0, -1}, want: []Amount{0, 0}},
+		{name: "zero total", total: 0, weights: []Amount{1, 2}, want: []Amount{0, 0}},
+		{name: "no weights", total: 1000, weights: nil, want: []Amount{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Allocate(tt.total, tt.weights)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Allocate(%d, %v) = %v, want %v", tt.total, tt.weights, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("Allocate(%d, %v) = %v, want %v", tt.total, tt.weights, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestAllocateAddsUp(t *testing.T) {
+	weights := []Amount{333, 1, 7919, 250000, 0, 13}
+	for _, total := range []Amount{1, 99, 10007, -10007, 123456789} {
+		if got := Sum(Allocate(total, weights)...); got != total {
+			t.Errorf("shares of %d add up to %d", total, got)
+		}
+	}
+}
+
+func TestCashRound(t *testing.T) {
+	tests := []struct {
+		currency Currency
+		in       string
+		want     string
+	}{
+		{currency: IDR, in: "12349.99", want: "12300.00"},
+		{currency: IDR, in: "12350.00", want: "12400.00"},
+		{currency: IDR, in: "12351", want: "12400.00"},
+		{currency: IDR, in: "-12350", want: "-12400.00"},
+		{currency: IDR, in: "-12349.99", want: "-12300.00"},
+		{currency: IDR, in: "49.99", want: "0.00"},
+		{currency: SGD, in: "1.02", want: "1.00"},
+		{currency: SGD, in: "1.03", want: "1.05"},
+		{currency: USD, in: "12.34", want: "12.34"},
+		{currency: Currency("XYZ"), in: "12.34", want: "12.34"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.currency.CashRound(MustParse(tt.in)).String(); got != tt.want {
+			t.Errorf("%s.CashRound(%s) = %s, want %s", tt.currency, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRoundTo(t *testing.T) {
+	hundred := FromUnits(100)
+
+	tests := []struct {
+		a, increment Amount
+		mode         RoundingMode
+		want         Amount
+	}{
+		{a: FromUnits(150), increment: hundred, mode: RoundHalfEven, want: FromUnits(200)},
+		{a: FromUnits(250), increment: hundred, mode: RoundHalfEven, want: FromUnits(200)},
+		{a: FromUnits(250), increment: hundred, mode: RoundHalfUp, want: FromUnits(300)},
+		{a: FromUnits(201), increment: hundred, mode: RoundUp, want: FromUnits(300)},
+		{a: FromUnits(299), increment: hundred, mode: RoundDown, want: FromUnits(200)},
+		{a: 1234, increment: 0, mode: RoundHalfUp, want: 1234},
+	}
+
+	for _, tt := range tests {
+		if got := RoundTo(tt.a, tt.increment, tt.mode); got != tt.want {
+			t.Errorf("RoundTo(%d, %d, %d) = %d, want %d", tt.a, tt.increment, tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestJSON(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Amount
+		wantErr bool
+	}{
+		{in: `"12.5"`, want: 1250},
+		{in: `12.5`, want: 1250},
+		{in: `-0.01`, want: -1},
+		{in: `null`, want: 0},
+		{in: `1e2`, wantErr: true},
+		{in: `"1.001"`, wantErr: true},
+		{in: `true`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		var got Amount
+		err := json.Unmarshal([]byte(tt.in), &got)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("unmarshal %s = %d, want error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("unmarshal %s = %d, %v, want %d", tt.in, got, err, tt.want)
+		}
+	}
+
+	data, err := json.Marshal(Amount(-1250))
+	if err != nil || string(data) != `"-12.50"` {
+		t.Errorf("marshal = %s, %v, want \"-12.50\"", data, err)
+	}
+}
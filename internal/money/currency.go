@@ -0,0 +1,46 @@
+package money
+
+import "strings"
+
+type Currency string
+
+const (
+	IDR Currency = "IDR"
+	USD Currency = "USD"
+	SGD Currency = "SGD"
+)
+
+const DefaultCurrency = IDR
+
+type currencyRule struct {
+	// cashIncrement is the smallest amount that can be paid in cash.
+	cashIncrement Amount
+	cashRounding  RoundingMode
+}
+
+var currencyRules = map[Currency]currencyRule{
+	IDR: {cashIncrement: FromUnits(100), cashRounding: RoundHalfUp},
+	USD: {cashIncrement: 1, cashRounding: RoundHalfUp},
+	SGD: {cashIncrement: 5, cashRounding: RoundHalfUp},
+}
+
+func ParseCurrency(code string) (Currency, bool) {
+	c := Currency(strings.ToUpper(strings.TrimSpace(code)))
+	_, ok := currencyRules[c]
+	return c, ok
+}
+
+func (c Currency) String() string {
+	return string(c)
+}
+
+// CashRound rounds an amount due to what can actually be paid in cash, e.g.
+// rupiah totals to the nearest Rp100.
+func (c Currency) CashRound(a Amount) Amount {
+	rule, ok := currencyRules[c]
+	if !ok {
+		return a
+	}
+
+	return RoundTo(a, rule.cashIncrement, rule.cashRounding)
+}
@@ -0,0 +1,82 @@
+package money
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var errNullAmount = errors.New("money: cannot scan NULL into Amount, use NullAmount")
+
+// ScanNumeric lets pgx scan NUMERIC/DECIMAL columns straight into an Amount.
+func (a *Amount) ScanNumeric(n pgtype.Numeric) error {
+	if !n.Valid {
+		return errNullAmount
+	}
+
+	v, err := numericToMinorUnits(n)
+	if err != nil {
+		return err
+	}
+
+	*a = Amount(v)
+	return nil
+}
+
+func (a Amount) NumericValue() (pgtype.Numeric, error) {
+	return pgtype.Numeric{Int: big.NewInt(int64(a)), Exp: -Scale, Valid: true}, nil
+}
+
+func numericToMinorUnits(n pgtype.Numeric) (int64, error) {
+	if n.NaN || n.InfinityModifier != pgtype.Finite {
+		return 0, ErrInvalidAmount
+	}
+
+	value := new(big.Int).Set(n.Int)
+	exp := n.Exp + Scale
+	if exp >= 0 {
+		value.Mul(value, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exp)), nil))
+	} else {
+		divisor := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(-exp)), nil)
+		remainder := new(big.Int)
+		value.QuoRem(value, divisor, remainder)
+		if remainder.Sign() != 0 {
+			return 0, ErrInvalidAmount
+		}
+	}
+
+	if !value.IsInt64() {
+		return 0, ErrInvalidAmount
+	}
+
+	return value.Int64(), nil
+}
+
+// NullAmount is an Amount that may be NULL, in the style of sql.NullInt64.
+type NullAmount struct {
+	Amount Amount
+	Valid  bool
+}
+
+func (n *NullAmount) ScanNumeric(v pgtype.Numeric) error {
+	if !v.Valid {
+		*n = NullAmount{}
+		return nil
+	}
+
+	minor, err := numericToMinorUnits(v)
+	if err != nil {
+		return err
+	}
+
+	*n = NullAmount{Amount: Amount(minor), Valid: true}
+	return nil
+}
+
+func (n NullAmount) NumericValue() (pgtype.Numeric, error) {
+	if !n.Valid {
+		return pgtype.Numeric{}, nil
+	}
+	return n.Amount.NumericValue()
+}
@@ -0,0 +1,48 @@
+package money
+
+type RoundingMode int
+
+const (
+	RoundHalfUp RoundingMode = iota
+	RoundHalfEven
+	RoundDown
+	RoundUp
+)
+
+// roundQuotient adjusts a truncated quotient q. cmp compares twice the
+// discarded remainder with the divisor (-1 below half, 0 exactly half, 1 above).
+func roundQuotient(q int64, negative bool, cmp int, mode RoundingMode) int64 {
+	awayFromZero := func() int64 {
+		if negative {
+			return q - 1
+		}
+		return q + 1
+	}
+
+	switch mode {
+	case RoundDown:
+		return q
+	case RoundUp:
+		return awayFromZero()
+	case RoundHalfEven:
+		if cmp > 0 || (cmp == 0 && q%2 != 0) {
+			return awayFromZero()
+		}
+		return q
+	default:
+		if cmp >= 0 {
+			return awayFromZero()
+		}
+		return q
+	}
+}
+
+// RoundTo rounds a to a multiple of increment, e.g. RoundTo(a, FromUnits(100),
+// RoundHalfUp) for rupiah cash rounding to Rp100.
+func RoundTo(a, increment Amount, mode RoundingMode) Amount {
+	if increment <= 0 {
+		return a
+	}
+
+	return a.MulDiv(1, int64(increment), mode) * increment
+}
@@ -0,0 +1,339 @@
+package qrcode
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestFormatBits(t *testing.T) {
+	// Format information of level M for each mask, from the standard.
+	want := [8]int{
+		0b101010000010010,
+		0b101000100100101,
+		0b101111001111100,
+		0b101101101001011,
+		0b100010111111001,
+		0b100000011001110,
+		0b100111110010111,
+		0b100101010100000,
+	}
+
+	for mask, w := range want {
+		if got := formatBits(mask); got != w {
+			t.Errorf("formatBits(%d) = %015b, want %015b", mask, got, w)
+		}
+	}
+}
+
+func TestVersionBits(t *testing.T) {
+	want := map[int]int{
+		7:  0x07c94,
+		8:  0x085bc,
+		9:  0x09a99,
+		10: 0x0a4d3,
+	}
+
+	for version, w := range want {
+		if got := versionBits(version); got != w {
+			t.Errorf("versionBits(%d) = %#x, want %#x", version, got, w)
+		}
+	}
+}
+
+func TestEncodeVersion(t *testing.T) {
+	tests := []struct {
+		length  int
+		version int
+	}{
+		{length: 0, version: 1},
+		{length: 14, version: 1},
+		{length: 15, version: 2},
+		{length: 26, version: 2},
+		{length: 27, version: 3},
+		{length: 62, version: 4},
+		{length: 106, version: 6},
+		{length: 107, version: 7},
+		{length: 152, version: 8},
+		{length: 180, version: 9},
+		{length: 213, version: 10},
+	}
+
+	for _, tt := range tests {
+		code, err := Encode(strings.Repeat("a", tt.length))
+		if err != nil {
+			t.Errorf("Encode of %d bytes returned error %v", tt.length, err)
+			continue
+		}
+		if want := 17 + 4*tt.version; code.Size != want {
+			t.Errorf("Encode of %d bytes is %d modules wide, want %d (version %d)", tt.length, code.Size, want, tt.version)
+		}
+	}
+
+	if _, err := Encode(strings.Repeat("a", 214)); !errors.Is(err, ErrTooLong) {
+		t.Errorf("Encode of 214 bytes returned %v, want ErrTooLong", err)
+	}
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	texts := []string{
+		"",
+		"hello",
+		"https://example.com/receipts/verify?id=4f6a2c1e-8d3b-4c55-9a0e-2b7d1f3c9e80&sig=AbCdEf0123456789",
+		"Struk KKI · Rp12.500,00",
+		strings.Repeat("0123456789abcdef", 8),
+		strings.Repeat("x", 152),
+		strings.Repeat("receipt-", 26) + "end!!",
+	}
+
+	for _, text := range texts {
+		code, err := Encode(text)
+		if err != nil {
+			t.Errorf("Encode(%q) returned error %v", text, err)
+			continue
+		}
+
+		got, err := decode(code)
+		if err != nil {
+			t.Errorf("decoding the code of %q failed: %v", text, err)
+			continue
+		}
+		if got != text {
+			t.Errorf("decoding the code of %q gave %q", text, got)
+		}
+	}
+}
+
+func TestEncodeFunctionPatterns(t *testing.T) {
+	code, err := Encode(strings.Repeat("a", 120))
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := code.Size
+
+	// Finder patterns in three corners.
+	for _, corner := range [][2]int{{0, 0}, {n - 7, 0}, {0, n - 7}} {
+		for dy := 0; dy < 7; dy++ {
+			for dx := 0; dx < 7; dx++ {
+				ring := max(abs(dx-3), abs(dy-3))
+				if want := ring != 2; code.Dark(corner[0]+dx, corner[1]+dy) != want {
+					t.Fatalf("finder at %v: module (%d, %d) dark = %v", corner, dx, dy, !want)
+				}
+			}
+		}
+	}
+
+	// Timing patterns between them.
+	for i := 8; i < n-8; i++ {
+		if code.Dark(i, 6) != (i%2 == 0) || code.Dark(6, i) != (i%2 == 0) {
+			t.Fatalf("timing pattern broken at %d", i)
+		}
+	}
+
+	if !code.Dark(8, n-8) {
+		t.Error("dark module is light")
+	}
+}
+
+// decode reads a code made by Encode back into its text. It checks the
+// format information, removes the mask, verifies every error correction block
+// and parses the byte mode segment.
+func decode(c *Code) (string, error) {
+	version := (c.Size - 17) / 4
+	if version < 1 || version > maxVersion || c.Size != 17+4*version {
+		return "", errors.New("bad size")
+	}
+
+	format1, format2 := readFormat(c)
+	if format1 != format2 {
+		return "", errors.New("format copies differ")
+	}
+	format := format1 ^ 0x5412
+	if format>>13 != 0 {
+		return "", errors.New("error correction level is not M")
+	}
+	mask := format >> 10 & 7
+	if formatBits(mask) != format1 {
+		return "", errors.New("format information fails its check")
+	}
+
+	if version >= 7 {
+		v := 0
+		for i := 0; i < 18; i++ {
+			if c.Dark(c.Size-11+i%3, i/3) {
+				v |= 1 << i
+			}
+		}
+		if v != versionBits(version) {
+			return "", errors.New("version information is wrong")
+		}
+	}
+
+	codewords := readCodewords(c, version, mask)
+
+	data, err := correctBlocks(version, codewords)
+	if err != nil {
+		return "", err
+	}
+
+	return parseByteSegment(version, data)
+}
+
+// readFormat returns both copies of the format information.
+func readFormat(c *Code) (int, int) {
+	var a, b int
+	put := func(v *int, i int, dark bool) {
+		if dark {
+			*v |= 1 << i
+		}
+	}
+
+	for i := 0; i <= 5; i++ {
+		put(&a, i, c.Dark(8, i))
+	}
+	put(&a, 6, c.Dark(8, 7))
+	put(&a, 7, c.Dark(8, 8))
+	put(&a, 8, c.Dark(7, 8))
+	for i := 9; i < 15; i++ {
+		put(&a, i, c.Dark(14-i, 8))
+	}
+
+	for i := 0; i < 8; i++ {
+		put(&b, i, c.Dark(c.Size-1-i, 8))
+	}
+	for i := 8; i < 15; i++ {
+		put(&b, i, c.Dark(8, c.Size-15+i))
+	}
+
+	return a, b
+}
+
+// readCodewords reads the data modules in placement order and removes the
+// mask, using the mask formulas of the standard with i the row and j the
+// column.
+func readCodewords(c *Code, version, mask int) []byte {
+	masked := [8]func(i, j int) bool{
+		func(i, j int) bool { return (i+j)%2 == 0 },
+		func(i, j int) bool { return i%2 == 0 },
+		func(i, j int) bool { return j%3 == 0 },
+		func(i, j int) bool { return (i+j)%3 == 0 },
+		func(i, j int) bool { return (i/2+j/3)%2 == 0 },
+		func(i, j int) bool { return (i*j)%2+(i*j)%3 == 0 },
+		func(i, j int) bool { return ((i*j)%2+(i*j)%3)%2 == 0 },
+		func(i, j int) bool { return ((i+j)%2+(i*j)%3)%2 == 0 },
+	}[mask]
+
+	function := newCode(version).function
+
+	var bits []bool
+	upward := true
+	for col := c.Size - 1; col > 0; col -= 2 {
+		if col == 6 {
+			col--
+		}
+		for k := 0; k < c.Size; k++ {
+			row := k
+			if upward {
+				row = c.Size - 1 - k
+			}
+			for _, x := range []int{col, col - 1} {
+				if function[row][x] {
+					continue
+				}
+				bits = append(bits, c.Dark(x, row) != masked(row, x))
+			}
+		}
+		upward = !upward
+	}
+
+	codewords := make([]byte, len(bits)/8)
+	for i := range codewords {
+		for j := 0; j < 8; j++ {
+			if bits[i*8+j] {
+				codewords[i] |= 1 << (7 - j)
+			}
+		}
+	}
+	return codewords
+}
+
+// correctBlocks de-interleaves the codewords, checks that the syndromes of
+// every block are zero and returns the data codewords.
+func correctBlocks(version int, codewords []byte) ([]byte, error) {
+	b := versionBlocks[version]
+	count := b.blocks1 + b.blocks2
+
+	blocks := make([][]byte, count)
+	sizes := make([]int, count)
+	for i := range blocks {
+		sizes[i] = b.dataBlock1
+		if i >= b.blocks1 {
+			sizes[i] = b.dataBlock2
+		}
+	}
+
+	next := 0
+	for j := 0; j < max(b.dataBlock1, b.dataBlock2); j++ {
+		for i := range blocks {
+			if j < sizes[i] {
+				blocks[i] = append(blocks[i], codewords[next])
+				next++
+			}
+		}
+	}
+	for j := 0; j < b.ecPerBlock; j++ {
+		for i := range blocks {
+			blocks[i] = append(blocks[i], codewords[next])
+			next++
+		}
+	}
+
+	exp := gfExpTable()
+	var data []byte
+	for i, block := range blocks {
+		for k := 0; k < b.ecPerBlock; k++ {
+			// Evaluate the block as a polynomial at alpha^k.
+			var s byte
+			for _, v := range block {
+				s = gfMultiply(s, exp[k]) ^ v
+			}
+			if s != 0 {
+				return nil, errors.New("error correction block does not check")
+			}
+		}
+		data = append(data, block[:sizes[i]]...)
+	}
+
+	return data, nil
+}
+
+func parseByteSegment(version int, data []byte) (string, error) {
+	pos := 0
+	read := func(n int) int {
+		v := 0
+		for i := 0; i < n; i++ {
+			v = v<<1 | int(data[pos/8]>>(7-pos%8)&1)
+			pos++
+		}
+		return v
+	}
+
+	if mode := read(4); mode != 0b0100 {
+		return "", errors.New("not a byte mode segment")
+	}
+
+	countBits := 8
+	if version >= 10 {
+		countBits = 16
+	}
+	length := read(countBits)
+	if pos+8*length > 8*len(data) {
+		return "", errors.New("segment is longer than the data")
+	}
+
+	text := make([]byte, length)
+	for i := range text {
+		text[i] = byte(read(8))
+	}
+	return string(text), nil
+}
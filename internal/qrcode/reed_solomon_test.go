@@ -0,0 +1,82 @@
+package qrcode
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestGFMultiply(t *testing.T) {
+	tests := []struct {
+		x, y, want byte
+	}{
+		{x: 0, y: 0x53, want: 0},
+		{x: 1, y: 0x53, want: 0x53},
+		{x: 0x02, y: 0x80, want: 0x1d},
+		{x: 0x80, y: 0x80, want: 0x13},
+		{x: 0x8e, y: 0x02, want: 0x01},
+	}
+
+	for _, tt := range tests {
+		if got := gfMultiply(tt.x, tt.y); got != tt.want {
+			t.Errorf("gfMultiply(%#x, %#x) = %#x, want %#x", tt.x, tt.y, got, tt.want)
+		}
+		if got := gfMultiply(tt.y, tt.x); got != tt.want {
+			t.Errorf("gfMultiply(%#x, %#x) = %#x, want %#x", tt.y, tt.x, got, tt.want)
+		}
+	}
+}
+
+func TestReedSolomonDivisor(t *testing.T) {
+	// The generator polynomial of degree 7 from the standard, as exponents of
+	// alpha: x^7 + a^87 x^6 + a^229 x^5 + a^146 x^4 + a^149 x^3 + a^238 x^2 +
+	// a^102 x + a^21.
+	exp := gfExpTable()
+	var want []byte
+	for _, e := range []int{87, 229, 146, 149, 238, 102, 21} {
+		want = append(want, exp[e])
+	}
+
+	if got := reedSolomonDivisor(7); !bytes.Equal(got, want) {
+		t.Errorf("reedSolomonDivisor(7) = %v, want %v", got, want)
+	}
+}
+
+func TestReedSolomonRemainder(t *testing.T) {
+	tests := []struct {
+		name       string
+		data, want []byte
+	}{
+		{
+			name: "1-M HELLO WORLD",
+			data: []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17},
+			want: []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23},
+		},
+		{
+			name: "5-Q first block",
+			data: []byte{67, 85, 70, 134, 87, 38, 85, 194, 119, 50, 6, 18, 6, 103, 38},
+			want: []byte{213, 199, 11, 45, 115, 247, 241, 223, 229, 248, 154, 117, 154, 111, 86, 161, 111, 39},
+		},
+	}
+
+	for _, tt := range tests {
+		got := reedSolomonRemainder(tt.data, reedSolomonDivisor(len(tt.want)))
+		if !bytes.Equal(got, tt.want) {
+			t.Errorf("%s: error correction = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// gfExpTable lists the powers of alpha in GF(2^8), built independently of
+// gfMultiply.
+func gfExpTable() [255]byte {
+	var exp [255]byte
+	v := 1
+	for i := range exp {
+		exp[i] = byte(v)
+		v <<= 1
+		if v&0x100 != 0 {
+			v ^= 0x11d
+		}
+	}
+	return exp
+}
@@ -0,0 +1,48 @@
+package receipt
+
+import (
+	"bytes"
+	"embed"
+	htmltemplate "html/template"
+	texttemplate "text/template"
+)
+
+//go:embed templates
+var templates embed.FS
+
+var (
+	emailHTML = htmltemplate.Must(htmltemplate.New("email.html").
+			Funcs(htmltemplate.FuncMap{"amount": formatAmount}).
+			ParseFS(templates, "templates/email.html"))
+	emailText = texttemplate.Must(texttemplate.ParseFS(templates, "templates/email.txt"))
+)
+
+// Email is the wording around a receipt sent by email.
+type Email struct {
+	Heading string
+	Intro   string
+}
+
+// RenderEmail writes r as the plain text and HTML bodies of an email. The
+// text body is the receipt as printed on 80mm paper.
+func RenderEmail(r *Receipt, e Email) (text, html string, err error) {
+	data := struct {
+		Email
+		Receipt *Receipt
+		Text    string
+	}{
+		Email:   e,
+		Receipt: r,
+		Text:    string(renderText(layout(r, Paper80mm.Columns()), Paper80mm.Columns())),
+	}
+
+	var t, h bytes.Buffer
+	if err := emailText.Execute(&t, data); err != nil {
+		return "", "", err
+	}
+	if err := emailHTML.Execute(&h, data); err != nil {
+		return "", "", err
+	}
+
+	return t.String(), h.String(), nil
+}
@@ -0,0 +1,115 @@
+package receipt
+
+import (
+	"bytes"
+
+	"github.com/hafiztri123/kki-be/internal/qrcode"
+)
+
+// ESC/POS commands used by the renderer. See the Epson ESC/POS command
+// reference; every thermal printer we support understands this subset.
+var (
+	escInit        = []byte{0x1b, '@'}
+	escAlignLeft   = []byte{0x1b, 'a', 0}
+	escAlignCenter = []byte{0x1b, 'a', 1}
+	escBoldOff     = []byte{0x1b, 'E', 0}
+	escBoldOn      = []byte{0x1b, 'E', 1}
+	gsSizeNormal   = []byte{0x1d, '!', 0x00}
+	gsSizeTall     = []byte{0x1d, '!', 0x01}
+	// escFeed feeds 4 lines so the last one clears the cutter.
+	escFeed = []byte{0x1b, 'd', 4}
+	// gsCut cuts the paper, leaving a point uncut so it does not drop.
+	gsCut = []byte{0x1d, 'V', 1}
+	// gsRaster prints a bit image, followed by its width in bytes and height
+	// in dots.
+	gsRaster = []byte{0x1d, 'v', '0', 0}
+)
+
+const (
+	// escposDotsPerColumn is the width of a character of the standard font.
+	escposDotsPerColumn = 12
+	// maxQRModuleDots keeps QR codes from taking up more paper than scanners
+	// need.
+	maxQRModuleDots = 6
+)
+
+// renderESCPOS writes the raw bytes to send to a thermal printer. The printer
+// aligns and emphasizes the text itself; characters outside ASCII, which its
+// code page may not have, are printed as '?'.
+func renderESCPOS(rows []row, columns int) []byte {
+	var b bytes.Buffer
+	b.Write(escInit)
+
+	for _, r := range rows {
+		if r.qr != nil {
+			writeRasterQR(&b, r.qr, columns*escposDotsPerColumn)
+			continue
+		}
+
+		if r.centered {
+			b.Write(escAlignCenter)
+		}
+		if r.bold {
+			b.Write(escBoldOn)
+		}
+		if r.tall {
+			b.Write(gsSizeTall)
+		}
+
+		b.WriteString(asciiOnly(r.text))
+		b.WriteByte('\n')
+
+		if r.tall {
+			b.Write(gsSizeNormal)
+		}
+		if r.bold {
+			b.Write(escBoldOff)
+		}
+		if r.centered {
+			b.Write(escAlignLeft)
+		}
+	}
+
+	b.Write(escFeed)
+	b.Write(gsCut)
+	return b.Bytes()
+}
+
+// writeRasterQR prints code as a bit image centred on paper dots wide. A bit
+// image works on every printer, unlike the QR command only some have.
+func writeRasterQR(b *bytes.Buffer, code *qrcode.Code, dots int) {
+	modules := code.Size + 2*qrcode.QuietZone
+	scale := max(min(dots/2/modules, maxQRModuleDots), 1)
+	left := (dots - modules*scale) / 2
+
+	widthBytes := dots / 8
+	height := modules * scale
+	b.Write(gsRaster)
+	b.Write([]byte{byte(widthBytes), byte(widthBytes >> 8), byte(height), byte(height >> 8)})
+
+	line := make([]byte, widthBytes)
+	for y := 0; y < height; y++ {
+		clear(line)
+		for x := 0; x < modules*scale; x++ {
+			mx, my := x/scale-qrcode.QuietZone, y/scale-qrcode.QuietZone
+			if mx < 0 || my < 0 || mx >= code.Size || my >= code.Size || !code.Dark(mx, my) {
+				continue
+			}
+			dot := left + x
+			line[dot/8] |= 0x80 >> (dot % 8)
+		}
+		b.Write(line)
+	}
+}
+
+// asciiOnly replaces characters outside printable ASCII with '?'.
+func asciiOnly(s string) string {
+	var b bytes.Buffer
+	for _, c := range s {
+		if c < 0x20 || c > 0x7e {
+			c = '?'
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
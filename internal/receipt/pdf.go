@@ -0,0 +1,124 @@
+package receipt
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/hafiztri123/kki-be/internal/qrcode"
+)
+
+const (
+	pdfPointsPerMM = 72 / 25.4
+	pdfMargin      = 8.0
+	// Courier characters are 0.6 of the font size wide.
+	pdfCharWidth = 0.6
+	pdfLeading   = 1.25
+)
+
+// renderPDF writes the receipt as a one page PDF the width of the paper roll
+// and as long as the receipt, in Courier so the columns line up as on a
+// thermal printer. The standard PDF fonts need no embedding, which keeps the
+// file small.
+func renderPDF(rows []row, paper Paper) []byte {
+	columns := paper.Columns()
+	pageWidth := float64(paper) * pdfPointsPerMM
+	fontSize := (pageWidth - 2*pdfMargin) / (float64(columns) * pdfCharWidth)
+	leading := fontSize * pdfLeading
+
+	pageHeight := 2 * pdfMargin
+	for _, r := range rows {
+		pageHeight += pdfRowHeight(r, pageWidth, leading)
+	}
+
+	var content bytes.Buffer
+	top := pageHeight - pdfMargin
+	for _, r := range rows {
+		height := pdfRowHeight(r, pageWidth, leading)
+		top -= height
+
+		if r.qr != nil {
+			writePDFQR(&content, r.qr, pageWidth, top, height)
+			continue
+		}
+
+		text := r.text
+		if r.centered {
+			text = center(text, columns)
+		}
+
+		font := "F1"
+		if r.bold {
+			font = "F2"
+		}
+
+		// The baseline sits the descent of the font above the bottom of the
+		// line.
+		y := top + (leading - fontSize)
+		fmt.Fprintf(&content, "BT /%s %.2f Tf 1 0 0 1 %.2f %.2f Tm (%s) Tj ET\n", font, fontSize, pdfMargin, y, pdfString(text))
+	}
+
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /Font << /F1 4 0 R /F2 5 0 R >> >> /Contents 6 0 R >>", pageWidth, pageHeight),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Courier-Bold /Encoding /WinAnsiEncoding >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()),
+	}
+
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.4\n")
+
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	return b.Bytes()
+}
+
+// pdfQRWidth is the share of the page width a QR code takes, quiet zone
+// included.
+const pdfQRWidth = 0.5
+
+func pdfRowHeight(r row, pageWidth, leading float64) float64 {
+	if r.qr != nil {
+		return pageWidth * pdfQRWidth
+	}
+	return leading
+}
+
+// writePDFQR draws code as filled squares, centred in the square of height
+// points whose bottom is at y.
+func writePDFQR(b *bytes.Buffer, code *qrcode.Code, pageWidth, y, height float64) {
+	modules := code.Size + 2*qrcode.QuietZone
+	module := height / float64(modules)
+	left := (pageWidth-height)/2 + module*qrcode.QuietZone
+	top := y + height - module*qrcode.QuietZone
+
+	b.WriteString("0 g\n")
+	for my := 0; my < code.Size; my++ {
+		for mx := 0; mx < code.Size; mx++ {
+			if code.Dark(mx, my) {
+				fmt.Fprintf(b, "%.2f %.2f %.2f %.2f re\n", left+module*float64(mx), top-module*float64(my+1), module, module)
+			}
+		}
+	}
+	b.WriteString("f\n")
+}
+
+// pdfString escapes text for a PDF literal string. Characters the standard
+// fonts cannot show are printed as '?'.
+func pdfString(s string) string {
+	s = asciiOnly(s)
+	return strings.NewReplacer(`\`, `\\`, `(`, `\(`, `)`, `\)`).Replace(s)
+}
@@ -0,0 +1,307 @@
+package receipt
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hafiztri123/kki-be/internal/money"
+	"github.com/hafiztri123/kki-be/internal/qrcode"
+)
+
+// Receipt is a sale order as it is printed. It is filled in by the service;
+// the renderers only lay it out.
+type Receipt struct {
+	Title    string
+	Header   []string
+	Details  []Field
+	Lines    []Line
+	Totals   []Entry
+	Payments []Entry
+	// Notice is printed in bold above the footer, e.g. for cancelled orders.
+	Notice string
+	// QR is printed as a QR code above the footer, or as text where the
+	// format cannot show one.
+	QR     string
+	Footer []string
+}
+
+type Field struct {
+	Label string
+	Value string
+}
+
+type Line struct {
+	Name      string
+	Quantity  int
+	UnitPrice money.Amount
+	Amount    money.Amount
+	Discount  money.Amount
+}
+
+// Entry is a labelled amount in the totals or payments. Bold entries, such as
+// the total, stand out.
+type Entry struct {
+	Label  string
+	Amount money.Amount
+	Bold   bool
+}
+
+type Format string
+
+const (
+	FormatText   Format = "text"
+	FormatESCPOS Format = "escpos"
+	FormatPDF    Format = "pdf"
+)
+
+// ParseFormat reads the format query parameter. An empty one means text.
+func ParseFormat(s string) (Format, bool) {
+	switch f := Format(strings.ToLower(strings.TrimSpace(s))); f {
+	case "":
+		return FormatText, true
+	case FormatText, FormatESCPOS, FormatPDF:
+		return f, true
+	default:
+		return "", false
+	}
+}
+
+func (f Format) ContentType() string {
+	switch f {
+	case FormatESCPOS:
+		return "application/octet-stream"
+	case FormatPDF:
+		return "application/pdf"
+	default:
+		return "text/plain; charset=utf-8"
+	}
+}
+
+// Paper is the width of a thermal paper roll in millimetres.
+type Paper int
+
+const (
+	Paper58mm Paper = 58
+	Paper80mm Paper = 80
+)
+
+// ParsePaper reads a width such as "58mm" or "80". An empty one means 80mm.
+func ParsePaper(s string) (Paper, bool) {
+	switch strings.TrimSuffix(strings.ToLower(strings.TrimSpace(s)), "mm") {
+	case "":
+		return Paper80mm, true
+	case "58":
+		return Paper58mm, true
+	case "80":
+		return Paper80mm, true
+	default:
+		return 0, false
+	}
+}
+
+// Columns is how many characters of the printer's standard font fit on a
+// line: 384 and 576 dots at 12 dots a character.
+func (p Paper) Columns() int {
+	if p == Paper58mm {
+		return 32
+	}
+	return 48
+}
+
+// Render lays out r for paper in the given format.
+func Render(r *Receipt, format Format, paper Paper) []byte {
+	rows := layout(r, paper.Columns())
+
+	switch format {
+	case FormatESCPOS:
+		return renderESCPOS(rows, paper.Columns())
+	case FormatPDF:
+		return renderPDF(rows, paper)
+	default:
+		return renderText(rows, paper.Columns())
+	}
+}
+
+// row is one printed line. Every renderer gets the same rows, so the three
+// formats only differ in how they show alignment and emphasis.
+type row struct {
+	text     string
+	centered bool
+	bold     bool
+	// tall rows are printed double height where the format allows it.
+	tall bool
+	// qr rows are printed as the code, or as their text in plain text.
+	qr *qrcode.Code
+}
+
+func layout(r *Receipt, columns int) []row {
+	var rows []row
+	rule := row{text: strings.Repeat("-", columns)}
+
+	if r.Title != "" {
+		for _, text := range wrap(r.Title, columns) {
+			rows = append(rows, row{text: text, centered: true, bold: true, tall: true})
+		}
+	}
+	for _, line := range r.Header {
+		for _, text := range wrap(line, columns) {
+			rows = append(rows, row{text: text, centered: true})
+		}
+	}
+	rows = append(rows, rule)
+
+	for _, f := range r.Details {
+		rows = append(rows, columnsRow(f.Label, f.Value, columns)...)
+	}
+	rows = append(rows, rule)
+
+	for _, line := range r.Lines {
+		for _, text := range wrap(line.Name, columns) {
+			rows = append(rows, row{text: text})
+		}
+		quantity := "  " + strconv.Itoa(line.Quantity) + " x " + formatAmount(line.UnitPrice)
+		rows = append(rows, columnsRow(quantity, formatAmount(line.Amount), columns)...)
+		if line.Discount != 0 {
+			rows = append(rows, columnsRow("  Discount", formatAmount(-line.Discount), columns)...)
+		}
+	}
+	rows = append(rows, rule)
+
+	rows = append(rows, entryRows(r.Totals, columns)...)
+
+	if len(r.Payments) > 0 {
+		rows = append(rows, rule)
+		rows = append(rows, entryRows(r.Payments, columns)...)
+	}
+
+	if r.Notice != "" {
+		rows = append(rows, rule)
+		for _, text := range wrap(r.Notice, columns) {
+			rows = append(rows, row{text: text, centered: true, bold: true})
+		}
+	}
+
+	if r.QR != "" {
+		rows = append(rows, rule)
+		if code, err := qrcode.Encode(r.QR); err == nil {
+			rows = append(rows, row{text: r.QR, centered: true, qr: code})
+		} else {
+			for _, text := range wrap(r.QR, columns) {
+				rows = append(rows, row{text: text, centered: true})
+			}
+		}
+	}
+
+	if len(r.Footer) > 0 {
+		rows = append(rows, rule)
+		for _, line := range r.Footer {
+			for _, text := range wrap(line, columns) {
+				rows = append(rows, row{text: text, centered: true})
+			}
+		}
+	}
+
+	return rows
+}
+
+func entryRows(entries []Entry, columns int) []row {
+	var rows []row
+	for _, e := range entries {
+		for _, r := range columnsRow(e.Label, formatAmount(e.Amount), columns) {
+			r.bold = e.Bold
+			r.tall = e.Bold
+			rows = append(rows, r)
+		}
+	}
+	return rows
+}
+
+// columnsRow puts left and right on one line, right aligned, or the left text
+// on lines of its own when both do not fit.
+func columnsRow(left, right string, columns int) []row {
+	gap := columns - width(left) - width(right)
+	if gap >= 1 {
+		return []row{{text: left + strings.Repeat(" ", gap) + right}}
+	}
+
+	var rows []row
+	for _, text := range wrap(left, columns) {
+		rows = append(rows, row{text: text})
+	}
+	return append(rows, row{text: strings.Repeat(" ", max(columns-width(right), 0)) + right})
+}
+
+// wrap breaks s into lines of at most columns characters, at spaces where it
+// can.
+func wrap(s string, columns int) []string {
+	var lines []string
+	var line string
+	for _, word := range strings.Fields(s) {
+		for width(word) > columns {
+			if line != "" {
+				lines = append(lines, line)
+				line = ""
+			}
+			cut := truncate(word, columns)
+			lines = append(lines, cut)
+			word = word[len(cut):]
+		}
+
+		switch {
+		case line == "":
+			line = word
+		case width(line)+1+width(word) <= columns:
+			line += " " + word
+		default:
+			lines = append(lines, line)
+			line = word
+		}
+	}
+
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func width(s string) int {
+	return utf8.RuneCountInString(s)
+}
+
+// truncate returns the first n characters of s.
+func truncate(s string, n int) string {
+	i := 0
+	for j := range s {
+		if i == n {
+			return s[:j]
+		}
+		i++
+	}
+	return s
+}
+
+// center pads s on the left so it sits in the middle of a line.
+func center(s string, columns int) string {
+	return strings.Repeat(" ", max((columns-width(s))/2, 0)) + s
+}
+
+// formatAmount writes an amount with thousands separators, e.g. 12,500.00.
+func formatAmount(a money.Amount) string {
+	s := a.String()
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+
+	units, cents, _ := strings.Cut(s, ".")
+	var b strings.Builder
+	for i, c := range units {
+		if i > 0 && (len(units)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(c)
+	}
+
+	return sign + b.String() + "." + cents
+}
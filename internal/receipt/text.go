@@ -0,0 +1,28 @@
+package receipt
+
+import "strings"
+
+// renderText writes the receipt as plain lines for printers driven by the
+// operating system, or for showing on screen. QR codes are written as the
+// text they encode.
+func renderText(rows []row, columns int) []byte {
+	var b strings.Builder
+	for _, r := range rows {
+		if r.qr != nil {
+			for _, text := range wrap(r.text, columns) {
+				b.WriteString(center(text, columns))
+				b.WriteByte('\n')
+			}
+			continue
+		}
+
+		text := r.text
+		if r.centered {
+			text = center(text, columns)
+		}
+		b.WriteString(strings.TrimRight(text, " "))
+		b.WriteByte('\n')
+	}
+
+	return []byte(b.String())
+}
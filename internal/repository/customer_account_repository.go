@@ -0,0 +1,327 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	apperror "github.com/hafiztri123/kki-be/internal/app_error"
+	"github.com/hafiztri123/kki-be/internal/constants"
+	"github.com/hafiztri123/kki-be/internal/models"
+	"github.com/hafiztri123/kki-be/internal/money"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const customerAccountColumns = `id, customer_id, customer_name, phone, currency, credit_limit, balance, created_by, created_at, updated_at`
+
+const customerAccountEntryColumns = `id, account_id, entry_type, amount, balance_after, sale_order_id, method, reference, approved_by, recorded_by, created_at`
+
+type CustomerAccountRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewCustomerAccountRepository(db *pgxpool.Pool) *CustomerAccountRepository {
+	return &CustomerAccountRepository{
+		db: db,
+	}
+}
+
+func scanCustomerAccount(row pgx.Row, a *models.CustomerAccount) error {
+	return row.Scan(
+		&a.ID,
+		&a.CustomerID,
+		&a.CustomerName,
+		&a.Phone,
+		&a.Currency,
+		&a.CreditLimit,
+		&a.Balance,
+		&a.CreatedBy,
+		&a.CreatedAt,
+		&a.UpdatedAt,
+	)
+}
+
+func scanCustomerAccountEntry(row pgx.Row, e *models.CustomerAccountEntry) error {
+	return row.Scan(
+		&e.ID,
+		&e.AccountID,
+		&e.EntryType,
+		&e.Amount,
+		&e.BalanceAfter,
+		&e.SaleOrderID,
+		&e.Method,
+		&e.Reference,
+		&e.ApprovedBy,
+		&e.RecordedBy,
+		&e.CreatedAt,
+	)
+}
+
+func (r *CustomerAccountRepository) InsertCustomerAccount(ctx context.Context, a *models.CustomerAccount) error {
+	query := `INSERT INTO customer_accounts (` + customerAccountColumns + `)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query,
+		a.ID,
+		a.CustomerID,
+		a.CustomerName,
+		a.Phone,
+		a.Currency,
+		a.CreditLimit,
+		a.Balance,
+		a.CreatedBy,
+		a.CreatedAt,
+		a.UpdatedAt,
+	)
+
+	return err
+}
+
+// GetCustomerAccounts lists accounts by customer name and linked customer.
+// An empty customerName and a nil customerID are not applied.
+func (r *CustomerAccountRepository) GetCustomerAccounts(ctx context.Context, customerName string, customerID *uuid.UUID, limit, offset int) ([]models.CustomerAccount, int64, error) {
+	where := newWhereBuilder()
+	if customerName != "" {
+		where.add("customer_name ILIKE $%d", likePattern(customerName))
+	}
+	if customerID != nil {
+		where.add("customer_id = $%d", *customerID)
+	}
+
+	var totalCount int64
+	countQuery := `SELECT COUNT(*) FROM customer_accounts ` + where.String()
+	if err := conn(ctx, r.db).QueryRow(ctx, countQuery, where.args...).Scan(&totalCount); err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT ` + customerAccountColumns + `
+			  FROM customer_accounts
+			  ` + where.String() + `
+			  ORDER BY customer_name ASC, id ASC
+			  LIMIT ` + where.next(limit) + ` OFFSET ` + where.next(offset)
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, where.args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var accounts []models.CustomerAccount
+	for rows.Next() {
+		var a models.CustomerAccount
+		if err := scanCustomerAccount(rows, &a); err != nil {
+			return nil, 0, err
+		}
+		accounts = append(accounts, a)
+	}
+
+	return accounts, totalCount, rows.Err()
+}
+
+func (r *CustomerAccountRepository) GetCustomerAccountByID(ctx context.Context, id uuid.UUID) (*models.CustomerAccount, error) {
+	query := `SELECT ` + customerAccountColumns + `
+			  FROM customer_accounts
+			  WHERE id = $1`
+
+	return r.getCustomerAccount(ctx, query, id)
+}
+
+// GetCustomerAccountByIDForUpdate locks the account until the surrounding
+// transaction ends, so concurrent charges cannot overrun its limit.
+func (r *CustomerAccountRepository) GetCustomerAccountByIDForUpdate(ctx context.Context, id uuid.UUID) (*models.CustomerAccount, error) {
+	query := `SELECT ` + customerAccountColumns + `
+			  FROM customer_accounts
+			  WHERE id = $1
+			  FOR UPDATE`
+
+	return r.getCustomerAccount(ctx, query, id)
+}
+
+func (r *CustomerAccountRepository) getCustomerAccount(ctx context.Context, query string, id uuid.UUID) (*models.CustomerAccount, error) {
+	var a models.CustomerAccount
+	err := scanCustomerAccount(conn(ctx, r.db).QueryRow(ctx, query, id), &a)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperror.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &a, nil
+}
+
+func (r *CustomerAccountRepository) UpdateCustomerAccount(ctx context.Context, a *models.CustomerAccount) error {
+	query := `UPDATE customer_accounts
+			  SET customer_id = $1, customer_name = $2, phone = $3, credit_limit = $4, balance = $5, updated_at = $6
+			  WHERE id = $7`
+
+	result, err := conn(ctx, r.db).Exec(ctx, query, a.CustomerID, a.CustomerName, a.Phone, a.CreditLimit, a.Balance, a.UpdatedAt, a.ID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return apperror.ErrNotFound
+	}
+
+	return nil
+}
+
+// ReassignCustomerAccounts moves the accounts of the from customers to the to
+// customer.
+func (r *CustomerAccountRepository) ReassignCustomerAccounts(ctx context.Context, from []uuid.UUID, to uuid.UUID, now time.Time) error {
+	query := `UPDATE customer_accounts
+			  SET customer_id = $1, updated_at = $2
+			  WHERE customer_id = ANY($3)`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query, to, now, from)
+	return err
+}
+
+func (r *CustomerAccountRepository) InsertEntry(ctx context.Context, e *models.CustomerAccountEntry) error {
+	query := `INSERT INTO customer_account_entries (` + customerAccountEntryColumns + `)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query,
+		e.ID,
+		e.AccountID,
+		e.EntryType,
+		e.Amount,
+		e.BalanceAfter,
+		e.SaleOrderID,
+		e.Method,
+		e.Reference,
+		e.ApprovedBy,
+		e.RecordedBy,
+		e.CreatedAt,
+	)
+
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == constants.UniqueConstraintViolationErrorCode && pgErr.ConstraintName == constants.CustomerAccountChargeConstraint {
+			return apperror.ErrSaleOrderAlreadyCharged
+		}
+		return err
+	}
+
+	return nil
+}
+
+// GetSaleOrderCharge returns what a sale order has on account, or ErrNotFound
+// when it was not charged or the charge was reversed.
+func (r *CustomerAccountRepository) GetSaleOrderCharge(ctx context.Context, saleOrderID uuid.UUID) (money.Amount, error) {
+	query := `SELECT amount
+			  FROM customer_account_entries
+			  WHERE sale_order_id = $1 AND entry_type = $2 AND reversed_at IS NULL`
+
+	var amount money.Amount
+	err := conn(ctx, r.db).QueryRow(ctx, query, saleOrderID, constants.CustomerAccountEntryCharge).Scan(&amount)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, apperror.ErrNotFound
+		}
+		return 0, err
+	}
+
+	return amount, nil
+}
+
+// GetUnreversedCharge locks the charge a sale order has on account, or returns
+// ErrNotFound when it was not charged or the charge was already reversed.
+func (r *CustomerAccountRepository) GetUnreversedCharge(ctx context.Context, saleOrderID uuid.UUID) (*models.CustomerAccountEntry, error) {
+	query := `SELECT ` + customerAccountEntryColumns + `
+			  FROM customer_account_entries
+			  WHERE sale_order_id = $1 AND entry_type = $2 AND reversed_at IS NULL
+			  FOR UPDATE`
+
+	var e models.CustomerAccountEntry
+	err := scanCustomerAccountEntry(conn(ctx, r.db).QueryRow(ctx, query, saleOrderID, constants.CustomerAccountEntryCharge), &e)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperror.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &e, nil
+}
+
+// MarkChargeReversed marks a charge as reversed at, which frees its sale
+// order to be charged again.
+func (r *CustomerAccountRepository) MarkChargeReversed(ctx context.Context, entryID uuid.UUID, at time.Time) error {
+	query := `UPDATE customer_account_entries
+			  SET reversed_at = $1
+			  WHERE id = $2 AND reversed_at IS NULL`
+
+	result, err := conn(ctx, r.db).Exec(ctx, query, at, entryID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return apperror.ErrNotFound
+	}
+
+	return nil
+}
+
+// GetEntries returns the ledger of an account, oldest first, limited to
+// entries created in [from, to). Nil bounds are not applied.
+func (r *CustomerAccountRepository) GetEntries(ctx context.Context, accountID uuid.UUID, from, to *time.Time) ([]models.CustomerAccountEntry, error) {
+	where := newWhereBuilder()
+	where.add("account_id = $%d", accountID)
+	if from != nil {
+		where.add("created_at >= $%d", *from)
+	}
+	if to != nil {
+		where.add("created_at < $%d", *to)
+	}
+
+	query := `SELECT ` + customerAccountEntryColumns + `
+			  FROM customer_account_entries
+			  ` + where.String() + `
+			  ORDER BY created_at ASC, id ASC`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, where.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []models.CustomerAccountEntry
+	for rows.Next() {
+		var e models.CustomerAccountEntry
+		if err := scanCustomerAccountEntry(rows, &e); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, rows.Err()
+}
+
+// GetBalanceAt returns the balance of an account just before at, taken from
+// the last ledger entry created before it. Accounts without earlier entries
+// owed nothing.
+func (r *CustomerAccountRepository) GetBalanceAt(ctx context.Context, accountID uuid.UUID, at time.Time) (money.Amount, error) {
+	query := `SELECT balance_after
+			  FROM customer_account_entries
+			  WHERE account_id = $1 AND created_at < $2
+			  ORDER BY created_at DESC, id DESC
+			  LIMIT 1`
+
+	var balance money.Amount
+	err := conn(ctx, r.db).QueryRow(ctx, query, accountID, at).Scan(&balance)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	return balance, nil
+}
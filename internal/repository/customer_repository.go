@@ -0,0 +1,251 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	apperror "github.com/hafiztri123/kki-be/internal/app_error"
+	"github.com/hafiztri123/kki-be/internal/constants"
+	"github.com/hafiztri123/kki-be/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const customerColumns = `id, name, phone, email, address, tags, notes, merged_into_id, created_by, created_at, updated_at, deleted_at`
+
+type CustomerRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewCustomerRepository(db *pgxpool.Pool) *CustomerRepository {
+	return &CustomerRepository{
+		db: db,
+	}
+}
+
+func scanCustomer(row pgx.Row, c *models.Customer) error {
+	return row.Scan(
+		&c.ID,
+		&c.Name,
+		&c.Phone,
+		&c.Email,
+		&c.Address,
+		&c.Tags,
+		&c.Notes,
+		&c.MergedIntoID,
+		&c.CreatedBy,
+		&c.CreatedAt,
+		&c.UpdatedAt,
+		&c.DeletedAt,
+	)
+}
+
+func (r *CustomerRepository) InsertCustomer(ctx context.Context, c *models.Customer) error {
+	query := `INSERT INTO customers (` + customerColumns + `)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query,
+		c.ID,
+		c.Name,
+		c.Phone,
+		c.Email,
+		c.Address,
+		c.Tags,
+		c.Notes,
+		c.MergedIntoID,
+		c.CreatedBy,
+		c.CreatedAt,
+		c.UpdatedAt,
+		c.DeletedAt,
+	)
+
+	return err
+}
+
+// GetCustomers searches customers by name, phone or email and optionally by
+// tag, ordered by name. Empty filters are not applied.
+func (r *CustomerRepository) GetCustomers(ctx context.Context, search, tag string, limit, offset int) ([]models.Customer, int64, error) {
+	where := newWhereBuilder("deleted_at IS NULL")
+	if search != "" {
+		pattern := likePattern(search)
+		where.add("(name ILIKE $%d OR phone ILIKE $%d OR email ILIKE $%d)", pattern, pattern, pattern)
+	}
+	if tag != "" {
+		where.add("$%d = ANY(tags)", tag)
+	}
+
+	var totalCount int64
+	countQuery := `SELECT COUNT(*) FROM customers ` + where.String()
+	if err := conn(ctx, r.db).QueryRow(ctx, countQuery, where.args...).Scan(&totalCount); err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT ` + customerColumns + `
+			  FROM customers
+			  ` + where.String() + `
+			  ORDER BY name ASC, id ASC
+			  LIMIT ` + where.next(limit) + ` OFFSET ` + where.next(offset)
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, where.args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var customers []models.Customer
+	for rows.Next() {
+		var c models.Customer
+		if err := scanCustomer(rows, &c); err != nil {
+			return nil, 0, err
+		}
+		customers = append(customers, c)
+	}
+
+	return customers, totalCount, rows.Err()
+}
+
+func (r *CustomerRepository) GetCustomerByID(ctx context.Context, id uuid.UUID) (*models.Customer, error) {
+	query := `SELECT ` + customerColumns + `
+			  FROM customers
+			  WHERE id = $1 AND deleted_at IS NULL`
+
+	return r.getCustomer(ctx, query, id)
+}
+
+// GetCustomerByIDForUpdate locks the customer until the surrounding
+// transaction ends, so it cannot be merged away while it is being changed.
+func (r *CustomerRepository) GetCustomerByIDForUpdate(ctx context.Context, id uuid.UUID) (*models.Customer, error) {
+	query := `SELECT ` + customerColumns + `
+			  FROM customers
+			  WHERE id = $1 AND deleted_at IS NULL
+			  FOR UPDATE`
+
+	return r.getCustomer(ctx, query, id)
+}
+
+func (r *CustomerRepository) getCustomer(ctx context.Context, query string, id uuid.UUID) (*models.Customer, error) {
+	var c models.Customer
+	err := scanCustomer(conn(ctx, r.db).QueryRow(ctx, query, id), &c)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperror.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+func (r *CustomerRepository) UpdateCustomer(ctx context.Context, c *models.Customer) error {
+	query := `UPDATE customers
+			  SET name = $1, phone = $2, email = $3, address = $4, tags = $5, notes = $6, updated_at = $7
+			  WHERE id = $8 AND deleted_at IS NULL`
+
+	result, err := conn(ctx, r.db).Exec(ctx, query,
+		c.Name,
+		c.Phone,
+		c.Email,
+		c.Address,
+		c.Tags,
+		c.Notes,
+		c.UpdatedAt,
+		c.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return apperror.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *CustomerRepository) DeleteCustomer(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE customers
+			  SET deleted_at = NOW()
+			  WHERE id = $1 AND deleted_at IS NULL`
+
+	result, err := conn(ctx, r.db).Exec(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return apperror.ErrNotFound
+	}
+
+	return nil
+}
+
+// MarkCustomerMerged soft deletes a duplicate and points it at the customer
+// it was merged into.
+func (r *CustomerRepository) MarkCustomerMerged(ctx context.Context, id, mergedIntoID uuid.UUID, at time.Time) error {
+	query := `UPDATE customers
+			  SET merged_into_id = $1, updated_at = $2, deleted_at = $2
+			  WHERE id = $3 AND deleted_at IS NULL`
+
+	result, err := conn(ctx, r.db).Exec(ctx, query, mergedIntoID, at, id)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return apperror.ErrNotFound
+	}
+
+	return nil
+}
+
+// ReassignSaleOrders points every order of the from customers at the to
+// customer and returns how many orders moved. The orders get a new version so
+// clients holding a copy reload it.
+func (r *CustomerRepository) ReassignSaleOrders(ctx context.Context, from []uuid.UUID, to uuid.UUID, at time.Time) (int64, error) {
+	query := `UPDATE sale_orders
+			  SET customer_id = $1, updated_at = $2, version = version + 1
+			  WHERE customer_id = ANY($3) AND deleted_at IS NULL`
+
+	result, err := conn(ctx, r.db).Exec(ctx, query, to, at, from)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected(), nil
+}
+
+// GetCustomerValues sums the completed orders of a customer per currency.
+func (r *CustomerRepository) GetCustomerValues(ctx context.Context, id uuid.UUID) ([]models.CustomerValue, error) {
+	query := `SELECT currency, COUNT(*), SUM(total_amount), MIN(created_at), MAX(created_at)
+			  FROM sale_orders
+			  WHERE customer_id = $1 AND status = $2 AND deleted_at IS NULL
+			  GROUP BY currency
+			  ORDER BY currency ASC`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, id, constants.SaleOrderStatusCompleted)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var values []models.CustomerValue
+	for rows.Next() {
+		var v models.CustomerValue
+		err := rows.Scan(
+			&v.Currency,
+			&v.OrderCount,
+			&v.LifetimeValue,
+			&v.FirstOrderAt,
+			&v.LastOrderAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+
+	return values, rows.Err()
+}
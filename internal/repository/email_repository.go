@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	apperror "github.com/hafiztri123/kki-be/internal/app_error"
+	"github.com/hafiztri123/kki-be/internal/constants"
+	"github.com/hafiztri123/kki-be/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const emailColumns = `id, kind, sale_order_id, recipient, subject, status, attempts, last_error, next_attempt_at, sent_at, created_by, created_at, updated_at`
+
+type EmailRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewEmailRepository(db *pgxpool.Pool) *EmailRepository {
+	return &EmailRepository{
+		db: db,
+	}
+}
+
+func scanEmailMessage(row pgx.Row, m *models.EmailMessage) error {
+	return row.Scan(
+		&m.ID,
+		&m.Kind,
+		&m.SaleOrderID,
+		&m.Recipient,
+		&m.Subject,
+		&m.Status,
+		&m.Attempts,
+		&m.LastError,
+		&m.NextAttemptAt,
+		&m.SentAt,
+		&m.CreatedBy,
+		&m.CreatedAt,
+		&m.UpdatedAt,
+	)
+}
+
+func (r *EmailRepository) InsertEmailMessage(ctx context.Context, m *models.EmailMessage) error {
+	query := `INSERT INTO email_outbox (` + emailColumns + `)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query,
+		m.ID,
+		m.Kind,
+		m.SaleOrderID,
+		m.Recipient,
+		m.Subject,
+		m.Status,
+		m.Attempts,
+		m.LastError,
+		m.NextAttemptAt,
+		m.SentAt,
+		m.CreatedBy,
+		m.CreatedAt,
+		m.UpdatedAt,
+	)
+	return err
+}
+
+func (r *EmailRepository) GetEmailMessages(ctx context.Context, status string, saleOrderID *uuid.UUID, limit, offset int) ([]models.EmailMessage, int64, error) {
+	where := newWhereBuilder()
+	if status != "" {
+		where.add("status = $%d", status)
+	}
+	if saleOrderID != nil {
+		where.add("sale_order_id = $%d", *saleOrderID)
+	}
+
+	var totalCount int64
+	countQuery := `SELECT COUNT(*) FROM email_outbox ` + where.String()
+	if err := conn(ctx, r.db).QueryRow(ctx, countQuery, where.args...).Scan(&totalCount); err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT ` + emailColumns + `
+			  FROM email_outbox
+			  ` + where.String() + `
+			  ORDER BY created_at DESC, id DESC
+			  LIMIT ` + where.next(limit) + ` OFFSET ` + where.next(offset)
+
+	messages, err := r.getEmailMessages(ctx, query, where.args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return messages, totalCount, nil
+}
+
+func (r *EmailRepository) GetEmailMessageByIDForUpdate(ctx context.Context, id uuid.UUID) (*models.EmailMessage, error) {
+	query := `SELECT ` + emailColumns + `
+			  FROM email_outbox
+			  WHERE id = $1
+			  FOR UPDATE`
+
+	var m models.EmailMessage
+	err := scanEmailMessage(conn(ctx, r.db).QueryRow(ctx, query, id), &m)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperror.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &m, nil
+}
+
+// GetDueEmailMessages returns up to limit pending emails whose next attempt
+// is due at now, locked until the surrounding transaction ends. Emails locked
+// by another worker are skipped, so each is sent by one worker only.
+func (r *EmailRepository) GetDueEmailMessages(ctx context.Context, now time.Time, limit int) ([]models.EmailMessage, error) {
+	query := `SELECT ` + emailColumns + `
+			  FROM email_outbox
+			  WHERE status = $1 AND next_attempt_at <= $2
+			  ORDER BY next_attempt_at ASC, id ASC
+			  LIMIT $3
+			  FOR UPDATE SKIP LOCKED`
+
+	return r.getEmailMessages(ctx, query, constants.EmailStatusPending, now, limit)
+}
+
+func (r *EmailRepository) getEmailMessages(ctx context.Context, query string, args ...any) ([]models.EmailMessage, error) {
+	rows, err := conn(ctx, r.db).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []models.EmailMessage
+	for rows.Next() {
+		var m models.EmailMessage
+		if err := scanEmailMessage(rows, &m); err != nil {
+			return nil, err
+		}
+		messages = append(messages, m)
+	}
+
+	return messages, rows.Err()
+}
+
+// UpdateEmailDelivery records the outcome of an attempt to send an email.
+func (r *EmailRepository) UpdateEmailDelivery(ctx context.Context, m *models.EmailMessage) error {
+	query := `UPDATE email_outbox
+			  SET subject = $1, status = $2, attempts = $3, last_error = $4, next_attempt_at = $5, sent_at = $6, updated_at = $7
+			  WHERE id = $8`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query,
+		m.Subject,
+		m.Status,
+		m.Attempts,
+		m.LastError,
+		m.NextAttemptAt,
+		m.SentAt,
+		m.UpdatedAt,
+		m.ID,
+	)
+	return err
+}
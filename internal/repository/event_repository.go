@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"time"
+
+	apperror "github.com/hafiztri123/kki-be/internal/app_error"
+	"github.com/hafiztri123/kki-be/internal/constants"
+	"github.com/hafiztri123/kki-be/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const domainEventColumns = `id, sequence, event_type, aggregate_id, payload, status, attempts, next_attempt_at, last_error, dispatched_at, created_at, updated_at`
+
+type EventRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewEventRepository(db *pgxpool.Pool) *EventRepository {
+	return &EventRepository{
+		db: db,
+	}
+}
+
+func scanDomainEvent(row pgx.Row, e *models.DomainEvent) error {
+	return row.Scan(
+		&e.ID,
+		&e.Sequence,
+		&e.EventType,
+		&e.AggregateID,
+		&e.Payload,
+		&e.Status,
+		&e.Attempts,
+		&e.NextAttemptAt,
+		&e.LastError,
+		&e.DispatchedAt,
+		&e.CreatedAt,
+		&e.UpdatedAt,
+	)
+}
+
+// InsertEvent writes an event to the outbox and sets its sequence.
+func (r *EventRepository) InsertEvent(ctx context.Context, e *models.DomainEvent) error {
+	query := `INSERT INTO domain_events (id, event_type, aggregate_id, payload, status, attempts, next_attempt_at, last_error, dispatched_at, created_at, updated_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			  RETURNING sequence`
+
+	return conn(ctx, r.db).QueryRow(ctx, query,
+		e.ID,
+		e.EventType,
+		e.AggregateID,
+		e.Payload,
+		e.Status,
+		e.Attempts,
+		e.NextAttemptAt,
+		e.LastError,
+		e.DispatchedAt,
+		e.CreatedAt,
+		e.UpdatedAt,
+	).Scan(&e.Sequence)
+}
+
+// GetDueEvents returns up to limit pending events whose next attempt is due
+// at now, oldest first, locked until the surrounding transaction ends. Events
+// locked by another poller are skipped, so each is dispatched by one poller
+// only.
+func (r *EventRepository) GetDueEvents(ctx context.Context, now time.Time, limit int) ([]models.DomainEvent, error) {
+	query := `SELECT ` + domainEventColumns + `
+			  FROM domain_events
+			  WHERE status = $1 AND next_attempt_at <= $2
+			  ORDER BY sequence ASC
+			  LIMIT $3
+			  FOR UPDATE SKIP LOCKED`
+
+	return r.getEvents(ctx, query, constants.EventStatusPending, now, limit)
+}
+
+func (r *EventRepository) GetEventBySequence(ctx context.Context, sequence int64) (*models.DomainEvent, error) {
+	query := `SELECT ` + domainEventColumns + `
+			  FROM domain_events
+			  WHERE sequence = $1`
+
+	var e models.DomainEvent
+	err := scanDomainEvent(conn(ctx, r.db).QueryRow(ctx, query, sequence), &e)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperror.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &e, nil
+}
+
+// GetDispatchedEventsAfter returns up to limit dispatched events of the given
+// types that come after sequence, in sequence order.
+func (r *EventRepository) GetDispatchedEventsAfter(ctx context.Context, eventTypes []string, sequence int64, limit int) ([]models.DomainEvent, error) {
+	query := `SELECT ` + domainEventColumns + `
+			  FROM domain_events
+			  WHERE status = $1 AND event_type = ANY($2) AND sequence > $3
+			  ORDER BY sequence ASC
+			  LIMIT $4`
+
+	return r.getEvents(ctx, query, constants.EventStatusDispatched, eventTypes, sequence, limit)
+}
+
+// GetLatestEventSequence returns the sequence of the newest event, or 0 when
+// there are none.
+func (r *EventRepository) GetLatestEventSequence(ctx context.Context) (int64, error) {
+	var sequence int64
+	err := conn(ctx, r.db).QueryRow(ctx, `SELECT COALESCE(MAX(sequence), 0) FROM domain_events`).Scan(&sequence)
+	return sequence, err
+}
+
+// GetOldestDispatchedEventSequence returns the sequence of the oldest
+// dispatched event still kept, or 0 when there are none.
+func (r *EventRepository) GetOldestDispatchedEventSequence(ctx context.Context) (int64, error) {
+	var sequence int64
+	err := conn(ctx, r.db).QueryRow(ctx, `SELECT COALESCE(MIN(sequence), 0) FROM domain_events WHERE status = $1`, constants.EventStatusDispatched).Scan(&sequence)
+	return sequence, err
+}
+
+func (r *EventRepository) getEvents(ctx context.Context, query string, args ...any) ([]models.DomainEvent, error) {
+	rows, err := conn(ctx, r.db).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []models.DomainEvent
+	for rows.Next() {
+		var e models.DomainEvent
+		if err := scanDomainEvent(rows, &e); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+
+	return events, rows.Err()
+}
+
+// UpdateEventDispatch records the outcome of an attempt to dispatch an event.
+func (r *EventRepository) UpdateEventDispatch(ctx context.Context, e *models.DomainEvent) error {
+	query := `UPDATE domain_events
+			  SET status = $1, attempts = $2, next_attempt_at = $3, last_error = $4, dispatched_at = $5, updated_at = $6
+			  WHERE id = $7`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query,
+		e.Status,
+		e.Attempts,
+		e.NextAttemptAt,
+		e.LastError,
+		e.DispatchedAt,
+		e.UpdatedAt,
+		e.ID,
+	)
+	return err
+}
+
+// DeleteDispatchedEvents removes events dispatched before the given time and
+// returns how many were removed. Pending and failed events are kept.
+func (r *EventRepository) DeleteDispatchedEvents(ctx context.Context, before time.Time) (int64, error) {
+	query := `DELETE FROM domain_events
+			  WHERE status = $1 AND dispatched_at < $2`
+
+	result, err := conn(ctx, r.db).Exec(ctx, query, constants.EventStatusDispatched, before)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected(), nil
+}
+
+// NotifyEvent sends the sequence of an event on a notification channel. Inside
+// a transaction the notification is only sent when it commits.
+func (r *EventRepository) NotifyEvent(ctx context.Context, channel string, sequence int64) error {
+	_, err := conn(ctx, r.db).Exec(ctx, `SELECT pg_notify($1, $2)`, channel, strconv.FormatInt(sequence, 10))
+	return err
+}
+
+// Listen runs fn with the payload of every notification sent on channel, by
+// any server, until ctx is done or the connection fails. It holds a pooled
+// connection for as long as it runs; the connection is closed afterwards
+// rather than handed back to the pool still listening.
+func (r *EventRepository) Listen(ctx context.Context, channel string, fn func(payload string)) error {
+	pooled, err := r.db.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	c := pooled.Hijack()
+	defer c.Close(context.Background())
+
+	if _, err := c.Exec(ctx, `LISTEN `+pgx.Identifier{channel}.Sanitize()); err != nil {
+		return err
+	}
+
+	for {
+		notification, err := c.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		fn(notification.Payload)
+	}
+}
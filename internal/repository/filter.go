@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hafiztri123/kki-be/internal/models"
+)
+
+// whereBuilder collects SQL conditions and their arguments. Conditions use
+// %d where the positional parameter number goes, so callers never splice
+// user input into the query text.
+type whereBuilder struct {
+	conditions []string
+	args       []any
+}
+
+func newWhereBuilder(conditions ...string) *whereBuilder {
+	return &whereBuilder{conditions: conditions}
+}
+
+// add appends a condition, binding each argument to the next parameter.
+func (b *whereBuilder) add(condition string, args ...any) {
+	positions := make([]any, len(args))
+	for i, arg := range args {
+		b.args = append(b.args, arg)
+		positions[i] = len(b.args)
+	}
+	b.conditions = append(b.conditions, fmt.Sprintf(condition, positions...))
+}
+
+// next returns the placeholder for an extra argument appended after the
+// conditions, such as LIMIT and OFFSET.
+func (b *whereBuilder) next(arg any) string {
+	b.args = append(b.args, arg)
+	return fmt.Sprintf("$%d", len(b.args))
+}
+
+func (b *whereBuilder) String() string {
+	if len(b.conditions) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(b.conditions, " AND ")
+}
+
+// orderByClause maps sort fields through a whitelist of columns. Unknown
+// fields are skipped, the fallback is used when nothing is left and the
+// tiebreaker column is always appended to keep pages stable.
+func orderByClause(sort []models.SortField, columns map[string]string, fallback, tiebreaker string) string {
+	parts := make([]string, 0, len(sort)+1)
+	for _, sf := range sort {
+		column, ok := columns[sf.Field]
+		if !ok {
+			continue
+		}
+
+		direction := "ASC"
+		if sf.Descending {
+			direction = "DESC"
+		}
+		parts = append(parts, column+" "+direction)
+	}
+
+	if len(parts) == 0 {
+		parts = append(parts, fallback)
+	}
+
+	return "ORDER BY " + strings.Join(append(parts, tiebreaker), ", ")
+}
+
+// likePattern escapes LIKE wildcards in s and wraps it for a substring match.
+func likePattern(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+	return "%" + s + "%"
+}
+
+// keysetClause narrows the conditions to rows after or before the page cursor
+// and returns the ORDER BY and LIMIT for a (created_at, id) keyset page. One
+// extra row is fetched so the caller can tell whether another page exists.
+// Backward pages are returned oldest first and must be reversed.
+func keysetClause(where *whereBuilder, page models.KeysetPage, prefix string) string {
+	direction := "DESC"
+	comparison := "<"
+	if page.Backward {
+		direction = "ASC"
+		comparison = ">"
+	}
+
+	if page.Cursor != nil {
+		where.add("("+prefix+"created_at, "+prefix+"id) "+comparison+" ($%d, $%d)", page.Cursor.CreatedAt, page.Cursor.ID)
+	}
+
+	return fmt.Sprintf("ORDER BY %screated_at %s, %sid %s LIMIT %s", prefix, direction, prefix, direction, where.next(page.Limit+1))
+}
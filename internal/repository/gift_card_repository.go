@@ -0,0 +1,278 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	apperror "github.com/hafiztri123/kki-be/internal/app_error"
+	"github.com/hafiztri123/kki-be/internal/constants"
+	"github.com/hafiztri123/kki-be/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const giftCardColumns = `id, code, card_type, currency, initial_amount, balance, expires_at, customer_id, issued_by, created_at, updated_at`
+
+const giftCardTransactionColumns = `id, gift_card_id, transaction_type, amount, balance_after, sale_order_id, method, reference, recorded_by, created_at`
+
+type GiftCardRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewGiftCardRepository(db *pgxpool.Pool) *GiftCardRepository {
+	return &GiftCardRepository{
+		db: db,
+	}
+}
+
+func scanGiftCard(row pgx.Row, g *models.GiftCard) error {
+	return row.Scan(
+		&g.ID,
+		&g.Code,
+		&g.CardType,
+		&g.Currency,
+		&g.InitialAmount,
+		&g.Balance,
+		&g.ExpiresAt,
+		&g.CustomerID,
+		&g.IssuedBy,
+		&g.CreatedAt,
+		&g.UpdatedAt,
+	)
+}
+
+func (r *GiftCardRepository) InsertGiftCard(ctx context.Context, g *models.GiftCard) error {
+	query := `INSERT INTO gift_cards (` + giftCardColumns + `)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query,
+		g.ID,
+		g.Code,
+		g.CardType,
+		g.Currency,
+		g.InitialAmount,
+		g.Balance,
+		g.ExpiresAt,
+		g.CustomerID,
+		g.IssuedBy,
+		g.CreatedAt,
+		g.UpdatedAt,
+	)
+
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == constants.UniqueConstraintViolationErrorCode && pgErr.ConstraintName == constants.GiftCardCodeConstraint {
+			return apperror.ErrGiftCardCodeConflict
+		}
+		return err
+	}
+
+	return nil
+}
+
+// GetGiftCards lists cards, newest first. Empty and nil filters are not
+// applied.
+func (r *GiftCardRepository) GetGiftCards(ctx context.Context, cardType string, customerID *uuid.UUID, limit, offset int) ([]models.GiftCard, int64, error) {
+	where := newWhereBuilder()
+	if cardType != "" {
+		where.add("card_type = $%d", cardType)
+	}
+	if customerID != nil {
+		where.add("customer_id = $%d", *customerID)
+	}
+
+	var totalCount int64
+	countQuery := `SELECT COUNT(*) FROM gift_cards ` + where.String()
+	if err := conn(ctx, r.db).QueryRow(ctx, countQuery, where.args...).Scan(&totalCount); err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT ` + giftCardColumns + `
+			  FROM gift_cards
+			  ` + where.String() + `
+			  ORDER BY created_at DESC, id DESC
+			  LIMIT ` + where.next(limit) + ` OFFSET ` + where.next(offset)
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, where.args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var cards []models.GiftCard
+	for rows.Next() {
+		var g models.GiftCard
+		if err := scanGiftCard(rows, &g); err != nil {
+			return nil, 0, err
+		}
+		cards = append(cards, g)
+	}
+
+	return cards, totalCount, rows.Err()
+}
+
+func (r *GiftCardRepository) GetGiftCardByCode(ctx context.Context, code string) (*models.GiftCard, error) {
+	query := `SELECT ` + giftCardColumns + `
+			  FROM gift_cards
+			  WHERE code = $1`
+
+	return r.getGiftCard(ctx, query, code)
+}
+
+// GetGiftCardByCodeForUpdate locks the card until the surrounding transaction
+// ends, so two registers cannot spend the same balance.
+func (r *GiftCardRepository) GetGiftCardByCodeForUpdate(ctx context.Context, code string) (*models.GiftCard, error) {
+	query := `SELECT ` + giftCardColumns + `
+			  FROM gift_cards
+			  WHERE code = $1
+			  FOR UPDATE`
+
+	return r.getGiftCard(ctx, query, code)
+}
+
+func (r *GiftCardRepository) GetGiftCardByID(ctx context.Context, id uuid.UUID) (*models.GiftCard, error) {
+	query := `SELECT ` + giftCardColumns + `
+			  FROM gift_cards
+			  WHERE id = $1`
+
+	return r.getGiftCard(ctx, query, id)
+}
+
+func (r *GiftCardRepository) GetGiftCardByIDForUpdate(ctx context.Context, id uuid.UUID) (*models.GiftCard, error) {
+	query := `SELECT ` + giftCardColumns + `
+			  FROM gift_cards
+			  WHERE id = $1
+			  FOR UPDATE`
+
+	return r.getGiftCard(ctx, query, id)
+}
+
+func (r *GiftCardRepository) getGiftCard(ctx context.Context, query string, arg any) (*models.GiftCard, error) {
+	var g models.GiftCard
+	err := scanGiftCard(conn(ctx, r.db).QueryRow(ctx, query, arg), &g)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperror.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &g, nil
+}
+
+func (r *GiftCardRepository) UpdateGiftCardBalance(ctx context.Context, g *models.GiftCard) error {
+	query := `UPDATE gift_cards
+			  SET balance = $1, updated_at = $2
+			  WHERE id = $3`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query, g.Balance, g.UpdatedAt, g.ID)
+	return err
+}
+
+// ReassignGiftCards moves the cards of the from customers to the to customer.
+func (r *GiftCardRepository) ReassignGiftCards(ctx context.Context, from []uuid.UUID, to uuid.UUID, now time.Time) error {
+	query := `UPDATE gift_cards
+			  SET customer_id = $1, updated_at = $2
+			  WHERE customer_id = ANY($3)`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query, to, now, from)
+	return err
+}
+
+func (r *GiftCardRepository) InsertTransaction(ctx context.Context, t *models.GiftCardTransaction) error {
+	query := `INSERT INTO gift_card_transactions (` + giftCardTransactionColumns + `)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query,
+		t.ID,
+		t.GiftCardID,
+		t.TransactionType,
+		t.Amount,
+		t.BalanceAfter,
+		t.SaleOrderID,
+		t.Method,
+		t.Reference,
+		t.RecordedBy,
+		t.CreatedAt,
+	)
+
+	return err
+}
+
+// GetTransactions lists the ledger of a card, newest first.
+func (r *GiftCardRepository) GetTransactions(ctx context.Context, giftCardID uuid.UUID, limit, offset int) ([]models.GiftCardTransaction, int64, error) {
+	var totalCount int64
+	countQuery := `SELECT COUNT(*) FROM gift_card_transactions WHERE gift_card_id = $1`
+	if err := conn(ctx, r.db).QueryRow(ctx, countQuery, giftCardID).Scan(&totalCount); err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT ` + giftCardTransactionColumns + `
+			  FROM gift_card_transactions
+			  WHERE gift_card_id = $1
+			  ORDER BY created_at DESC, id DESC
+			  LIMIT $2 OFFSET $3`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, giftCardID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var transactions []models.GiftCardTransaction
+	for rows.Next() {
+		var t models.GiftCardTransaction
+		err := rows.Scan(
+			&t.ID,
+			&t.GiftCardID,
+			&t.TransactionType,
+			&t.Amount,
+			&t.BalanceAfter,
+			&t.SaleOrderID,
+			&t.Method,
+			&t.Reference,
+			&t.RecordedBy,
+			&t.CreatedAt,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+		transactions = append(transactions, t)
+	}
+
+	return transactions, totalCount, rows.Err()
+}
+
+// GetSaleOrderTenders returns what each card still pays on a sale order:
+// its redemptions less what was refunded to it. Cards paying nothing are
+// left out.
+func (r *GiftCardRepository) GetSaleOrderTenders(ctx context.Context, saleOrderID uuid.UUID) ([]models.GiftCardTender, error) {
+	query := `SELECT gift_card_id,
+			         SUM(CASE WHEN transaction_type = $2 THEN amount ELSE -amount END) AS tendered
+			  FROM gift_card_transactions
+			  WHERE sale_order_id = $1 AND transaction_type IN ($2, $3)
+			  GROUP BY gift_card_id
+			  HAVING SUM(CASE WHEN transaction_type = $2 THEN amount ELSE -amount END) > 0
+			  ORDER BY gift_card_id ASC`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, saleOrderID, constants.GiftCardTransactionRedeem, constants.GiftCardTransactionRefund)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tenders []models.GiftCardTender
+	for rows.Next() {
+		var t models.GiftCardTender
+		if err := rows.Scan(&t.GiftCardID, &t.Amount); err != nil {
+			return nil, err
+		}
+		tenders = append(tenders, t)
+	}
+
+	return tenders, rows.Err()
+}
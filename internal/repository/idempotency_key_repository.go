@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	apperror "github.com/hafiztri123/kki-be/internal/app_error"
+	"github.com/hafiztri123/kki-be/internal/models"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type IdempotencyKeyRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewIdempotencyKeyRepository(db *pgxpool.Pool) *IdempotencyKeyRepository {
+	return &IdempotencyKeyRepository{
+		db: db,
+	}
+}
+
+// InsertIdempotencyKey claims a key and reports whether this call created it.
+// While the claiming transaction is open, a concurrent insert of the same key
+// blocks on the primary key until that transaction ends.
+func (r *IdempotencyKeyRepository) InsertIdempotencyKey(ctx context.Context, key *models.IdempotencyKey) (bool, error) {
+	query := `INSERT INTO idempotency_keys (user_id, idempotency_key, request_fingerprint, created_at, expires_at)
+			  VALUES ($1, $2, $3, $4, $5)
+			  ON CONFLICT (user_id, idempotency_key) DO NOTHING`
+
+	result, err := conn(ctx, r.db).Exec(ctx, query,
+		key.UserID,
+		key.Key,
+		key.RequestFingerprint,
+		key.CreatedAt,
+		key.ExpiresAt,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return result.RowsAffected() == 1, nil
+}
+
+// GetIdempotencyKeyForUpdate loads a key and locks its row until the end of
+// the surrounding transaction.
+func (r *IdempotencyKeyRepository) GetIdempotencyKeyForUpdate(ctx context.Context, userID uuid.UUID, key string) (*models.IdempotencyKey, error) {
+	query := `SELECT user_id, idempotency_key, request_fingerprint, response_status, response_content_type, response_body, created_at, expires_at
+			  FROM idempotency_keys
+			  WHERE user_id = $1 AND idempotency_key = $2
+			  FOR UPDATE`
+
+	var k models.IdempotencyKey
+	err := conn(ctx, r.db).QueryRow(ctx, query, userID, key).Scan(
+		&k.UserID,
+		&k.Key,
+		&k.RequestFingerprint,
+		&k.ResponseStatus,
+		&k.ResponseContentType,
+		&k.ResponseBody,
+		&k.CreatedAt,
+		&k.ExpiresAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperror.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &k, nil
+}
+
+// ResetIdempotencyKey reuses an expired key for a new request.
+func (r *IdempotencyKeyRepository) ResetIdempotencyKey(ctx context.Context, key *models.IdempotencyKey) error {
+	query := `UPDATE idempotency_keys
+			  SET request_fingerprint = $1, response_status = NULL, response_content_type = NULL, response_body = NULL,
+			      created_at = $2, expires_at = $3
+			  WHERE user_id = $4 AND idempotency_key = $5`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query,
+		key.RequestFingerprint,
+		key.CreatedAt,
+		key.ExpiresAt,
+		key.UserID,
+		key.Key,
+	)
+
+	return err
+}
+
+func (r *IdempotencyKeyRepository) SaveIdempotencyResponse(ctx context.Context, key *models.IdempotencyKey) error {
+	query := `UPDATE idempotency_keys
+			  SET response_status = $1, response_content_type = $2, response_body = $3
+			  WHERE user_id = $4 AND idempotency_key = $5`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query,
+		key.ResponseStatus,
+		key.ResponseContentType,
+		key.ResponseBody,
+		key.UserID,
+		key.Key,
+	)
+
+	return err
+}
+
+func (r *IdempotencyKeyRepository) DeleteExpiredIdempotencyKeys(ctx context.Context, now time.Time) (int64, error) {
+	query := `DELETE FROM idempotency_keys WHERE expires_at < $1`
+
+	result, err := conn(ctx, r.db).Exec(ctx, query, now)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected(), nil
+}
@@ -0,0 +1,292 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	apperror "github.com/hafiztri123/kki-be/internal/app_error"
+	"github.com/hafiztri123/kki-be/internal/constants"
+	"github.com/hafiztri123/kki-be/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const invoiceColumns = `id, invoice_number, sale_order_id, customer_name, store_id, currency, total_amount, paid_amount,
+			  payment_term_days, issued_at, due_at, status, created_by, created_at, updated_at`
+
+type InvoiceRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewInvoiceRepository(db *pgxpool.Pool) *InvoiceRepository {
+	return &InvoiceRepository{
+		db: db,
+	}
+}
+
+func scanInvoice(row pgx.Row, i *models.Invoice) error {
+	return row.Scan(
+		&i.ID,
+		&i.InvoiceNumber,
+		&i.SaleOrderID,
+		&i.CustomerName,
+		&i.StoreID,
+		&i.Currency,
+		&i.TotalAmount,
+		&i.PaidAmount,
+		&i.PaymentTermDays,
+		&i.IssuedAt,
+		&i.DueAt,
+		&i.Status,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+}
+
+func (r *InvoiceRepository) InsertInvoice(ctx context.Context, i *models.Invoice) error {
+	query := `INSERT INTO invoices (` + invoiceColumns + `)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query,
+		i.ID,
+		i.InvoiceNumber,
+		i.SaleOrderID,
+		i.CustomerName,
+		i.StoreID,
+		i.Currency,
+		i.TotalAmount,
+		i.PaidAmount,
+		i.PaymentTermDays,
+		i.IssuedAt,
+		i.DueAt,
+		i.Status,
+		i.CreatedBy,
+		i.CreatedAt,
+		i.UpdatedAt,
+	)
+
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == constants.UniqueConstraintViolationErrorCode && pgErr.ConstraintName == constants.InvoiceSaleOrderConstraint {
+			return apperror.ErrInvoiceAlreadyExists
+		}
+		return err
+	}
+
+	return nil
+}
+
+// GetInvoices lists invoices, newest first. Nil and empty filters are not
+// applied.
+func (r *InvoiceRepository) GetInvoices(ctx context.Context, createdBy *uuid.UUID, status, customerName string, limit, offset int) ([]models.Invoice, int64, error) {
+	where := newWhereBuilder()
+	if createdBy != nil {
+		where.add("created_by = $%d", *createdBy)
+	}
+	if status != "" {
+		where.add("status = $%d", status)
+	}
+	if customerName != "" {
+		where.add("customer_name ILIKE $%d", likePattern(customerName))
+	}
+
+	var totalCount int64
+	countQuery := `SELECT COUNT(*) FROM invoices ` + where.String()
+	if err := conn(ctx, r.db).QueryRow(ctx, countQuery, where.args...).Scan(&totalCount); err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT ` + invoiceColumns + `
+			  FROM invoices
+			  ` + where.String() + `
+			  ORDER BY issued_at DESC, id DESC
+			  LIMIT ` + where.next(limit) + ` OFFSET ` + where.next(offset)
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, where.args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var invoices []models.Invoice
+	for rows.Next() {
+		var i models.Invoice
+		if err := scanInvoice(rows, &i); err != nil {
+			return nil, 0, err
+		}
+		invoices = append(invoices, i)
+	}
+
+	return invoices, totalCount, rows.Err()
+}
+
+func (r *InvoiceRepository) GetInvoiceByID(ctx context.Context, id uuid.UUID) (*models.Invoice, error) {
+	query := `SELECT ` + invoiceColumns + `
+			  FROM invoices
+			  WHERE id = $1`
+
+	return r.getInvoice(ctx, query, id)
+}
+
+// GetInvoiceByIDForUpdate locks the invoice until the surrounding transaction
+// ends, so concurrent payments cannot overpay it.
+func (r *InvoiceRepository) GetInvoiceByIDForUpdate(ctx context.Context, id uuid.UUID) (*models.Invoice, error) {
+	query := `SELECT ` + invoiceColumns + `
+			  FROM invoices
+			  WHERE id = $1
+			  FOR UPDATE`
+
+	return r.getInvoice(ctx, query, id)
+}
+
+func (r *InvoiceRepository) getInvoice(ctx context.Context, query string, id uuid.UUID) (*models.Invoice, error) {
+	var i models.Invoice
+	err := scanInvoice(conn(ctx, r.db).QueryRow(ctx, query, id), &i)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperror.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &i, nil
+}
+
+func (r *InvoiceRepository) UpdateInvoicePaid(ctx context.Context, i *models.Invoice) error {
+	query := `UPDATE invoices
+			  SET paid_amount = $1, status = $2, updated_at = $3
+			  WHERE id = $4`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query, i.PaidAmount, i.Status, i.UpdatedAt, i.ID)
+	return err
+}
+
+// MarkOverdueInvoices moves unpaid invoices whose due date passed before now
+// to overdue and returns how many were changed.
+func (r *InvoiceRepository) MarkOverdueInvoices(ctx context.Context, now time.Time) (int64, error) {
+	query := `UPDATE invoices
+			  SET status = $1, updated_at = $2
+			  WHERE status IN ($3, $4) AND due_at < $2`
+
+	result, err := conn(ctx, r.db).Exec(ctx, query,
+		constants.InvoiceStatusOverdue,
+		now,
+		constants.InvoiceStatusOpen,
+		constants.InvoiceStatusPartiallyPaid,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected(), nil
+}
+
+func (r *InvoiceRepository) InsertInvoicePayment(ctx context.Context, p *models.InvoicePayment) error {
+	query := `INSERT INTO invoice_payments (id, invoice_id, amount, method, reference, paid_at, recorded_by, created_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query,
+		p.ID,
+		p.InvoiceID,
+		p.Amount,
+		p.Method,
+		p.Reference,
+		p.PaidAt,
+		p.RecordedBy,
+		p.CreatedAt,
+	)
+
+	return err
+}
+
+func (r *InvoiceRepository) GetInvoicePayments(ctx context.Context, invoiceID uuid.UUID) ([]models.InvoicePayment, error) {
+	query := `SELECT id, invoice_id, amount, method, reference, paid_at, recorded_by, created_at
+			  FROM invoice_payments
+			  WHERE invoice_id = $1
+			  ORDER BY paid_at ASC, id ASC`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var payments []models.InvoicePayment
+	for rows.Next() {
+		var p models.InvoicePayment
+		err := rows.Scan(
+			&p.ID,
+			&p.InvoiceID,
+			&p.Amount,
+			&p.Method,
+			&p.Reference,
+			&p.PaidAt,
+			&p.RecordedBy,
+			&p.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		payments = append(payments, p)
+	}
+
+	return payments, rows.Err()
+}
+
+// GetReceivablesAging sums what each customer still owed at asOf, bucketed by
+// days past due. Invoices not yet due count in the 0-30 bucket, and payments
+// made after asOf are not deducted, so past dates report the balance as it
+// was then.
+func (r *InvoiceRepository) GetReceivablesAging(ctx context.Context, asOf time.Time) ([]models.ReceivablesAging, error) {
+	query := `WITH balances AS (
+				  SELECT i.customer_name, i.currency,
+				         i.total_amount - COALESCE((
+				             SELECT SUM(p.amount) FROM invoice_payments p
+				             WHERE p.invoice_id = i.id AND p.paid_at <= $1
+				         ), 0) AS balance,
+				         $1::date - i.due_at::date AS days_past_due
+				  FROM invoices i
+				  WHERE i.issued_at <= $1
+			  )
+			  SELECT customer_name, currency,
+			         COALESCE(SUM(balance) FILTER (WHERE days_past_due <= 30), 0),
+			         COALESCE(SUM(balance) FILTER (WHERE days_past_due BETWEEN 31 AND 60), 0),
+			         COALESCE(SUM(balance) FILTER (WHERE days_past_due BETWEEN 61 AND 90), 0),
+			         COALESCE(SUM(balance) FILTER (WHERE days_past_due > 90), 0),
+			         SUM(balance)
+			  FROM balances
+			  WHERE balance > 0
+			  GROUP BY customer_name, currency
+			  ORDER BY customer_name ASC, currency ASC`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, asOf)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var aging []models.ReceivablesAging
+	for rows.Next() {
+		var a models.ReceivablesAging
+		err := rows.Scan(
+			&a.CustomerName,
+			&a.Currency,
+			&a.Days0To30,
+			&a.Days31To60,
+			&a.Days61To90,
+			&a.Days90Plus,
+			&a.Total,
+		)
+		if err != nil {
+			return nil, err
+		}
+		aging = append(aging, a)
+	}
+
+	return aging, rows.Err()
+}
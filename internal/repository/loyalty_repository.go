@@ -0,0 +1,333 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	apperror "github.com/hafiztri123/kki-be/internal/app_error"
+	"github.com/hafiztri123/kki-be/internal/constants"
+	"github.com/hafiztri123/kki-be/internal/models"
+	"github.com/hafiztri123/kki-be/internal/money"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const loyaltyEntryColumns = `id, customer_id, entry_type, points, remaining_points, expires_at, sale_order_id, amount, created_by, created_at`
+
+type LoyaltyRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewLoyaltyRepository(db *pgxpool.Pool) *LoyaltyRepository {
+	return &LoyaltyRepository{
+		db: db,
+	}
+}
+
+func scanLoyaltyEntry(row pgx.Row, e *models.LoyaltyPointEntry) error {
+	return row.Scan(
+		&e.ID,
+		&e.CustomerID,
+		&e.EntryType,
+		&e.Points,
+		&e.RemainingPoints,
+		&e.ExpiresAt,
+		&e.SaleOrderID,
+		&e.Amount,
+		&e.CreatedBy,
+		&e.CreatedAt,
+	)
+}
+
+func (r *LoyaltyRepository) GetSettings(ctx context.Context) (*models.LoyaltySettings, error) {
+	query := `SELECT enabled, spend_per_point, point_value, expiry_days, min_redeem_points, updated_by, updated_at
+			  FROM loyalty_settings
+			  WHERE id`
+
+	var s models.LoyaltySettings
+	err := conn(ctx, r.db).QueryRow(ctx, query).Scan(
+		&s.Enabled,
+		&s.SpendPerPoint,
+		&s.PointValue,
+		&s.ExpiryDays,
+		&s.MinRedeemPoints,
+		&s.UpdatedBy,
+		&s.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperror.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+func (r *LoyaltyRepository) UpdateSettings(ctx context.Context, s *models.LoyaltySettings) error {
+	query := `INSERT INTO loyalty_settings (id, enabled, spend_per_point, point_value, expiry_days, min_redeem_points, updated_by, updated_at)
+			  VALUES (TRUE, $1, $2, $3, $4, $5, $6, $7)
+			  ON CONFLICT (id) DO UPDATE
+			  SET enabled = EXCLUDED.enabled, spend_per_point = EXCLUDED.spend_per_point, point_value = EXCLUDED.point_value,
+			      expiry_days = EXCLUDED.expiry_days, min_redeem_points = EXCLUDED.min_redeem_points,
+			      updated_by = EXCLUDED.updated_by, updated_at = EXCLUDED.updated_at`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query,
+		s.Enabled,
+		s.SpendPerPoint,
+		s.PointValue,
+		s.ExpiryDays,
+		s.MinRedeemPoints,
+		s.UpdatedBy,
+		s.UpdatedAt,
+	)
+
+	return err
+}
+
+func (r *LoyaltyRepository) GetMultipliers(ctx context.Context) ([]models.LoyaltyMultiplier, error) {
+	query := `SELECT category, multiplier
+			  FROM loyalty_category_multipliers
+			  ORDER BY category ASC`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var multipliers []models.LoyaltyMultiplier
+	for rows.Next() {
+		var m models.LoyaltyMultiplier
+		if err := rows.Scan(&m.Category, &m.Multiplier); err != nil {
+			return nil, err
+		}
+		multipliers = append(multipliers, m)
+	}
+
+	return multipliers, rows.Err()
+}
+
+// ReplaceMultipliers swaps the category multipliers for the given ones.
+func (r *LoyaltyRepository) ReplaceMultipliers(ctx context.Context, multipliers []models.LoyaltyMultiplier) error {
+	if _, err := conn(ctx, r.db).Exec(ctx, `DELETE FROM loyalty_category_multipliers`); err != nil {
+		return err
+	}
+
+	query := `INSERT INTO loyalty_category_multipliers (category, multiplier)
+			  VALUES ($1, $2)`
+
+	for _, m := range multipliers {
+		if _, err := conn(ctx, r.db).Exec(ctx, query, m.Category, m.Multiplier); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// LockCustomer locks a customer row, deleted or not, so changes to its points
+// are serialized.
+func (r *LoyaltyRepository) LockCustomer(ctx context.Context, customerID uuid.UUID) error {
+	query := `SELECT id FROM customers WHERE id = $1 FOR UPDATE`
+
+	var id uuid.UUID
+	err := conn(ctx, r.db).QueryRow(ctx, query, customerID).Scan(&id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return apperror.ErrNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (r *LoyaltyRepository) InsertEntry(ctx context.Context, e *models.LoyaltyPointEntry) error {
+	query := `INSERT INTO loyalty_point_entries (` + loyaltyEntryColumns + `)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query,
+		e.ID,
+		e.CustomerID,
+		e.EntryType,
+		e.Points,
+		e.RemainingPoints,
+		e.ExpiresAt,
+		e.SaleOrderID,
+		e.Amount,
+		e.CreatedBy,
+		e.CreatedAt,
+	)
+
+	return err
+}
+
+// GetAvailablePoints sums what is left of the lots of a customer that have
+// not expired by now.
+func (r *LoyaltyRepository) GetAvailablePoints(ctx context.Context, customerID uuid.UUID, now time.Time) (int64, error) {
+	query := `SELECT COALESCE(SUM(remaining_points), 0)
+			  FROM loyalty_point_entries
+			  WHERE customer_id = $1 AND remaining_points > 0 AND (expires_at IS NULL OR expires_at > $2)`
+
+	var points int64
+	err := conn(ctx, r.db).QueryRow(ctx, query, customerID, now).Scan(&points)
+	return points, err
+}
+
+// GetOpenLots returns the unexpired lots of a customer with points left,
+// soonest to expire first, and locks them until the surrounding transaction
+// ends.
+func (r *LoyaltyRepository) GetOpenLots(ctx context.Context, customerID uuid.UUID, now time.Time) ([]models.LoyaltyPointEntry, error) {
+	query := `SELECT ` + loyaltyEntryColumns + `
+			  FROM loyalty_point_entries
+			  WHERE customer_id = $1 AND remaining_points > 0 AND (expires_at IS NULL OR expires_at > $2)
+			  ORDER BY expires_at ASC NULLS LAST, created_at ASC, id ASC
+			  FOR UPDATE`
+
+	return r.getEntries(ctx, query, customerID, now)
+}
+
+// GetExpiredLots returns up to limit lots that expired before now with points
+// left, locked until the surrounding transaction ends. Lots locked by another
+// transaction are skipped.
+func (r *LoyaltyRepository) GetExpiredLots(ctx context.Context, now time.Time, limit int) ([]models.LoyaltyPointEntry, error) {
+	query := `SELECT ` + loyaltyEntryColumns + `
+			  FROM loyalty_point_entries
+			  WHERE remaining_points > 0 AND expires_at <= $1
+			  ORDER BY expires_at ASC, id ASC
+			  LIMIT $2
+			  FOR UPDATE SKIP LOCKED`
+
+	return r.getEntries(ctx, query, now, limit)
+}
+
+func (r *LoyaltyRepository) SetRemainingPoints(ctx context.Context, id uuid.UUID, remaining int64) error {
+	query := `UPDATE loyalty_point_entries
+			  SET remaining_points = $1
+			  WHERE id = $2`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query, remaining, id)
+	return err
+}
+
+// GetEntries lists the ledger of a customer, newest first.
+func (r *LoyaltyRepository) GetEntries(ctx context.Context, customerID uuid.UUID, limit, offset int) ([]models.LoyaltyPointEntry, int64, error) {
+	var totalCount int64
+	countQuery := `SELECT COUNT(*) FROM loyalty_point_entries WHERE customer_id = $1`
+	if err := conn(ctx, r.db).QueryRow(ctx, countQuery, customerID).Scan(&totalCount); err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT ` + loyaltyEntryColumns + `
+			  FROM loyalty_point_entries
+			  WHERE customer_id = $1
+			  ORDER BY created_at DESC, id DESC
+			  LIMIT $2 OFFSET $3`
+
+	entries, err := r.getEntries(ctx, query, customerID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return entries, totalCount, nil
+}
+
+func (r *LoyaltyRepository) getEntries(ctx context.Context, query string, args ...any) ([]models.LoyaltyPointEntry, error) {
+	rows, err := conn(ctx, r.db).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []models.LoyaltyPointEntry
+	for rows.Next() {
+		var e models.LoyaltyPointEntry
+		if err := scanLoyaltyEntry(rows, &e); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, rows.Err()
+}
+
+// GetSaleOrderPoints sums what a sale order earned, redeemed and gave back,
+// and whose points they were.
+func (r *LoyaltyRepository) GetSaleOrderPoints(ctx context.Context, saleOrderID uuid.UUID) (*models.LoyaltyOrderPoints, error) {
+	query := `SELECT entry_type, customer_id, COALESCE(SUM(ABS(points)), 0), COALESCE(SUM(amount), 0)
+			  FROM loyalty_point_entries
+			  WHERE sale_order_id = $1
+			  GROUP BY entry_type, customer_id`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, saleOrderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var p models.LoyaltyOrderPoints
+	for rows.Next() {
+		var entryType string
+		var customerID uuid.UUID
+		var points int64
+		var amount money.Amount
+		if err := rows.Scan(&entryType, &customerID, &points, &amount); err != nil {
+			return nil, err
+		}
+
+		switch entryType {
+		case constants.LoyaltyEntryEarn:
+			p.Earned += points
+			p.EarnCustomerID = uuid.NullUUID{UUID: customerID, Valid: true}
+		case constants.LoyaltyEntryReverse:
+			p.Reversed += points
+		case constants.LoyaltyEntryRedeem:
+			p.Redeemed += points
+			p.RedeemedAmount += amount
+			p.RedeemCustomerID = uuid.NullUUID{UUID: customerID, Valid: true}
+		case constants.LoyaltyEntryRestore:
+			p.Restored += points
+			p.RestoredAmount += amount
+		}
+	}
+
+	return &p, rows.Err()
+}
+
+// GetNextExpiry returns how many points of a customer expire first and when.
+// It reports ErrNotFound when no points are due to expire.
+func (r *LoyaltyRepository) GetNextExpiry(ctx context.Context, customerID uuid.UUID) (int64, time.Time, error) {
+	query := `SELECT SUM(remaining_points), expires_at
+			  FROM loyalty_point_entries
+			  WHERE customer_id = $1 AND remaining_points > 0 AND expires_at IS NOT NULL
+			  GROUP BY expires_at
+			  ORDER BY expires_at ASC
+			  LIMIT 1`
+
+	var points int64
+	var at time.Time
+	err := conn(ctx, r.db).QueryRow(ctx, query, customerID).Scan(&points, &at)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, time.Time{}, apperror.ErrNotFound
+		}
+		return 0, time.Time{}, err
+	}
+
+	return points, at, nil
+}
+
+// ReassignEntries moves the points ledgers of the from customers to the to
+// customer.
+func (r *LoyaltyRepository) ReassignEntries(ctx context.Context, from []uuid.UUID, to uuid.UUID) error {
+	query := `UPDATE loyalty_point_entries
+			  SET customer_id = $1
+			  WHERE customer_id = ANY($2)`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query, to, from)
+	return err
+}
@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type OrderNumberRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewOrderNumberRepository(db *pgxpool.Pool) *OrderNumberRepository {
+	return &OrderNumberRepository{
+		db: db,
+	}
+}
+
+// NextValue increments and returns the counter for the scope and period. The
+// counter row stays locked until the surrounding transaction ends, so numbers
+// are handed out in order and a rolled back order does not leave a gap.
+func (r *OrderNumberRepository) NextValue(ctx context.Context, scope, period string) (int64, error) {
+	query := `INSERT INTO order_number_sequences (scope, period, last_value, updated_at)
+			  VALUES ($1, $2, 1, NOW())
+			  ON CONFLICT (scope, period)
+			  DO UPDATE SET last_value = order_number_sequences.last_value + 1, updated_at = NOW()
+			  RETURNING last_value`
+
+	var value int64
+	err := conn(ctx, r.db).QueryRow(ctx, query, scope, period).Scan(&value)
+	return value, err
+}
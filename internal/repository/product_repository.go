@@ -0,0 +1,239 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	apperror "github.com/hafiztri123/kki-be/internal/app_error"
+	"github.com/hafiztri123/kki-be/internal/constants"
+	"github.com/hafiztri123/kki-be/internal/models"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ProductRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewProductRepository(db *pgxpool.Pool) *ProductRepository {
+	return &ProductRepository{
+		db: db,
+	}
+}
+
+func (r *ProductRepository) InsertProduct(ctx context.Context, product *models.Product) error {
+	query := `INSERT INTO products (id, sku, name, category, unit_price, created_at, updated_at, deleted_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query,
+		product.ID,
+		product.SKU,
+		product.Name,
+		product.Category,
+		product.UnitPrice,
+		product.CreatedAt,
+		product.UpdatedAt,
+		product.DeletedAt,
+	)
+
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			if pgErr.Code == constants.UniqueConstraintViolationErrorCode {
+				return apperror.ErrSKUAlreadyExists
+			}
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (r *ProductRepository) GetProducts(ctx context.Context, limit, offset int) ([]models.Product, int64, error) {
+	var totalCount int64
+	countQuery := `SELECT COUNT(*) FROM products WHERE deleted_at IS NULL`
+	err := conn(ctx, r.db).QueryRow(ctx, countQuery).Scan(&totalCount)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT id, sku, name, category, unit_price, created_at, updated_at, deleted_at
+			  FROM products
+			  WHERE deleted_at IS NULL
+			  ORDER BY name ASC
+			  LIMIT $1 OFFSET $2`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var products []models.Product
+	for rows.Next() {
+		var p models.Product
+		err := rows.Scan(
+			&p.ID,
+			&p.SKU,
+			&p.Name,
+			&p.Category,
+			&p.UnitPrice,
+			&p.CreatedAt,
+			&p.UpdatedAt,
+			&p.DeletedAt,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+		products = append(products, p)
+	}
+
+	return products, totalCount, rows.Err()
+}
+
+func (r *ProductRepository) GetProductByID(ctx context.Context, id uuid.UUID) (*models.Product, error) {
+	query := `SELECT id, sku, name, category, unit_price, created_at, updated_at, deleted_at
+			  FROM products
+			  WHERE id = $1 AND deleted_at IS NULL`
+
+	var p models.Product
+	err := conn(ctx, r.db).QueryRow(ctx, query, id).Scan(
+		&p.ID,
+		&p.SKU,
+		&p.Name,
+		&p.Category,
+		&p.UnitPrice,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+		&p.DeletedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperror.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+func (r *ProductRepository) GetProductsByIDs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]models.Product, error) {
+	query := `SELECT id, sku, name, category, unit_price, created_at, updated_at, deleted_at
+			  FROM products
+			  WHERE id = ANY($1) AND deleted_at IS NULL`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	products := make(map[uuid.UUID]models.Product, len(ids))
+	for rows.Next() {
+		var p models.Product
+		err := rows.Scan(
+			&p.ID,
+			&p.SKU,
+			&p.Name,
+			&p.Category,
+			&p.UnitPrice,
+			&p.CreatedAt,
+			&p.UpdatedAt,
+			&p.DeletedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		products[p.ID] = p
+	}
+
+	return products, rows.Err()
+}
+
+func (r *ProductRepository) UpdateProduct(ctx context.Context, product *models.Product) error {
+	query := `UPDATE products
+			  SET sku = $1, name = $2, category = $3, unit_price = $4, updated_at = $5
+			  WHERE id = $6 AND deleted_at IS NULL`
+
+	result, err := conn(ctx, r.db).Exec(ctx, query,
+		product.SKU,
+		product.Name,
+		product.Category,
+		product.UnitPrice,
+		product.UpdatedAt,
+		product.ID,
+	)
+
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			if pgErr.Code == constants.UniqueConstraintViolationErrorCode {
+				return apperror.ErrSKUAlreadyExists
+			}
+		}
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return apperror.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *ProductRepository) DeleteProduct(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE products
+			  SET deleted_at = NOW()
+			  WHERE id = $1 AND deleted_at IS NULL`
+
+	result, err := conn(ctx, r.db).Exec(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return apperror.ErrNotFound
+	}
+
+	return nil
+}
+
+// GetProductsChangedSince returns products created, updated or deleted after
+// since, including soft deleted rows so clients can drop them.
+func (r *ProductRepository) GetProductsChangedSince(ctx context.Context, since time.Time) ([]models.Product, error) {
+	query := `SELECT id, sku, name, category, unit_price, created_at, updated_at, deleted_at
+			  FROM products
+			  WHERE updated_at > $1 OR deleted_at > $1
+			  ORDER BY updated_at ASC, id ASC`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var products []models.Product
+	for rows.Next() {
+		var p models.Product
+		err := rows.Scan(
+			&p.ID,
+			&p.SKU,
+			&p.Name,
+			&p.Category,
+			&p.UnitPrice,
+			&p.CreatedAt,
+			&p.UpdatedAt,
+			&p.DeletedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		products = append(products, p)
+	}
+
+	return products, rows.Err()
+}
@@ -0,0 +1,267 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	apperror "github.com/hafiztri123/kki-be/internal/app_error"
+	"github.com/hafiztri123/kki-be/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const promotionColumns = `id, name, type, product_id, category, buy_quantity, get_quantity, percentage, amount, min_spend,
+			  starts_at, ends_at, priority, stackable, usage_limit, usage_count, is_active, created_at, updated_at, deleted_at`
+
+type PromotionRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewPromotionRepository(db *pgxpool.Pool) *PromotionRepository {
+	return &PromotionRepository{
+		db: db,
+	}
+}
+
+func scanPromotion(row pgx.Row, p *models.Promotion) error {
+	return row.Scan(
+		&p.ID,
+		&p.Name,
+		&p.Type,
+		&p.ProductID,
+		&p.Category,
+		&p.BuyQuantity,
+		&p.GetQuantity,
+		&p.Percentage,
+		&p.Amount,
+		&p.MinSpend,
+		&p.StartsAt,
+		&p.EndsAt,
+		&p.Priority,
+		&p.Stackable,
+		&p.UsageLimit,
+		&p.UsageCount,
+		&p.IsActive,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+		&p.DeletedAt,
+	)
+}
+
+func (r *PromotionRepository) InsertPromotion(ctx context.Context, p *models.Promotion) error {
+	query := `INSERT INTO promotions (` + promotionColumns + `)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20)`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query,
+		p.ID,
+		p.Name,
+		p.Type,
+		p.ProductID,
+		p.Category,
+		p.BuyQuantity,
+		p.GetQuantity,
+		p.Percentage,
+		p.Amount,
+		p.MinSpend,
+		p.StartsAt,
+		p.EndsAt,
+		p.Priority,
+		p.Stackable,
+		p.UsageLimit,
+		p.UsageCount,
+		p.IsActive,
+		p.CreatedAt,
+		p.UpdatedAt,
+		p.DeletedAt,
+	)
+
+	return err
+}
+
+func (r *PromotionRepository) GetPromotions(ctx context.Context, limit, offset int) ([]models.Promotion, int64, error) {
+	var totalCount int64
+	countQuery := `SELECT COUNT(*) FROM promotions WHERE deleted_at IS NULL`
+	err := conn(ctx, r.db).QueryRow(ctx, countQuery).Scan(&totalCount)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT ` + promotionColumns + `
+			  FROM promotions
+			  WHERE deleted_at IS NULL
+			  ORDER BY priority ASC, created_at DESC
+			  LIMIT $1 OFFSET $2`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var promotions []models.Promotion
+	for rows.Next() {
+		var p models.Promotion
+		if err := scanPromotion(rows, &p); err != nil {
+			return nil, 0, err
+		}
+		promotions = append(promotions, p)
+	}
+
+	return promotions, totalCount, rows.Err()
+}
+
+func (r *PromotionRepository) GetPromotionByID(ctx context.Context, id uuid.UUID) (*models.Promotion, error) {
+	query := `SELECT ` + promotionColumns + `
+			  FROM promotions
+			  WHERE id = $1 AND deleted_at IS NULL`
+
+	var p models.Promotion
+	err := scanPromotion(conn(ctx, r.db).QueryRow(ctx, query, id), &p)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperror.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+// GetApplicablePromotions returns active promotions valid at the given time that
+// still have usage left, in evaluation order (lowest priority value first).
+func (r *PromotionRepository) GetApplicablePromotions(ctx context.Context, at time.Time) ([]models.Promotion, error) {
+	query := `SELECT ` + promotionColumns + `
+			  FROM promotions
+			  WHERE deleted_at IS NULL
+			    AND is_active
+			    AND starts_at <= $1 AND ends_at > $1
+			    AND (usage_limit IS NULL OR usage_count < usage_limit)
+			  ORDER BY priority ASC, created_at ASC`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, at)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var promotions []models.Promotion
+	for rows.Next() {
+		var p models.Promotion
+		if err := scanPromotion(rows, &p); err != nil {
+			return nil, err
+		}
+		promotions = append(promotions, p)
+	}
+
+	return promotions, rows.Err()
+}
+
+func (r *PromotionRepository) UpdatePromotion(ctx context.Context, p *models.Promotion) error {
+	query := `UPDATE promotions
+			  SET name = $1, type = $2, product_id = $3, category = $4, buy_quantity = $5, get_quantity = $6,
+			      percentage = $7, amount = $8, min_spend = $9, starts_at = $10, ends_at = $11, priority = $12,
+			      stackable = $13, usage_limit = $14, is_active = $15, updated_at = $16
+			  WHERE id = $17 AND deleted_at IS NULL`
+
+	result, err := conn(ctx, r.db).Exec(ctx, query,
+		p.Name,
+		p.Type,
+		p.ProductID,
+		p.Category,
+		p.BuyQuantity,
+		p.GetQuantity,
+		p.Percentage,
+		p.Amount,
+		p.MinSpend,
+		p.StartsAt,
+		p.EndsAt,
+		p.Priority,
+		p.Stackable,
+		p.UsageLimit,
+		p.IsActive,
+		p.UpdatedAt,
+		p.ID,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return apperror.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *PromotionRepository) DeletePromotion(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE promotions
+			  SET deleted_at = NOW()
+			  WHERE id = $1 AND deleted_at IS NULL`
+
+	result, err := conn(ctx, r.db).Exec(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return apperror.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *PromotionRepository) IncrementUsage(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE promotions
+			  SET usage_count = usage_count + 1
+			  WHERE id = $1 AND (usage_limit IS NULL OR usage_count < usage_limit)`
+
+	result, err := conn(ctx, r.db).Exec(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return apperror.ErrPromotionUsageLimitReached
+	}
+
+	return nil
+}
+
+func (r *PromotionRepository) DecrementUsage(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE promotions
+			  SET usage_count = usage_count - 1
+			  WHERE id = $1 AND usage_count > 0`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query, id)
+	return err
+}
+
+// GetPromotionsChangedSince returns promotions created, updated or deleted
+// after since, including soft deleted rows.
+func (r *PromotionRepository) GetPromotionsChangedSince(ctx context.Context, since time.Time) ([]models.Promotion, error) {
+	query := `SELECT ` + promotionColumns + `
+			  FROM promotions
+			  WHERE updated_at > $1 OR deleted_at > $1
+			  ORDER BY updated_at ASC, id ASC`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var promotions []models.Promotion
+	for rows.Next() {
+		var p models.Promotion
+		if err := scanPromotion(rows, &p); err != nil {
+			return nil, err
+		}
+		promotions = append(promotions, p)
+	}
+
+	return promotions, rows.Err()
+}
@@ -0,0 +1,268 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	apperror "github.com/hafiztri123/kki-be/internal/app_error"
+	"github.com/hafiztri123/kki-be/internal/constants"
+	"github.com/hafiztri123/kki-be/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const quotationColumns = `id, customer_name, store_id, currency, price_includes_tax, tax_exempt, tax_exemption_reference,
+			  subtotal_amount, discount_amount, tax_amount, total_amount, status, valid_until, sale_order_id, created_by, created_at, updated_at, deleted_at`
+
+type QuotationRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewQuotationRepository(db *pgxpool.Pool) *QuotationRepository {
+	return &QuotationRepository{
+		db: db,
+	}
+}
+
+func scanQuotation(row pgx.Row, q *models.Quotation) error {
+	return row.Scan(
+		&q.ID,
+		&q.CustomerName,
+		&q.StoreID,
+		&q.Currency,
+		&q.PriceIncludesTax,
+		&q.TaxExempt,
+		&q.TaxExemptionReference,
+		&q.SubtotalAmount,
+		&q.DiscountAmount,
+		&q.TaxAmount,
+		&q.TotalAmount,
+		&q.Status,
+		&q.ValidUntil,
+		&q.SaleOrderID,
+		&q.CreatedBy,
+		&q.CreatedAt,
+		&q.UpdatedAt,
+		&q.DeletedAt,
+	)
+}
+
+func (r *QuotationRepository) InsertQuotation(ctx context.Context, q *models.Quotation) error {
+	query := `INSERT INTO quotations (` + quotationColumns + `)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query,
+		q.ID,
+		q.CustomerName,
+		q.StoreID,
+		q.Currency,
+		q.PriceIncludesTax,
+		q.TaxExempt,
+		q.TaxExemptionReference,
+		q.SubtotalAmount,
+		q.DiscountAmount,
+		q.TaxAmount,
+		q.TotalAmount,
+		q.Status,
+		q.ValidUntil,
+		q.SaleOrderID,
+		q.CreatedBy,
+		q.CreatedAt,
+		q.UpdatedAt,
+		q.DeletedAt,
+	)
+
+	return err
+}
+
+// GetQuotations lists quotations, newest first. A nil createdBy lists every
+// quotation and an empty status every status.
+func (r *QuotationRepository) GetQuotations(ctx context.Context, createdBy *uuid.UUID, status string, limit, offset int) ([]models.Quotation, int64, error) {
+	where := newWhereBuilder("deleted_at IS NULL")
+	if createdBy != nil {
+		where.add("created_by = $%d", *createdBy)
+	}
+	if status != "" {
+		where.add("status = $%d", status)
+	}
+
+	var totalCount int64
+	countQuery := `SELECT COUNT(*) FROM quotations ` + where.String()
+	if err := conn(ctx, r.db).QueryRow(ctx, countQuery, where.args...).Scan(&totalCount); err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT ` + quotationColumns + `
+			  FROM quotations
+			  ` + where.String() + `
+			  ORDER BY created_at DESC, id DESC
+			  LIMIT ` + where.next(limit) + ` OFFSET ` + where.next(offset)
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, where.args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var quotations []models.Quotation
+	for rows.Next() {
+		var q models.Quotation
+		if err := scanQuotation(rows, &q); err != nil {
+			return nil, 0, err
+		}
+		quotations = append(quotations, q)
+	}
+
+	return quotations, totalCount, rows.Err()
+}
+
+func (r *QuotationRepository) GetQuotationByID(ctx context.Context, id uuid.UUID) (*models.Quotation, error) {
+	query := `SELECT ` + quotationColumns + `
+			  FROM quotations
+			  WHERE id = $1 AND deleted_at IS NULL`
+
+	return r.getQuotation(ctx, query, id)
+}
+
+// GetQuotationByIDForUpdate locks the quotation until the surrounding
+// transaction ends, so it is converted at most once.
+func (r *QuotationRepository) GetQuotationByIDForUpdate(ctx context.Context, id uuid.UUID) (*models.Quotation, error) {
+	query := `SELECT ` + quotationColumns + `
+			  FROM quotations
+			  WHERE id = $1 AND deleted_at IS NULL
+			  FOR UPDATE`
+
+	return r.getQuotation(ctx, query, id)
+}
+
+func (r *QuotationRepository) getQuotation(ctx context.Context, query string, id uuid.UUID) (*models.Quotation, error) {
+	var q models.Quotation
+	err := scanQuotation(conn(ctx, r.db).QueryRow(ctx, query, id), &q)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperror.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &q, nil
+}
+
+// UpdateQuotationStatus moves a quotation from one status to another. It
+// reports ErrNotFound when the quotation is gone or no longer in from.
+func (r *QuotationRepository) UpdateQuotationStatus(ctx context.Context, id uuid.UUID, from, to string, at time.Time) error {
+	query := `UPDATE quotations
+			  SET status = $1, updated_at = $2
+			  WHERE id = $3 AND status = $4 AND deleted_at IS NULL`
+
+	result, err := conn(ctx, r.db).Exec(ctx, query, to, at, id, from)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return apperror.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *QuotationRepository) SetQuotationSaleOrder(ctx context.Context, id, saleOrderID uuid.UUID, at time.Time) error {
+	query := `UPDATE quotations
+			  SET sale_order_id = $1, updated_at = $2
+			  WHERE id = $3 AND deleted_at IS NULL`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query, saleOrderID, at, id)
+	return err
+}
+
+// ExpireQuotations marks sent quotations whose validity ended before now as
+// expired and returns how many were changed.
+func (r *QuotationRepository) ExpireQuotations(ctx context.Context, now time.Time) (int64, error) {
+	query := `UPDATE quotations
+			  SET status = $1, updated_at = $2
+			  WHERE status = $3 AND valid_until < $2 AND deleted_at IS NULL`
+
+	result, err := conn(ctx, r.db).Exec(ctx, query, constants.QuotationStatusExpired, now, constants.QuotationStatusSent)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected(), nil
+}
+
+func (r *QuotationRepository) InsertQuotationItems(ctx context.Context, items []models.QuotationItem) error {
+	query := `INSERT INTO quotation_items (id, quotation_id, line_number, product_id, product_name, category, quantity, unit_price, subtotal_amount, discount_amount, total_amount,
+			  tax_rate, taxable_amount, tax_amount, created_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)`
+
+	for _, item := range items {
+		_, err := conn(ctx, r.db).Exec(ctx, query,
+			item.ID,
+			item.QuotationID,
+			item.LineNumber,
+			item.ProductID,
+			item.ProductName,
+			item.Category,
+			item.Quantity,
+			item.UnitPrice,
+			item.SubtotalAmount,
+			item.DiscountAmount,
+			item.TotalAmount,
+			item.TaxRate,
+			item.TaxableAmount,
+			item.TaxAmount,
+			item.CreatedAt,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *QuotationRepository) GetQuotationItems(ctx context.Context, quotationID uuid.UUID) ([]models.QuotationItem, error) {
+	query := `SELECT id, quotation_id, line_number, product_id, product_name, category, quantity, unit_price, subtotal_amount, discount_amount, total_amount,
+			  tax_rate, taxable_amount, tax_amount, created_at
+			  FROM quotation_items
+			  WHERE quotation_id = $1
+			  ORDER BY line_number ASC`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, quotationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []models.QuotationItem
+	for rows.Next() {
+		var item models.QuotationItem
+		err := rows.Scan(
+			&item.ID,
+			&item.QuotationID,
+			&item.LineNumber,
+			&item.ProductID,
+			&item.ProductName,
+			&item.Category,
+			&item.Quantity,
+			&item.UnitPrice,
+			&item.SubtotalAmount,
+			&item.DiscountAmount,
+			&item.TotalAmount,
+			&item.TaxRate,
+			&item.TaxableAmount,
+			&item.TaxAmount,
+			&item.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
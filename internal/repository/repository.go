@@ -1,17 +1,109 @@
 package repository
 
-import "github.com/jackc/pgx/v5/pgxpool"
+import (
+	"context"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
 
+type DBTX interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+type txKey struct{}
+
+type Transactor struct {
+	db *pgxpool.Pool
+}
+
+func NewTransactor(db *pgxpool.Pool) *Transactor {
+	return &Transactor{
+		db: db,
+	}
+}
+
+// WithinTransaction runs fn inside a database transaction. Repository calls made
+// with the context passed to fn use that transaction. A nested call runs in a
+// savepoint, so its failure can be handled without aborting the outer transaction.
+func (t *Transactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	var tx pgx.Tx
+	var err error
+
+	if outer, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		tx, err = outer.Begin(ctx)
+	} else {
+		tx, err = t.db.Begin(ctx)
+	}
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func conn(ctx context.Context, db *pgxpool.Pool) DBTX {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return tx
+	}
+
+	return db
+}
 
 type Repositories struct {
-	UserRepository      *UserRepository
-	SaleOrderRepository *SaleOrderRepository
+	Transactor                   *Transactor
+	UserRepository               *UserRepository
+	SaleOrderRepository          *SaleOrderRepository
+	ProductRepository            *ProductRepository
+	PromotionRepository          *PromotionRepository
+	StoreRepository              *StoreRepository
+	TaxRateRepository            *TaxRateRepository
+	OrderNumberRepository        *OrderNumberRepository
+	IdempotencyKeyRepository     *IdempotencyKeyRepository
+	SaleOrderRevisionRepository  *SaleOrderRevisionRepository
+	SaleOrderHoldEventRepository *SaleOrderHoldEventRepository
+	QuotationRepository          *QuotationRepository
+	InvoiceRepository            *InvoiceRepository
+	CustomerAccountRepository    *CustomerAccountRepository
+	CustomerRepository           *CustomerRepository
+	LoyaltyRepository            *LoyaltyRepository
+	GiftCardRepository           *GiftCardRepository
+	VoucherRepository            *VoucherRepository
+	EmailRepository              *EmailRepository
+	WebhookRepository            *WebhookRepository
+	EventRepository              *EventRepository
 }
 
 func NewRepositories(db *pgxpool.Pool) *Repositories {
 	return &Repositories{
-		UserRepository:      NewUserRepository(db),
-		SaleOrderRepository: NewSaleOrderRepository(db),
+		Transactor:                   NewTransactor(db),
+		UserRepository:               NewUserRepository(db),
+		SaleOrderRepository:          NewSaleOrderRepository(db),
+		ProductRepository:            NewProductRepository(db),
+		PromotionRepository:          NewPromotionRepository(db),
+		StoreRepository:              NewStoreRepository(db),
+		TaxRateRepository:            NewTaxRateRepository(db),
+		OrderNumberRepository:        NewOrderNumberRepository(db),
+		IdempotencyKeyRepository:     NewIdempotencyKeyRepository(db),
+		SaleOrderRevisionRepository:  NewSaleOrderRevisionRepository(db),
+		SaleOrderHoldEventRepository: NewSaleOrderHoldEventRepository(db),
+		QuotationRepository:          NewQuotationRepository(db),
+		InvoiceRepository:            NewInvoiceRepository(db),
+		CustomerAccountRepository:    NewCustomerAccountRepository(db),
+		CustomerRepository:           NewCustomerRepository(db),
+		LoyaltyRepository:            NewLoyaltyRepository(db),
+		GiftCardRepository:           NewGiftCardRepository(db),
+		VoucherRepository:            NewVoucherRepository(db),
+		EmailRepository:              NewEmailRepository(db),
+		WebhookRepository:            NewWebhookRepository(db),
+		EventRepository:              NewEventRepository(db),
 	}
 }
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/hafiztri123/kki-be/internal/models"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type SaleOrderHoldEventRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewSaleOrderHoldEventRepository(db *pgxpool.Pool) *SaleOrderHoldEventRepository {
+	return &SaleOrderHoldEventRepository{
+		db: db,
+	}
+}
+
+func (r *SaleOrderHoldEventRepository) InsertHoldEvent(ctx context.Context, event *models.SaleOrderHoldEvent) error {
+	query := `INSERT INTO sale_order_hold_events (id, sale_order_id, action, actor_id, previous_cashier_id, register_id, created_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7)`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query,
+		event.ID,
+		event.SaleOrderID,
+		event.Action,
+		event.ActorID,
+		event.PreviousCashierID,
+		event.RegisterID,
+		event.CreatedAt,
+	)
+
+	return err
+}
+
+func (r *SaleOrderHoldEventRepository) GetHoldEvents(ctx context.Context, saleOrderID uuid.UUID) ([]models.SaleOrderHoldEvent, error) {
+	query := `SELECT id, sale_order_id, action, actor_id, previous_cashier_id, register_id, created_at
+			  FROM sale_order_hold_events
+			  WHERE sale_order_id = $1
+			  ORDER BY created_at ASC, id ASC`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, saleOrderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []models.SaleOrderHoldEvent
+	for rows.Next() {
+		var e models.SaleOrderHoldEvent
+		err := rows.Scan(
+			&e.ID,
+			&e.SaleOrderID,
+			&e.Action,
+			&e.ActorID,
+			&e.PreviousCashierID,
+			&e.RegisterID,
+			&e.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+
+	return events, rows.Err()
+}
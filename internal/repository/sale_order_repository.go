@@ -4,13 +4,20 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"time"
 
+	"github.com/google/uuid"
 	apperror "github.com/hafiztri123/kki-be/internal/app_error"
+	"github.com/hafiztri123/kki-be/internal/constants"
 	"github.com/hafiztri123/kki-be/internal/models"
-	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+const saleOrderColumns = `id, order_number, customer_name, store_id, currency, price_includes_tax, tax_exempt, tax_exemption_reference,
+			  subtotal_amount, discount_amount, tax_amount, total_amount, status, created_by, device_id, synced_at, hold_label, register_id, held_at, quotation_id, customer_id, cash_tendered, created_at, updated_at, version, deleted_at`
+
 type SaleOrderRepository struct {
 	db *pgxpool.Pool
 }
@@ -21,40 +28,152 @@ func NewSaleOrderRepository(db *pgxpool.Pool) *SaleOrderRepository {
 	}
 }
 
+func scanSaleOrder(row pgx.Row, so *models.SaleOrder) error {
+	return row.Scan(
+		&so.ID,
+		&so.OrderNumber,
+		&so.CustomerName,
+		&so.StoreID,
+		&so.Currency,
+		&so.PriceIncludesTax,
+		&so.TaxExempt,
+		&so.TaxExemptionReference,
+		&so.SubtotalAmount,
+		&so.DiscountAmount,
+		&so.TaxAmount,
+		&so.TotalAmount,
+		&so.Status,
+		&so.CreatedBy,
+		&so.DeviceID,
+		&so.SyncedAt,
+		&so.HoldLabel,
+		&so.RegisterID,
+		&so.HeldAt,
+		&so.QuotationID,
+		&so.CustomerID,
+		&so.CashTendered,
+		&so.CreatedAt,
+		&so.UpdatedAt,
+		&so.Version,
+		&so.DeletedAt,
+	)
+}
+
 func (r *SaleOrderRepository) InsertSaleOrder(ctx context.Context, saleOrder *models.SaleOrder) error {
-	query := `INSERT INTO sale_orders (id, order_number, customer_name, total_amount, status, created_by, created_at, updated_at, deleted_at)
-			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+	query := `INSERT INTO sale_orders (` + saleOrderColumns + `)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26)`
 
-	_, err := r.db.Exec(ctx, query,
+	_, err := conn(ctx, r.db).Exec(ctx, query,
 		saleOrder.ID,
 		saleOrder.OrderNumber,
 		saleOrder.CustomerName,
+		saleOrder.StoreID,
+		saleOrder.Currency,
+		saleOrder.PriceIncludesTax,
+		saleOrder.TaxExempt,
+		saleOrder.TaxExemptionReference,
+		saleOrder.SubtotalAmount,
+		saleOrder.DiscountAmount,
+		saleOrder.TaxAmount,
 		saleOrder.TotalAmount,
 		saleOrder.Status,
 		saleOrder.CreatedBy,
+		saleOrder.DeviceID,
+		saleOrder.SyncedAt,
+		saleOrder.HoldLabel,
+		saleOrder.RegisterID,
+		saleOrder.HeldAt,
+		saleOrder.QuotationID,
+		saleOrder.CustomerID,
+		saleOrder.CashTendered,
 		saleOrder.CreatedAt,
 		saleOrder.UpdatedAt,
+		saleOrder.Version,
 		saleOrder.DeletedAt,
 	)
 
-	return err
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			if pgErr.Code == constants.UniqueConstraintViolationErrorCode && pgErr.ConstraintName == constants.SaleOrderOrderNumberConstraint {
+				return apperror.ErrOrderNumberConflict
+			}
+			if pgErr.Code == constants.UniqueConstraintViolationErrorCode && pgErr.ConstraintName == constants.SaleOrderPrimaryKeyConstraint {
+				return apperror.ErrSaleOrderAlreadyExists
+			}
+		}
+		return err
+	}
+
+	return nil
 }
 
-func (r *SaleOrderRepository) GetSaleOrders(ctx context.Context, limit, offset int) ([]models.SaleOrder, int64, error) {
-	var totalCount int64
-	countQuery := `SELECT COUNT(*) FROM sale_orders WHERE deleted_at IS NULL`
-	err := r.db.QueryRow(ctx, countQuery).Scan(&totalCount)
+// saleOrderSortColumns whitelists the fields a sale order list can be sorted by.
+var saleOrderSortColumns = map[string]string{
+	"created_at":    "created_at",
+	"updated_at":    "updated_at",
+	"order_number":  "order_number",
+	"customer_name": "customer_name",
+	"total_amount":  "total_amount",
+	"status":        "status",
+}
+
+func saleOrderWhere(filter models.SaleOrderFilter) *whereBuilder {
+	where := newWhereBuilder("deleted_at IS NULL")
+
+	if len(filter.Statuses) > 0 {
+		where.add("status = ANY($%d)", filter.Statuses)
+	}
+	if filter.CreatedFrom != nil {
+		where.add("created_at >= $%d", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		where.add("created_at < $%d", *filter.CreatedTo)
+	}
+	if filter.CustomerName != "" {
+		where.add("customer_name ILIKE $%d", likePattern(filter.CustomerName))
+	}
+	if filter.CustomerID != nil {
+		where.add("customer_id = $%d", *filter.CustomerID)
+	}
+	if filter.CreatedBy != nil {
+		where.add("created_by = $%d", *filter.CreatedBy)
+	}
+	if filter.StoreID != nil {
+		where.add("store_id = $%d", *filter.StoreID)
+	}
+	if filter.RegisterID != "" {
+		where.add("register_id = $%d", filter.RegisterID)
+	}
+	if filter.MinAmount != nil {
+		where.add("total_amount >= $%d", *filter.MinAmount)
+	}
+	if filter.MaxAmount != nil {
+		where.add("total_amount <= $%d", *filter.MaxAmount)
+	}
+	if filter.Query != "" {
+		pattern := likePattern(filter.Query)
+		where.add("(order_number ILIKE $%d OR customer_name ILIKE $%d)", pattern, pattern)
+	}
+
+	return where
+}
+
+func (r *SaleOrderRepository) GetSaleOrders(ctx context.Context, filter models.SaleOrderFilter, limit, offset int) ([]models.SaleOrder, int64, error) {
+	totalCount, err := r.CountSaleOrders(ctx, filter)
 	if err != nil {
 		return nil, 0, err
 	}
 
-	query := `SELECT id, order_number, customer_name, total_amount, status, created_by, created_at, updated_at, deleted_at
+	where := saleOrderWhere(filter)
+
+	query := `SELECT ` + saleOrderColumns + `
 			  FROM sale_orders
-			  WHERE deleted_at IS NULL
-			  ORDER BY created_at DESC
-			  LIMIT $1 OFFSET $2`
+			  ` + where.String() + `
+			  ` + orderByClause(filter.Sort, saleOrderSortColumns, "created_at DESC", "id DESC") + `
+			  LIMIT ` + where.next(limit) + ` OFFSET ` + where.next(offset)
 
-	rows, err := r.db.Query(ctx, query, limit, offset)
+	rows, err := conn(ctx, r.db).Query(ctx, query, where.args...)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -63,18 +182,7 @@ func (r *SaleOrderRepository) GetSaleOrders(ctx context.Context, limit, offset i
 	var saleOrders []models.SaleOrder
 	for rows.Next() {
 		var so models.SaleOrder
-		err := rows.Scan(
-			&so.ID,
-			&so.OrderNumber,
-			&so.CustomerName,
-			&so.TotalAmount,
-			&so.Status,
-			&so.CreatedBy,
-			&so.CreatedAt,
-			&so.UpdatedAt,
-			&so.DeletedAt,
-		)
-		if err != nil {
+		if err := scanSaleOrder(rows, &so); err != nil {
 			return nil, 0, err
 		}
 		saleOrders = append(saleOrders, so)
@@ -83,24 +191,52 @@ func (r *SaleOrderRepository) GetSaleOrders(ctx context.Context, limit, offset i
 	return saleOrders, totalCount, nil
 }
 
+func (r *SaleOrderRepository) CountSaleOrders(ctx context.Context, filter models.SaleOrderFilter) (int64, error) {
+	where := saleOrderWhere(filter)
+
+	var totalCount int64
+	query := `SELECT COUNT(*) FROM sale_orders ` + where.String()
+	err := conn(ctx, r.db).QueryRow(ctx, query, where.args...).Scan(&totalCount)
+
+	return totalCount, err
+}
+
+// GetSaleOrdersKeyset lists sale orders by (created_at, id) without an offset
+// or a count. See keysetClause for the shape of the result.
+func (r *SaleOrderRepository) GetSaleOrdersKeyset(ctx context.Context, filter models.SaleOrderFilter, page models.KeysetPage) ([]models.SaleOrder, error) {
+	where := saleOrderWhere(filter)
+	orderAndLimit := keysetClause(where, page, "")
+
+	query := `SELECT ` + saleOrderColumns + `
+			  FROM sale_orders
+			  ` + where.String() + `
+			  ` + orderAndLimit
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, where.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var saleOrders []models.SaleOrder
+	for rows.Next() {
+		var so models.SaleOrder
+		if err := scanSaleOrder(rows, &so); err != nil {
+			return nil, err
+		}
+		saleOrders = append(saleOrders, so)
+	}
+
+	return saleOrders, rows.Err()
+}
+
 func (r *SaleOrderRepository) GetSaleOrderByID(ctx context.Context, id uuid.UUID) (*models.SaleOrder, error) {
-	query := `SELECT id, order_number, customer_name, total_amount, status, created_by, created_at, updated_at, deleted_at
+	query := `SELECT ` + saleOrderColumns + `
 			  FROM sale_orders
 			  WHERE id = $1 AND deleted_at IS NULL`
 
 	var so models.SaleOrder
-	err := r.db.QueryRow(ctx, query, id).Scan(
-		&so.ID,
-		&so.OrderNumber,
-		&so.CustomerName,
-		&so.TotalAmount,
-		&so.Status,
-		&so.CreatedBy,
-		&so.CreatedAt,
-		&so.UpdatedAt,
-		&so.DeletedAt,
-	)
-
+	err := scanSaleOrder(conn(ctx, r.db).QueryRow(ctx, query, id), &so)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, apperror.ErrNotFound
@@ -111,17 +247,36 @@ func (r *SaleOrderRepository) GetSaleOrderByID(ctx context.Context, id uuid.UUID
 	return &so, nil
 }
 
+// UpdateSaleOrder writes the order only if it is still at saleOrder.Version
+// and bumps the version on success.
 func (r *SaleOrderRepository) UpdateSaleOrder(ctx context.Context, saleOrder *models.SaleOrder) error {
 	query := `UPDATE sale_orders
-			  SET customer_name = $1, total_amount = $2, status = $3, updated_at = $4
-			  WHERE id = $5 AND deleted_at IS NULL`
+			  SET customer_name = $1, store_id = $2, currency = $3, price_includes_tax = $4, tax_exempt = $5, tax_exemption_reference = $6,
+			      subtotal_amount = $7, discount_amount = $8, tax_amount = $9, total_amount = $10, status = $11, created_by = $12,
+			      hold_label = $13, register_id = $14, held_at = $15, customer_id = $16, cash_tendered = $17, updated_at = $18, version = version + 1
+			  WHERE id = $19 AND version = $20 AND deleted_at IS NULL`
 
-	result, err := r.db.Exec(ctx, query,
+	result, err := conn(ctx, r.db).Exec(ctx, query,
 		saleOrder.CustomerName,
+		saleOrder.StoreID,
+		saleOrder.Currency,
+		saleOrder.PriceIncludesTax,
+		saleOrder.TaxExempt,
+		saleOrder.TaxExemptionReference,
+		saleOrder.SubtotalAmount,
+		saleOrder.DiscountAmount,
+		saleOrder.TaxAmount,
 		saleOrder.TotalAmount,
 		saleOrder.Status,
+		saleOrder.CreatedBy,
+		saleOrder.HoldLabel,
+		saleOrder.RegisterID,
+		saleOrder.HeldAt,
+		saleOrder.CustomerID,
+		saleOrder.CashTendered,
 		saleOrder.UpdatedAt,
 		saleOrder.ID,
+		saleOrder.Version,
 	)
 
 	if err != nil {
@@ -130,26 +285,276 @@ func (r *SaleOrderRepository) UpdateSaleOrder(ctx context.Context, saleOrder *mo
 
 	rowsAffected := result.RowsAffected()
 	if rowsAffected == 0 {
-		return apperror.ErrNotFound
+		return r.versionConflict(ctx, saleOrder.ID)
 	}
 
+	saleOrder.Version++
 	return nil
 }
 
-func (r *SaleOrderRepository) DeleteSaleOrder(ctx context.Context, id uuid.UUID) error {
+// versionConflict explains why a conditional write matched no row: the order
+// is gone or someone else changed it first.
+func (r *SaleOrderRepository) versionConflict(ctx context.Context, id uuid.UUID) error {
+	query := `SELECT EXISTS (SELECT 1 FROM sale_orders WHERE id = $1 AND deleted_at IS NULL)`
+
+	var exists bool
+	if err := conn(ctx, r.db).QueryRow(ctx, query, id).Scan(&exists); err != nil {
+		return err
+	}
+
+	if !exists {
+		return apperror.ErrNotFound
+	}
+
+	return apperror.ErrVersionMismatch
+}
+
+// DeleteSaleOrder soft deletes the order if it is still at version.
+func (r *SaleOrderRepository) DeleteSaleOrder(ctx context.Context, id uuid.UUID, version int64) error {
 	query := `UPDATE sale_orders
-			  SET deleted_at = NOW()
-			  WHERE id = $1 AND deleted_at IS NULL`
+			  SET deleted_at = NOW(), version = version + 1
+			  WHERE id = $1 AND version = $2 AND deleted_at IS NULL`
 
-	result, err := r.db.Exec(ctx, query, id)
+	result, err := conn(ctx, r.db).Exec(ctx, query, id, version)
 	if err != nil {
 		return err
 	}
 
 	rowsAffected := result.RowsAffected()
 	if rowsAffected == 0 {
-		return apperror.ErrNotFound
+		return r.versionConflict(ctx, id)
+	}
+
+	return nil
+}
+
+func (r *SaleOrderRepository) InsertSaleOrderItems(ctx context.Context, items []models.SaleOrderItem) error {
+	query := `INSERT INTO sale_order_items (id, sale_order_id, line_number, product_id, product_name, category, quantity, unit_price, subtotal_amount, discount_amount, total_amount,
+			  tax_rate, taxable_amount, tax_amount, created_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)`
+
+	for _, item := range items {
+		_, err := conn(ctx, r.db).Exec(ctx, query,
+			item.ID,
+			item.SaleOrderID,
+			item.LineNumber,
+			item.ProductID,
+			item.ProductName,
+			item.Category,
+			item.Quantity,
+			item.UnitPrice,
+			item.SubtotalAmount,
+			item.DiscountAmount,
+			item.TotalAmount,
+			item.TaxRate,
+			item.TaxableAmount,
+			item.TaxAmount,
+			item.CreatedAt,
+		)
+		if err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
+
+func (r *SaleOrderRepository) GetSaleOrderItems(ctx context.Context, saleOrderID uuid.UUID) ([]models.SaleOrderItem, error) {
+	query := `SELECT id, sale_order_id, line_number, product_id, product_name, category, quantity, unit_price, subtotal_amount, discount_amount, total_amount,
+			  tax_rate, taxable_amount, tax_amount, created_at
+			  FROM sale_order_items
+			  WHERE sale_order_id = $1
+			  ORDER BY line_number ASC`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, saleOrderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []models.SaleOrderItem
+	for rows.Next() {
+		var item models.SaleOrderItem
+		err := rows.Scan(
+			&item.ID,
+			&item.SaleOrderID,
+			&item.LineNumber,
+			&item.ProductID,
+			&item.ProductName,
+			&item.Category,
+			&item.Quantity,
+			&item.UnitPrice,
+			&item.SubtotalAmount,
+			&item.DiscountAmount,
+			&item.TotalAmount,
+			&item.TaxRate,
+			&item.TaxableAmount,
+			&item.TaxAmount,
+			&item.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+func (r *SaleOrderRepository) DeleteSaleOrderItems(ctx context.Context, saleOrderID uuid.UUID) error {
+	query := `DELETE FROM sale_order_items WHERE sale_order_id = $1`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query, saleOrderID)
+	return err
+}
+
+func (r *SaleOrderRepository) InsertSaleOrderPromotions(ctx context.Context, promotions []models.SaleOrderPromotion) error {
+	query := `INSERT INTO sale_order_promotions (id, sale_order_id, promotion_id, promotion_name, discount_amount, created_at)
+			  VALUES ($1, $2, $3, $4, $5, $6)`
+
+	for _, p := range promotions {
+		_, err := conn(ctx, r.db).Exec(ctx, query,
+			p.ID,
+			p.SaleOrderID,
+			p.PromotionID,
+			p.PromotionName,
+			p.DiscountAmount,
+			p.CreatedAt,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *SaleOrderRepository) GetSaleOrderPromotions(ctx context.Context, saleOrderID uuid.UUID) ([]models.SaleOrderPromotion, error) {
+	query := `SELECT id, sale_order_id, promotion_id, promotion_name, discount_amount, created_at
+			  FROM sale_order_promotions
+			  WHERE sale_order_id = $1
+			  ORDER BY created_at ASC, id ASC`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, saleOrderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var promotions []models.SaleOrderPromotion
+	for rows.Next() {
+		var p models.SaleOrderPromotion
+		err := rows.Scan(
+			&p.ID,
+			&p.SaleOrderID,
+			&p.PromotionID,
+			&p.PromotionName,
+			&p.DiscountAmount,
+			&p.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		promotions = append(promotions, p)
+	}
+
+	return promotions, rows.Err()
+}
+
+func (r *SaleOrderRepository) DeleteSaleOrderPromotions(ctx context.Context, saleOrderID uuid.UUID) error {
+	query := `DELETE FROM sale_order_promotions WHERE sale_order_id = $1`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query, saleOrderID)
+	return err
+}
+
+// GetSaleOrdersChangedSince returns orders created, updated or deleted after
+// since, including soft deleted rows. Only the CreatedBy and StoreID fields of
+// the filter are applied, which is how callers scope the result.
+func (r *SaleOrderRepository) GetSaleOrdersChangedSince(ctx context.Context, filter models.SaleOrderFilter, since time.Time) ([]models.SaleOrder, error) {
+	where := newWhereBuilder()
+	where.add("(updated_at > $%d OR deleted_at > $%d)", since, since)
+	if filter.CreatedBy != nil {
+		where.add("created_by = $%d", *filter.CreatedBy)
+	}
+	if filter.StoreID != nil {
+		where.add("store_id = $%d", *filter.StoreID)
+	}
+
+	query := `SELECT ` + saleOrderColumns + `
+			  FROM sale_orders
+			  ` + where.String() + `
+			  ORDER BY updated_at ASC, id ASC`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, where.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var saleOrders []models.SaleOrder
+	for rows.Next() {
+		var so models.SaleOrder
+		if err := scanSaleOrder(rows, &so); err != nil {
+			return nil, err
+		}
+		saleOrders = append(saleOrders, so)
+	}
+
+	return saleOrders, rows.Err()
+}
+
+// GetHeldSaleOrders lists the held orders matching filter, oldest hold first.
+func (r *SaleOrderRepository) GetHeldSaleOrders(ctx context.Context, filter models.SaleOrderFilter) ([]models.SaleOrder, error) {
+	filter.Statuses = []string{constants.SaleOrderStatusHeld}
+	where := saleOrderWhere(filter)
+
+	query := `SELECT ` + saleOrderColumns + `
+			  FROM sale_orders
+			  ` + where.String() + `
+			  ORDER BY held_at ASC, id ASC`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, where.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var saleOrders []models.SaleOrder
+	for rows.Next() {
+		var so models.SaleOrder
+		if err := scanSaleOrder(rows, &so); err != nil {
+			return nil, err
+		}
+		saleOrders = append(saleOrders, so)
+	}
+
+	return saleOrders, rows.Err()
+}
+
+// GetHeldSaleOrdersBefore lists orders that have been held since before
+// cutoff. Orders put on hold through a plain update have no held_at and are
+// aged by updated_at instead.
+func (r *SaleOrderRepository) GetHeldSaleOrdersBefore(ctx context.Context, cutoff time.Time) ([]models.SaleOrder, error) {
+	query := `SELECT ` + saleOrderColumns + `
+			  FROM sale_orders
+			  WHERE status = $1 AND COALESCE(held_at, updated_at) < $2 AND deleted_at IS NULL
+			  ORDER BY id ASC`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, constants.SaleOrderStatusHeld, cutoff)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var saleOrders []models.SaleOrder
+	for rows.Next() {
+		var so models.SaleOrder
+		if err := scanSaleOrder(rows, &so); err != nil {
+			return nil, err
+		}
+		saleOrders = append(saleOrders, so)
+	}
+
+	return saleOrders, rows.Err()
+}
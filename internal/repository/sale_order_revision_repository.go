@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/google/uuid"
+	apperror "github.com/hafiztri123/kki-be/internal/app_error"
+	"github.com/hafiztri123/kki-be/internal/models"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type SaleOrderRevisionRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewSaleOrderRevisionRepository(db *pgxpool.Pool) *SaleOrderRevisionRepository {
+	return &SaleOrderRevisionRepository{
+		db: db,
+	}
+}
+
+func (r *SaleOrderRevisionRepository) InsertRevision(ctx context.Context, revision *models.SaleOrderRevision) error {
+	snapshot, err := json.Marshal(revision.Snapshot)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO sale_order_revisions (id, sale_order_id, revision_number, snapshot, changed_by, created_at)
+			  VALUES ($1, $2, $3, $4, $5, $6)`
+
+	_, err = conn(ctx, r.db).Exec(ctx, query,
+		revision.ID,
+		revision.SaleOrderID,
+		revision.RevisionNumber,
+		snapshot,
+		revision.ChangedBy,
+		revision.CreatedAt,
+	)
+
+	return err
+}
+
+// GetRevisions lists the revisions of an order, oldest first. Snapshots are
+// not loaded.
+func (r *SaleOrderRevisionRepository) GetRevisions(ctx context.Context, saleOrderID uuid.UUID) ([]models.SaleOrderRevision, error) {
+	query := `SELECT id, sale_order_id, revision_number, changed_by, created_at
+			  FROM sale_order_revisions
+			  WHERE sale_order_id = $1
+			  ORDER BY revision_number ASC`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, saleOrderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var revisions []models.SaleOrderRevision
+	for rows.Next() {
+		var rev models.SaleOrderRevision
+		err := rows.Scan(
+			&rev.ID,
+			&rev.SaleOrderID,
+			&rev.RevisionNumber,
+			&rev.ChangedBy,
+			&rev.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, rev)
+	}
+
+	return revisions, rows.Err()
+}
+
+func (r *SaleOrderRevisionRepository) GetRevision(ctx context.Context, saleOrderID uuid.UUID, revisionNumber int64) (*models.SaleOrderRevision, error) {
+	query := `SELECT id, sale_order_id, revision_number, snapshot, changed_by, created_at
+			  FROM sale_order_revisions
+			  WHERE sale_order_id = $1 AND revision_number = $2`
+
+	return r.scanRevision(ctx, query, saleOrderID, revisionNumber)
+}
+
+// GetNextRevision returns the first revision taken after revisionNumber.
+func (r *SaleOrderRevisionRepository) GetNextRevision(ctx context.Context, saleOrderID uuid.UUID, revisionNumber int64) (*models.SaleOrderRevision, error) {
+	query := `SELECT id, sale_order_id, revision_number, snapshot, changed_by, created_at
+			  FROM sale_order_revisions
+			  WHERE sale_order_id = $1 AND revision_number > $2
+			  ORDER BY revision_number ASC
+			  LIMIT 1`
+
+	return r.scanRevision(ctx, query, saleOrderID, revisionNumber)
+}
+
+func (r *SaleOrderRevisionRepository) scanRevision(ctx context.Context, query string, args ...any) (*models.SaleOrderRevision, error) {
+	var rev models.SaleOrderRevision
+	var snapshot []byte
+
+	err := conn(ctx, r.db).QueryRow(ctx, query, args...).Scan(
+		&rev.ID,
+		&rev.SaleOrderID,
+		&rev.RevisionNumber,
+		&snapshot,
+		&rev.ChangedBy,
+		&rev.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperror.ErrNotFound
+		}
+		return nil, err
+	}
+
+	if err := json.Unmarshal(snapshot, &rev.Snapshot); err != nil {
+		return nil, err
+	}
+
+	return &rev, nil
+}
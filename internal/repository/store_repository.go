@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	apperror "github.com/hafiztri123/kki-be/internal/app_error"
+	"github.com/hafiztri123/kki-be/internal/constants"
+	"github.com/hafiztri123/kki-be/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const storeColumns = `id, code, name, address, currency, price_includes_tax, default_tax_rate, order_number_format, receipt_header, receipt_footer, created_at, updated_at, deleted_at`
+
+type StoreRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewStoreRepository(db *pgxpool.Pool) *StoreRepository {
+	return &StoreRepository{
+		db: db,
+	}
+}
+
+func scanStore(row pgx.Row, s *models.Store) error {
+	return row.Scan(
+		&s.ID,
+		&s.Code,
+		&s.Name,
+		&s.Address,
+		&s.Currency,
+		&s.PriceIncludesTax,
+		&s.DefaultTaxRate,
+		&s.OrderNumberFormat,
+		&s.ReceiptHeader,
+		&s.ReceiptFooter,
+		&s.CreatedAt,
+		&s.UpdatedAt,
+		&s.DeletedAt,
+	)
+}
+
+func (r *StoreRepository) InsertStore(ctx context.Context, store *models.Store) error {
+	query := `INSERT INTO stores (` + storeColumns + `)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query,
+		store.ID,
+		store.Code,
+		store.Name,
+		store.Address,
+		store.Currency,
+		store.PriceIncludesTax,
+		store.DefaultTaxRate,
+		store.OrderNumberFormat,
+		store.ReceiptHeader,
+		store.ReceiptFooter,
+		store.CreatedAt,
+		store.UpdatedAt,
+		store.DeletedAt,
+	)
+
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			if pgErr.Code == constants.UniqueConstraintViolationErrorCode {
+				return apperror.ErrStoreCodeAlreadyExists
+			}
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (r *StoreRepository) GetStores(ctx context.Context, limit, offset int) ([]models.Store, int64, error) {
+	var totalCount int64
+	countQuery := `SELECT COUNT(*) FROM stores WHERE deleted_at IS NULL`
+	err := conn(ctx, r.db).QueryRow(ctx, countQuery).Scan(&totalCount)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT ` + storeColumns + `
+			  FROM stores
+			  WHERE deleted_at IS NULL
+			  ORDER BY code ASC
+			  LIMIT $1 OFFSET $2`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var stores []models.Store
+	for rows.Next() {
+		var s models.Store
+		if err := scanStore(rows, &s); err != nil {
+			return nil, 0, err
+		}
+		stores = append(stores, s)
+	}
+
+	return stores, totalCount, rows.Err()
+}
+
+func (r *StoreRepository) GetStoreByID(ctx context.Context, id uuid.UUID) (*models.Store, error) {
+	query := `SELECT ` + storeColumns + `
+			  FROM stores
+			  WHERE id = $1 AND deleted_at IS NULL`
+
+	var s models.Store
+	err := scanStore(conn(ctx, r.db).QueryRow(ctx, query, id), &s)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperror.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+func (r *StoreRepository) UpdateStore(ctx context.Context, store *models.Store) error {
+	query := `UPDATE stores
+			  SET code = $1, name = $2, address = $3, currency = $4, price_includes_tax = $5, default_tax_rate = $6,
+			      order_number_format = $7, receipt_header = $8, receipt_footer = $9, updated_at = $10
+			  WHERE id = $11 AND deleted_at IS NULL`
+
+	result, err := conn(ctx, r.db).Exec(ctx, query,
+		store.Code,
+		store.Name,
+		store.Address,
+		store.Currency,
+		store.PriceIncludesTax,
+		store.DefaultTaxRate,
+		store.OrderNumberFormat,
+		store.ReceiptHeader,
+		store.ReceiptFooter,
+		store.UpdatedAt,
+		store.ID,
+	)
+
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			if pgErr.Code == constants.UniqueConstraintViolationErrorCode {
+				return apperror.ErrStoreCodeAlreadyExists
+			}
+		}
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return apperror.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *StoreRepository) DeleteStore(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE stores
+			  SET deleted_at = NOW()
+			  WHERE id = $1 AND deleted_at IS NULL`
+
+	result, err := conn(ctx, r.db).Exec(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return apperror.ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,217 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	apperror "github.com/hafiztri123/kki-be/internal/app_error"
+	"github.com/hafiztri123/kki-be/internal/constants"
+	"github.com/hafiztri123/kki-be/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const taxRateColumns = `id, store_id, category, name, rate, created_at, updated_at, deleted_at`
+
+type TaxRateRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewTaxRateRepository(db *pgxpool.Pool) *TaxRateRepository {
+	return &TaxRateRepository{
+		db: db,
+	}
+}
+
+func scanTaxRate(row pgx.Row, t *models.TaxRate) error {
+	return row.Scan(
+		&t.ID,
+		&t.StoreID,
+		&t.Category,
+		&t.Name,
+		&t.Rate,
+		&t.CreatedAt,
+		&t.UpdatedAt,
+		&t.DeletedAt,
+	)
+}
+
+func (r *TaxRateRepository) InsertTaxRate(ctx context.Context, taxRate *models.TaxRate) error {
+	query := `INSERT INTO tax_rates (` + taxRateColumns + `)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query,
+		taxRate.ID,
+		taxRate.StoreID,
+		taxRate.Category,
+		taxRate.Name,
+		taxRate.Rate,
+		taxRate.CreatedAt,
+		taxRate.UpdatedAt,
+		taxRate.DeletedAt,
+	)
+
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			if pgErr.Code == constants.UniqueConstraintViolationErrorCode {
+				return apperror.ErrTaxRateAlreadyExists
+			}
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (r *TaxRateRepository) GetTaxRates(ctx context.Context, limit, offset int) ([]models.TaxRate, int64, error) {
+	var totalCount int64
+	countQuery := `SELECT COUNT(*) FROM tax_rates WHERE deleted_at IS NULL`
+	err := conn(ctx, r.db).QueryRow(ctx, countQuery).Scan(&totalCount)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT ` + taxRateColumns + `
+			  FROM tax_rates
+			  WHERE deleted_at IS NULL
+			  ORDER BY category ASC, store_id ASC NULLS FIRST
+			  LIMIT $1 OFFSET $2`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var taxRates []models.TaxRate
+	for rows.Next() {
+		var t models.TaxRate
+		if err := scanTaxRate(rows, &t); err != nil {
+			return nil, 0, err
+		}
+		taxRates = append(taxRates, t)
+	}
+
+	return taxRates, totalCount, rows.Err()
+}
+
+// GetTaxRatesForStore returns the store specific rates together with the rates
+// that apply to every store.
+func (r *TaxRateRepository) GetTaxRatesForStore(ctx context.Context, storeID uuid.UUID) ([]models.TaxRate, error) {
+	query := `SELECT ` + taxRateColumns + `
+			  FROM tax_rates
+			  WHERE deleted_at IS NULL AND (store_id = $1 OR store_id IS NULL)`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, storeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var taxRates []models.TaxRate
+	for rows.Next() {
+		var t models.TaxRate
+		if err := scanTaxRate(rows, &t); err != nil {
+			return nil, err
+		}
+		taxRates = append(taxRates, t)
+	}
+
+	return taxRates, rows.Err()
+}
+
+func (r *TaxRateRepository) GetTaxRateByID(ctx context.Context, id uuid.UUID) (*models.TaxRate, error) {
+	query := `SELECT ` + taxRateColumns + `
+			  FROM tax_rates
+			  WHERE id = $1 AND deleted_at IS NULL`
+
+	var t models.TaxRate
+	err := scanTaxRate(conn(ctx, r.db).QueryRow(ctx, query, id), &t)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperror.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+func (r *TaxRateRepository) UpdateTaxRate(ctx context.Context, taxRate *models.TaxRate) error {
+	query := `UPDATE tax_rates
+			  SET store_id = $1, category = $2, name = $3, rate = $4, updated_at = $5
+			  WHERE id = $6 AND deleted_at IS NULL`
+
+	result, err := conn(ctx, r.db).Exec(ctx, query,
+		taxRate.StoreID,
+		taxRate.Category,
+		taxRate.Name,
+		taxRate.Rate,
+		taxRate.UpdatedAt,
+		taxRate.ID,
+	)
+
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			if pgErr.Code == constants.UniqueConstraintViolationErrorCode {
+				return apperror.ErrTaxRateAlreadyExists
+			}
+		}
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return apperror.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *TaxRateRepository) DeleteTaxRate(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE tax_rates
+			  SET deleted_at = NOW()
+			  WHERE id = $1 AND deleted_at IS NULL`
+
+	result, err := conn(ctx, r.db).Exec(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return apperror.ErrNotFound
+	}
+
+	return nil
+}
+
+// GetTaxRatesChangedSince returns tax rates created, updated or deleted after
+// since, including soft deleted rows.
+func (r *TaxRateRepository) GetTaxRatesChangedSince(ctx context.Context, since time.Time) ([]models.TaxRate, error) {
+	query := `SELECT ` + taxRateColumns + `
+			  FROM tax_rates
+			  WHERE updated_at > $1 OR deleted_at > $1
+			  ORDER BY updated_at ASC, id ASC`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var taxRates []models.TaxRate
+	for rows.Next() {
+		var t models.TaxRate
+		if err := scanTaxRate(rows, &t); err != nil {
+			return nil, err
+		}
+		taxRates = append(taxRates, t)
+	}
+
+	return taxRates, rows.Err()
+}
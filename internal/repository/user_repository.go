@@ -24,15 +24,16 @@ func NewUserRepository(db *pgxpool.Pool) *UserRepository {
 
 func (r *UserRepository) InsertUser(ctx context.Context, user *models.User) error {
 
-	sql := `INSERT INTO users (id, username, email, password, role, name, created_at, updated_at, deleted_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+	sql := `INSERT INTO users (id, username, email, password, role, name, store_id, created_at, updated_at, deleted_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
 
-	_, err := r.db.Exec(ctx, sql, 
+	_, err := conn(ctx, r.db).Exec(ctx, sql, 
 		user.ID, 
 		user.Username, 
 		user.Email, 
 		user.Password, 
 		user.Role, 
 		user.Name, 
+		user.StoreID,
 		user.CreatedAt, 
 		user.UpdatedAt, 
 		user.DeletedAt,
@@ -55,18 +56,20 @@ func (r *UserRepository) InsertUser(ctx context.Context, user *models.User) erro
 
 func (r *UserRepository) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
 
-	query := `SELECT id, username, email, password, role, name, created_at, updated_at, deleted_at FROM users WHERE email = $1`
+	query := `SELECT id, username, email, password, role, name, store_id, created_at, updated_at, version, deleted_at FROM users WHERE email = $1`
 
 	var user models.User
-	err := r.db.QueryRow(ctx, query, email).Scan(
+	err := conn(ctx, r.db).QueryRow(ctx, query, email).Scan(
 		&user.ID, 
 		&user.Username, 
 		&user.Email, 
 		&user.Password, 
 		&user.Role, 
 		&user.Name, 
+		&user.StoreID,
 		&user.CreatedAt, 
 		&user.UpdatedAt, 
+		&user.Version,
 		&user.DeletedAt,
 	)
 
@@ -84,18 +87,18 @@ func (r *UserRepository) GetUserByEmail(ctx context.Context, email string) (*mod
 func (r *UserRepository) GetUsersByRole(ctx context.Context, role string, limit, offset int) ([]models.User, int64, error) {
 	var totalCount int64
 	countQuery := `SELECT COUNT(*) FROM users WHERE role = $1 AND deleted_at IS NULL`
-	err := r.db.QueryRow(ctx, countQuery, role).Scan(&totalCount)
+	err := conn(ctx, r.db).QueryRow(ctx, countQuery, role).Scan(&totalCount)
 	if err != nil {
 		return nil, 0, err
 	}
 
-	query := `SELECT id, username, email, password, role, name, created_at, updated_at, deleted_at
+	query := `SELECT id, username, email, password, role, name, store_id, created_at, updated_at, version, deleted_at
 			  FROM users
 			  WHERE role = $1 AND deleted_at IS NULL
 			  ORDER BY created_at DESC
 			  LIMIT $2 OFFSET $3`
 
-	rows, err := r.db.Query(ctx, query, role, limit, offset)
+	rows, err := conn(ctx, r.db).Query(ctx, query, role, limit, offset)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -111,8 +114,10 @@ func (r *UserRepository) GetUsersByRole(ctx context.Context, role string, limit,
 			&user.Password,
 			&user.Role,
 			&user.Name,
+			&user.StoreID,
 			&user.CreatedAt,
 			&user.UpdatedAt,
+			&user.Version,
 			&user.DeletedAt,
 		)
 		if err != nil {
@@ -124,21 +129,73 @@ func (r *UserRepository) GetUsersByRole(ctx context.Context, role string, limit,
 	return users, totalCount, nil
 }
 
+func (r *UserRepository) CountUsersByRole(ctx context.Context, role string) (int64, error) {
+	var totalCount int64
+	query := `SELECT COUNT(*) FROM users WHERE role = $1 AND deleted_at IS NULL`
+	err := conn(ctx, r.db).QueryRow(ctx, query, role).Scan(&totalCount)
+
+	return totalCount, err
+}
+
+func (r *UserRepository) GetUsersByRoleKeyset(ctx context.Context, role string, page models.KeysetPage) ([]models.User, error) {
+	where := newWhereBuilder()
+	where.add("role = $%d", role)
+	where.add("deleted_at IS NULL")
+	orderAndLimit := keysetClause(where, page, "")
+
+	query := `SELECT id, username, email, password, role, name, store_id, created_at, updated_at, version, deleted_at
+			  FROM users
+			  ` + where.String() + `
+			  ` + orderAndLimit
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, where.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []models.User
+	for rows.Next() {
+		var user models.User
+		err := rows.Scan(
+			&user.ID,
+			&user.Username,
+			&user.Email,
+			&user.Password,
+			&user.Role,
+			&user.Name,
+			&user.StoreID,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+			&user.Version,
+			&user.DeletedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
+
 func (r *UserRepository) GetUserByID(ctx context.Context, id string) (*models.User, error) {
-	query := `SELECT id, username, email, password, role, name, created_at, updated_at, deleted_at
+	query := `SELECT id, username, email, password, role, name, store_id, created_at, updated_at, version, deleted_at
 			  FROM users
 			  WHERE id = $1 AND deleted_at IS NULL`
 
 	var user models.User
-	err := r.db.QueryRow(ctx, query, id).Scan(
+	err := conn(ctx, r.db).QueryRow(ctx, query, id).Scan(
 		&user.ID,
 		&user.Username,
 		&user.Email,
 		&user.Password,
 		&user.Role,
 		&user.Name,
+		&user.StoreID,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&user.Version,
 		&user.DeletedAt,
 	)
 
@@ -152,17 +209,21 @@ func (r *UserRepository) GetUserByID(ctx context.Context, id string) (*models.Us
 	return &user, nil
 }
 
+// UpdateUser writes the user only if it is still at user.Version and bumps
+// the version on success.
 func (r *UserRepository) UpdateUser(ctx context.Context, user *models.User) error {
 	query := `UPDATE users
-			  SET username = $1, email = $2, name = $3, updated_at = $4
-			  WHERE id = $5 AND deleted_at IS NULL`
+			  SET username = $1, email = $2, name = $3, store_id = $4, updated_at = $5, version = version + 1
+			  WHERE id = $6 AND version = $7 AND deleted_at IS NULL`
 
-	result, err := r.db.Exec(ctx, query,
+	result, err := conn(ctx, r.db).Exec(ctx, query,
 		user.Username,
 		user.Email,
 		user.Name,
+		user.StoreID,
 		user.UpdatedAt,
 		user.ID,
+		user.Version,
 	)
 
 	if err != nil {
@@ -177,25 +238,44 @@ func (r *UserRepository) UpdateUser(ctx context.Context, user *models.User) erro
 
 	rowsAffected := result.RowsAffected()
 	if rowsAffected == 0 {
-		return apperror.ErrNotFound
+		return r.versionConflict(ctx, user.ID.String())
 	}
 
+	user.Version++
 	return nil
 }
 
-func (r *UserRepository) DeleteUser(ctx context.Context, id string) error {
+// versionConflict explains why a conditional write matched no row: the user
+// is gone or someone else changed it first.
+func (r *UserRepository) versionConflict(ctx context.Context, id string) error {
+	query := `SELECT EXISTS (SELECT 1 FROM users WHERE id = $1 AND deleted_at IS NULL)`
+
+	var exists bool
+	if err := conn(ctx, r.db).QueryRow(ctx, query, id).Scan(&exists); err != nil {
+		return err
+	}
+
+	if !exists {
+		return apperror.ErrNotFound
+	}
+
+	return apperror.ErrVersionMismatch
+}
+
+// DeleteUser soft deletes the user if it is still at version.
+func (r *UserRepository) DeleteUser(ctx context.Context, id string, version int64) error {
 	query := `UPDATE users
-			  SET deleted_at = NOW()
-			  WHERE id = $1 AND deleted_at IS NULL`
+			  SET deleted_at = NOW(), version = version + 1
+			  WHERE id = $1 AND version = $2 AND deleted_at IS NULL`
 
-	result, err := r.db.Exec(ctx, query, id)
+	result, err := conn(ctx, r.db).Exec(ctx, query, id, version)
 	if err != nil {
 		return err
 	}
 
 	rowsAffected := result.RowsAffected()
 	if rowsAffected == 0 {
-		return apperror.ErrNotFound
+		return r.versionConflict(ctx, id)
 	}
 
 	return nil
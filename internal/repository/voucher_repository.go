@@ -0,0 +1,403 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	apperror "github.com/hafiztri123/kki-be/internal/app_error"
+	"github.com/hafiztri123/kki-be/internal/constants"
+	"github.com/hafiztri123/kki-be/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const voucherCampaignColumns = `id, name, discount_type, percentage, amount, max_discount, min_spend, currency,
+			  max_uses_per_code, max_uses_per_customer, starts_at, expires_at, created_by, created_at, updated_at`
+
+// voucherColumns selects a code joined with its campaign, aliased v and c.
+const voucherColumns = `v.id, v.campaign_id, v.code, v.use_count, v.created_at,
+			  c.id, c.name, c.discount_type, c.percentage, c.amount, c.max_discount, c.min_spend, c.currency,
+			  c.max_uses_per_code, c.max_uses_per_customer, c.starts_at, c.expires_at, c.created_by, c.created_at, c.updated_at`
+
+type VoucherRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewVoucherRepository(db *pgxpool.Pool) *VoucherRepository {
+	return &VoucherRepository{
+		db: db,
+	}
+}
+
+func voucherCampaignFields(c *models.VoucherCampaign) []any {
+	return []any{
+		&c.ID,
+		&c.Name,
+		&c.DiscountType,
+		&c.Percentage,
+		&c.Amount,
+		&c.MaxDiscount,
+		&c.MinSpend,
+		&c.Currency,
+		&c.MaxUsesPerCode,
+		&c.MaxUsesPerCustomer,
+		&c.StartsAt,
+		&c.ExpiresAt,
+		&c.CreatedBy,
+		&c.CreatedAt,
+		&c.UpdatedAt,
+	}
+}
+
+func scanVoucher(row pgx.Row, v *models.Voucher) error {
+	fields := []any{&v.ID, &v.CampaignID, &v.Code, &v.UseCount, &v.CreatedAt}
+	return row.Scan(append(fields, voucherCampaignFields(&v.Campaign)...)...)
+}
+
+func (r *VoucherRepository) InsertCampaign(ctx context.Context, c *models.VoucherCampaign) error {
+	query := `INSERT INTO voucher_campaigns (` + voucherCampaignColumns + `)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query,
+		c.ID,
+		c.Name,
+		c.DiscountType,
+		c.Percentage,
+		c.Amount,
+		c.MaxDiscount,
+		c.MinSpend,
+		c.Currency,
+		c.MaxUsesPerCode,
+		c.MaxUsesPerCustomer,
+		c.StartsAt,
+		c.ExpiresAt,
+		c.CreatedBy,
+		c.CreatedAt,
+		c.UpdatedAt,
+	)
+
+	return err
+}
+
+// InsertCode stores a single code chosen by the owner.
+func (r *VoucherRepository) InsertCode(ctx context.Context, campaignID uuid.UUID, code string, now time.Time) error {
+	query := `INSERT INTO vouchers (id, campaign_id, code, use_count, created_at)
+			  VALUES ($1, $2, $3, 0, $4)`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query, uuid.New(), campaignID, code, now)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == constants.UniqueConstraintViolationErrorCode && pgErr.ConstraintName == constants.VoucherCodeConstraint {
+			return apperror.ErrVoucherCodeConflict
+		}
+		return err
+	}
+
+	return nil
+}
+
+// InsertCodes stores generated codes in one statement, skipping codes that
+// already exist, and returns how many were stored.
+func (r *VoucherRepository) InsertCodes(ctx context.Context, campaignID uuid.UUID, codes []string, now time.Time) (int64, error) {
+	ids := make([]uuid.UUID, len(codes))
+	for i := range ids {
+		ids[i] = uuid.New()
+	}
+
+	query := `INSERT INTO vouchers (id, campaign_id, code, use_count, created_at)
+			  SELECT id, $3, code, 0, $4
+			  FROM unnest($1::uuid[], $2::text[]) AS t(id, code)
+			  ON CONFLICT (code) DO NOTHING`
+
+	result, err := conn(ctx, r.db).Exec(ctx, query, ids, codes, campaignID, now)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected(), nil
+}
+
+// GetCampaigns lists campaigns, newest first.
+func (r *VoucherRepository) GetCampaigns(ctx context.Context, limit, offset int) ([]models.VoucherCampaign, int64, error) {
+	var totalCount int64
+	countQuery := `SELECT COUNT(*) FROM voucher_campaigns`
+	if err := conn(ctx, r.db).QueryRow(ctx, countQuery).Scan(&totalCount); err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT ` + voucherCampaignColumns + `
+			  FROM voucher_campaigns
+			  ORDER BY created_at DESC, id DESC
+			  LIMIT $1 OFFSET $2`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var campaigns []models.VoucherCampaign
+	for rows.Next() {
+		var c models.VoucherCampaign
+		if err := rows.Scan(voucherCampaignFields(&c)...); err != nil {
+			return nil, 0, err
+		}
+		campaigns = append(campaigns, c)
+	}
+
+	return campaigns, totalCount, rows.Err()
+}
+
+func (r *VoucherRepository) GetCampaignByID(ctx context.Context, id uuid.UUID) (*models.VoucherCampaign, error) {
+	query := `SELECT ` + voucherCampaignColumns + `
+			  FROM voucher_campaigns
+			  WHERE id = $1`
+
+	return r.getCampaign(ctx, query, id)
+}
+
+// GetCampaignByIDForUpdate locks the campaign until the surrounding
+// transaction ends, so per-customer limits hold across its codes.
+func (r *VoucherRepository) GetCampaignByIDForUpdate(ctx context.Context, id uuid.UUID) (*models.VoucherCampaign, error) {
+	query := `SELECT ` + voucherCampaignColumns + `
+			  FROM voucher_campaigns
+			  WHERE id = $1
+			  FOR UPDATE`
+
+	return r.getCampaign(ctx, query, id)
+}
+
+func (r *VoucherRepository) getCampaign(ctx context.Context, query string, id uuid.UUID) (*models.VoucherCampaign, error) {
+	var c models.VoucherCampaign
+	err := conn(ctx, r.db).QueryRow(ctx, query, id).Scan(voucherCampaignFields(&c)...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperror.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+func (r *VoucherRepository) GetCampaignStats(ctx context.Context, campaignID uuid.UUID) (*models.VoucherCampaignStats, error) {
+	query := `SELECT (SELECT COUNT(*) FROM vouchers WHERE campaign_id = $1),
+			         (SELECT COUNT(*) FROM vouchers WHERE campaign_id = $1 AND use_count > 0),
+			         COUNT(*),
+			         COALESCE(SUM(discount_amount), 0)
+			  FROM voucher_redemptions
+			  WHERE campaign_id = $1 AND reversed_at IS NULL`
+
+	var s models.VoucherCampaignStats
+	err := conn(ctx, r.db).QueryRow(ctx, query, campaignID).Scan(&s.Codes, &s.CodesUsed, &s.Redemptions, &s.DiscountAmount)
+	if err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+// EachCode calls fn for every code of a campaign in code order, without
+// holding them all in memory.
+func (r *VoucherRepository) EachCode(ctx context.Context, campaignID uuid.UUID, fn func(v *models.Voucher) error) error {
+	query := `SELECT ` + voucherColumns + `
+			  FROM vouchers v
+			  JOIN voucher_campaigns c ON c.id = v.campaign_id
+			  WHERE v.campaign_id = $1
+			  ORDER BY v.code ASC`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, campaignID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var v models.Voucher
+		if err := scanVoucher(rows, &v); err != nil {
+			return err
+		}
+		if err := fn(&v); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+func (r *VoucherRepository) GetVoucherByCode(ctx context.Context, code string) (*models.Voucher, error) {
+	query := `SELECT ` + voucherColumns + `
+			  FROM vouchers v
+			  JOIN voucher_campaigns c ON c.id = v.campaign_id
+			  WHERE v.code = $1`
+
+	return r.getVoucher(ctx, query, code)
+}
+
+func (r *VoucherRepository) GetVoucherByID(ctx context.Context, id uuid.UUID) (*models.Voucher, error) {
+	query := `SELECT ` + voucherColumns + `
+			  FROM vouchers v
+			  JOIN voucher_campaigns c ON c.id = v.campaign_id
+			  WHERE v.id = $1`
+
+	return r.getVoucher(ctx, query, id)
+}
+
+// GetVoucherByIDForUpdate locks the code, not its campaign, until the
+// surrounding transaction ends, so concurrent orders cannot use it past its
+// limit.
+func (r *VoucherRepository) GetVoucherByIDForUpdate(ctx context.Context, id uuid.UUID) (*models.Voucher, error) {
+	query := `SELECT ` + voucherColumns + `
+			  FROM vouchers v
+			  JOIN voucher_campaigns c ON c.id = v.campaign_id
+			  WHERE v.id = $1
+			  FOR UPDATE OF v`
+
+	return r.getVoucher(ctx, query, id)
+}
+
+func (r *VoucherRepository) getVoucher(ctx context.Context, query string, arg any) (*models.Voucher, error) {
+	var v models.Voucher
+	err := scanVoucher(conn(ctx, r.db).QueryRow(ctx, query, arg), &v)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperror.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &v, nil
+}
+
+// AddUseCount changes how often a code was used by delta.
+func (r *VoucherRepository) AddUseCount(ctx context.Context, id uuid.UUID, delta int) error {
+	query := `UPDATE vouchers
+			  SET use_count = use_count + $1
+			  WHERE id = $2`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query, delta, id)
+	return err
+}
+
+// CountCustomerRedemptions counts the codes of a campaign a customer used on
+// orders that were not refunded.
+func (r *VoucherRepository) CountCustomerRedemptions(ctx context.Context, campaignID, customerID uuid.UUID) (int, error) {
+	query := `SELECT COUNT(*)
+			  FROM voucher_redemptions
+			  WHERE campaign_id = $1 AND customer_id = $2 AND reversed_at IS NULL`
+
+	var count int
+	err := conn(ctx, r.db).QueryRow(ctx, query, campaignID, customerID).Scan(&count)
+	return count, err
+}
+
+// GetSaleOrderVoucher returns the code applied to a sale order, or
+// ErrNotFound when it has none.
+func (r *VoucherRepository) GetSaleOrderVoucher(ctx context.Context, saleOrderID uuid.UUID) (*models.SaleOrderVoucher, error) {
+	query := `SELECT id, sale_order_id, voucher_id, code, discount_amount, created_at
+			  FROM sale_order_vouchers
+			  WHERE sale_order_id = $1`
+
+	var v models.SaleOrderVoucher
+	err := conn(ctx, r.db).QueryRow(ctx, query, saleOrderID).Scan(
+		&v.ID,
+		&v.SaleOrderID,
+		&v.VoucherID,
+		&v.Code,
+		&v.DiscountAmount,
+		&v.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperror.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &v, nil
+}
+
+func (r *VoucherRepository) InsertSaleOrderVoucher(ctx context.Context, v *models.SaleOrderVoucher) error {
+	query := `INSERT INTO sale_order_vouchers (id, sale_order_id, voucher_id, code, discount_amount, created_at)
+			  VALUES ($1, $2, $3, $4, $5, $6)`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query, v.ID, v.SaleOrderID, v.VoucherID, v.Code, v.DiscountAmount, v.CreatedAt)
+	return err
+}
+
+func (r *VoucherRepository) DeleteSaleOrderVoucher(ctx context.Context, saleOrderID uuid.UUID) error {
+	query := `DELETE FROM sale_order_vouchers WHERE sale_order_id = $1`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query, saleOrderID)
+	return err
+}
+
+func (r *VoucherRepository) InsertRedemption(ctx context.Context, rd *models.VoucherRedemption) error {
+	query := `INSERT INTO voucher_redemptions (id, voucher_id, campaign_id, sale_order_id, customer_id, discount_amount, redeemed_at, reversed_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query,
+		rd.ID,
+		rd.VoucherID,
+		rd.CampaignID,
+		rd.SaleOrderID,
+		rd.CustomerID,
+		rd.DiscountAmount,
+		rd.RedeemedAt,
+		rd.ReversedAt,
+	)
+
+	return err
+}
+
+// GetActiveRedemption returns the redemption of a paid sale order that was
+// not reversed, or ErrNotFound when there is none.
+func (r *VoucherRepository) GetActiveRedemption(ctx context.Context, saleOrderID uuid.UUID) (*models.VoucherRedemption, error) {
+	query := `SELECT id, voucher_id, campaign_id, sale_order_id, customer_id, discount_amount, redeemed_at, reversed_at
+			  FROM voucher_redemptions
+			  WHERE sale_order_id = $1 AND reversed_at IS NULL`
+
+	var rd models.VoucherRedemption
+	err := conn(ctx, r.db).QueryRow(ctx, query, saleOrderID).Scan(
+		&rd.ID,
+		&rd.VoucherID,
+		&rd.CampaignID,
+		&rd.SaleOrderID,
+		&rd.CustomerID,
+		&rd.DiscountAmount,
+		&rd.RedeemedAt,
+		&rd.ReversedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperror.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &rd, nil
+}
+
+// ReassignRedemptions moves the voucher redemptions of the from customers to
+// the to customer, so per-customer limits keep counting them.
+func (r *VoucherRepository) ReassignRedemptions(ctx context.Context, from []uuid.UUID, to uuid.UUID) error {
+	query := `UPDATE voucher_redemptions
+			  SET customer_id = $1
+			  WHERE customer_id = ANY($2)`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query, to, from)
+	return err
+}
+
+func (r *VoucherRepository) ReverseRedemption(ctx context.Context, id uuid.UUID, at time.Time) error {
+	query := `UPDATE voucher_redemptions
+			  SET reversed_at = $1
+			  WHERE id = $2`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query, at, id)
+	return err
+}
@@ -0,0 +1,317 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	apperror "github.com/hafiztri123/kki-be/internal/app_error"
+	"github.com/hafiztri123/kki-be/internal/constants"
+	"github.com/hafiztri123/kki-be/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const webhookEndpointColumns = `id, url, description, secret, events, active, created_by, created_at, updated_at, deleted_at`
+
+const webhookDeliveryColumns = `id, endpoint_id, event_id, event_type, payload, status, attempts, next_attempt_at, last_status_code, last_error, delivered_at, created_at, updated_at`
+
+type WebhookRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewWebhookRepository(db *pgxpool.Pool) *WebhookRepository {
+	return &WebhookRepository{
+		db: db,
+	}
+}
+
+func scanWebhookEndpoint(row pgx.Row, e *models.WebhookEndpoint) error {
+	return row.Scan(
+		&e.ID,
+		&e.URL,
+		&e.Description,
+		&e.Secret,
+		&e.Events,
+		&e.Active,
+		&e.CreatedBy,
+		&e.CreatedAt,
+		&e.UpdatedAt,
+		&e.DeletedAt,
+	)
+}
+
+func scanWebhookDelivery(row pgx.Row, d *models.WebhookDelivery) error {
+	return row.Scan(
+		&d.ID,
+		&d.EndpointID,
+		&d.EventID,
+		&d.EventType,
+		&d.Payload,
+		&d.Status,
+		&d.Attempts,
+		&d.NextAttemptAt,
+		&d.LastStatusCode,
+		&d.LastError,
+		&d.DeliveredAt,
+		&d.CreatedAt,
+		&d.UpdatedAt,
+	)
+}
+
+func (r *WebhookRepository) InsertEndpoint(ctx context.Context, e *models.WebhookEndpoint) error {
+	query := `INSERT INTO webhook_endpoints (` + webhookEndpointColumns + `)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query,
+		e.ID,
+		e.URL,
+		e.Description,
+		e.Secret,
+		e.Events,
+		e.Active,
+		e.CreatedBy,
+		e.CreatedAt,
+		e.UpdatedAt,
+		e.DeletedAt,
+	)
+	return err
+}
+
+func (r *WebhookRepository) GetEndpoints(ctx context.Context, limit, offset int) ([]models.WebhookEndpoint, int64, error) {
+	var totalCount int64
+	countQuery := `SELECT COUNT(*) FROM webhook_endpoints WHERE deleted_at IS NULL`
+	if err := conn(ctx, r.db).QueryRow(ctx, countQuery).Scan(&totalCount); err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT ` + webhookEndpointColumns + `
+			  FROM webhook_endpoints
+			  WHERE deleted_at IS NULL
+			  ORDER BY created_at DESC, id DESC
+			  LIMIT $1 OFFSET $2`
+
+	endpoints, err := r.getEndpoints(ctx, query, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return endpoints, totalCount, nil
+}
+
+// GetSubscribedEndpoints returns the active endpoints subscribed to an event.
+func (r *WebhookRepository) GetSubscribedEndpoints(ctx context.Context, eventType string) ([]models.WebhookEndpoint, error) {
+	query := `SELECT ` + webhookEndpointColumns + `
+			  FROM webhook_endpoints
+			  WHERE deleted_at IS NULL AND active AND $1 = ANY(events)`
+
+	return r.getEndpoints(ctx, query, eventType)
+}
+
+func (r *WebhookRepository) getEndpoints(ctx context.Context, query string, args ...any) ([]models.WebhookEndpoint, error) {
+	rows, err := conn(ctx, r.db).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var endpoints []models.WebhookEndpoint
+	for rows.Next() {
+		var e models.WebhookEndpoint
+		if err := scanWebhookEndpoint(rows, &e); err != nil {
+			return nil, err
+		}
+		endpoints = append(endpoints, e)
+	}
+
+	return endpoints, rows.Err()
+}
+
+func (r *WebhookRepository) GetEndpointByID(ctx context.Context, id uuid.UUID) (*models.WebhookEndpoint, error) {
+	query := `SELECT ` + webhookEndpointColumns + `
+			  FROM webhook_endpoints
+			  WHERE id = $1 AND deleted_at IS NULL`
+
+	var e models.WebhookEndpoint
+	err := scanWebhookEndpoint(conn(ctx, r.db).QueryRow(ctx, query, id), &e)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperror.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &e, nil
+}
+
+func (r *WebhookRepository) UpdateEndpoint(ctx context.Context, e *models.WebhookEndpoint) error {
+	query := `UPDATE webhook_endpoints
+			  SET url = $1, description = $2, events = $3, active = $4, updated_at = $5
+			  WHERE id = $6 AND deleted_at IS NULL`
+
+	result, err := conn(ctx, r.db).Exec(ctx, query,
+		e.URL,
+		e.Description,
+		e.Events,
+		e.Active,
+		e.UpdatedAt,
+		e.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return apperror.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *WebhookRepository) DeleteEndpoint(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE webhook_endpoints
+			  SET deleted_at = NOW()
+			  WHERE id = $1 AND deleted_at IS NULL`
+
+	result, err := conn(ctx, r.db).Exec(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return apperror.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *WebhookRepository) InsertDelivery(ctx context.Context, d *models.WebhookDelivery) error {
+	query := `INSERT INTO webhook_deliveries (` + webhookDeliveryColumns + `)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query,
+		d.ID,
+		d.EndpointID,
+		d.EventID,
+		d.EventType,
+		d.Payload,
+		d.Status,
+		d.Attempts,
+		d.NextAttemptAt,
+		d.LastStatusCode,
+		d.LastError,
+		d.DeliveredAt,
+		d.CreatedAt,
+		d.UpdatedAt,
+	)
+	return err
+}
+
+// GetDeliveries lists the deliveries to an endpoint, newest first. Empty
+// filters are not applied.
+func (r *WebhookRepository) GetDeliveries(ctx context.Context, endpointID uuid.UUID, status, eventType string, limit, offset int) ([]models.WebhookDelivery, int64, error) {
+	where := newWhereBuilder()
+	where.add("endpoint_id = $%d", endpointID)
+	if status != "" {
+		where.add("status = $%d", status)
+	}
+	if eventType != "" {
+		where.add("event_type = $%d", eventType)
+	}
+
+	var totalCount int64
+	countQuery := `SELECT COUNT(*) FROM webhook_deliveries ` + where.String()
+	if err := conn(ctx, r.db).QueryRow(ctx, countQuery, where.args...).Scan(&totalCount); err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT ` + webhookDeliveryColumns + `
+			  FROM webhook_deliveries
+			  ` + where.String() + `
+			  ORDER BY created_at DESC, id DESC
+			  LIMIT ` + where.next(limit) + ` OFFSET ` + where.next(offset)
+
+	deliveries, err := r.getDeliveries(ctx, query, where.args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return deliveries, totalCount, nil
+}
+
+func (r *WebhookRepository) GetDeliveryByID(ctx context.Context, id uuid.UUID) (*models.WebhookDelivery, error) {
+	query := `SELECT ` + webhookDeliveryColumns + `
+			  FROM webhook_deliveries
+			  WHERE id = $1`
+
+	var d models.WebhookDelivery
+	err := scanWebhookDelivery(conn(ctx, r.db).QueryRow(ctx, query, id), &d)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperror.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &d, nil
+}
+
+// ClaimDueDeliveries takes up to limit pending deliveries whose next attempt
+// is due at now and pushes their next attempt to leaseUntil, so no other
+// worker picks them up while they are being sent. A worker that dies mid-send
+// leaves them to be retried once the lease runs out.
+func (r *WebhookRepository) ClaimDueDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]models.WebhookDelivery, error) {
+	query := `UPDATE webhook_deliveries
+			  SET next_attempt_at = $1
+			  WHERE id IN (
+				  SELECT id
+				  FROM webhook_deliveries
+				  WHERE status = $2 AND next_attempt_at <= $3
+				  ORDER BY next_attempt_at ASC, id ASC
+				  LIMIT $4
+				  FOR UPDATE SKIP LOCKED
+			  )
+			  RETURNING ` + webhookDeliveryColumns
+
+	return r.getDeliveries(ctx, query, leaseUntil, constants.WebhookDeliveryPending, now, limit)
+}
+
+func (r *WebhookRepository) getDeliveries(ctx context.Context, query string, args ...any) ([]models.WebhookDelivery, error) {
+	rows, err := conn(ctx, r.db).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []models.WebhookDelivery
+	for rows.Next() {
+		var d models.WebhookDelivery
+		if err := scanWebhookDelivery(rows, &d); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// UpdateDeliveryAttempt records the outcome of an attempt to send a delivery.
+func (r *WebhookRepository) UpdateDeliveryAttempt(ctx context.Context, d *models.WebhookDelivery) error {
+	query := `UPDATE webhook_deliveries
+			  SET status = $1, attempts = $2, next_attempt_at = $3, last_status_code = $4, last_error = $5, delivered_at = $6, updated_at = $7
+			  WHERE id = $8`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query,
+		d.Status,
+		d.Attempts,
+		d.NextAttemptAt,
+		d.LastStatusCode,
+		d.LastError,
+		d.DeliveredAt,
+		d.UpdatedAt,
+		d.ID,
+	)
+	return err
+}
@@ -0,0 +1,412 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	apperror "github.com/hafiztri123/kki-be/internal/app_error"
+	"github.com/hafiztri123/kki-be/internal/constants"
+	"github.com/hafiztri123/kki-be/internal/dto"
+	"github.com/hafiztri123/kki-be/internal/models"
+	"github.com/hafiztri123/kki-be/internal/money"
+	"github.com/hafiztri123/kki-be/internal/repository"
+)
+
+const (
+	maxCustomerNameLength = 255
+	maxPhoneLength        = 50
+)
+
+// CustomerAccountService runs customer credit (kasbon) accounts: sale orders
+// charged to an account raise its balance and repayments lower it. Cancelling
+// or deleting a charged order reverses its charge. Owners open accounts and
+// set their limits; cashiers charge and take repayments.
+type CustomerAccountService struct {
+	transactor       *repository.Transactor
+	accountRepo      *repository.CustomerAccountRepository
+	customerRepo     *repository.CustomerRepository
+	saleOrderService *SaleOrderService
+}
+
+func NewCustomerAccountService(transactor *repository.Transactor, accountRepo *repository.CustomerAccountRepository, customerRepo *repository.CustomerRepository, saleOrderService *SaleOrderService) *CustomerAccountService {
+	return &CustomerAccountService{
+		transactor:       transactor,
+		accountRepo:      accountRepo,
+		customerRepo:     customerRepo,
+		saleOrderService: saleOrderService,
+	}
+}
+
+func newCustomerAccountResponse(a *models.CustomerAccount) dto.CustomerAccountResponse {
+	return dto.CustomerAccountResponse{
+		ID:              a.ID,
+		CustomerID:      storeIDPtr(a.CustomerID),
+		CustomerName:    a.CustomerName,
+		Phone:           a.Phone.String,
+		Currency:        a.Currency,
+		CreditLimit:     a.CreditLimit,
+		Balance:         a.Balance,
+		AvailableCredit: a.AvailableCredit(),
+		CreatedBy:       a.CreatedBy,
+		CreatedAt:       a.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:       a.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+func newCustomerAccountEntryResponse(e *models.CustomerAccountEntry) dto.CustomerAccountEntryResponse {
+	return dto.CustomerAccountEntryResponse{
+		ID:           e.ID,
+		EntryType:    e.EntryType,
+		Amount:       e.Amount,
+		BalanceAfter: e.BalanceAfter,
+		SaleOrderID:  storeIDPtr(e.SaleOrderID),
+		Method:       e.Method.String,
+		Reference:    e.Reference.String,
+		ApprovedBy:   storeIDPtr(e.ApprovedBy),
+		RecordedBy:   storeIDPtr(e.RecordedBy),
+		CreatedAt:    e.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func validCustomerAccount(name, phone string, creditLimit money.Amount) bool {
+	return name != "" && len(name) <= maxCustomerNameLength && len(phone) <= maxPhoneLength && !creditLimit.IsNegative()
+}
+
+// resolveAccountCustomer links an account to the customer customerID, or to
+// none when it is nil. An empty name takes the name of the customer.
+func (s *CustomerAccountService) resolveAccountCustomer(ctx context.Context, customerID *uuid.UUID, name string) (uuid.NullUUID, string, error) {
+	if customerID == nil {
+		return uuid.NullUUID{}, name, nil
+	}
+
+	customer, err := s.customerRepo.GetCustomerByID(ctx, *customerID)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return uuid.NullUUID{}, "", apperror.ErrInvalidCustomerAccount
+		}
+		return uuid.NullUUID{}, "", err
+	}
+
+	if name == "" {
+		name = customer.Name
+	}
+
+	return uuid.NullUUID{UUID: customer.ID, Valid: true}, name, nil
+}
+
+func (s *CustomerAccountService) CreateCustomerAccount(ctx context.Context, caller dto.Caller, req *dto.CreateCustomerAccountRequest) (*dto.CustomerAccountResponse, error) {
+	customerID, name, err := s.resolveAccountCustomer(ctx, req.CustomerID, strings.TrimSpace(req.CustomerName))
+	if err != nil {
+		return nil, err
+	}
+
+	phone := strings.TrimSpace(req.Phone)
+	if !validCustomerAccount(name, phone, req.CreditLimit) {
+		return nil, apperror.ErrInvalidCustomerAccount
+	}
+
+	currency := money.DefaultCurrency
+	if code := strings.TrimSpace(req.Currency); code != "" {
+		var ok bool
+		if currency, ok = money.ParseCurrency(code); !ok {
+			return nil, apperror.ErrInvalidCustomerAccount
+		}
+	}
+
+	now := time.Now()
+	account := &models.CustomerAccount{
+		ID:           uuid.New(),
+		CustomerID:   customerID,
+		CustomerName: name,
+		Phone:        sql.NullString{String: phone, Valid: phone != ""},
+		Currency:     currency,
+		CreditLimit:  req.CreditLimit,
+		CreatedBy:    caller.ID,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+
+	if err := s.accountRepo.InsertCustomerAccount(ctx, account); err != nil {
+		return nil, err
+	}
+
+	response := newCustomerAccountResponse(account)
+	return &response, nil
+}
+
+func (s *CustomerAccountService) GetCustomerAccounts(ctx context.Context, customerName string, customerID *uuid.UUID, limit, offset int) ([]dto.CustomerAccountResponse, int64, error) {
+	accounts, totalCount, err := s.accountRepo.GetCustomerAccounts(ctx, strings.TrimSpace(customerName), customerID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	responses := make([]dto.CustomerAccountResponse, 0, len(accounts))
+	for i := range accounts {
+		responses = append(responses, newCustomerAccountResponse(&accounts[i]))
+	}
+
+	return responses, totalCount, nil
+}
+
+func (s *CustomerAccountService) GetCustomerAccountByID(ctx context.Context, id uuid.UUID) (*dto.CustomerAccountResponse, error) {
+	account, err := s.accountRepo.GetCustomerAccountByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := newCustomerAccountResponse(account)
+	return &response, nil
+}
+
+// UpdateCustomerAccount changes the details and credit limit of an account.
+// A limit below the current balance is allowed; it only blocks new charges.
+func (s *CustomerAccountService) UpdateCustomerAccount(ctx context.Context, id uuid.UUID, req *dto.UpdateCustomerAccountRequest) (*dto.CustomerAccountResponse, error) {
+	customerID, name, err := s.resolveAccountCustomer(ctx, req.CustomerID, strings.TrimSpace(req.CustomerName))
+	if err != nil {
+		return nil, err
+	}
+
+	phone := strings.TrimSpace(req.Phone)
+	if !validCustomerAccount(name, phone, req.CreditLimit) {
+		return nil, apperror.ErrInvalidCustomerAccount
+	}
+
+	var response dto.CustomerAccountResponse
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		account, err := s.accountRepo.GetCustomerAccountByIDForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		account.CustomerID = customerID
+		account.CustomerName = name
+		account.Phone = sql.NullString{String: phone, Valid: phone != ""}
+		account.CreditLimit = req.CreditLimit
+		account.UpdatedAt = time.Now()
+
+		if err := s.accountRepo.UpdateCustomerAccount(ctx, account); err != nil {
+			return err
+		}
+
+		response = newCustomerAccountResponse(account)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// ChargeToAccount settles a draft sale order on a customer's account and
+// completes it. An order linked to another customer than the account cannot be
+// charged to it. A charge past the available credit is refused with
+// ErrCreditLimitExceeded, together with the account figures, unless an owner
+// makes it with ApproveOverLimit; the approving owner is kept on the entry.
+func (s *CustomerAccountService) ChargeToAccount(ctx context.Context, caller dto.Caller, saleOrderID uuid.UUID, version int64, req *dto.ChargeToAccountRequest) (*dto.CustomerAccountResponse, *dto.CreditLimitExceededResponse, error) {
+	if req.AccountID == uuid.Nil {
+		return nil, nil, apperror.ErrInvalidCharge
+	}
+
+	if req.ApproveOverLimit && !isOwner(caller) {
+		return nil, nil, apperror.ErrForbidden
+	}
+
+	var response dto.CustomerAccountResponse
+	var exceeded *dto.CreditLimitExceededResponse
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		saleOrder, err := s.saleOrderService.getSaleOrderForChange(ctx, caller, saleOrderID)
+		if err != nil {
+			return err
+		}
+
+		if err := checkVersion(version, saleOrder.Version); err != nil {
+			return err
+		}
+
+		if saleOrder.Status != constants.SaleOrderStatusDraft {
+			return apperror.ErrSaleOrderNotDraft
+		}
+
+		account, err := s.accountRepo.GetCustomerAccountByIDForUpdate(ctx, req.AccountID)
+		if err != nil {
+			return err
+		}
+
+		// Points and gift cards may already have paid part of the order.
+		chargeAmount, err := s.saleOrderService.amountDue(ctx, saleOrder)
+		if err != nil {
+			return err
+		}
+
+		if chargeAmount <= 0 || saleOrder.Currency != account.Currency {
+			return apperror.ErrInvalidCharge
+		}
+
+		if account.CustomerID.Valid && saleOrder.CustomerID.Valid && account.CustomerID.UUID != saleOrder.CustomerID.UUID {
+			return apperror.ErrInvalidCharge
+		}
+
+		var approvedBy uuid.NullUUID
+		if chargeAmount > account.AvailableCredit() {
+			if !req.ApproveOverLimit {
+				exceeded = &dto.CreditLimitExceededResponse{
+					CreditLimit:     account.CreditLimit,
+					Balance:         account.Balance,
+					AvailableCredit: account.AvailableCredit(),
+					ChargeAmount:    chargeAmount,
+				}
+				return apperror.ErrCreditLimitExceeded
+			}
+			approvedBy = uuid.NullUUID{UUID: caller.ID, Valid: true}
+		}
+
+		now := time.Now()
+		account.Balance += chargeAmount
+		account.UpdatedAt = now
+		if err := s.accountRepo.UpdateCustomerAccount(ctx, account); err != nil {
+			return err
+		}
+
+		err = s.accountRepo.InsertEntry(ctx, &models.CustomerAccountEntry{
+			ID:           uuid.New(),
+			AccountID:    account.ID,
+			EntryType:    constants.CustomerAccountEntryCharge,
+			Amount:       chargeAmount,
+			BalanceAfter: account.Balance,
+			SaleOrderID:  uuid.NullUUID{UUID: saleOrder.ID, Valid: true},
+			ApprovedBy:   approvedBy,
+			RecordedBy:   uuid.NullUUID{UUID: caller.ID, Valid: true},
+			CreatedAt:    now,
+		})
+		if err != nil {
+			return err
+		}
+
+		previousStatus := saleOrder.Status
+		saleOrder.Status = constants.SaleOrderStatusCompleted
+		saleOrder.UpdatedAt = now
+		if err := s.saleOrderService.saveSaleOrder(ctx, saleOrder, previousStatus); err != nil {
+			return err
+		}
+
+		response = newCustomerAccountResponse(account)
+		return nil
+	})
+	if err != nil {
+		return nil, exceeded, err
+	}
+
+	return &response, nil, nil
+}
+
+// RecordRepayment lowers the balance of an account. Repayments larger than
+// the balance are refused.
+func (s *CustomerAccountService) RecordRepayment(ctx context.Context, caller dto.Caller, accountID uuid.UUID, req *dto.CreateRepaymentRequest) (*dto.CustomerAccountResponse, error) {
+	reference := strings.TrimSpace(req.Reference)
+	if req.Amount <= 0 || !slices.Contains(invoicePaymentMethods, req.Method) || len(reference) > maxPaymentReferenceLength {
+		return nil, apperror.ErrInvalidPayment
+	}
+
+	var response dto.CustomerAccountResponse
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		account, err := s.accountRepo.GetCustomerAccountByIDForUpdate(ctx, accountID)
+		if err != nil {
+			return err
+		}
+
+		if req.Amount > account.Balance {
+			return apperror.ErrRepaymentExceedsBalance
+		}
+
+		now := time.Now()
+		account.Balance -= req.Amount
+		account.UpdatedAt = now
+		if err := s.accountRepo.UpdateCustomerAccount(ctx, account); err != nil {
+			return err
+		}
+
+		err = s.accountRepo.InsertEntry(ctx, &models.CustomerAccountEntry{
+			ID:           uuid.New(),
+			AccountID:    account.ID,
+			EntryType:    constants.CustomerAccountEntryRepayment,
+			Amount:       req.Amount,
+			BalanceAfter: account.Balance,
+			Method:       sql.NullString{String: req.Method, Valid: true},
+			Reference:    sql.NullString{String: reference, Valid: reference != ""},
+			RecordedBy:   uuid.NullUUID{UUID: caller.ID, Valid: true},
+			CreatedAt:    now,
+		})
+		if err != nil {
+			return err
+		}
+
+		response = newCustomerAccountResponse(account)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// GetStatement lists the ledger of an account for entries created in
+// [from, to), with the balance owed before and after the period.
+func (s *CustomerAccountService) GetStatement(ctx context.Context, id uuid.UUID, from, to *time.Time) (*dto.CustomerAccountStatementResponse, error) {
+	if from != nil && to != nil && !from.Before(*to) {
+		return nil, apperror.ErrInvalidFilter
+	}
+
+	account, err := s.accountRepo.GetCustomerAccountByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var opening money.Amount
+	if from != nil {
+		opening, err = s.accountRepo.GetBalanceAt(ctx, id, *from)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	entries, err := s.accountRepo.GetEntries(ctx, id, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	statement := &dto.CustomerAccountStatementResponse{
+		Account:        newCustomerAccountResponse(account),
+		OpeningBalance: opening,
+		ClosingBalance: opening,
+		Entries:        make([]dto.CustomerAccountEntryResponse, 0, len(entries)),
+	}
+	if from != nil {
+		statement.From = from.Format(time.RFC3339)
+	}
+	if to != nil {
+		statement.To = to.Format(time.RFC3339)
+	}
+
+	for i := range entries {
+		e := &entries[i]
+		switch e.EntryType {
+		case constants.CustomerAccountEntryCharge:
+			statement.TotalCharges += e.Amount
+		case constants.CustomerAccountEntryRepayment:
+			statement.TotalRepayments += e.Amount
+		case constants.CustomerAccountEntryReversal:
+			statement.TotalReversals += e.Amount
+		}
+		statement.ClosingBalance = e.BalanceAfter
+		statement.Entries = append(statement.Entries, newCustomerAccountEntryResponse(e))
+	}
+
+	return statement, nil
+}
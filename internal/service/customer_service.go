@@ -0,0 +1,320 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/mail"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	apperror "github.com/hafiztri123/kki-be/internal/app_error"
+	"github.com/hafiztri123/kki-be/internal/dto"
+	"github.com/hafiztri123/kki-be/internal/models"
+	"github.com/hafiztri123/kki-be/internal/repository"
+)
+
+const (
+	maxCustomerEmailLength = 255
+	maxCustomerTags        = 20
+	maxCustomerTagLength   = 50
+	maxCustomerMerge       = 50
+)
+
+// CustomerService keeps customer master data. Sale orders link to a customer
+// through customer_id; their customer_name stays as entered at the till.
+type CustomerService struct {
+	transactor       *repository.Transactor
+	customerRepo     *repository.CustomerRepository
+	loyaltyRepo      *repository.LoyaltyRepository
+	giftCardRepo     *repository.GiftCardRepository
+	voucherRepo      *repository.VoucherRepository
+	accountRepo      *repository.CustomerAccountRepository
+	saleOrderService *SaleOrderService
+}
+
+func NewCustomerService(transactor *repository.Transactor, customerRepo *repository.CustomerRepository, loyaltyRepo *repository.LoyaltyRepository, giftCardRepo *repository.GiftCardRepository, voucherRepo *repository.VoucherRepository, accountRepo *repository.CustomerAccountRepository, saleOrderService *SaleOrderService) *CustomerService {
+	return &CustomerService{
+		transactor:       transactor,
+		customerRepo:     customerRepo,
+		loyaltyRepo:      loyaltyRepo,
+		giftCardRepo:     giftCardRepo,
+		voucherRepo:      voucherRepo,
+		accountRepo:      accountRepo,
+		saleOrderService: saleOrderService,
+	}
+}
+
+func newCustomerResponse(c *models.Customer) dto.CustomerResponse {
+	tags := c.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+
+	return dto.CustomerResponse{
+		ID:        c.ID,
+		Name:      c.Name,
+		Phone:     c.Phone.String,
+		Email:     c.Email.String,
+		Address:   c.Address.String,
+		Tags:      tags,
+		Notes:     c.Notes.String,
+		CreatedBy: c.CreatedBy,
+		CreatedAt: c.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: c.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+// normalizeTags trims and lowercases tags and drops empty and repeated ones.
+func normalizeTags(tags []string) ([]string, bool) {
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || slices.Contains(normalized, tag) {
+			continue
+		}
+		if len(tag) > maxCustomerTagLength {
+			return nil, false
+		}
+		normalized = append(normalized, tag)
+	}
+
+	return normalized, len(normalized) <= maxCustomerTags
+}
+
+// applyCustomerRequest validates req and copies it onto c.
+func applyCustomerRequest(c *models.Customer, req *dto.CustomerRequest) error {
+	name := strings.TrimSpace(req.Name)
+	phone := strings.TrimSpace(req.Phone)
+	email := strings.TrimSpace(req.Email)
+	address := strings.TrimSpace(req.Address)
+	notes := strings.TrimSpace(req.Notes)
+
+	if name == "" || len(name) > maxCustomerNameLength || len(phone) > maxPhoneLength || len(email) > maxCustomerEmailLength {
+		return apperror.ErrInvalidCustomer
+	}
+
+	if email != "" {
+		if _, err := mail.ParseAddress(email); err != nil {
+			return apperror.ErrInvalidCustomer
+		}
+	}
+
+	tags, ok := normalizeTags(req.Tags)
+	if !ok {
+		return apperror.ErrInvalidCustomer
+	}
+
+	c.Name = name
+	c.Phone = sql.NullString{String: phone, Valid: phone != ""}
+	c.Email = sql.NullString{String: email, Valid: email != ""}
+	c.Address = sql.NullString{String: address, Valid: address != ""}
+	c.Tags = tags
+	c.Notes = sql.NullString{String: notes, Valid: notes != ""}
+	return nil
+}
+
+func (s *CustomerService) CreateCustomer(ctx context.Context, caller dto.Caller, req *dto.CustomerRequest) (*dto.CustomerResponse, error) {
+	now := time.Now()
+	customer := &models.Customer{
+		ID:        uuid.New(),
+		CreatedBy: caller.ID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := applyCustomerRequest(customer, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.customerRepo.InsertCustomer(ctx, customer); err != nil {
+		return nil, err
+	}
+
+	response := newCustomerResponse(customer)
+	return &response, nil
+}
+
+func (s *CustomerService) GetCustomers(ctx context.Context, search, tag string, limit, offset int) ([]dto.CustomerResponse, int64, error) {
+	customers, totalCount, err := s.customerRepo.GetCustomers(ctx, strings.TrimSpace(search), strings.ToLower(strings.TrimSpace(tag)), limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	responses := make([]dto.CustomerResponse, 0, len(customers))
+	for i := range customers {
+		responses = append(responses, newCustomerResponse(&customers[i]))
+	}
+
+	return responses, totalCount, nil
+}
+
+// GetCustomerByID returns a customer with its lifetime value.
+func (s *CustomerService) GetCustomerByID(ctx context.Context, id uuid.UUID) (*dto.CustomerResponse, error) {
+	customer, err := s.customerRepo.GetCustomerByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := s.customerRepo.GetCustomerValues(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := newCustomerResponse(customer)
+	for _, v := range values {
+		response.Values = append(response.Values, dto.CustomerValueResponse{
+			Currency:      v.Currency,
+			OrderCount:    v.OrderCount,
+			LifetimeValue: v.LifetimeValue,
+			FirstOrderAt:  v.FirstOrderAt.Format(time.RFC3339),
+			LastOrderAt:   v.LastOrderAt.Format(time.RFC3339),
+		})
+	}
+
+	return &response, nil
+}
+
+func (s *CustomerService) UpdateCustomer(ctx context.Context, id uuid.UUID, req *dto.CustomerRequest) (*dto.CustomerResponse, error) {
+	customer, err := s.customerRepo.GetCustomerByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := applyCustomerRequest(customer, req); err != nil {
+		return nil, err
+	}
+	customer.UpdatedAt = time.Now()
+
+	if err := s.customerRepo.UpdateCustomer(ctx, customer); err != nil {
+		return nil, err
+	}
+
+	response := newCustomerResponse(customer)
+	return &response, nil
+}
+
+// DeleteCustomer soft deletes a customer. Its orders keep their customer_id.
+func (s *CustomerService) DeleteCustomer(ctx context.Context, id uuid.UUID) error {
+	return s.customerRepo.DeleteCustomer(ctx, id)
+}
+
+// GetCustomerSaleOrders lists the orders of a customer the caller may read,
+// newest first.
+func (s *CustomerService) GetCustomerSaleOrders(ctx context.Context, caller dto.Caller, id uuid.UUID, limit, offset int) ([]dto.SaleOrderResponse, int64, error) {
+	if _, err := s.customerRepo.GetCustomerByID(ctx, id); err != nil {
+		return nil, 0, err
+	}
+
+	return s.saleOrderService.GetSaleOrders(ctx, caller, &dto.SaleOrderFilterRequest{CustomerID: &id}, limit, offset)
+}
+
+// MergeCustomers folds duplicates into the customer id. Their orders, loyalty
+// points, store credit and credit accounts are re-pointed at id, their tags
+// added to it, and contact details it lacks are taken from them. The
+// duplicates are soft deleted and remember where they went.
+func (s *CustomerService) MergeCustomers(ctx context.Context, id uuid.UUID, req *dto.MergeCustomersRequest) (*dto.MergeCustomersResponse, error) {
+	duplicateIDs := make([]uuid.UUID, 0, len(req.DuplicateIDs))
+	for _, duplicateID := range req.DuplicateIDs {
+		if duplicateID == id || duplicateID == uuid.Nil {
+			return nil, apperror.ErrInvalidCustomerMerge
+		}
+		if !slices.Contains(duplicateIDs, duplicateID) {
+			duplicateIDs = append(duplicateIDs, duplicateID)
+		}
+	}
+
+	if len(duplicateIDs) == 0 || len(duplicateIDs) > maxCustomerMerge {
+		return nil, apperror.ErrInvalidCustomerMerge
+	}
+
+	var response dto.MergeCustomersResponse
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		customer, err := s.customerRepo.GetCustomerByIDForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		for _, duplicateID := range duplicateIDs {
+			duplicate, err := s.customerRepo.GetCustomerByIDForUpdate(ctx, duplicateID)
+			if err != nil {
+				if errors.Is(err, apperror.ErrNotFound) {
+					return apperror.ErrInvalidCustomerMerge
+				}
+				return err
+			}
+
+			mergeCustomerDetails(customer, duplicate)
+		}
+
+		now := time.Now()
+		moved, err := s.customerRepo.ReassignSaleOrders(ctx, duplicateIDs, id, now)
+		if err != nil {
+			return err
+		}
+
+		if err := s.loyaltyRepo.ReassignEntries(ctx, duplicateIDs, id); err != nil {
+			return err
+		}
+
+		if err := s.giftCardRepo.ReassignGiftCards(ctx, duplicateIDs, id, now); err != nil {
+			return err
+		}
+
+		if err := s.voucherRepo.ReassignRedemptions(ctx, duplicateIDs, id); err != nil {
+			return err
+		}
+
+		if err := s.accountRepo.ReassignCustomerAccounts(ctx, duplicateIDs, id, now); err != nil {
+			return err
+		}
+
+		for _, duplicateID := range duplicateIDs {
+			if err := s.customerRepo.MarkCustomerMerged(ctx, duplicateID, id, now); err != nil {
+				return err
+			}
+		}
+
+		customer.UpdatedAt = now
+		if err := s.customerRepo.UpdateCustomer(ctx, customer); err != nil {
+			return err
+		}
+
+		response = dto.MergeCustomersResponse{
+			Customer:        newCustomerResponse(customer),
+			MergedIDs:       duplicateIDs,
+			MovedSaleOrders: moved,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// mergeCustomerDetails fills what customer lacks from duplicate. Details the
+// surviving customer already has are kept.
+func mergeCustomerDetails(customer, duplicate *models.Customer) {
+	if !customer.Phone.Valid {
+		customer.Phone = duplicate.Phone
+	}
+	if !customer.Email.Valid {
+		customer.Email = duplicate.Email
+	}
+	if !customer.Address.Valid {
+		customer.Address = duplicate.Address
+	}
+	if !customer.Notes.Valid {
+		customer.Notes = duplicate.Notes
+	}
+
+	for _, tag := range duplicate.Tags {
+		if !slices.Contains(customer.Tags, tag) && len(customer.Tags) < maxCustomerTags {
+			customer.Tags = append(customer.Tags, tag)
+		}
+	}
+}
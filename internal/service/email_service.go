@@ -0,0 +1,371 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	apperror "github.com/hafiztri123/kki-be/internal/app_error"
+	"github.com/hafiztri123/kki-be/internal/constants"
+	"github.com/hafiztri123/kki-be/internal/dto"
+	"github.com/hafiztri123/kki-be/internal/events"
+	"github.com/hafiztri123/kki-be/internal/mailer"
+	"github.com/hafiztri123/kki-be/internal/models"
+	"github.com/hafiztri123/kki-be/internal/receipt"
+	"github.com/hafiztri123/kki-be/internal/repository"
+	"github.com/hafiztri123/kki-be/internal/utils"
+)
+
+const (
+	emailBatchSize = 20
+	// maxEmailAttempts is how often an email is tried before it is marked
+	// failed. The waits between attempts double from emailRetryDelay.
+	maxEmailAttempts      = 6
+	emailRetryDelay       = time.Minute
+	maxEmailErrorLength   = 1000
+	maxEmailAddressLength = 255
+)
+
+// emailContent is the wording of each kind of email.
+var emailContent = map[string]struct {
+	subject string
+	email   receipt.Email
+}{
+	constants.EmailKindReceipt: {
+		subject: "Your receipt for order ",
+		email: receipt.Email{
+			Heading: "Thank you for your purchase",
+			Intro:   "Your receipt is below.",
+		},
+	},
+	constants.EmailKindRefund: {
+		subject: "Your refund for order ",
+		email: receipt.Email{
+			Heading: "Your order was refunded",
+			Intro:   "Your order was cancelled and what you paid has been refunded. The order is below for your records.",
+		},
+	},
+}
+
+// EmailService sends the emails queued in the outbox. Emails are queued when a
+// sale order changes status, by a domain event handler, or when a cashier asks
+// for one, and a background worker renders and sends them, retrying failures.
+type EmailService struct {
+	transactor     *repository.Transactor
+	emailRepo      *repository.EmailRepository
+	receiptService *ReceiptService
+	transport      mailer.Transport
+	from           string
+}
+
+func NewEmailService(transactor *repository.Transactor, emailRepo *repository.EmailRepository, receiptService *ReceiptService, transport mailer.Transport, from string) *EmailService {
+	return &EmailService{
+		transactor:     transactor,
+		emailRepo:      emailRepo,
+		receiptService: receiptService,
+		transport:      transport,
+		from:           from,
+	}
+}
+
+// newMailTransport picks the transport from MAIL_TRANSPORT: "smtp", or "file"
+// to drop emails in MAIL_DROP_DIR for reading locally.
+func newMailTransport() mailer.Transport {
+	if utils.GetEnvOrDefault("MAIL_TRANSPORT", "file") == "smtp" {
+		return mailer.NewSMTPTransport(
+			utils.GetEnv("SMTP_HOST"),
+			utils.GetEnvOrDefault("SMTP_PORT", "587"),
+			utils.GetEnvOrDefault("SMTP_USERNAME", ""),
+			utils.GetEnvOrDefault("SMTP_PASSWORD", ""),
+		)
+	}
+
+	return mailer.NewFileTransport(utils.GetEnvOrDefault("MAIL_DROP_DIR", "./mail"))
+}
+
+func mailFrom() string {
+	return utils.GetEnvOrDefault("MAIL_FROM", "Receipts <receipts@localhost>")
+}
+
+func newEmailMessage(kind string, saleOrderID uuid.UUID, recipient string, createdBy uuid.NullUUID, now time.Time) *models.EmailMessage {
+	return &models.EmailMessage{
+		ID:            uuid.New(),
+		Kind:          kind,
+		SaleOrderID:   saleOrderID,
+		Recipient:     recipient,
+		Status:        constants.EmailStatusPending,
+		NextAttemptAt: now,
+		CreatedBy:     createdBy,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+}
+
+func newEmailMessageResponse(m *models.EmailMessage) dto.EmailMessageResponse {
+	response := dto.EmailMessageResponse{
+		ID:          m.ID,
+		Kind:        m.Kind,
+		SaleOrderID: m.SaleOrderID,
+		Recipient:   m.Recipient,
+		Subject:     m.Subject.String,
+		Status:      m.Status,
+		Attempts:    m.Attempts,
+		LastError:   m.LastError.String,
+		SentAt:      formatNullTime(m.SentAt),
+		CreatedBy:   storeIDPtr(m.CreatedBy),
+		CreatedAt:   m.CreatedAt.Format(time.RFC3339),
+	}
+
+	if m.Status == constants.EmailStatusPending {
+		response.NextAttemptAt = m.NextAttemptAt.Format(time.RFC3339)
+	}
+
+	return response
+}
+
+// subscribe registers the handlers that queue emails on the bus.
+func (s *EmailService) subscribe(bus *events.Bus) {
+	bus.Subscribe("order_emails", s.queueOrderEmail, constants.EventSaleOrderStatusChanged)
+}
+
+// queueOrderEmail queues the email a status change sends the customer of an
+// order: the receipt when it is completed, and a refund notice when a
+// completed order is cancelled. Customers without an email get none.
+func (s *EmailService) queueOrderEmail(ctx context.Context, event *models.DomainEvent) error {
+	var change dto.SaleOrderStatusChangedData
+	if err := json.Unmarshal(event.Payload, &change); err != nil {
+		return err
+	}
+
+	var kind string
+	switch {
+	case change.PreviousStatus != constants.SaleOrderStatusCompleted && change.Status == constants.SaleOrderStatusCompleted:
+		kind = constants.EmailKindReceipt
+	case change.PreviousStatus == constants.SaleOrderStatusCompleted && change.Status == constants.SaleOrderStatusCancelled:
+		kind = constants.EmailKindRefund
+	default:
+		return nil
+	}
+
+	if change.SaleOrder.CustomerID == nil {
+		return nil
+	}
+
+	customer, err := s.receiptService.saleOrderService.customerRepo.GetCustomerByID(ctx, *change.SaleOrder.CustomerID)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	if !customer.Email.Valid {
+		return nil
+	}
+
+	return s.emailRepo.InsertEmailMessage(ctx, newEmailMessage(kind, change.SaleOrder.ID, customer.Email.String, uuid.NullUUID{}, time.Now()))
+}
+
+// SendReceiptEmail queues the receipt of an order the caller may read, to
+// the given address or else to the email of the order's customer.
+func (s *EmailService) SendReceiptEmail(ctx context.Context, caller dto.Caller, saleOrderID uuid.UUID, req *dto.SendReceiptEmailRequest) (*dto.EmailMessageResponse, error) {
+	saleOrderService := s.receiptService.saleOrderService
+	saleOrder, err := saleOrderService.getSaleOrderForCaller(ctx, caller, saleOrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	recipient := strings.TrimSpace(req.Email)
+	if recipient == "" && saleOrder.CustomerID.Valid {
+		customer, err := saleOrderService.customerRepo.GetCustomerByID(ctx, saleOrder.CustomerID.UUID)
+		if err != nil && !errors.Is(err, apperror.ErrNotFound) {
+			return nil, err
+		}
+		if customer != nil {
+			recipient = customer.Email.String
+		}
+	}
+
+	if recipient == "" || len(recipient) > maxEmailAddressLength {
+		return nil, apperror.ErrInvalidEmailRecipient
+	}
+
+	address, err := mail.ParseAddress(recipient)
+	if err != nil {
+		return nil, apperror.ErrInvalidEmailRecipient
+	}
+
+	message := newEmailMessage(constants.EmailKindReceipt, saleOrder.ID, address.Address, uuid.NullUUID{UUID: caller.ID, Valid: true}, time.Now())
+	if err := s.emailRepo.InsertEmailMessage(ctx, message); err != nil {
+		return nil, err
+	}
+
+	response := newEmailMessageResponse(message)
+	return &response, nil
+}
+
+func (s *EmailService) GetEmailMessages(ctx context.Context, status string, saleOrderID *uuid.UUID, limit, offset int) ([]dto.EmailMessageResponse, int64, error) {
+	messages, totalCount, err := s.emailRepo.GetEmailMessages(ctx, status, saleOrderID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	responses := make([]dto.EmailMessageResponse, len(messages))
+	for i := range messages {
+		responses[i] = newEmailMessageResponse(&messages[i])
+	}
+
+	return responses, totalCount, nil
+}
+
+// GetSaleOrderEmailMessages lists the emails of an order the caller may read.
+func (s *EmailService) GetSaleOrderEmailMessages(ctx context.Context, caller dto.Caller, saleOrderID uuid.UUID, limit, offset int) ([]dto.EmailMessageResponse, int64, error) {
+	if _, err := s.receiptService.saleOrderService.getSaleOrderForCaller(ctx, caller, saleOrderID); err != nil {
+		return nil, 0, err
+	}
+
+	return s.GetEmailMessages(ctx, "", &saleOrderID, limit, offset)
+}
+
+// RetryEmailMessage queues a failed email again with a fresh set of attempts.
+func (s *EmailService) RetryEmailMessage(ctx context.Context, id uuid.UUID) (*dto.EmailMessageResponse, error) {
+	var response dto.EmailMessageResponse
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		message, err := s.emailRepo.GetEmailMessageByIDForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if message.Status != constants.EmailStatusFailed {
+			return apperror.ErrEmailNotRetryable
+		}
+
+		now := time.Now()
+		message.Status = constants.EmailStatusPending
+		message.Attempts = 0
+		message.NextAttemptAt = now
+		message.UpdatedAt = now
+
+		if err := s.emailRepo.UpdateEmailDelivery(ctx, message); err != nil {
+			return err
+		}
+
+		response = newEmailMessageResponse(message)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// DeliverEmails sends the emails that are due. Each batch is locked while it
+// is sent, so workers running side by side never send the same email.
+func (s *EmailService) DeliverEmails(ctx context.Context) error {
+	var sent, failed int
+	for {
+		var batch int
+		err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+			messages, err := s.emailRepo.GetDueEmailMessages(ctx, time.Now(), emailBatchSize)
+			if err != nil {
+				return err
+			}
+			batch = len(messages)
+
+			for i := range messages {
+				message := &messages[i]
+				message.Attempts++
+
+				now := time.Now()
+				if err := s.deliver(ctx, message); err != nil {
+					message.LastError = sql.NullString{String: truncateError(err), Valid: true}
+					// An order that is gone will not come back, so there is
+					// no point in trying again.
+					if errors.Is(err, apperror.ErrNotFound) || message.Attempts >= maxEmailAttempts {
+						message.Status = constants.EmailStatusFailed
+						failed++
+					} else {
+						message.NextAttemptAt = now.Add(emailRetryDelay << (message.Attempts - 1))
+					}
+				} else {
+					message.Status = constants.EmailStatusSent
+					message.SentAt = sql.NullTime{Time: now, Valid: true}
+					message.LastError = sql.NullString{}
+					sent++
+				}
+				message.UpdatedAt = now
+
+				if err := s.emailRepo.UpdateEmailDelivery(ctx, message); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		if batch < emailBatchSize {
+			break
+		}
+	}
+
+	if sent > 0 || failed > 0 {
+		slog.InfoContext(ctx, "emails delivered", "sent", sent, "failed", failed)
+	}
+
+	return nil
+}
+
+// deliver renders an email from the order as it is now and sends it.
+func (s *EmailService) deliver(ctx context.Context, message *models.EmailMessage) error {
+	content, ok := emailContent[message.Kind]
+	if !ok {
+		return apperror.ErrNotFound
+	}
+
+	saleOrder, err := s.receiptService.saleOrderService.saleOrderRepo.GetSaleOrderByID(ctx, message.SaleOrderID)
+	if err != nil {
+		return err
+	}
+
+	r, err := s.receiptService.buildReceipt(ctx, saleOrder)
+	if err != nil {
+		return err
+	}
+
+	text, html, err := receipt.RenderEmail(r, content.email)
+	if err != nil {
+		return err
+	}
+
+	subject := content.subject + saleOrder.OrderNumber
+	if r.Title != "" {
+		subject = r.Title + ": " + subject
+	}
+	message.Subject = sql.NullString{String: subject, Valid: true}
+
+	return s.transport.Send(ctx, &mailer.Message{
+		From:    s.from,
+		To:      message.Recipient,
+		Subject: subject,
+		Text:    text,
+		HTML:    html,
+	})
+}
+
+func truncateError(err error) string {
+	msg := err.Error()
+	if len(msg) > maxEmailErrorLength {
+		return msg[:maxEmailErrorLength]
+	}
+	return msg
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hafiztri123/kki-be/internal/constants"
+	"github.com/hafiztri123/kki-be/internal/events"
+	"github.com/hafiztri123/kki-be/internal/models"
+	"github.com/hafiztri123/kki-be/internal/repository"
+)
+
+const (
+	eventBatchSize = 50
+	// maxEventAttempts is how often an event is dispatched before it is
+	// marked failed. The waits between attempts double from eventRetryDelay.
+	maxEventAttempts = 10
+	eventRetryDelay  = 5 * time.Second
+	// eventRetention is how long dispatched events are kept before they are
+	// purged.
+	eventRetention = 7 * 24 * time.Hour
+)
+
+// EventService is the transactional outbox of domain events. Services record
+// events in the transaction that makes the change and a background poller
+// dispatches them to the handlers subscribed on the bus, so features react to
+// a change only once it has committed and without the changing service
+// knowing about them.
+type EventService struct {
+	transactor *repository.Transactor
+	eventRepo  *repository.EventRepository
+	bus        *events.Bus
+}
+
+func NewEventService(transactor *repository.Transactor, eventRepo *repository.EventRepository, bus *events.Bus) *EventService {
+	return &EventService{
+		transactor: transactor,
+		eventRepo:  eventRepo,
+		bus:        bus,
+	}
+}
+
+// record writes an event about aggregateID to the outbox with data as its
+// payload. It runs inside the transaction that makes the change, so the event
+// is only dispatched if that transaction commits.
+func (s *EventService) record(ctx context.Context, eventType string, aggregateID uuid.UUID, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	return s.eventRepo.InsertEvent(ctx, &models.DomainEvent{
+		ID:            uuid.New(),
+		EventType:     eventType,
+		AggregateID:   aggregateID,
+		Payload:       payload,
+		Status:        constants.EventStatusPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	})
+}
+
+// DispatchEvents hands the pending events to their handlers, oldest first.
+// Each batch is locked while it is dispatched, so pollers running side by
+// side never dispatch the same event. An event whose handlers fail is retried
+// later and may then run after newer events.
+func (s *EventService) DispatchEvents(ctx context.Context) error {
+	for {
+		var batch int
+		err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+			pending, err := s.eventRepo.GetDueEvents(ctx, time.Now(), eventBatchSize)
+			if err != nil {
+				return err
+			}
+			batch = len(pending)
+
+			for i := range pending {
+				event := &pending[i]
+				event.Attempts++
+
+				// The handlers run in a savepoint, so what they wrote before
+				// one of them failed is undone without losing the batch.
+				err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+					return s.bus.Dispatch(ctx, event)
+				})
+
+				now := time.Now()
+				if err != nil {
+					event.LastError = sql.NullString{String: truncateError(err), Valid: true}
+					if event.Attempts >= maxEventAttempts {
+						event.Status = constants.EventStatusFailed
+						slog.ErrorContext(ctx, "domain event failed", "event_id", event.ID, "event_type", event.EventType, "error", err.Error())
+					} else {
+						event.NextAttemptAt = now.Add(eventRetryDelay << (event.Attempts - 1))
+					}
+				} else {
+					event.Status = constants.EventStatusDispatched
+					event.DispatchedAt = sql.NullTime{Time: now, Valid: true}
+					event.LastError = sql.NullString{}
+				}
+				event.UpdatedAt = now
+
+				if err := s.eventRepo.UpdateEventDispatch(ctx, event); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		if batch < eventBatchSize {
+			return nil
+		}
+	}
+}
+
+// PurgeDispatchedEvents deletes dispatched events past their retention.
+func (s *EventService) PurgeDispatchedEvents(ctx context.Context) error {
+	deleted, err := s.eventRepo.DeleteDispatchedEvents(ctx, time.Now().Add(-eventRetention))
+	if err != nil {
+		return err
+	}
+
+	if deleted > 0 {
+		slog.InfoContext(ctx, "purged dispatched domain events", "count", deleted)
+	}
+
+	return nil
+}
@@ -0,0 +1,420 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	apperror "github.com/hafiztri123/kki-be/internal/app_error"
+	"github.com/hafiztri123/kki-be/internal/constants"
+	"github.com/hafiztri123/kki-be/internal/dto"
+	"github.com/hafiztri123/kki-be/internal/models"
+	"github.com/hafiztri123/kki-be/internal/money"
+	"github.com/hafiztri123/kki-be/internal/repository"
+)
+
+const (
+	// giftCardCodeAlphabet leaves out 0, O, 1 and I, which are easily misread.
+	giftCardCodeAlphabet    = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	giftCardCodeGroups      = 4
+	giftCardCodeGroupLength = 4
+	maxGiftCardCodeAttempts = 5
+)
+
+var giftCardTypes = []string{
+	constants.GiftCardTypeGiftCard,
+	constants.GiftCardTypeStoreCredit,
+}
+
+// GiftCardService keeps gift cards and store credit. Owners issue them,
+// cashiers top them up and spend them as a tender on sale orders. Every
+// balance change locks the card row and appends to its ledger.
+type GiftCardService struct {
+	transactor   *repository.Transactor
+	giftCardRepo *repository.GiftCardRepository
+	customerRepo *repository.CustomerRepository
+}
+
+func NewGiftCardService(transactor *repository.Transactor, giftCardRepo *repository.GiftCardRepository, customerRepo *repository.CustomerRepository) *GiftCardService {
+	return &GiftCardService{
+		transactor:   transactor,
+		giftCardRepo: giftCardRepo,
+		customerRepo: customerRepo,
+	}
+}
+
+func newGiftCardResponse(g *models.GiftCard, now time.Time) dto.GiftCardResponse {
+	return dto.GiftCardResponse{
+		ID:            g.ID,
+		Code:          g.Code,
+		CardType:      g.CardType,
+		Currency:      g.Currency,
+		InitialAmount: g.InitialAmount,
+		Balance:       g.Balance,
+		ExpiresAt:     formatNullTime(g.ExpiresAt),
+		Expired:       g.Expired(now),
+		CustomerID:    storeIDPtr(g.CustomerID),
+		IssuedBy:      g.IssuedBy,
+		CreatedAt:     g.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:     g.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+// generateGiftCardCode returns a random code such as ABCD-EFGH-JKLM-NPQR.
+func generateGiftCardCode() string {
+	b := make([]byte, giftCardCodeGroups*giftCardCodeGroupLength)
+	rand.Read(b)
+
+	var code strings.Builder
+	for i, c := range b {
+		if i > 0 && i%giftCardCodeGroupLength == 0 {
+			code.WriteByte('-')
+		}
+		code.WriteByte(giftCardCodeAlphabet[int(c)%len(giftCardCodeAlphabet)])
+	}
+
+	return code.String()
+}
+
+// normalizeGiftCardCode accepts codes typed in lower case or with stray
+// spaces around them.
+func normalizeGiftCardCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// IssueGiftCard issues a card with a fresh code and the given opening
+// balance.
+func (s *GiftCardService) IssueGiftCard(ctx context.Context, caller dto.Caller, req *dto.IssueGiftCardRequest) (*dto.GiftCardResponse, error) {
+	now := time.Now()
+
+	if !slices.Contains(giftCardTypes, req.CardType) || req.Amount <= 0 {
+		return nil, apperror.ErrInvalidGiftCard
+	}
+
+	if req.CardType == constants.GiftCardTypeStoreCredit && req.CustomerID == nil {
+		return nil, apperror.ErrInvalidGiftCard
+	}
+
+	currency := money.DefaultCurrency
+	if code := strings.TrimSpace(req.Currency); code != "" {
+		var ok bool
+		if currency, ok = money.ParseCurrency(code); !ok {
+			return nil, apperror.ErrInvalidGiftCard
+		}
+	}
+
+	var expiresAt sql.NullTime
+	if req.ExpiresAt != nil {
+		expiresAt = sql.NullTime{Time: req.ExpiresAt.In(time.Local), Valid: true}
+		if !expiresAt.Time.After(now) {
+			return nil, apperror.ErrInvalidGiftCard
+		}
+	}
+
+	var customerID uuid.NullUUID
+	if req.CustomerID != nil {
+		if _, err := s.customerRepo.GetCustomerByID(ctx, *req.CustomerID); err != nil {
+			if errors.Is(err, apperror.ErrNotFound) {
+				return nil, apperror.ErrInvalidGiftCard
+			}
+			return nil, err
+		}
+		customerID = uuid.NullUUID{UUID: *req.CustomerID, Valid: true}
+	}
+
+	card := &models.GiftCard{
+		ID:            uuid.New(),
+		CardType:      req.CardType,
+		Currency:      currency,
+		InitialAmount: req.Amount,
+		Balance:       req.Amount,
+		ExpiresAt:     expiresAt,
+		CustomerID:    customerID,
+		IssuedBy:      caller.ID,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.insertWithCode(ctx, card); err != nil {
+			return err
+		}
+
+		return s.giftCardRepo.InsertTransaction(ctx, &models.GiftCardTransaction{
+			ID:              uuid.New(),
+			GiftCardID:      card.ID,
+			TransactionType: constants.GiftCardTransactionIssue,
+			Amount:          card.Balance,
+			BalanceAfter:    card.Balance,
+			RecordedBy:      uuid.NullUUID{UUID: caller.ID, Valid: true},
+			CreatedAt:       now,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := newGiftCardResponse(card, now)
+	return &response, nil
+}
+
+// insertWithCode stores card under a new code, drawing again on the rare
+// collision with an existing one.
+func (s *GiftCardService) insertWithCode(ctx context.Context, card *models.GiftCard) error {
+	for attempt := 0; attempt < maxGiftCardCodeAttempts; attempt++ {
+		card.Code = generateGiftCardCode()
+
+		err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+			return s.giftCardRepo.InsertGiftCard(ctx, card)
+		})
+		if !errors.Is(err, apperror.ErrGiftCardCodeConflict) {
+			return err
+		}
+	}
+
+	return apperror.ErrGiftCardCodeConflict
+}
+
+func (s *GiftCardService) GetGiftCards(ctx context.Context, cardType string, customerID *uuid.UUID, limit, offset int) ([]dto.GiftCardResponse, int64, error) {
+	cards, totalCount, err := s.giftCardRepo.GetGiftCards(ctx, cardType, customerID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	now := time.Now()
+	responses := make([]dto.GiftCardResponse, 0, len(cards))
+	for i := range cards {
+		responses = append(responses, newGiftCardResponse(&cards[i], now))
+	}
+
+	return responses, totalCount, nil
+}
+
+// GetGiftCardByCode is the balance check done at the till.
+func (s *GiftCardService) GetGiftCardByCode(ctx context.Context, code string) (*dto.GiftCardResponse, error) {
+	card, err := s.giftCardRepo.GetGiftCardByCode(ctx, normalizeGiftCardCode(code))
+	if err != nil {
+		return nil, err
+	}
+
+	response := newGiftCardResponse(card, time.Now())
+	return &response, nil
+}
+
+// TopUpGiftCard adds to the balance of a card that has not expired.
+func (s *GiftCardService) TopUpGiftCard(ctx context.Context, caller dto.Caller, code string, req *dto.TopUpGiftCardRequest) (*dto.GiftCardResponse, error) {
+	reference := strings.TrimSpace(req.Reference)
+	if req.Amount <= 0 || !slices.Contains(invoicePaymentMethods, req.Method) || len(reference) > maxPaymentReferenceLength {
+		return nil, apperror.ErrInvalidPayment
+	}
+
+	var response dto.GiftCardResponse
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		card, err := s.giftCardRepo.GetGiftCardByCodeForUpdate(ctx, normalizeGiftCardCode(code))
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if card.Expired(now) {
+			return apperror.ErrGiftCardExpired
+		}
+
+		card.Balance += req.Amount
+		card.UpdatedAt = now
+		if err := s.giftCardRepo.UpdateGiftCardBalance(ctx, card); err != nil {
+			return err
+		}
+
+		err = s.giftCardRepo.InsertTransaction(ctx, &models.GiftCardTransaction{
+			ID:              uuid.New(),
+			GiftCardID:      card.ID,
+			TransactionType: constants.GiftCardTransactionTopUp,
+			Amount:          req.Amount,
+			BalanceAfter:    card.Balance,
+			Method:          sql.NullString{String: req.Method, Valid: true},
+			Reference:       sql.NullString{String: reference, Valid: reference != ""},
+			RecordedBy:      uuid.NullUUID{UUID: caller.ID, Valid: true},
+			CreatedAt:       now,
+		})
+		if err != nil {
+			return err
+		}
+
+		response = newGiftCardResponse(card, now)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+func (s *GiftCardService) GetTransactions(ctx context.Context, code string, limit, offset int) ([]dto.GiftCardTransactionResponse, int64, error) {
+	card, err := s.giftCardRepo.GetGiftCardByCode(ctx, normalizeGiftCardCode(code))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	transactions, totalCount, err := s.giftCardRepo.GetTransactions(ctx, card.ID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	responses := make([]dto.GiftCardTransactionResponse, 0, len(transactions))
+	for _, t := range transactions {
+		responses = append(responses, dto.GiftCardTransactionResponse{
+			ID:              t.ID,
+			TransactionType: t.TransactionType,
+			Amount:          t.Amount,
+			BalanceAfter:    t.BalanceAfter,
+			SaleOrderID:     storeIDPtr(t.SaleOrderID),
+			Method:          t.Method.String,
+			Reference:       t.Reference.String,
+			RecordedBy:      storeIDPtr(t.RecordedBy),
+			CreatedAt:       t.CreatedAt.Format(time.RFC3339),
+		})
+	}
+
+	return responses, totalCount, nil
+}
+
+// redeemedAmount is what cards currently pay on an order.
+func (s *GiftCardService) redeemedAmount(ctx context.Context, saleOrderID uuid.UUID) (money.Amount, error) {
+	tenders, err := s.giftCardRepo.GetSaleOrderTenders(ctx, saleOrderID)
+	if err != nil {
+		return 0, err
+	}
+
+	var amount money.Amount
+	for _, t := range tenders {
+		amount += t.Amount
+	}
+
+	return amount, nil
+}
+
+// redeem pays up to due of saleOrder from the card with the given code. A
+// zero amount takes as much as the card and due allow; what is not spent
+// stays on the card.
+func (s *GiftCardService) redeem(ctx context.Context, caller dto.Caller, saleOrder *models.SaleOrder, req *dto.RedeemGiftCardRequest, due money.Amount) (*dto.GiftCardRedemptionResponse, error) {
+	if req.Amount < 0 {
+		return nil, apperror.ErrInvalidGiftCardRedemption
+	}
+
+	card, err := s.giftCardRepo.GetGiftCardByCodeForUpdate(ctx, normalizeGiftCardCode(req.Code))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if card.Expired(now) {
+		return nil, apperror.ErrGiftCardExpired
+	}
+
+	if card.Currency != saleOrder.Currency {
+		return nil, apperror.ErrInvalidGiftCardRedemption
+	}
+
+	amount := req.Amount
+	if amount == 0 {
+		amount = money.Min(card.Balance, due)
+	}
+
+	if amount <= 0 || amount > due {
+		return nil, apperror.ErrInvalidGiftCardRedemption
+	}
+
+	if amount > card.Balance {
+		return nil, apperror.ErrInsufficientGiftCardBalance
+	}
+
+	card.Balance -= amount
+	card.UpdatedAt = now
+	if err := s.giftCardRepo.UpdateGiftCardBalance(ctx, card); err != nil {
+		return nil, err
+	}
+
+	err = s.giftCardRepo.InsertTransaction(ctx, &models.GiftCardTransaction{
+		ID:              uuid.New(),
+		GiftCardID:      card.ID,
+		TransactionType: constants.GiftCardTransactionRedeem,
+		Amount:          amount,
+		BalanceAfter:    card.Balance,
+		SaleOrderID:     uuid.NullUUID{UUID: saleOrder.ID, Valid: true},
+		RecordedBy:      uuid.NullUUID{UUID: caller.ID, Valid: true},
+		CreatedAt:       now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.GiftCardRedemptionResponse{
+		SaleOrderID:    saleOrder.ID,
+		Code:           card.Code,
+		RedeemedAmount: amount,
+		CardBalance:    card.Balance,
+		TotalAmount:    saleOrder.TotalAmount,
+		AmountDue:      due - amount,
+	}, nil
+}
+
+// refund gives what cards pay on an order back to them. Expired cards are
+// refunded too; the balance just cannot be spent. recordedBy is nil when the
+// refund follows a cancellation made by the system.
+func (s *GiftCardService) refund(ctx context.Context, saleOrderID uuid.UUID, recordedBy *uuid.UUID) error {
+	tenders, err := s.giftCardRepo.GetSaleOrderTenders(ctx, saleOrderID)
+	if err != nil {
+		return err
+	}
+
+	var recorder uuid.NullUUID
+	if recordedBy != nil {
+		recorder = uuid.NullUUID{UUID: *recordedBy, Valid: true}
+	}
+
+	now := time.Now()
+	for _, t := range tenders {
+		card, err := s.giftCardRepo.GetGiftCardByIDForUpdate(ctx, t.GiftCardID)
+		if err != nil {
+			return err
+		}
+
+		card.Balance += t.Amount
+		card.UpdatedAt = now
+		if err := s.giftCardRepo.UpdateGiftCardBalance(ctx, card); err != nil {
+			return err
+		}
+
+		err = s.giftCardRepo.InsertTransaction(ctx, &models.GiftCardTransaction{
+			ID:              uuid.New(),
+			GiftCardID:      card.ID,
+			TransactionType: constants.GiftCardTransactionRefund,
+			Amount:          t.Amount,
+			BalanceAfter:    card.Balance,
+			SaleOrderID:     uuid.NullUUID{UUID: saleOrderID, Valid: true},
+			RecordedBy:      recorder,
+			CreatedAt:       now,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// settleSaleOrder refunds the cards that paid an order once it is cancelled.
+func (s *GiftCardService) settleSaleOrder(ctx context.Context, saleOrder *models.SaleOrder, from, to string) error {
+	if from == to || to != constants.SaleOrderStatusCancelled {
+		return nil
+	}
+
+	return s.refund(ctx, saleOrder.ID, nil)
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	apperror "github.com/hafiztri123/kki-be/internal/app_error"
+	"github.com/hafiztri123/kki-be/internal/dto"
+	"github.com/hafiztri123/kki-be/internal/models"
+	"github.com/hafiztri123/kki-be/internal/repository"
+	"github.com/hafiztri123/kki-be/internal/utils"
+)
+
+const defaultIdempotencyRetention = 24 * time.Hour
+
+// errDiscardIdempotentResponse rolls back a request that failed on the server
+// side, so the key is released and a retry runs the request again.
+var errDiscardIdempotentResponse = errors.New("discard idempotent response")
+
+type IdempotencyService struct {
+	transactor         *repository.Transactor
+	idempotencyKeyRepo *repository.IdempotencyKeyRepository
+}
+
+func NewIdempotencyService(transactor *repository.Transactor, idempotencyKeyRepo *repository.IdempotencyKeyRepository) *IdempotencyService {
+	return &IdempotencyService{
+		transactor:         transactor,
+		idempotencyKeyRepo: idempotencyKeyRepo,
+	}
+}
+
+func idempotencyRetention() time.Duration {
+	retention, err := time.ParseDuration(utils.GetEnvOrDefault("IDEMPOTENCY_RETENTION", ""))
+	if err != nil || retention <= 0 {
+		return defaultIdempotencyRetention
+	}
+	return retention
+}
+
+// Execute runs fn at most once per caller and key. fn runs in the same
+// transaction that holds the key, so its writes and the stored response
+// commit together, and a concurrent retry waits on the key's row lock and
+// then replays the stored response. replayed reports whether the response
+// came from storage.
+func (s *IdempotencyService) Execute(
+	ctx context.Context,
+	userID uuid.UUID,
+	key string,
+	fingerprint string,
+	fn func(ctx context.Context) *dto.IdempotentResponse,
+) (response *dto.IdempotentResponse, replayed bool, err error) {
+	now := time.Now()
+	record := &models.IdempotencyKey{
+		UserID:             userID,
+		Key:                key,
+		RequestFingerprint: fingerprint,
+		CreatedAt:          now,
+		ExpiresAt:          now.Add(idempotencyRetention()),
+	}
+
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		inserted, err := s.idempotencyKeyRepo.InsertIdempotencyKey(ctx, record)
+		if err != nil {
+			return err
+		}
+
+		if !inserted {
+			existing, err := s.idempotencyKeyRepo.GetIdempotencyKeyForUpdate(ctx, userID, key)
+			if err != nil {
+				return err
+			}
+
+			switch {
+			case existing.ExpiresAt.Before(now):
+				if err := s.idempotencyKeyRepo.ResetIdempotencyKey(ctx, record); err != nil {
+					return err
+				}
+			case existing.RequestFingerprint != fingerprint:
+				return apperror.ErrIdempotencyKeyMismatch
+			case existing.ResponseStatus.Valid:
+				response = &dto.IdempotentResponse{
+					Status:      int(existing.ResponseStatus.Int32),
+					ContentType: existing.ResponseContentType.String,
+					Body:        existing.ResponseBody,
+				}
+				replayed = true
+				return nil
+			}
+		}
+
+		response = fn(ctx)
+		if response.Status >= http.StatusInternalServerError {
+			return errDiscardIdempotentResponse
+		}
+
+		record.ResponseStatus = sql.NullInt32{Int32: int32(response.Status), Valid: true}
+		record.ResponseContentType = sql.NullString{String: response.ContentType, Valid: response.ContentType != ""}
+		record.ResponseBody = response.Body
+
+		return s.idempotencyKeyRepo.SaveIdempotencyResponse(ctx, record)
+	})
+
+	if errors.Is(err, errDiscardIdempotentResponse) {
+		return response, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	return response, replayed, nil
+}
+
+// PurgeExpired deletes keys past their retention window.
+func (s *IdempotencyService) PurgeExpired(ctx context.Context) error {
+	deleted, err := s.idempotencyKeyRepo.DeleteExpiredIdempotencyKeys(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if deleted > 0 {
+		slog.InfoContext(ctx, "purged expired idempotency keys", "count", deleted)
+	}
+
+	return nil
+}
@@ -0,0 +1,293 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	apperror "github.com/hafiztri123/kki-be/internal/app_error"
+	"github.com/hafiztri123/kki-be/internal/constants"
+	"github.com/hafiztri123/kki-be/internal/dto"
+	"github.com/hafiztri123/kki-be/internal/models"
+	"github.com/hafiztri123/kki-be/internal/repository"
+)
+
+const (
+	invoiceNumberPrefix       = "INV-"
+	maxPaymentReferenceLength = 255
+)
+
+// invoicePaymentTerms are the NET terms an invoice can be issued on, in days.
+var invoicePaymentTerms = []int{7, 14, 30}
+
+var invoicePaymentMethods = []string{
+	constants.InvoicePaymentMethodCash,
+	constants.InvoicePaymentMethodBankTransfer,
+	constants.InvoicePaymentMethodCard,
+	constants.InvoicePaymentMethodOther,
+}
+
+// InvoiceService bills completed sale orders on payment terms. Cashiers work
+// on the invoices they issued, owners on every invoice.
+type InvoiceService struct {
+	transactor       *repository.Transactor
+	invoiceRepo      *repository.InvoiceRepository
+	saleOrderService *SaleOrderService
+}
+
+func NewInvoiceService(transactor *repository.Transactor, invoiceRepo *repository.InvoiceRepository, saleOrderService *SaleOrderService) *InvoiceService {
+	return &InvoiceService{
+		transactor:       transactor,
+		invoiceRepo:      invoiceRepo,
+		saleOrderService: saleOrderService,
+	}
+}
+
+func newInvoiceResponse(i *models.Invoice, payments []models.InvoicePayment) dto.InvoiceResponse {
+	response := dto.InvoiceResponse{
+		ID:              i.ID,
+		InvoiceNumber:   i.InvoiceNumber,
+		SaleOrderID:     i.SaleOrderID,
+		CustomerName:    i.CustomerName,
+		StoreID:         storeIDPtr(i.StoreID),
+		Currency:        i.Currency,
+		TotalAmount:     i.TotalAmount,
+		PaidAmount:      i.PaidAmount,
+		BalanceAmount:   i.Balance(),
+		PaymentTermDays: i.PaymentTermDays,
+		IssuedAt:        i.IssuedAt.Format(time.RFC3339),
+		DueAt:           i.DueAt.Format(time.RFC3339),
+		Status:          i.Status,
+		CreatedBy:       i.CreatedBy,
+		CreatedAt:       i.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:       i.UpdatedAt.Format(time.RFC3339),
+	}
+
+	for _, p := range payments {
+		response.Payments = append(response.Payments, dto.InvoicePaymentResponse{
+			ID:         p.ID,
+			Amount:     p.Amount,
+			Method:     p.Method,
+			Reference:  p.Reference.String,
+			PaidAt:     p.PaidAt.Format(time.RFC3339),
+			RecordedBy: p.RecordedBy,
+		})
+	}
+
+	return response
+}
+
+// invoiceStatus derives the status of an invoice from its balance and due
+// date.
+func invoiceStatus(i *models.Invoice, now time.Time) string {
+	switch {
+	case i.Balance() <= 0:
+		return constants.InvoiceStatusPaid
+	case now.After(i.DueAt):
+		return constants.InvoiceStatusOverdue
+	case i.PaidAmount > 0:
+		return constants.InvoiceStatusPartiallyPaid
+	default:
+		return constants.InvoiceStatusOpen
+	}
+}
+
+// checkInvoiceAccess hides invoices issued by other cashiers.
+func checkInvoiceAccess(caller dto.Caller, invoice *models.Invoice) error {
+	if isOwner(caller) || invoice.CreatedBy == caller.ID {
+		return nil
+	}
+	return apperror.ErrNotFound
+}
+
+// CreateInvoice issues an invoice for a completed sale order, due the given
+// number of days from now.
+func (s *InvoiceService) CreateInvoice(ctx context.Context, caller dto.Caller, saleOrderID uuid.UUID, req *dto.CreateInvoiceRequest) (*dto.InvoiceResponse, error) {
+	if !slices.Contains(invoicePaymentTerms, req.PaymentTermDays) {
+		return nil, apperror.ErrInvalidInvoice
+	}
+
+	saleOrder, err := s.saleOrderService.getSaleOrderForCaller(ctx, caller, saleOrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if saleOrder.Status != constants.SaleOrderStatusCompleted {
+		return nil, apperror.ErrSaleOrderNotCompleted
+	}
+
+	now := time.Now()
+	invoice := &models.Invoice{
+		ID:              uuid.New(),
+		InvoiceNumber:   invoiceNumberPrefix + saleOrder.OrderNumber,
+		SaleOrderID:     saleOrder.ID,
+		CustomerName:    saleOrder.CustomerName,
+		StoreID:         saleOrder.StoreID,
+		Currency:        saleOrder.Currency,
+		TotalAmount:     saleOrder.TotalAmount,
+		PaymentTermDays: req.PaymentTermDays,
+		IssuedAt:        now,
+		DueAt:           now.AddDate(0, 0, req.PaymentTermDays),
+		Status:          constants.InvoiceStatusOpen,
+		CreatedBy:       caller.ID,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	invoice.Status = invoiceStatus(invoice, now)
+
+	if err := s.invoiceRepo.InsertInvoice(ctx, invoice); err != nil {
+		return nil, err
+	}
+
+	response := newInvoiceResponse(invoice, nil)
+	return &response, nil
+}
+
+func (s *InvoiceService) GetInvoices(ctx context.Context, caller dto.Caller, status, customerName string, limit, offset int) ([]dto.InvoiceResponse, int64, error) {
+	var createdBy *uuid.UUID
+	if !isOwner(caller) {
+		createdBy = &caller.ID
+	}
+
+	invoices, totalCount, err := s.invoiceRepo.GetInvoices(ctx, createdBy, status, strings.TrimSpace(customerName), limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	responses := make([]dto.InvoiceResponse, 0, len(invoices))
+	for i := range invoices {
+		responses = append(responses, newInvoiceResponse(&invoices[i], nil))
+	}
+
+	return responses, totalCount, nil
+}
+
+func (s *InvoiceService) GetInvoiceByID(ctx context.Context, caller dto.Caller, id uuid.UUID) (*dto.InvoiceResponse, error) {
+	invoice, err := s.invoiceRepo.GetInvoiceByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkInvoiceAccess(caller, invoice); err != nil {
+		return nil, err
+	}
+
+	payments, err := s.invoiceRepo.GetInvoicePayments(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := newInvoiceResponse(invoice, payments)
+	return &response, nil
+}
+
+// RecordPayment applies a full or partial payment to an invoice and moves it
+// to its new status. Payments larger than the balance are refused.
+func (s *InvoiceService) RecordPayment(ctx context.Context, caller dto.Caller, invoiceID uuid.UUID, req *dto.CreateInvoicePaymentRequest) (*dto.InvoiceResponse, error) {
+	now := time.Now()
+
+	paidAt := now
+	if req.PaidAt != nil {
+		paidAt = req.PaidAt.In(time.Local)
+	}
+
+	reference := strings.TrimSpace(req.Reference)
+	if req.Amount <= 0 || !slices.Contains(invoicePaymentMethods, req.Method) || len(reference) > maxPaymentReferenceLength || paidAt.After(now) {
+		return nil, apperror.ErrInvalidPayment
+	}
+
+	var response dto.InvoiceResponse
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		invoice, err := s.invoiceRepo.GetInvoiceByIDForUpdate(ctx, invoiceID)
+		if err != nil {
+			return err
+		}
+
+		if err := checkInvoiceAccess(caller, invoice); err != nil {
+			return err
+		}
+
+		if req.Amount > invoice.Balance() {
+			return apperror.ErrPaymentExceedsBalance
+		}
+
+		payment := models.InvoicePayment{
+			ID:         uuid.New(),
+			InvoiceID:  invoice.ID,
+			Amount:     req.Amount,
+			Method:     req.Method,
+			Reference:  sql.NullString{String: reference, Valid: reference != ""},
+			PaidAt:     paidAt,
+			RecordedBy: caller.ID,
+			CreatedAt:  now,
+		}
+		if err := s.invoiceRepo.InsertInvoicePayment(ctx, &payment); err != nil {
+			return err
+		}
+
+		invoice.PaidAmount += req.Amount
+		invoice.Status = invoiceStatus(invoice, now)
+		invoice.UpdatedAt = now
+		if err := s.invoiceRepo.UpdateInvoicePaid(ctx, invoice); err != nil {
+			return err
+		}
+
+		payments, err := s.invoiceRepo.GetInvoicePayments(ctx, invoice.ID)
+		if err != nil {
+			return err
+		}
+
+		response = newInvoiceResponse(invoice, payments)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// MarkOverdueInvoices moves unpaid invoices past their due date to overdue.
+func (s *InvoiceService) MarkOverdueInvoices(ctx context.Context) error {
+	marked, err := s.invoiceRepo.MarkOverdueInvoices(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if marked > 0 {
+		slog.InfoContext(ctx, "invoices marked overdue", "count", marked)
+	}
+
+	return nil
+}
+
+// GetReceivablesAging reports the unpaid balance per customer at asOf, split
+// into 0-30, 31-60, 61-90 and 90+ days past due.
+func (s *InvoiceService) GetReceivablesAging(ctx context.Context, asOf time.Time) (*dto.ReceivablesAgingResponse, error) {
+	aging, err := s.invoiceRepo.GetReceivablesAging(ctx, asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.ReceivablesAgingResponse{
+		AsOf:      asOf.Format(time.RFC3339),
+		Customers: make([]dto.ReceivablesAgingRow, 0, len(aging)),
+	}
+	for _, a := range aging {
+		response.Customers = append(response.Customers, dto.ReceivablesAgingRow{
+			CustomerName: a.CustomerName,
+			Currency:     a.Currency,
+			Days0To30:    a.Days0To30,
+			Days31To60:   a.Days31To60,
+			Days61To90:   a.Days61To90,
+			Days90Plus:   a.Days90Plus,
+			Total:        a.Total,
+		})
+	}
+
+	return response, nil
+}
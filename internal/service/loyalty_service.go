@@ -0,0 +1,543 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	apperror "github.com/hafiztri123/kki-be/internal/app_error"
+	"github.com/hafiztri123/kki-be/internal/constants"
+	"github.com/hafiztri123/kki-be/internal/dto"
+	"github.com/hafiztri123/kki-be/internal/models"
+	"github.com/hafiztri123/kki-be/internal/money"
+	"github.com/hafiztri123/kki-be/internal/repository"
+)
+
+const (
+	maxLoyaltyExpiryDays   = 3650
+	maxLoyaltyMultiplier   = 100
+	loyaltyExpiryBatchSize = 500
+)
+
+// LoyaltyService runs the points program. Customers linked to a completed
+// sale order earn points on it; points are redeemed as a tender on draft
+// orders. A completed order that is cancelled or deleted counts as refunded:
+// its points are taken back and the points redeemed on it are returned.
+//
+// Points live in lots, one per earn or return, that expire on their own and
+// are consumed soonest to expire first. Every change to the points of a
+// customer locks the customer row first.
+type LoyaltyService struct {
+	transactor    *repository.Transactor
+	loyaltyRepo   *repository.LoyaltyRepository
+	customerRepo  *repository.CustomerRepository
+	saleOrderRepo *repository.SaleOrderRepository
+}
+
+func NewLoyaltyService(transactor *repository.Transactor, loyaltyRepo *repository.LoyaltyRepository, customerRepo *repository.CustomerRepository, saleOrderRepo *repository.SaleOrderRepository) *LoyaltyService {
+	return &LoyaltyService{
+		transactor:    transactor,
+		loyaltyRepo:   loyaltyRepo,
+		customerRepo:  customerRepo,
+		saleOrderRepo: saleOrderRepo,
+	}
+}
+
+func newLoyaltyEntryResponse(e *models.LoyaltyPointEntry) dto.LoyaltyEntryResponse {
+	return dto.LoyaltyEntryResponse{
+		ID:              e.ID,
+		EntryType:       e.EntryType,
+		Points:          e.Points,
+		RemainingPoints: e.RemainingPoints,
+		ExpiresAt:       formatNullTime(e.ExpiresAt),
+		SaleOrderID:     storeIDPtr(e.SaleOrderID),
+		Amount:          e.Amount,
+		CreatedBy:       storeIDPtr(e.CreatedBy),
+		CreatedAt:       e.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func (s *LoyaltyService) GetSettings(ctx context.Context) (*dto.LoyaltySettingsResponse, error) {
+	settings, err := s.loyaltyRepo.GetSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	multipliers, err := s.loyaltyRepo.GetMultipliers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.LoyaltySettingsResponse{
+		Enabled:             settings.Enabled,
+		SpendPerPoint:       settings.SpendPerPoint,
+		PointValue:          settings.PointValue,
+		ExpiryDays:          settings.ExpiryDays,
+		MinRedeemPoints:     settings.MinRedeemPoints,
+		CategoryMultipliers: make(map[string]float64, len(multipliers)),
+		UpdatedBy:           storeIDPtr(settings.UpdatedBy),
+		UpdatedAt:           settings.UpdatedAt.Format(time.RFC3339),
+	}
+	for _, m := range multipliers {
+		response.CategoryMultipliers[m.Category] = m.Multiplier
+	}
+
+	return response, nil
+}
+
+// UpdateSettings replaces the earn and redeem rule. It applies to orders
+// completed and points redeemed from now on; points already earned keep
+// their expiry.
+func (s *LoyaltyService) UpdateSettings(ctx context.Context, caller dto.Caller, req *dto.LoyaltySettingsRequest) (*dto.LoyaltySettingsResponse, error) {
+	if req.SpendPerPoint <= 0 || req.PointValue <= 0 || req.ExpiryDays < 0 || req.ExpiryDays > maxLoyaltyExpiryDays || req.MinRedeemPoints < 0 {
+		return nil, apperror.ErrInvalidLoyaltySettings
+	}
+
+	multipliers := make([]models.LoyaltyMultiplier, 0, len(req.CategoryMultipliers))
+	for category, multiplier := range req.CategoryMultipliers {
+		if category == "" || multiplier < 0 || multiplier > maxLoyaltyMultiplier {
+			return nil, apperror.ErrInvalidLoyaltySettings
+		}
+		multipliers = append(multipliers, models.LoyaltyMultiplier{Category: category, Multiplier: multiplier})
+	}
+
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		err := s.loyaltyRepo.UpdateSettings(ctx, &models.LoyaltySettings{
+			Enabled:         req.Enabled,
+			SpendPerPoint:   req.SpendPerPoint,
+			PointValue:      req.PointValue,
+			ExpiryDays:      req.ExpiryDays,
+			MinRedeemPoints: req.MinRedeemPoints,
+			UpdatedBy:       uuid.NullUUID{UUID: caller.ID, Valid: true},
+			UpdatedAt:       time.Now(),
+		})
+		if err != nil {
+			return err
+		}
+
+		return s.loyaltyRepo.ReplaceMultipliers(ctx, multipliers)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetSettings(ctx)
+}
+
+func (s *LoyaltyService) GetBalance(ctx context.Context, customerID uuid.UUID) (*dto.LoyaltyBalanceResponse, error) {
+	if _, err := s.customerRepo.GetCustomerByID(ctx, customerID); err != nil {
+		return nil, err
+	}
+
+	settings, err := s.loyaltyRepo.GetSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	points, err := s.loyaltyRepo.GetAvailablePoints(ctx, customerID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.LoyaltyBalanceResponse{
+		CustomerID:  customerID,
+		Points:      points,
+		PointsValue: settings.PointValue * money.Amount(points),
+	}
+
+	expiring, at, err := s.loyaltyRepo.GetNextExpiry(ctx, customerID)
+	switch {
+	case err == nil:
+		response.NextExpiryPoints = expiring
+		response.NextExpiryAt = at.Format(time.RFC3339)
+	case !errors.Is(err, apperror.ErrNotFound):
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (s *LoyaltyService) GetHistory(ctx context.Context, customerID uuid.UUID, limit, offset int) ([]dto.LoyaltyEntryResponse, int64, error) {
+	if _, err := s.customerRepo.GetCustomerByID(ctx, customerID); err != nil {
+		return nil, 0, err
+	}
+
+	entries, totalCount, err := s.loyaltyRepo.GetEntries(ctx, customerID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	responses := make([]dto.LoyaltyEntryResponse, 0, len(entries))
+	for i := range entries {
+		responses = append(responses, newLoyaltyEntryResponse(&entries[i]))
+	}
+
+	return responses, totalCount, nil
+}
+
+// lotExpiry is when points earned or returned at now expire.
+func lotExpiry(settings *models.LoyaltySettings, now time.Time) sql.NullTime {
+	if settings.ExpiryDays == 0 {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: now.AddDate(0, 0, settings.ExpiryDays), Valid: true}
+}
+
+// earnablePoints applies the earn rule to a completed order. Points are earned
+// on what was paid other than with points, weighted per line by the category
+// multipliers. Orders without lines earn at 1x on their total.
+func earnablePoints(saleOrder *models.SaleOrder, items []models.SaleOrderItem, multipliers map[string]float64, redeemed money.Amount, settings *models.LoyaltySettings) int64 {
+	paid := money.Max(saleOrder.TotalAmount-redeemed, 0)
+
+	eligible := paid
+	var base, weighted money.Amount
+	for _, item := range items {
+		multiplier, ok := multipliers[item.Category]
+		if !ok {
+			multiplier = 1
+		}
+		base += item.TotalAmount
+		weighted += item.TotalAmount.Percent(multiplier*100, money.RoundDown)
+	}
+	if base > 0 {
+		eligible = paid.MulDiv(weighted.MinorUnits(), base.MinorUnits(), money.RoundDown)
+	}
+
+	return eligible.MinorUnits() / settings.SpendPerPoint.MinorUnits()
+}
+
+// consume takes points from the open lots of a customer, soonest to expire
+// first. The caller holds the customer lock and has checked the balance.
+func (s *LoyaltyService) consume(ctx context.Context, customerID uuid.UUID, points int64, now time.Time) error {
+	lots, err := s.loyaltyRepo.GetOpenLots(ctx, customerID, now)
+	if err != nil {
+		return err
+	}
+
+	for _, lot := range lots {
+		if points == 0 {
+			break
+		}
+
+		take := min(lot.RemainingPoints, points)
+		if err := s.loyaltyRepo.SetRemainingPoints(ctx, lot.ID, lot.RemainingPoints-take); err != nil {
+			return err
+		}
+		points -= take
+	}
+
+	if points > 0 {
+		return apperror.ErrInsufficientPoints
+	}
+
+	return nil
+}
+
+// settleSaleOrder keeps the points of an order in step with a status change
+// from one status to another. It runs inside the transaction that changes
+// the order.
+func (s *LoyaltyService) settleSaleOrder(ctx context.Context, saleOrder *models.SaleOrder, from, to string) error {
+	if from == to {
+		return nil
+	}
+
+	if to == constants.SaleOrderStatusCompleted {
+		return s.earn(ctx, saleOrder)
+	}
+
+	if from == constants.SaleOrderStatusCompleted {
+		if err := s.reverse(ctx, saleOrder); err != nil {
+			return err
+		}
+	}
+
+	if to == constants.SaleOrderStatusCancelled {
+		return s.restoreRedemptions(ctx, saleOrder.ID, nil)
+	}
+
+	return nil
+}
+
+// earn credits the customer of a completed order. An order earns once; it can
+// earn again only after its points were reversed.
+func (s *LoyaltyService) earn(ctx context.Context, saleOrder *models.SaleOrder) error {
+	if !saleOrder.CustomerID.Valid || saleOrder.Currency != money.DefaultCurrency {
+		return nil
+	}
+
+	settings, err := s.loyaltyRepo.GetSettings(ctx)
+	if err != nil {
+		return err
+	}
+	if !settings.Enabled {
+		return nil
+	}
+
+	if err := s.loyaltyRepo.LockCustomer(ctx, saleOrder.CustomerID.UUID); err != nil {
+		return err
+	}
+
+	orderPoints, err := s.loyaltyRepo.GetSaleOrderPoints(ctx, saleOrder.ID)
+	if err != nil {
+		return err
+	}
+	if orderPoints.NetEarned() > 0 {
+		return nil
+	}
+
+	items, err := s.saleOrderRepo.GetSaleOrderItems(ctx, saleOrder.ID)
+	if err != nil {
+		return err
+	}
+
+	multipliers, err := s.loyaltyRepo.GetMultipliers(ctx)
+	if err != nil {
+		return err
+	}
+
+	byCategory := make(map[string]float64, len(multipliers))
+	for _, m := range multipliers {
+		byCategory[m.Category] = m.Multiplier
+	}
+
+	_, redeemed := orderPoints.NetRedeemed()
+	points := earnablePoints(saleOrder, items, byCategory, redeemed, settings)
+	if points <= 0 {
+		return nil
+	}
+
+	now := time.Now()
+	return s.loyaltyRepo.InsertEntry(ctx, &models.LoyaltyPointEntry{
+		ID:              uuid.New(),
+		CustomerID:      saleOrder.CustomerID.UUID,
+		EntryType:       constants.LoyaltyEntryEarn,
+		Points:          points,
+		RemainingPoints: points,
+		ExpiresAt:       lotExpiry(settings, now),
+		SaleOrderID:     uuid.NullUUID{UUID: saleOrder.ID, Valid: true},
+		CreatedAt:       now,
+	})
+}
+
+// reverse takes back the points a refunded order earned. Points the customer
+// already spent are not clawed back, so at most the current balance is
+// reversed.
+func (s *LoyaltyService) reverse(ctx context.Context, saleOrder *models.SaleOrder) error {
+	orderPoints, err := s.loyaltyRepo.GetSaleOrderPoints(ctx, saleOrder.ID)
+	if err != nil {
+		return err
+	}
+
+	earned := orderPoints.NetEarned()
+	if earned <= 0 || !orderPoints.EarnCustomerID.Valid {
+		return nil
+	}
+	customerID := orderPoints.EarnCustomerID.UUID
+
+	if err := s.loyaltyRepo.LockCustomer(ctx, customerID); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	available, err := s.loyaltyRepo.GetAvailablePoints(ctx, customerID, now)
+	if err != nil {
+		return err
+	}
+
+	points := min(earned, available)
+	if points <= 0 {
+		return nil
+	}
+
+	if err := s.consume(ctx, customerID, points, now); err != nil {
+		return err
+	}
+
+	return s.loyaltyRepo.InsertEntry(ctx, &models.LoyaltyPointEntry{
+		ID:          uuid.New(),
+		CustomerID:  customerID,
+		EntryType:   constants.LoyaltyEntryReverse,
+		Points:      -points,
+		SaleOrderID: uuid.NullUUID{UUID: saleOrder.ID, Valid: true},
+		CreatedAt:   now,
+	})
+}
+
+// restoreRedemptions gives the points redeemed on an order back to the
+// customer they came from, as a new lot.
+func (s *LoyaltyService) restoreRedemptions(ctx context.Context, saleOrderID uuid.UUID, caller *dto.Caller) error {
+	orderPoints, err := s.loyaltyRepo.GetSaleOrderPoints(ctx, saleOrderID)
+	if err != nil {
+		return err
+	}
+
+	points, amount := orderPoints.NetRedeemed()
+	if points <= 0 || !orderPoints.RedeemCustomerID.Valid {
+		return nil
+	}
+	customerID := orderPoints.RedeemCustomerID.UUID
+
+	settings, err := s.loyaltyRepo.GetSettings(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := s.loyaltyRepo.LockCustomer(ctx, customerID); err != nil {
+		return err
+	}
+
+	var createdBy uuid.NullUUID
+	if caller != nil {
+		createdBy = uuid.NullUUID{UUID: caller.ID, Valid: true}
+	}
+
+	now := time.Now()
+	return s.loyaltyRepo.InsertEntry(ctx, &models.LoyaltyPointEntry{
+		ID:              uuid.New(),
+		CustomerID:      customerID,
+		EntryType:       constants.LoyaltyEntryRestore,
+		Points:          points,
+		RemainingPoints: points,
+		ExpiresAt:       lotExpiry(settings, now),
+		SaleOrderID:     uuid.NullUUID{UUID: saleOrderID, Valid: true},
+		Amount:          amount,
+		CreatedBy:       createdBy,
+		CreatedAt:       now,
+	})
+}
+
+// redeemedAmount is what points currently pay on an order.
+func (s *LoyaltyService) redeemedAmount(ctx context.Context, saleOrderID uuid.UUID) (money.Amount, error) {
+	orderPoints, err := s.loyaltyRepo.GetSaleOrderPoints(ctx, saleOrderID)
+	if err != nil {
+		return 0, err
+	}
+
+	_, amount := orderPoints.NetRedeemed()
+	return amount, nil
+}
+
+// redeem pays part of a draft order with the points of its customer. The
+// points cannot be worth more than due, what is left to pay on the order.
+func (s *LoyaltyService) redeem(ctx context.Context, caller dto.Caller, saleOrder *models.SaleOrder, points int64, due money.Amount) (*dto.LoyaltyRedemptionResponse, error) {
+	settings, err := s.loyaltyRepo.GetSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !settings.Enabled {
+		return nil, apperror.ErrLoyaltyDisabled
+	}
+
+	if !saleOrder.CustomerID.Valid || saleOrder.Currency != money.DefaultCurrency || points <= 0 || points < settings.MinRedeemPoints {
+		return nil, apperror.ErrInvalidRedemption
+	}
+	customerID := saleOrder.CustomerID.UUID
+
+	if err := s.loyaltyRepo.LockCustomer(ctx, customerID); err != nil {
+		return nil, err
+	}
+
+	orderPoints, err := s.loyaltyRepo.GetSaleOrderPoints(ctx, saleOrder.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	redeemedPoints, redeemedAmount := orderPoints.NetRedeemed()
+	if redeemedPoints > 0 && orderPoints.RedeemCustomerID.UUID != customerID {
+		return nil, apperror.ErrInvalidRedemption
+	}
+
+	amount := settings.PointValue * money.Amount(points)
+	if amount > due {
+		return nil, apperror.ErrInvalidRedemption
+	}
+
+	now := time.Now()
+	available, err := s.loyaltyRepo.GetAvailablePoints(ctx, customerID, now)
+	if err != nil {
+		return nil, err
+	}
+	if available < points {
+		return nil, apperror.ErrInsufficientPoints
+	}
+
+	if err := s.consume(ctx, customerID, points, now); err != nil {
+		return nil, err
+	}
+
+	err = s.loyaltyRepo.InsertEntry(ctx, &models.LoyaltyPointEntry{
+		ID:          uuid.New(),
+		CustomerID:  customerID,
+		EntryType:   constants.LoyaltyEntryRedeem,
+		Points:      -points,
+		SaleOrderID: uuid.NullUUID{UUID: saleOrder.ID, Valid: true},
+		Amount:      amount,
+		CreatedBy:   uuid.NullUUID{UUID: caller.ID, Valid: true},
+		CreatedAt:   now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.LoyaltyRedemptionResponse{
+		SaleOrderID:    saleOrder.ID,
+		PointsRedeemed: redeemedPoints + points,
+		RedeemedAmount: redeemedAmount + amount,
+		TotalAmount:    saleOrder.TotalAmount,
+		AmountDue:      due - amount,
+		PointsBalance:  available - points,
+	}, nil
+}
+
+// ExpirePoints writes off what is left of lots past their expiry date.
+func (s *LoyaltyService) ExpirePoints(ctx context.Context) error {
+	var expired int64
+	for {
+		var batch int
+		err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+			now := time.Now()
+			lots, err := s.loyaltyRepo.GetExpiredLots(ctx, now, loyaltyExpiryBatchSize)
+			if err != nil {
+				return err
+			}
+			batch = len(lots)
+
+			for _, lot := range lots {
+				if err := s.loyaltyRepo.SetRemainingPoints(ctx, lot.ID, 0); err != nil {
+					return err
+				}
+
+				err := s.loyaltyRepo.InsertEntry(ctx, &models.LoyaltyPointEntry{
+					ID:          uuid.New(),
+					CustomerID:  lot.CustomerID,
+					EntryType:   constants.LoyaltyEntryExpire,
+					Points:      -lot.RemainingPoints,
+					SaleOrderID: lot.SaleOrderID,
+					CreatedAt:   now,
+				})
+				if err != nil {
+					return err
+				}
+				expired += lot.RemainingPoints
+			}
+
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		if batch < loyaltyExpiryBatchSize {
+			break
+		}
+	}
+
+	if expired > 0 {
+		slog.InfoContext(ctx, "loyalty points expired", "points", expired)
+	}
+
+	return nil
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	apperror "github.com/hafiztri123/kki-be/internal/app_error"
+)
+
+const (
+	defaultOrderNumberFormat    = "{STORE}-{YYYYMMDD}-{SEQ:5}"
+	defaultOrderNumberStoreCode = "SO"
+	maxOrderNumberAttempts      = 5
+	maxOrderNumberSeqWidth      = 12
+)
+
+var (
+	orderNumberTokenPattern   = regexp.MustCompile(`\{([A-Z]+)(?::(\d+))?\}`)
+	orderNumberLiteralPattern = regexp.MustCompile(`^[A-Za-z0-9\-_/.]*$`)
+)
+
+// validateOrderNumberFormat checks a format such as "{STORE}-{YYYYMMDD}-{SEQ:5}".
+// Supported tokens are STORE, YYYY, YY, MM, DD, YYYYMM, YYYYMMDD and SEQ with an
+// optional zero padded width; SEQ must appear exactly once.
+func validateOrderNumberFormat(format string) error {
+	seqCount := 0
+	for _, match := range orderNumberTokenPattern.FindAllStringSubmatch(format, -1) {
+		switch match[1] {
+		case "STORE", "YYYY", "YY", "MM", "DD", "YYYYMM", "YYYYMMDD":
+			if match[2] != "" {
+				return apperror.ErrInvalidOrderNumberFormat
+			}
+		case "SEQ":
+			seqCount++
+			if match[2] != "" {
+				width, err := strconv.Atoi(match[2])
+				if err != nil || width < 1 || width > maxOrderNumberSeqWidth {
+					return apperror.ErrInvalidOrderNumberFormat
+				}
+			}
+		default:
+			return apperror.ErrInvalidOrderNumberFormat
+		}
+	}
+
+	literals := orderNumberTokenPattern.ReplaceAllString(format, "")
+	if seqCount != 1 || !orderNumberLiteralPattern.MatchString(literals) {
+		return apperror.ErrInvalidOrderNumberFormat
+	}
+
+	return nil
+}
+
+// orderNumberPeriod returns the counter period implied by the date tokens in
+// the format: a format with a day restarts every day, one with only a month
+// every month, and so on.
+func orderNumberPeriod(format string, t time.Time) string {
+	switch {
+	case strings.Contains(format, "{DD}") || strings.Contains(format, "{YYYYMMDD}"):
+		return t.Format("20060102")
+	case strings.Contains(format, "{MM}") || strings.Contains(format, "{YYYYMM}"):
+		return t.Format("200601")
+	case strings.Contains(format, "{YYYY}") || strings.Contains(format, "{YY}"):
+		return t.Format("2006")
+	default:
+		return "all"
+	}
+}
+
+func formatOrderNumber(format, storeCode string, t time.Time, seq int64) string {
+	return orderNumberTokenPattern.ReplaceAllStringFunc(format, func(token string) string {
+		match := orderNumberTokenPattern.FindStringSubmatch(token)
+		switch match[1] {
+		case "STORE":
+			return storeCode
+		case "YYYY":
+			return t.Format("2006")
+		case "YY":
+			return t.Format("06")
+		case "MM":
+			return t.Format("01")
+		case "DD":
+			return t.Format("02")
+		case "YYYYMM":
+			return t.Format("200601")
+		case "YYYYMMDD":
+			return t.Format("20060102")
+		case "SEQ":
+			if match[2] == "" {
+				return strconv.FormatInt(seq, 10)
+			}
+			width, _ := strconv.Atoi(match[2])
+			return fmt.Sprintf("%0*d", width, seq)
+		}
+		return token
+	})
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"slices"
+
+	"github.com/hafiztri123/kki-be/internal/dto"
+	"github.com/hafiztri123/kki-be/internal/models"
+	"github.com/hafiztri123/kki-be/internal/utils"
+)
+
+func newKeysetPage(cursor *dto.Cursor, limit int) models.KeysetPage {
+	page := models.KeysetPage{Limit: limit}
+	if cursor != nil {
+		page.Cursor = &models.Keyset{CreatedAt: cursor.CreatedAt, ID: cursor.ID}
+		page.Backward = cursor.Backward
+	}
+	return page
+}
+
+// trimKeysetPage drops the look-ahead row fetched by the repository, restores
+// newest-first order and builds the cursors of the neighbouring pages.
+func trimKeysetPage[T any](rows []T, page models.KeysetPage, key func(*T) models.Keyset) ([]T, dto.CursorPageInfo) {
+	hasMore := len(rows) > page.Limit
+	if hasMore {
+		rows = rows[:page.Limit]
+	}
+	if page.Backward {
+		slices.Reverse(rows)
+	}
+
+	var info dto.CursorPageInfo
+	if len(rows) == 0 {
+		return rows, info
+	}
+
+	// Walking forward there is a previous page whenever we started from a
+	// cursor; walking backward there is always a next page.
+	hasNext, hasPrev := hasMore, page.Cursor != nil
+	if page.Backward {
+		hasNext, hasPrev = true, hasMore
+	}
+
+	if hasNext {
+		last := key(&rows[len(rows)-1])
+		info.NextCursor = utils.EncodeCursor(dto.Cursor{CreatedAt: last.CreatedAt, ID: last.ID})
+	}
+	if hasPrev {
+		first := key(&rows[0])
+		info.PrevCursor = utils.EncodeCursor(dto.Cursor{CreatedAt: first.CreatedAt, ID: first.ID, Backward: true})
+	}
+
+	return rows, info
+}
@@ -0,0 +1,256 @@
+package service
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hafiztri123/kki-be/internal/constants"
+	"github.com/hafiztri123/kki-be/internal/models"
+	"github.com/hafiztri123/kki-be/internal/money"
+)
+
+type pricingResult struct {
+	Items          []models.SaleOrderItem
+	Promotions     []models.SaleOrderPromotion
+	Voucher        *models.SaleOrderVoucher
+	SubtotalAmount money.Amount
+	DiscountAmount money.Amount
+	TotalAmount    money.Amount
+}
+
+// applyPromotions prices the given lines and applies promotions in the order
+// they are passed. A non-stackable promotion is only applied when nothing else
+// has been applied yet, and once applied it stops further evaluation.
+func applyPromotions(items []models.SaleOrderItem, promotions []models.Promotion, now time.Time) pricingResult {
+	for i := range items {
+		items[i].SubtotalAmount = items[i].UnitPrice.Mul(items[i].Quantity)
+		items[i].DiscountAmount = 0
+	}
+
+	var applied []models.SaleOrderPromotion
+	exclusive := false
+
+	for _, p := range promotions {
+		if exclusive {
+			break
+		}
+
+		if !p.IsActive || now.Before(p.StartsAt) || !now.Before(p.EndsAt) {
+			continue
+		}
+
+		if p.UsageLimit.Valid && p.UsageCount >= int(p.UsageLimit.Int32) {
+			continue
+		}
+
+		if !p.Stackable && len(applied) > 0 {
+			continue
+		}
+
+		if remainingAmount(items, nil) < p.MinSpend {
+			continue
+		}
+
+		var discount money.Amount
+		switch p.Type {
+		case constants.PromotionTypeBuyXGetY:
+			discount = applyBuyXGetY(items, p)
+		case constants.PromotionTypePercentage:
+			discount = applyPercentage(items, p)
+		case constants.PromotionTypeFixedAmount:
+			discount = applyFixedAmount(items, p)
+		}
+
+		if discount <= 0 {
+			continue
+		}
+
+		applied = append(applied, models.SaleOrderPromotion{
+			ID:             uuid.New(),
+			PromotionID:    p.ID,
+			PromotionName:  p.Name,
+			DiscountAmount: discount,
+		})
+
+		if !p.Stackable {
+			exclusive = true
+		}
+	}
+
+	result := pricingResult{
+		Items:      items,
+		Promotions: applied,
+	}
+	result.sumLines()
+
+	return result
+}
+
+// sumLines nets each line of its discounts and totals the lines.
+func (r *pricingResult) sumLines() {
+	r.SubtotalAmount = 0
+	r.DiscountAmount = 0
+	for i := range r.Items {
+		r.Items[i].TotalAmount = r.Items[i].SubtotalAmount - r.Items[i].DiscountAmount
+		r.SubtotalAmount += r.Items[i].SubtotalAmount
+		r.DiscountAmount += r.Items[i].DiscountAmount
+	}
+
+	r.TotalAmount = r.SubtotalAmount - r.DiscountAmount
+}
+
+func promotionMatches(p models.Promotion, item models.SaleOrderItem) bool {
+	if p.ProductID.Valid {
+		return p.ProductID.UUID == item.ProductID
+	}
+
+	if p.Category.Valid {
+		return p.Category.String == item.Category
+	}
+
+	return true
+}
+
+func remainingAmount(items []models.SaleOrderItem, match func(models.SaleOrderItem) bool) money.Amount {
+	var total money.Amount
+	for _, item := range items {
+		if match == nil || match(item) {
+			total += item.SubtotalAmount - item.DiscountAmount
+		}
+	}
+	return total
+}
+
+// applyBuyXGetY gives away the cheapest matching units: for every buy+get
+// units of matching products, get units are free.
+func applyBuyXGetY(items []models.SaleOrderItem, p models.Promotion) money.Amount {
+	if !p.BuyQuantity.Valid || !p.GetQuantity.Valid || p.BuyQuantity.Int32 <= 0 || p.GetQuantity.Int32 <= 0 {
+		return 0
+	}
+
+	var matching []int
+	totalUnits := 0
+	for i, item := range items {
+		if promotionMatches(p, item) {
+			matching = append(matching, i)
+			totalUnits += item.Quantity
+		}
+	}
+
+	groupSize := int(p.BuyQuantity.Int32 + p.GetQuantity.Int32)
+	freeUnits := (totalUnits / groupSize) * int(p.GetQuantity.Int32)
+	if freeUnits == 0 {
+		return 0
+	}
+
+	sort.SliceStable(matching, func(a, b int) bool {
+		return items[matching[a]].UnitPrice < items[matching[b]].UnitPrice
+	})
+
+	var discount money.Amount
+	for _, i := range matching {
+		if freeUnits == 0 {
+			break
+		}
+
+		units := min(freeUnits, items[i].Quantity)
+		lineDiscount := money.Min(items[i].UnitPrice.Mul(units), items[i].SubtotalAmount-items[i].DiscountAmount)
+		items[i].DiscountAmount += lineDiscount
+		discount += lineDiscount
+		freeUnits -= units
+	}
+
+	return discount
+}
+
+func applyPercentage(items []models.SaleOrderItem, p models.Promotion) money.Amount {
+	if !p.Percentage.Valid || p.Percentage.Float64 <= 0 {
+		return 0
+	}
+
+	var discount money.Amount
+	for i := range items {
+		if !promotionMatches(p, items[i]) {
+			continue
+		}
+
+		remaining := items[i].SubtotalAmount - items[i].DiscountAmount
+		lineDiscount := remaining.Percent(p.Percentage.Float64, money.RoundHalfUp)
+		items[i].DiscountAmount += lineDiscount
+		discount += lineDiscount
+	}
+
+	return discount
+}
+
+// applyFixedAmount spreads a fixed discount over the matching lines in
+// proportion to what is left on each line, so line totals stay net of discounts.
+func applyFixedAmount(items []models.SaleOrderItem, p models.Promotion) money.Amount {
+	if !p.Amount.Valid || p.Amount.Amount <= 0 {
+		return 0
+	}
+
+	weights := make([]money.Amount, len(items))
+	for i := range items {
+		if promotionMatches(p, items[i]) {
+			weights[i] = items[i].SubtotalAmount - items[i].DiscountAmount
+		}
+	}
+
+	discount := money.Min(p.Amount.Amount, money.Sum(weights...))
+	if discount <= 0 {
+		return 0
+	}
+
+	shares := money.Allocate(discount, weights)
+	for i := range items {
+		items[i].DiscountAmount += shares[i]
+	}
+
+	return discount
+}
+
+// applyVoucher takes a voucher discount off what is left on the lines after
+// promotions, spread in proportion like a fixed amount promotion. Percentage
+// discounts are capped at the campaign's MaxDiscount. It gives nothing when
+// the lines come to less than the campaign's minimum spend.
+func applyVoucher(items []models.SaleOrderItem, c *models.VoucherCampaign) money.Amount {
+	weights := make([]money.Amount, len(items))
+	for i := range items {
+		weights[i] = items[i].SubtotalAmount - items[i].DiscountAmount
+	}
+
+	remaining := money.Sum(weights...)
+	if remaining <= 0 || remaining < c.MinSpend {
+		return 0
+	}
+
+	discount := voucherDiscount(c, remaining)
+	if discount <= 0 {
+		return 0
+	}
+
+	shares := money.Allocate(discount, weights)
+	for i := range items {
+		items[i].DiscountAmount += shares[i]
+	}
+
+	return discount
+}
+
+// voucherDiscount is what a campaign takes off spend, never more than spend.
+func voucherDiscount(c *models.VoucherCampaign, spend money.Amount) money.Amount {
+	var discount money.Amount
+	switch c.DiscountType {
+	case constants.VoucherDiscountPercentage:
+		discount = spend.Percent(c.Percentage.Float64, money.RoundHalfUp)
+		if c.MaxDiscount.Valid {
+			discount = money.Min(discount, c.MaxDiscount.Amount)
+		}
+	case constants.VoucherDiscountFixedAmount:
+		discount = c.Amount.Amount
+	}
+
+	return money.Max(money.Min(discount, spend), 0)
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	apperror "github.com/hafiztri123/kki-be/internal/app_error"
+	"github.com/hafiztri123/kki-be/internal/dto"
+	"github.com/hafiztri123/kki-be/internal/models"
+	"github.com/hafiztri123/kki-be/internal/repository"
+)
+
+type ProductService struct {
+	productRepo *repository.ProductRepository
+}
+
+func NewProductService(productRepo *repository.ProductRepository) *ProductService {
+	return &ProductService{
+		productRepo: productRepo,
+	}
+}
+
+func newProductResponse(p *models.Product) dto.ProductResponse {
+	return dto.ProductResponse{
+		ID:        p.ID,
+		SKU:       p.SKU,
+		Name:      p.Name,
+		Category:  p.Category,
+		UnitPrice: p.UnitPrice,
+		CreatedAt: p.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: p.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+func validateProductRequest(req *dto.CreateProductRequest) error {
+	if strings.TrimSpace(req.SKU) == "" || strings.TrimSpace(req.Name) == "" || strings.TrimSpace(req.Category) == "" || req.UnitPrice < 0 {
+		return apperror.ErrInvalidProduct
+	}
+	return nil
+}
+
+func (s *ProductService) CreateProduct(ctx context.Context, req *dto.CreateProductRequest) error {
+	if err := validateProductRequest(req); err != nil {
+		return err
+	}
+
+	return s.productRepo.InsertProduct(ctx, &models.Product{
+		ID:        uuid.New(),
+		SKU:       strings.TrimSpace(req.SKU),
+		Name:      strings.TrimSpace(req.Name),
+		Category:  strings.TrimSpace(req.Category),
+		UnitPrice: req.UnitPrice,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		DeletedAt: sql.NullTime{},
+	})
+}
+
+func (s *ProductService) GetProducts(ctx context.Context, limit, offset int) ([]dto.ProductResponse, int64, error) {
+	products, totalCount, err := s.productRepo.GetProducts(ctx, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	responses := make([]dto.ProductResponse, 0, len(products))
+	for i := range products {
+		responses = append(responses, newProductResponse(&products[i]))
+	}
+
+	return responses, totalCount, nil
+}
+
+func (s *ProductService) GetProductByID(ctx context.Context, id uuid.UUID) (*dto.ProductResponse, error) {
+	product, err := s.productRepo.GetProductByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := newProductResponse(product)
+	return &response, nil
+}
+
+func (s *ProductService) UpdateProduct(ctx context.Context, id uuid.UUID, req *dto.UpdateProductRequest) error {
+	if err := validateProductRequest(req); err != nil {
+		return err
+	}
+
+	product, err := s.productRepo.GetProductByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	product.SKU = strings.TrimSpace(req.SKU)
+	product.Name = strings.TrimSpace(req.Name)
+	product.Category = strings.TrimSpace(req.Category)
+	product.UnitPrice = req.UnitPrice
+	product.UpdatedAt = time.Now()
+
+	return s.productRepo.UpdateProduct(ctx, product)
+}
+
+func (s *ProductService) DeleteProduct(ctx context.Context, id uuid.UUID) error {
+	return s.productRepo.DeleteProduct(ctx, id)
+}
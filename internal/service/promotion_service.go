@@ -0,0 +1,239 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	apperror "github.com/hafiztri123/kki-be/internal/app_error"
+	"github.com/hafiztri123/kki-be/internal/constants"
+	"github.com/hafiztri123/kki-be/internal/dto"
+	"github.com/hafiztri123/kki-be/internal/models"
+	"github.com/hafiztri123/kki-be/internal/money"
+	"github.com/hafiztri123/kki-be/internal/repository"
+)
+
+type PromotionService struct {
+	promotionRepo *repository.PromotionRepository
+	productRepo   *repository.ProductRepository
+}
+
+func NewPromotionService(promotionRepo *repository.PromotionRepository, productRepo *repository.ProductRepository) *PromotionService {
+	return &PromotionService{
+		promotionRepo: promotionRepo,
+		productRepo:   productRepo,
+	}
+}
+
+func newPromotionResponse(p *models.Promotion) dto.PromotionResponse {
+	response := dto.PromotionResponse{
+		ID:         p.ID,
+		Name:       p.Name,
+		Type:       p.Type,
+		MinSpend:   p.MinSpend,
+		StartsAt:   p.StartsAt.Format(time.RFC3339),
+		EndsAt:     p.EndsAt.Format(time.RFC3339),
+		Priority:   p.Priority,
+		Stackable:  p.Stackable,
+		UsageCount: p.UsageCount,
+		IsActive:   p.IsActive,
+		CreatedAt:  p.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:  p.UpdatedAt.Format(time.RFC3339),
+	}
+
+	if p.ProductID.Valid {
+		response.ProductID = &p.ProductID.UUID
+	}
+	if p.Category.Valid {
+		response.Category = &p.Category.String
+	}
+	if p.BuyQuantity.Valid {
+		v := int(p.BuyQuantity.Int32)
+		response.BuyQuantity = &v
+	}
+	if p.GetQuantity.Valid {
+		v := int(p.GetQuantity.Int32)
+		response.GetQuantity = &v
+	}
+	if p.Percentage.Valid {
+		response.Percentage = &p.Percentage.Float64
+	}
+	if p.Amount.Valid {
+		response.Amount = &p.Amount.Amount
+	}
+	if p.UsageLimit.Valid {
+		v := int(p.UsageLimit.Int32)
+		response.UsageLimit = &v
+	}
+
+	return response
+}
+
+func nullInt32(v *int) sql.NullInt32 {
+	if v == nil {
+		return sql.NullInt32{}
+	}
+	return sql.NullInt32{Int32: int32(*v), Valid: true}
+}
+
+func nullFloat64(v *float64) sql.NullFloat64 {
+	if v == nil {
+		return sql.NullFloat64{}
+	}
+	return sql.NullFloat64{Float64: *v, Valid: true}
+}
+
+func nullString(v *string) sql.NullString {
+	if v == nil || strings.TrimSpace(*v) == "" {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: strings.TrimSpace(*v), Valid: true}
+}
+
+func nullUUID(v *uuid.UUID) uuid.NullUUID {
+	if v == nil || *v == uuid.Nil {
+		return uuid.NullUUID{}
+	}
+	return uuid.NullUUID{UUID: *v, Valid: true}
+}
+
+func boolOrDefault(v *bool, def bool) bool {
+	if v == nil {
+		return def
+	}
+	return *v
+}
+
+func (s *PromotionService) validatePromotionRequest(ctx context.Context, req *dto.CreatePromotionRequest) error {
+	if strings.TrimSpace(req.Name) == "" || req.StartsAt.IsZero() || !req.EndsAt.After(req.StartsAt) || req.MinSpend < 0 {
+		return apperror.ErrInvalidPromotion
+	}
+
+	if req.ProductID != nil && req.Category != nil {
+		return apperror.ErrInvalidPromotion
+	}
+
+	if req.UsageLimit != nil && *req.UsageLimit <= 0 {
+		return apperror.ErrInvalidPromotion
+	}
+
+	switch req.Type {
+	case constants.PromotionTypeBuyXGetY:
+		if req.BuyQuantity == nil || req.GetQuantity == nil || *req.BuyQuantity <= 0 || *req.GetQuantity <= 0 {
+			return apperror.ErrInvalidPromotion
+		}
+	case constants.PromotionTypePercentage:
+		if req.Percentage == nil || *req.Percentage <= 0 || *req.Percentage > 100 {
+			return apperror.ErrInvalidPromotion
+		}
+	case constants.PromotionTypeFixedAmount:
+		if req.Amount == nil || *req.Amount <= 0 {
+			return apperror.ErrInvalidPromotion
+		}
+	default:
+		return apperror.ErrInvalidPromotion
+	}
+
+	if req.ProductID != nil {
+		if _, err := s.productRepo.GetProductByID(ctx, *req.ProductID); err != nil {
+			if errors.Is(err, apperror.ErrNotFound) {
+				return apperror.ErrInvalidPromotion
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+func applyPromotionRequest(p *models.Promotion, req *dto.CreatePromotionRequest) {
+	p.Name = strings.TrimSpace(req.Name)
+	p.Type = req.Type
+	p.ProductID = nullUUID(req.ProductID)
+	p.Category = nullString(req.Category)
+	p.BuyQuantity = sql.NullInt32{}
+	p.GetQuantity = sql.NullInt32{}
+	p.Percentage = sql.NullFloat64{}
+	p.Amount = money.NullAmount{}
+
+	switch req.Type {
+	case constants.PromotionTypeBuyXGetY:
+		p.BuyQuantity = nullInt32(req.BuyQuantity)
+		p.GetQuantity = nullInt32(req.GetQuantity)
+	case constants.PromotionTypePercentage:
+		p.Percentage = nullFloat64(req.Percentage)
+	case constants.PromotionTypeFixedAmount:
+		p.Amount = money.NullAmount{Amount: *req.Amount, Valid: true}
+	}
+
+	p.MinSpend = req.MinSpend
+	p.StartsAt = req.StartsAt
+	p.EndsAt = req.EndsAt
+	p.Priority = req.Priority
+	p.Stackable = boolOrDefault(req.Stackable, true)
+	p.UsageLimit = nullInt32(req.UsageLimit)
+	p.IsActive = boolOrDefault(req.IsActive, true)
+}
+
+func (s *PromotionService) CreatePromotion(ctx context.Context, req *dto.CreatePromotionRequest) error {
+	if err := s.validatePromotionRequest(ctx, req); err != nil {
+		return err
+	}
+
+	promotion := &models.Promotion{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		DeletedAt: sql.NullTime{},
+	}
+	applyPromotionRequest(promotion, req)
+
+	return s.promotionRepo.InsertPromotion(ctx, promotion)
+}
+
+func (s *PromotionService) GetPromotions(ctx context.Context, limit, offset int) ([]dto.PromotionResponse, int64, error) {
+	promotions, totalCount, err := s.promotionRepo.GetPromotions(ctx, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	responses := make([]dto.PromotionResponse, 0, len(promotions))
+	for i := range promotions {
+		responses = append(responses, newPromotionResponse(&promotions[i]))
+	}
+
+	return responses, totalCount, nil
+}
+
+func (s *PromotionService) GetPromotionByID(ctx context.Context, id uuid.UUID) (*dto.PromotionResponse, error) {
+	promotion, err := s.promotionRepo.GetPromotionByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := newPromotionResponse(promotion)
+	return &response, nil
+}
+
+func (s *PromotionService) UpdatePromotion(ctx context.Context, id uuid.UUID, req *dto.UpdatePromotionRequest) error {
+	if err := s.validatePromotionRequest(ctx, req); err != nil {
+		return err
+	}
+
+	promotion, err := s.promotionRepo.GetPromotionByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	applyPromotionRequest(promotion, req)
+	promotion.UpdatedAt = time.Now()
+
+	return s.promotionRepo.UpdatePromotion(ctx, promotion)
+}
+
+func (s *PromotionService) DeletePromotion(ctx context.Context, id uuid.UUID) error {
+	return s.promotionRepo.DeletePromotion(ctx, id)
+}
@@ -0,0 +1,384 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	apperror "github.com/hafiztri123/kki-be/internal/app_error"
+	"github.com/hafiztri123/kki-be/internal/constants"
+	"github.com/hafiztri123/kki-be/internal/dto"
+	"github.com/hafiztri123/kki-be/internal/models"
+	"github.com/hafiztri123/kki-be/internal/repository"
+)
+
+const defaultQuotationValidity = 14 * 24 * time.Hour
+
+// QuotationService prices quotes with the sale order pricing rules and turns
+// accepted quotes into sale orders. Cashiers work on the quotes they created,
+// owners on every quote.
+type QuotationService struct {
+	transactor       *repository.Transactor
+	quotationRepo    *repository.QuotationRepository
+	productRepo      *repository.ProductRepository
+	saleOrderService *SaleOrderService
+}
+
+func NewQuotationService(
+	transactor *repository.Transactor,
+	quotationRepo *repository.QuotationRepository,
+	productRepo *repository.ProductRepository,
+	saleOrderService *SaleOrderService,
+) *QuotationService {
+	return &QuotationService{
+		transactor:       transactor,
+		quotationRepo:    quotationRepo,
+		productRepo:      productRepo,
+		saleOrderService: saleOrderService,
+	}
+}
+
+func newQuotationResponse(q *models.Quotation, items []models.QuotationItem) dto.QuotationResponse {
+	response := dto.QuotationResponse{
+		ID:                    q.ID,
+		CustomerName:          q.CustomerName,
+		StoreID:               storeIDPtr(q.StoreID),
+		Currency:              q.Currency,
+		PriceIncludesTax:      q.PriceIncludesTax,
+		TaxExempt:             q.TaxExempt,
+		TaxExemptionReference: q.TaxExemptionReference.String,
+		SubtotalAmount:        q.SubtotalAmount,
+		DiscountAmount:        q.DiscountAmount,
+		TaxAmount:             q.TaxAmount,
+		TotalAmount:           q.TotalAmount,
+		Status:                q.Status,
+		ValidUntil:            q.ValidUntil.Format(time.RFC3339),
+		SaleOrderID:           storeIDPtr(q.SaleOrderID),
+		CreatedBy:             q.CreatedBy,
+		CreatedAt:             q.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:             q.UpdatedAt.Format(time.RFC3339),
+	}
+
+	for _, item := range items {
+		response.Items = append(response.Items, dto.QuotationItemResponse{
+			ProductID:      item.ProductID,
+			ProductName:    item.ProductName,
+			Category:       item.Category,
+			Quantity:       item.Quantity,
+			UnitPrice:      item.UnitPrice,
+			SubtotalAmount: item.SubtotalAmount,
+			DiscountAmount: item.DiscountAmount,
+			TotalAmount:    item.TotalAmount,
+			TaxRate:        item.TaxRate,
+			TaxableAmount:  item.TaxableAmount,
+			TaxAmount:      item.TaxAmount,
+		})
+	}
+
+	return response
+}
+
+// priceQuote prices lines as a sale order of the given store would be priced
+// at now. The returned order is not stored; it only carries the totals.
+func (s *QuotationService) priceQuote(ctx context.Context, storeID uuid.NullUUID, taxExempt bool, reqItems []dto.SaleOrderItemRequest, now time.Time) (*models.SaleOrder, []models.SaleOrderItem, error) {
+	priced, err := s.saleOrderService.priceItems(ctx, uuid.Nil, reqItems, now, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	draft := &models.SaleOrder{
+		StoreID:        storeID,
+		TaxExempt:      taxExempt,
+		SubtotalAmount: priced.SubtotalAmount,
+		DiscountAmount: priced.DiscountAmount,
+	}
+
+	if err := s.saleOrderService.applyOrderTaxes(ctx, draft, priced.Items); err != nil {
+		return nil, nil, err
+	}
+
+	return draft, priced.Items, nil
+}
+
+func (s *QuotationService) CreateQuotation(ctx context.Context, caller dto.Caller, req *dto.CreateQuotationRequest) error {
+	now := time.Now()
+
+	validUntil := now.Add(defaultQuotationValidity)
+	if req.ValidUntil != nil {
+		validUntil = req.ValidUntil.In(time.Local)
+	}
+
+	if strings.TrimSpace(req.CustomerName) == "" || len(req.Items) == 0 || !validUntil.After(now) {
+		return apperror.ErrInvalidQuotation
+	}
+
+	if err := s.saleOrderService.checkOrderStore(ctx, caller, req.StoreID); err != nil {
+		return err
+	}
+
+	store, err := s.saleOrderService.resolveOrderStore(ctx, req.StoreID, caller.ID)
+	if err != nil {
+		return err
+	}
+
+	quotation := &models.Quotation{
+		ID:                    uuid.New(),
+		CustomerName:          strings.TrimSpace(req.CustomerName),
+		TaxExempt:             req.TaxExempt,
+		TaxExemptionReference: taxExemptionReference(req.TaxExempt, req.TaxExemptionReference),
+		Status:                constants.QuotationStatusSent,
+		ValidUntil:            validUntil,
+		CreatedBy:             caller.ID,
+		CreatedAt:             now,
+		UpdatedAt:             now,
+		DeletedAt:             sql.NullTime{},
+	}
+	if store != nil {
+		quotation.StoreID = uuid.NullUUID{UUID: store.ID, Valid: true}
+	}
+
+	draft, pricedItems, err := s.priceQuote(ctx, quotation.StoreID, quotation.TaxExempt, req.Items, now)
+	if err != nil {
+		return err
+	}
+
+	quotation.Currency = draft.Currency
+	quotation.PriceIncludesTax = draft.PriceIncludesTax
+	quotation.SubtotalAmount = draft.SubtotalAmount
+	quotation.DiscountAmount = draft.DiscountAmount
+	quotation.TaxAmount = draft.TaxAmount
+	quotation.TotalAmount = draft.TotalAmount
+
+	items := make([]models.QuotationItem, 0, len(pricedItems))
+	for _, item := range pricedItems {
+		items = append(items, models.QuotationItem{
+			ID:             uuid.New(),
+			QuotationID:    quotation.ID,
+			LineNumber:     item.LineNumber,
+			ProductID:      item.ProductID,
+			ProductName:    item.ProductName,
+			Category:       item.Category,
+			Quantity:       item.Quantity,
+			UnitPrice:      item.UnitPrice,
+			SubtotalAmount: item.SubtotalAmount,
+			DiscountAmount: item.DiscountAmount,
+			TotalAmount:    item.TotalAmount,
+			TaxRate:        item.TaxRate,
+			TaxableAmount:  item.TaxableAmount,
+			TaxAmount:      item.TaxAmount,
+			CreatedAt:      now,
+		})
+	}
+
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.quotationRepo.InsertQuotation(ctx, quotation); err != nil {
+			return err
+		}
+
+		return s.quotationRepo.InsertQuotationItems(ctx, items)
+	})
+}
+
+func (s *QuotationService) GetQuotations(ctx context.Context, caller dto.Caller, status string, limit, offset int) ([]dto.QuotationResponse, int64, error) {
+	var createdBy *uuid.UUID
+	if !isOwner(caller) {
+		createdBy = &caller.ID
+	}
+
+	quotations, totalCount, err := s.quotationRepo.GetQuotations(ctx, createdBy, status, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	responses := make([]dto.QuotationResponse, 0, len(quotations))
+	for i := range quotations {
+		responses = append(responses, newQuotationResponse(&quotations[i], nil))
+	}
+
+	return responses, totalCount, nil
+}
+
+// checkQuotationAccess hides quotations of other cashiers.
+func checkQuotationAccess(caller dto.Caller, quotation *models.Quotation) error {
+	if isOwner(caller) || quotation.CreatedBy == caller.ID {
+		return nil
+	}
+	return apperror.ErrNotFound
+}
+
+func (s *QuotationService) GetQuotationByID(ctx context.Context, caller dto.Caller, id uuid.UUID) (*dto.QuotationResponse, error) {
+	quotation, err := s.quotationRepo.GetQuotationByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkQuotationAccess(caller, quotation); err != nil {
+		return nil, err
+	}
+
+	items, err := s.quotationRepo.GetQuotationItems(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := newQuotationResponse(quotation, items)
+	return &response, nil
+}
+
+// UpdateQuotationStatus records whether the customer accepted or rejected a
+// sent quote. An expired quote can no longer be accepted.
+func (s *QuotationService) UpdateQuotationStatus(ctx context.Context, caller dto.Caller, id uuid.UUID, req *dto.UpdateQuotationStatusRequest) error {
+	if req.Status != constants.QuotationStatusAccepted && req.Status != constants.QuotationStatusRejected {
+		return apperror.ErrInvalidQuotationStatus
+	}
+
+	quotation, err := s.quotationRepo.GetQuotationByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := checkQuotationAccess(caller, quotation); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if quotation.Status != constants.QuotationStatusSent || (req.Status == constants.QuotationStatusAccepted && now.After(quotation.ValidUntil)) {
+		return apperror.ErrInvalidQuotationStatus
+	}
+
+	err = s.quotationRepo.UpdateQuotationStatus(ctx, id, constants.QuotationStatusSent, req.Status, now)
+	if errors.Is(err, apperror.ErrNotFound) {
+		// Answered or expired since it was read.
+		return apperror.ErrInvalidQuotationStatus
+	}
+
+	return err
+}
+
+// ConvertQuotation creates a draft sale order from an accepted quote. The
+// lines are priced again at today's prices and promotions, and a quoted
+// product that was removed from the catalog stops the conversion. When the
+// price differs from the quote, the order is only created if the caller
+// accepts the change; otherwise the returned response lists the differences
+// along with ErrQuotationPriceChanged.
+func (s *QuotationService) ConvertQuotation(ctx context.Context, caller dto.Caller, id uuid.UUID, req *dto.ConvertQuotationRequest) (*dto.ConvertQuotationResponse, error) {
+	var response *dto.ConvertQuotationResponse
+
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		quotation, err := s.quotationRepo.GetQuotationByIDForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if err := checkQuotationAccess(caller, quotation); err != nil {
+			return err
+		}
+
+		if quotation.SaleOrderID.Valid {
+			return apperror.ErrQuotationAlreadyConverted
+		}
+
+		if quotation.Status != constants.QuotationStatusAccepted {
+			return apperror.ErrQuotationNotAccepted
+		}
+
+		items, err := s.quotationRepo.GetQuotationItems(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		productIDs := make([]uuid.UUID, 0, len(items))
+		reqItems := make([]dto.SaleOrderItemRequest, 0, len(items))
+		for _, item := range items {
+			productIDs = append(productIDs, item.ProductID)
+			reqItems = append(reqItems, dto.SaleOrderItemRequest{ProductID: item.ProductID, Quantity: item.Quantity})
+		}
+
+		products, err := s.productRepo.GetProductsByIDs(ctx, productIDs)
+		if err != nil {
+			return err
+		}
+		for _, productID := range productIDs {
+			if _, ok := products[productID]; !ok {
+				return apperror.ErrQuotationProductUnavailable
+			}
+		}
+
+		now := time.Now()
+		current, currentItems, err := s.priceQuote(ctx, quotation.StoreID, quotation.TaxExempt, reqItems, now)
+		if err != nil {
+			return err
+		}
+
+		response = &dto.ConvertQuotationResponse{
+			QuotedTotalAmount: quotation.TotalAmount,
+			TotalAmount:       current.TotalAmount,
+			PriceChanges:      make([]dto.QuotationPriceChange, 0),
+		}
+		for i, item := range items {
+			if currentItems[i].UnitPrice != item.UnitPrice {
+				response.PriceChanges = append(response.PriceChanges, dto.QuotationPriceChange{
+					ProductID:        item.ProductID,
+					ProductName:      item.ProductName,
+					QuotedUnitPrice:  item.UnitPrice,
+					CurrentUnitPrice: currentItems[i].UnitPrice,
+				})
+			}
+		}
+
+		if current.TotalAmount != quotation.TotalAmount && !req.AcceptPriceChanges {
+			return apperror.ErrQuotationPriceChanged
+		}
+
+		saleOrder := &models.SaleOrder{
+			ID:          uuid.New(),
+			QuotationID: uuid.NullUUID{UUID: quotation.ID, Valid: true},
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+
+		err = s.saleOrderService.createSaleOrder(ctx, caller, &dto.CreateSaleOrderRequest{
+			CustomerName:          quotation.CustomerName,
+			StoreID:               storeIDPtr(quotation.StoreID),
+			TaxExempt:             quotation.TaxExempt,
+			TaxExemptionReference: quotation.TaxExemptionReference.String,
+			Items:                 reqItems,
+		}, saleOrder)
+		if err != nil {
+			return err
+		}
+
+		if err := s.quotationRepo.SetQuotationSaleOrder(ctx, quotation.ID, saleOrder.ID, now); err != nil {
+			return err
+		}
+
+		response.SaleOrderID = &saleOrder.ID
+		response.OrderNumber = saleOrder.OrderNumber
+		response.TotalAmount = saleOrder.TotalAmount
+		return nil
+	})
+
+	if err != nil && !errors.Is(err, apperror.ErrQuotationPriceChanged) {
+		return nil, err
+	}
+
+	return response, err
+}
+
+// ExpireQuotations marks sent quotations past their validity as expired.
+func (s *QuotationService) ExpireQuotations(ctx context.Context) error {
+	expired, err := s.quotationRepo.ExpireQuotations(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if expired > 0 {
+		slog.InfoContext(ctx, "quotations expired", "count", expired)
+	}
+
+	return nil
+}
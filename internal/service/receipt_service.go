@@ -0,0 +1,291 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	apperror "github.com/hafiztri123/kki-be/internal/app_error"
+	"github.com/hafiztri123/kki-be/internal/constants"
+	"github.com/hafiztri123/kki-be/internal/dto"
+	"github.com/hafiztri123/kki-be/internal/models"
+	"github.com/hafiztri123/kki-be/internal/money"
+	"github.com/hafiztri123/kki-be/internal/receipt"
+	"github.com/hafiztri123/kki-be/internal/repository"
+	"github.com/hafiztri123/kki-be/internal/utils"
+)
+
+// receiptCodeDigits is how much of a gift card code is printed; the rest is
+// masked so a thrown away receipt cannot be used to spend the card.
+const receiptCodeDigits = 4
+
+// ReceiptService builds the printable receipt of a sale order from the order,
+// its store settings and how it was paid.
+type ReceiptService struct {
+	saleOrderService *SaleOrderService
+	giftCardRepo     *repository.GiftCardRepository
+	accountRepo      *repository.CustomerAccountRepository
+}
+
+func NewReceiptService(saleOrderService *SaleOrderService, giftCardRepo *repository.GiftCardRepository, accountRepo *repository.CustomerAccountRepository) *ReceiptService {
+	return &ReceiptService{
+		saleOrderService: saleOrderService,
+		giftCardRepo:     giftCardRepo,
+		accountRepo:      accountRepo,
+	}
+}
+
+// GetReceipt returns the receipt of an order the caller may read. Payments
+// and change are only printed once the order is completed.
+func (s *ReceiptService) GetReceipt(ctx context.Context, caller dto.Caller, id uuid.UUID) (*receipt.Receipt, error) {
+	saleOrder, err := s.saleOrderService.getSaleOrderForCaller(ctx, caller, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.buildReceipt(ctx, saleOrder)
+}
+
+func (s *ReceiptService) buildReceipt(ctx context.Context, saleOrder *models.SaleOrder) (*receipt.Receipt, error) {
+	r := &receipt.Receipt{}
+
+	if saleOrder.StoreID.Valid {
+		store, err := s.saleOrderService.storeRepo.GetStoreByID(ctx, saleOrder.StoreID.UUID)
+		if err != nil && !errors.Is(err, apperror.ErrNotFound) {
+			return nil, err
+		}
+		if store != nil {
+			r.Title = store.Name
+			r.Header = receiptTextLines(store.Address)
+			r.Header = append(r.Header, receiptTextLines(store.ReceiptHeader.String)...)
+			r.Footer = receiptTextLines(store.ReceiptFooter.String)
+		}
+	}
+
+	if err := s.addDetails(ctx, r, saleOrder); err != nil {
+		return nil, err
+	}
+
+	items, err := s.saleOrderService.saleOrderRepo.GetSaleOrderItems(ctx, saleOrder.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range items {
+		r.Lines = append(r.Lines, receipt.Line{
+			Name:      item.ProductName,
+			Quantity:  item.Quantity,
+			UnitPrice: item.UnitPrice,
+			Amount:    item.SubtotalAmount,
+			Discount:  item.DiscountAmount,
+		})
+	}
+
+	if err := s.addTotals(ctx, r, saleOrder, items); err != nil {
+		return nil, err
+	}
+
+	switch saleOrder.Status {
+	case constants.SaleOrderStatusCompleted:
+		if err := s.addPayments(ctx, r, saleOrder); err != nil {
+			return nil, err
+		}
+	case constants.SaleOrderStatusCancelled:
+		r.Notice = "CANCELLED"
+	default:
+		r.Notice = "NOT PAID - NOT A RECEIPT"
+	}
+
+	r.QR = receiptVerificationURL(saleOrder.ID)
+	return r, nil
+}
+
+// receiptVerificationURL is the link the QR code on a receipt opens to check
+// it is genuine. Without PUBLIC_BASE_URL receipts are printed without one.
+func receiptVerificationURL(id uuid.UUID) string {
+	base := strings.TrimRight(utils.GetEnvOrDefault("PUBLIC_BASE_URL", ""), "/")
+	if base == "" {
+		return ""
+	}
+	return base + "/r/" + utils.GenerateReceiptToken(id)
+}
+
+// VerifyReceipt checks the token of a receipt QR code and returns the order it
+// was printed for. Forged tokens and deleted orders are ErrNotFound alike.
+func (s *ReceiptService) VerifyReceipt(ctx context.Context, token string) (*dto.ReceiptVerificationResponse, error) {
+	id, err := utils.ParseReceiptToken(token)
+	if err != nil {
+		return nil, apperror.ErrNotFound
+	}
+
+	saleOrder, err := s.saleOrderService.saleOrderRepo.GetSaleOrderByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.ReceiptVerificationResponse{
+		OrderNumber: saleOrder.OrderNumber,
+		Date:        saleOrder.CreatedAt.Format(time.RFC3339),
+		Currency:    saleOrder.Currency,
+		TotalAmount: saleOrder.TotalAmount,
+		Status:      saleOrder.Status,
+	}, nil
+}
+
+func (s *ReceiptService) addDetails(ctx context.Context, r *receipt.Receipt, saleOrder *models.SaleOrder) error {
+	r.Details = []receipt.Field{
+		{Label: "Order", Value: saleOrder.OrderNumber},
+		{Label: "Date", Value: saleOrder.CreatedAt.Format("2006-01-02 15:04")},
+	}
+
+	cashier, err := s.saleOrderService.userRepo.GetUserByID(ctx, saleOrder.CreatedBy.String())
+	if err != nil && !errors.Is(err, apperror.ErrNotFound) {
+		return err
+	}
+	if cashier != nil {
+		name := cashier.Name
+		if name == "" {
+			name = cashier.Username
+		}
+		r.Details = append(r.Details, receipt.Field{Label: "Cashier", Value: name})
+	}
+
+	if saleOrder.CustomerName != "" {
+		r.Details = append(r.Details, receipt.Field{Label: "Customer", Value: saleOrder.CustomerName})
+	}
+
+	return nil
+}
+
+// addTotals prints the discounts of the order above its total, and the tax
+// in it by rate. Item discounts are already on their lines.
+func (s *ReceiptService) addTotals(ctx context.Context, r *receipt.Receipt, saleOrder *models.SaleOrder, items []models.SaleOrderItem) error {
+	r.Totals = append(r.Totals, receipt.Entry{Label: "Subtotal", Amount: saleOrder.SubtotalAmount})
+
+	promotions, err := s.saleOrderService.saleOrderRepo.GetSaleOrderPromotions(ctx, saleOrder.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range promotions {
+		r.Totals = append(r.Totals, receipt.Entry{Label: p.PromotionName, Amount: -p.DiscountAmount})
+	}
+
+	voucher, err := s.saleOrderService.vouchers.appliedVoucher(ctx, saleOrder.ID)
+	if err != nil {
+		return err
+	}
+
+	if voucher != nil {
+		r.Totals = append(r.Totals, receipt.Entry{Label: "Voucher " + voucher.Code, Amount: -voucher.DiscountAmount})
+	}
+
+	label := "Tax"
+	if saleOrder.PriceIncludesTax {
+		label = "Incl. tax"
+	}
+	for _, tax := range newTaxBreakdown(items) {
+		if tax.TaxAmount == 0 {
+			continue
+		}
+		rate := strconv.FormatFloat(tax.Rate, 'f', -1, 64)
+		r.Totals = append(r.Totals, receipt.Entry{Label: fmt.Sprintf("%s %s%%", label, rate), Amount: tax.TaxAmount})
+	}
+
+	if saleOrder.TaxExempt {
+		label := "Tax exempt"
+		if saleOrder.TaxExemptionReference.Valid {
+			label += " " + saleOrder.TaxExemptionReference.String
+		}
+		r.Totals = append(r.Totals, receipt.Entry{Label: label})
+	}
+
+	r.Totals = append(r.Totals, receipt.Entry{Label: "TOTAL " + string(saleOrder.Currency), Amount: saleOrder.TotalAmount, Bold: true})
+	return nil
+}
+
+// addPayments prints what points, gift cards and the customer account paid,
+// and the cash for the rest with the change given.
+func (s *ReceiptService) addPayments(ctx context.Context, r *receipt.Receipt, saleOrder *models.SaleOrder) error {
+	points, err := s.saleOrderService.loyalty.redeemedAmount(ctx, saleOrder.ID)
+	if err != nil {
+		return err
+	}
+
+	if points > 0 {
+		r.Payments = append(r.Payments, receipt.Entry{Label: "Points", Amount: points})
+	}
+
+	tenders, err := s.giftCardRepo.GetSaleOrderTenders(ctx, saleOrder.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, tender := range tenders {
+		card, err := s.giftCardRepo.GetGiftCardByID(ctx, tender.GiftCardID)
+		if err != nil {
+			return err
+		}
+
+		label := "Gift card"
+		if card.CardType == constants.GiftCardTypeStoreCredit {
+			label = "Store credit"
+		}
+		r.Payments = append(r.Payments, receipt.Entry{Label: label + " " + maskCode(card.Code), Amount: tender.Amount})
+	}
+
+	due, err := s.saleOrderService.amountDue(ctx, saleOrder)
+	if err != nil {
+		return err
+	}
+
+	charged, err := s.accountRepo.GetSaleOrderCharge(ctx, saleOrder.ID)
+	if err != nil && !errors.Is(err, apperror.ErrNotFound) {
+		return err
+	}
+
+	if charged > 0 {
+		r.Payments = append(r.Payments, receipt.Entry{Label: "On account", Amount: charged})
+		due = money.Max(due-charged, 0)
+	}
+
+	cashDue := saleOrder.Currency.CashRound(due)
+	if rounding := cashDue - due; rounding != 0 {
+		r.Totals = append(r.Totals, receipt.Entry{Label: "Rounding", Amount: rounding})
+	}
+
+	tendered := cashDue
+	if saleOrder.CashTendered.Valid {
+		tendered = saleOrder.CashTendered.Amount
+	}
+
+	if tendered > 0 {
+		r.Payments = append(r.Payments, receipt.Entry{Label: "Cash", Amount: tendered})
+		r.Payments = append(r.Payments, receipt.Entry{Label: "Change", Amount: money.Max(tendered-cashDue, 0)})
+	}
+
+	return nil
+}
+
+// receiptTextLines splits a multi-line store setting, dropping blank lines.
+func receiptTextLines(text string) []string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// maskCode keeps the last receiptCodeDigits characters of a code.
+func maskCode(code string) string {
+	if len(code) <= receiptCodeDigits {
+		return code
+	}
+	return strings.Repeat("*", len(code)-receiptCodeDigits) + code[len(code)-receiptCodeDigits:]
+}
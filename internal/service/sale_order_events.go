@@ -0,0 +1,32 @@
+package service
+
+import (
+	"context"
+
+	"github.com/hafiztri123/kki-be/internal/constants"
+	"github.com/hafiztri123/kki-be/internal/dto"
+	"github.com/hafiztri123/kki-be/internal/models"
+)
+
+// recordSaleOrderEvent records that an order was created, changed or
+// deleted. The order is recorded without its lines.
+func (s *SaleOrderService) recordSaleOrderEvent(ctx context.Context, eventType string, saleOrder *models.SaleOrder) error {
+	return s.events.record(ctx, eventType, saleOrder.ID, newSaleOrderResponse(saleOrder))
+}
+
+// recordStatusChange records that an order moved from one status to another.
+// Deleting an order moves it to cancelled.
+func (s *SaleOrderService) recordStatusChange(ctx context.Context, saleOrder *models.SaleOrder, from, to string) error {
+	if from == to {
+		return nil
+	}
+
+	response := newSaleOrderResponse(saleOrder)
+	response.Status = to
+
+	return s.events.record(ctx, constants.EventSaleOrderStatusChanged, saleOrder.ID, dto.SaleOrderStatusChangedData{
+		SaleOrder:      response,
+		PreviousStatus: from,
+		Status:         to,
+	})
+}
@@ -0,0 +1,236 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	apperror "github.com/hafiztri123/kki-be/internal/app_error"
+	"github.com/hafiztri123/kki-be/internal/constants"
+	"github.com/hafiztri123/kki-be/internal/dto"
+	"github.com/hafiztri123/kki-be/internal/models"
+	"github.com/hafiztri123/kki-be/internal/utils"
+)
+
+const (
+	defaultHeldOrderTTL = 4 * time.Hour
+	maxHoldLabelLength  = 100
+	maxRegisterIDLength = 100
+)
+
+func heldOrderTTL() time.Duration {
+	ttl, err := time.ParseDuration(utils.GetEnvOrDefault("HELD_ORDER_TTL", ""))
+	if err != nil || ttl <= 0 {
+		return defaultHeldOrderTTL
+	}
+	return ttl
+}
+
+func (s *SaleOrderService) recordHoldEvent(ctx context.Context, saleOrder *models.SaleOrder, action string, actorID uuid.NullUUID, previousCashierID uuid.NullUUID) error {
+	return s.holdEventRepo.InsertHoldEvent(ctx, &models.SaleOrderHoldEvent{
+		ID:                uuid.New(),
+		SaleOrderID:       saleOrder.ID,
+		Action:            action,
+		ActorID:           actorID,
+		PreviousCashierID: previousCashierID,
+		RegisterID:        saleOrder.RegisterID,
+		CreatedAt:         time.Now(),
+	})
+}
+
+// HoldSaleOrder parks a draft at a register so the cashier can serve the next
+// customer.
+func (s *SaleOrderService) HoldSaleOrder(ctx context.Context, caller dto.Caller, id uuid.UUID, version int64, req *dto.HoldSaleOrderRequest) error {
+	registerID := strings.TrimSpace(req.RegisterID)
+	label := strings.TrimSpace(req.Label)
+	if registerID == "" || len(registerID) > maxRegisterIDLength || len(label) > maxHoldLabelLength {
+		return apperror.ErrInvalidHold
+	}
+
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		saleOrder, err := s.getSaleOrderForChange(ctx, caller, id)
+		if err != nil {
+			return err
+		}
+
+		if err := checkVersion(version, saleOrder.Version); err != nil {
+			return err
+		}
+
+		if saleOrder.Status != constants.SaleOrderStatusDraft {
+			return apperror.ErrSaleOrderNotDraft
+		}
+
+		now := time.Now()
+		previousStatus := saleOrder.Status
+		saleOrder.Status = constants.SaleOrderStatusHeld
+		saleOrder.HoldLabel = sql.NullString{String: label, Valid: label != ""}
+		saleOrder.RegisterID = sql.NullString{String: registerID, Valid: true}
+		saleOrder.HeldAt = sql.NullTime{Time: now, Valid: true}
+		saleOrder.UpdatedAt = now
+
+		if err := s.saveSaleOrder(ctx, saleOrder, previousStatus); err != nil {
+			return err
+		}
+
+		return s.recordHoldEvent(ctx, saleOrder, constants.HoldActionHold, uuid.NullUUID{UUID: caller.ID, Valid: true}, uuid.NullUUID{})
+	})
+}
+
+// ResumeSaleOrder brings a held order back as a draft of the cashier who held
+// it.
+func (s *SaleOrderService) ResumeSaleOrder(ctx context.Context, caller dto.Caller, id uuid.UUID, version int64, req *dto.ResumeSaleOrderRequest) error {
+	return s.unholdSaleOrder(ctx, caller, id, version, req, false)
+}
+
+// TakeOverSaleOrder resumes a held order as a draft of the caller, who need
+// not be the cashier that held it. The previous cashier is kept in the audit
+// trail.
+func (s *SaleOrderService) TakeOverSaleOrder(ctx context.Context, caller dto.Caller, id uuid.UUID, version int64, req *dto.ResumeSaleOrderRequest) error {
+	return s.unholdSaleOrder(ctx, caller, id, version, req, true)
+}
+
+func (s *SaleOrderService) unholdSaleOrder(ctx context.Context, caller dto.Caller, id uuid.UUID, version int64, req *dto.ResumeSaleOrderRequest, takeOver bool) error {
+	registerID := strings.TrimSpace(req.RegisterID)
+	if len(registerID) > maxRegisterIDLength {
+		return apperror.ErrInvalidHold
+	}
+
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		saleOrder, err := s.saleOrderRepo.GetSaleOrderByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		ok, err := s.canTakeOverSaleOrder(ctx, caller, saleOrder)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return apperror.ErrNotFound
+		}
+
+		if !takeOver && saleOrder.CreatedBy != caller.ID && !isOwner(caller) {
+			return apperror.ErrForbidden
+		}
+
+		if err := checkVersion(version, saleOrder.Version); err != nil {
+			return err
+		}
+
+		if saleOrder.Status != constants.SaleOrderStatusHeld {
+			return apperror.ErrSaleOrderNotHeld
+		}
+
+		action := constants.HoldActionResume
+		previousCashierID := uuid.NullUUID{}
+		if takeOver && saleOrder.CreatedBy != caller.ID {
+			action = constants.HoldActionTakeOver
+			previousCashierID = uuid.NullUUID{UUID: saleOrder.CreatedBy, Valid: true}
+			saleOrder.CreatedBy = caller.ID
+		}
+
+		previousStatus := saleOrder.Status
+		saleOrder.Status = constants.SaleOrderStatusDraft
+		saleOrder.HoldLabel = sql.NullString{}
+		saleOrder.HeldAt = sql.NullTime{}
+		if registerID != "" {
+			saleOrder.RegisterID = sql.NullString{String: registerID, Valid: true}
+		}
+		saleOrder.UpdatedAt = time.Now()
+
+		if err := s.saveSaleOrder(ctx, saleOrder, previousStatus); err != nil {
+			return err
+		}
+
+		return s.recordHoldEvent(ctx, saleOrder, action, uuid.NullUUID{UUID: caller.ID, Valid: true}, previousCashierID)
+	})
+}
+
+// GetHeldSaleOrders lists the orders parked at registerID, or at every
+// register when it is empty. Owners may narrow the list to a store.
+func (s *SaleOrderService) GetHeldSaleOrders(ctx context.Context, caller dto.Caller, registerID string, storeID *uuid.UUID) ([]dto.SaleOrderResponse, error) {
+	filter := models.SaleOrderFilter{
+		RegisterID: strings.TrimSpace(registerID),
+		StoreID:    storeID,
+	}
+
+	if err := s.scopeHeldOrderFilter(ctx, caller, &filter); err != nil {
+		return nil, err
+	}
+
+	saleOrders, err := s.saleOrderRepo.GetHeldSaleOrders(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.SaleOrderResponse, 0, len(saleOrders))
+	for i := range saleOrders {
+		responses = append(responses, newSaleOrderResponse(&saleOrders[i]))
+	}
+
+	return responses, nil
+}
+
+func (s *SaleOrderService) GetSaleOrderHoldEvents(ctx context.Context, caller dto.Caller, id uuid.UUID) ([]dto.SaleOrderHoldEventResponse, error) {
+	if _, err := s.getSaleOrderForCaller(ctx, caller, id); err != nil {
+		return nil, err
+	}
+
+	events, err := s.holdEventRepo.GetHoldEvents(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.SaleOrderHoldEventResponse, 0, len(events))
+	for _, e := range events {
+		responses = append(responses, dto.SaleOrderHoldEventResponse{
+			Action:            e.Action,
+			ActorID:           storeIDPtr(e.ActorID),
+			PreviousCashierID: storeIDPtr(e.PreviousCashierID),
+			RegisterID:        e.RegisterID.String,
+			CreatedAt:         e.CreatedAt.Format(time.RFC3339),
+		})
+	}
+
+	return responses, nil
+}
+
+// ExpireHeldOrders cancels orders held for longer than HELD_ORDER_TTL. An
+// order resumed while the job runs keeps its new state.
+func (s *SaleOrderService) ExpireHeldOrders(ctx context.Context) error {
+	saleOrders, err := s.saleOrderRepo.GetHeldSaleOrdersBefore(ctx, time.Now().Add(-heldOrderTTL()))
+	if err != nil {
+		return err
+	}
+
+	for i := range saleOrders {
+		saleOrder := &saleOrders[i]
+
+		err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+			previousStatus := saleOrder.Status
+			saleOrder.Status = constants.SaleOrderStatusCancelled
+			saleOrder.UpdatedAt = time.Now()
+
+			if err := s.saveSaleOrder(ctx, saleOrder, previousStatus); err != nil {
+				return err
+			}
+
+			return s.recordHoldEvent(ctx, saleOrder, constants.HoldActionExpire, uuid.NullUUID{}, uuid.NullUUID{})
+		})
+		if err != nil {
+			if errors.Is(err, apperror.ErrVersionMismatch) || errors.Is(err, apperror.ErrNotFound) {
+				continue
+			}
+			return err
+		}
+
+		slog.InfoContext(ctx, "held order expired", "sale_order_id", saleOrder.ID.String())
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hafiztri123/kki-be/internal/dto"
+)
+
+// RedeemPoints pays part of a draft with the loyalty points of its customer.
+// Redeeming again adds to what is already redeemed on the order. The order
+// version is bumped so a stale client cannot complete it unaware.
+func (s *SaleOrderService) RedeemPoints(ctx context.Context, caller dto.Caller, id uuid.UUID, version int64, req *dto.RedeemPointsRequest) (*dto.LoyaltyRedemptionResponse, error) {
+	var response *dto.LoyaltyRedemptionResponse
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		saleOrder, err := s.getDraftForRedemption(ctx, caller, id, version)
+		if err != nil {
+			return err
+		}
+
+		due, err := s.amountDue(ctx, saleOrder)
+		if err != nil {
+			return err
+		}
+
+		response, err = s.loyalty.redeem(ctx, caller, saleOrder, req.Points, due)
+		if err != nil {
+			return err
+		}
+
+		saleOrder.UpdatedAt = time.Now()
+		return s.saveSaleOrder(ctx, saleOrder, saleOrder.Status)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// ReleasePointsRedemption gives the points redeemed on a draft back to the
+// customer.
+func (s *SaleOrderService) ReleasePointsRedemption(ctx context.Context, caller dto.Caller, id uuid.UUID, version int64) error {
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		saleOrder, err := s.getDraftForRedemption(ctx, caller, id, version)
+		if err != nil {
+			return err
+		}
+
+		if err := s.loyalty.restoreRedemptions(ctx, saleOrder.ID, &caller); err != nil {
+			return err
+		}
+
+		saleOrder.UpdatedAt = time.Now()
+		return s.saveSaleOrder(ctx, saleOrder, saleOrder.Status)
+	})
+}
@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	apperror "github.com/hafiztri123/kki-be/internal/app_error"
+	"github.com/hafiztri123/kki-be/internal/constants"
+	"github.com/hafiztri123/kki-be/internal/dto"
+	"github.com/hafiztri123/kki-be/internal/models"
+	"github.com/hafiztri123/kki-be/internal/utils"
+)
+
+// Sale order access rules:
+//   - owners can read and change every order;
+//   - cashiers read the orders they created, or every order of their store when
+//     CASHIER_ORDER_SCOPE is "store";
+//   - cashiers change only their own orders and only while they are drafts.
+//
+// Orders a caller may not read are reported as not found so their existence
+// does not leak.
+
+func isOwner(caller dto.Caller) bool {
+	return caller.Role == constants.RoleOwner
+}
+
+func cashierOrderScope() string {
+	return utils.GetEnvOrDefault("CASHIER_ORDER_SCOPE", constants.CashierOrderScopeOwn)
+}
+
+// callerStoreID returns the store a cashier is assigned to, if any.
+func (s *SaleOrderService) callerStoreID(ctx context.Context, caller dto.Caller) (uuid.NullUUID, error) {
+	user, err := s.userRepo.GetUserByID(ctx, caller.ID.String())
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return uuid.NullUUID{}, nil
+		}
+		return uuid.NullUUID{}, err
+	}
+
+	return user.StoreID, nil
+}
+
+// scopeSaleOrderFilter restricts a listing to the orders the caller may read.
+func (s *SaleOrderService) scopeSaleOrderFilter(ctx context.Context, caller dto.Caller, filter *models.SaleOrderFilter) error {
+	if isOwner(caller) {
+		return nil
+	}
+
+	if cashierOrderScope() == constants.CashierOrderScopeStore {
+		storeID, err := s.callerStoreID(ctx, caller)
+		if err != nil {
+			return err
+		}
+		if storeID.Valid {
+			filter.StoreID = &storeID.UUID
+			return nil
+		}
+	}
+
+	filter.CreatedBy = &caller.ID
+	return nil
+}
+
+func (s *SaleOrderService) canReadSaleOrder(ctx context.Context, caller dto.Caller, saleOrder *models.SaleOrder) (bool, error) {
+	if isOwner(caller) || saleOrder.CreatedBy == caller.ID {
+		return true, nil
+	}
+
+	if cashierOrderScope() != constants.CashierOrderScopeStore || !saleOrder.StoreID.Valid {
+		return false, nil
+	}
+
+	storeID, err := s.callerStoreID(ctx, caller)
+	if err != nil {
+		return false, err
+	}
+
+	return storeID.Valid && storeID.UUID == saleOrder.StoreID.UUID, nil
+}
+
+// getSaleOrderForCaller loads an order the caller may read.
+func (s *SaleOrderService) getSaleOrderForCaller(ctx context.Context, caller dto.Caller, id uuid.UUID) (*models.SaleOrder, error) {
+	saleOrder, err := s.saleOrderRepo.GetSaleOrderByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	ok, err := s.canReadSaleOrder(ctx, caller, saleOrder)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, apperror.ErrNotFound
+	}
+
+	return saleOrder, nil
+}
+
+// getSaleOrderForChange loads an order the caller may update or delete.
+func (s *SaleOrderService) getSaleOrderForChange(ctx context.Context, caller dto.Caller, id uuid.UUID) (*models.SaleOrder, error) {
+	saleOrder, err := s.getSaleOrderForCaller(ctx, caller, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if isOwner(caller) {
+		return saleOrder, nil
+	}
+
+	if saleOrder.CreatedBy != caller.ID || saleOrder.Status != constants.SaleOrderStatusDraft {
+		return nil, apperror.ErrForbidden
+	}
+
+	return saleOrder, nil
+}
+
+// checkOrderStore stops a cashier assigned to a store from creating orders
+// for another store.
+func (s *SaleOrderService) checkOrderStore(ctx context.Context, caller dto.Caller, storeID *uuid.UUID) error {
+	if isOwner(caller) || storeID == nil {
+		return nil
+	}
+
+	callerStore, err := s.callerStoreID(ctx, caller)
+	if err != nil {
+		return err
+	}
+
+	if callerStore.Valid && callerStore.UUID != *storeID {
+		return apperror.ErrForbidden
+	}
+
+	return nil
+}
+
+// Held orders are shared at the register: a cashier sees and can take over
+// every held order of their store, or only their own when they have no store.
+
+// scopeHeldOrderFilter restricts the held order list to the orders the caller
+// may resume or take over.
+func (s *SaleOrderService) scopeHeldOrderFilter(ctx context.Context, caller dto.Caller, filter *models.SaleOrderFilter) error {
+	if isOwner(caller) {
+		return nil
+	}
+
+	storeID, err := s.callerStoreID(ctx, caller)
+	if err != nil {
+		return err
+	}
+
+	if storeID.Valid {
+		filter.StoreID = &storeID.UUID
+		return nil
+	}
+
+	filter.CreatedBy = &caller.ID
+	return nil
+}
+
+func (s *SaleOrderService) canTakeOverSaleOrder(ctx context.Context, caller dto.Caller, saleOrder *models.SaleOrder) (bool, error) {
+	if isOwner(caller) || saleOrder.CreatedBy == caller.ID {
+		return true, nil
+	}
+
+	storeID, err := s.callerStoreID(ctx, caller)
+	if err != nil {
+		return false, err
+	}
+
+	return storeID.Valid && saleOrder.StoreID.Valid && storeID.UUID == saleOrder.StoreID.UUID, nil
+}
+
+// saleOrderReader returns whether the caller may read an order created by
+// createdBy for storeID, by the same rules as canReadSaleOrder. The caller's
+// store is looked up once, for checking many orders such as a live feed.
+func (s *SaleOrderService) saleOrderReader(ctx context.Context, caller dto.Caller) (func(createdBy uuid.UUID, storeID *uuid.UUID) bool, error) {
+	if isOwner(caller) {
+		return func(uuid.UUID, *uuid.UUID) bool { return true }, nil
+	}
+
+	var callerStore uuid.NullUUID
+	if cashierOrderScope() == constants.CashierOrderScopeStore {
+		var err error
+		callerStore, err = s.callerStoreID(ctx, caller)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return func(createdBy uuid.UUID, storeID *uuid.UUID) bool {
+		if createdBy == caller.ID {
+			return true
+		}
+		return callerStore.Valid && storeID != nil && *storeID == callerStore.UUID
+	}, nil
+}
@@ -0,0 +1,282 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	apperror "github.com/hafiztri123/kki-be/internal/app_error"
+	"github.com/hafiztri123/kki-be/internal/constants"
+	"github.com/hafiztri123/kki-be/internal/dto"
+	"github.com/hafiztri123/kki-be/internal/models"
+)
+
+// Fields that change on every update and would only add noise to a diff.
+var revisionDiffIgnored = map[string]bool{
+	"updated_at": true,
+	"version":    true,
+}
+
+// Array fields of the order response whose elements are matched by a key
+// rather than by position, so reordering lines is not reported as a change.
+var revisionDiffKeys = map[string]string{
+	"items":      "product_id",
+	"promotions": "promotion_id",
+	"taxes":      "rate",
+}
+
+// saveRevision stores the current state of saleOrder, before it is changed,
+// as the revision numbered after its version.
+func (s *SaleOrderService) saveRevision(ctx context.Context, caller dto.Caller, saleOrder *models.SaleOrder) error {
+	snapshot, err := s.snapshotSaleOrder(ctx, saleOrder)
+	if err != nil {
+		return err
+	}
+
+	return s.revisionRepo.InsertRevision(ctx, &models.SaleOrderRevision{
+		ID:             uuid.New(),
+		SaleOrderID:    saleOrder.ID,
+		RevisionNumber: saleOrder.Version,
+		Snapshot:       *snapshot,
+		ChangedBy:      caller.ID,
+		CreatedAt:      time.Now(),
+	})
+}
+
+func (s *SaleOrderService) snapshotSaleOrder(ctx context.Context, saleOrder *models.SaleOrder) (*models.SaleOrderSnapshot, error) {
+	items, err := s.saleOrderRepo.GetSaleOrderItems(ctx, saleOrder.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	promotions, err := s.saleOrderRepo.GetSaleOrderPromotions(ctx, saleOrder.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	voucher, err := s.vouchers.appliedVoucher(ctx, saleOrder.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.SaleOrderSnapshot{
+		Order:      *saleOrder,
+		Items:      items,
+		Promotions: promotions,
+		Voucher:    voucher,
+	}, nil
+}
+
+func (s *SaleOrderService) GetSaleOrderRevisions(ctx context.Context, caller dto.Caller, id uuid.UUID) ([]dto.SaleOrderRevisionResponse, error) {
+	if _, err := s.getSaleOrderForCaller(ctx, caller, id); err != nil {
+		return nil, err
+	}
+
+	revisions, err := s.revisionRepo.GetRevisions(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.SaleOrderRevisionResponse, 0, len(revisions))
+	for _, rev := range revisions {
+		responses = append(responses, dto.SaleOrderRevisionResponse{
+			RevisionNumber: rev.RevisionNumber,
+			ChangedBy:      rev.ChangedBy,
+			CreatedAt:      rev.CreatedAt.Format(time.RFC3339),
+		})
+	}
+
+	return responses, nil
+}
+
+// DiffSaleOrderRevision lists the fields that changed between revision n and
+// the state that replaced it: the next revision, or the current order when n
+// is the latest one.
+func (s *SaleOrderService) DiffSaleOrderRevision(ctx context.Context, caller dto.Caller, id uuid.UUID, n int64) (*dto.SaleOrderRevisionDiffResponse, error) {
+	saleOrder, err := s.getSaleOrderForCaller(ctx, caller, id)
+	if err != nil {
+		return nil, err
+	}
+
+	from, err := s.revisionRepo.GetRevision(ctx, id, n)
+	if err != nil {
+		return nil, err
+	}
+
+	var to *models.SaleOrderSnapshot
+	toRevision := saleOrder.Version
+
+	next, err := s.revisionRepo.GetNextRevision(ctx, id, n)
+	switch {
+	case err == nil:
+		to = &next.Snapshot
+		toRevision = next.RevisionNumber
+	case errors.Is(err, apperror.ErrNotFound):
+		to, err = s.snapshotSaleOrder(ctx, saleOrder)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, err
+	}
+
+	oldFields, err := flattenSnapshot(&from.Snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	newFields, err := flattenSnapshot(to)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.SaleOrderRevisionDiffResponse{
+		FromRevision: n,
+		ToRevision:   toRevision,
+		Changes:      diffFields(oldFields, newFields),
+	}, nil
+}
+
+// RestoreSaleOrderRevision puts a draft order back to the lines, promotions,
+// voucher and totals of revision n. The order stays a draft, and its current state is
+// kept as a revision of its own so the restore can be undone.
+func (s *SaleOrderService) RestoreSaleOrderRevision(ctx context.Context, caller dto.Caller, id uuid.UUID, n int64, version int64) error {
+	if !isOwner(caller) {
+		return apperror.ErrForbidden
+	}
+
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		saleOrder, err := s.getSaleOrderForChange(ctx, caller, id)
+		if err != nil {
+			return err
+		}
+
+		if err := checkVersion(version, saleOrder.Version); err != nil {
+			return err
+		}
+
+		if saleOrder.Status != constants.SaleOrderStatusDraft {
+			return apperror.ErrSaleOrderNotDraft
+		}
+
+		rev, err := s.revisionRepo.GetRevision(ctx, id, n)
+		if err != nil {
+			return err
+		}
+
+		if err := s.saveRevision(ctx, caller, saleOrder); err != nil {
+			return err
+		}
+
+		if err := s.releasePricing(ctx, id); err != nil {
+			return err
+		}
+
+		if err := s.savePricing(ctx, &pricingResult{
+			Items:      rev.Snapshot.Items,
+			Promotions: rev.Snapshot.Promotions,
+			Voucher:    rev.Snapshot.Voucher,
+		}); err != nil {
+			return err
+		}
+
+		restored := rev.Snapshot.Order
+		saleOrder.CustomerName = restored.CustomerName
+		saleOrder.CustomerID = restored.CustomerID
+		saleOrder.TaxExempt = restored.TaxExempt
+		saleOrder.TaxExemptionReference = restored.TaxExemptionReference
+		saleOrder.SubtotalAmount = restored.SubtotalAmount
+		saleOrder.DiscountAmount = restored.DiscountAmount
+		saleOrder.TaxAmount = restored.TaxAmount
+		saleOrder.TotalAmount = restored.TotalAmount
+		saleOrder.UpdatedAt = time.Now()
+
+		return s.saveSaleOrder(ctx, saleOrder, saleOrder.Status)
+	})
+}
+
+// flattenSnapshot renders a snapshot the way GET /sale-orders/{id} does and
+// flattens it into dotted field paths.
+func flattenSnapshot(snapshot *models.SaleOrderSnapshot) (map[string]any, error) {
+	response := newSaleOrderDetailResponse(&snapshot.Order, snapshot.Items, snapshot.Promotions, snapshot.Voucher)
+
+	raw, err := json.Marshal(response)
+	if err != nil {
+		return nil, err
+	}
+
+	var tree map[string]any
+	if err := json.Unmarshal(raw, &tree); err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]any)
+	flattenValue(fields, "", tree)
+	return fields, nil
+}
+
+func flattenValue(fields map[string]any, path string, value any) {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, child := range v {
+			if revisionDiffIgnored[key] || (path != "" && key == "id") {
+				continue
+			}
+			flattenValue(fields, joinFieldPath(path, key), child)
+		}
+	case []any:
+		keyField := revisionDiffKeys[path]
+		for i, child := range v {
+			key := fmt.Sprint(i)
+			if obj, ok := child.(map[string]any); ok && keyField != "" {
+				if k, ok := obj[keyField]; ok {
+					key = fmt.Sprint(k)
+				}
+			}
+			flattenValue(fields, fmt.Sprintf("%s[%s]", path, key), child)
+		}
+	default:
+		fields[path] = v
+	}
+}
+
+func joinFieldPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func diffFields(oldFields, newFields map[string]any) []dto.FieldChange {
+	paths := make(map[string]struct{}, len(oldFields)+len(newFields))
+	for path := range oldFields {
+		paths[path] = struct{}{}
+	}
+	for path := range newFields {
+		paths[path] = struct{}{}
+	}
+
+	changes := make([]dto.FieldChange, 0)
+	for path := range paths {
+		oldValue, newValue := oldFields[path], newFields[path]
+		if reflect.DeepEqual(oldValue, newValue) {
+			continue
+		}
+		changes = append(changes, dto.FieldChange{
+			Field:    path,
+			OldValue: oldValue,
+			NewValue: newValue,
+		})
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Field < changes[j].Field
+	})
+
+	return changes
+}
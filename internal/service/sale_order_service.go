@@ -3,98 +3,782 @@ package service
 import (
 	"context"
 	"database/sql"
-	"fmt"
+	"errors"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	apperror "github.com/hafiztri123/kki-be/internal/app_error"
+	"github.com/hafiztri123/kki-be/internal/constants"
 	"github.com/hafiztri123/kki-be/internal/dto"
 	"github.com/hafiztri123/kki-be/internal/models"
+	"github.com/hafiztri123/kki-be/internal/money"
 	"github.com/hafiztri123/kki-be/internal/repository"
+	"github.com/hafiztri123/kki-be/internal/utils"
 )
 
 type SaleOrderService struct {
+	transactor    *repository.Transactor
 	saleOrderRepo *repository.SaleOrderRepository
+	productRepo   *repository.ProductRepository
+	promotionRepo *repository.PromotionRepository
+	userRepo      *repository.UserRepository
+	storeRepo     *repository.StoreRepository
+	taxRateRepo   *repository.TaxRateRepository
+	orderNumRepo  *repository.OrderNumberRepository
+	revisionRepo  *repository.SaleOrderRevisionRepository
+	holdEventRepo *repository.SaleOrderHoldEventRepository
+	customerRepo  *repository.CustomerRepository
+	accountRepo   *repository.CustomerAccountRepository
+	loyalty       *LoyaltyService
+	giftCards     *GiftCardService
+	vouchers      *VoucherService
+	events        *EventService
 }
 
-func NewSaleOrderService(saleOrderRepo *repository.SaleOrderRepository) *SaleOrderService {
+func NewSaleOrderService(
+	transactor *repository.Transactor,
+	saleOrderRepo *repository.SaleOrderRepository,
+	productRepo *repository.ProductRepository,
+	promotionRepo *repository.PromotionRepository,
+	userRepo *repository.UserRepository,
+	storeRepo *repository.StoreRepository,
+	taxRateRepo *repository.TaxRateRepository,
+	orderNumRepo *repository.OrderNumberRepository,
+	revisionRepo *repository.SaleOrderRevisionRepository,
+	holdEventRepo *repository.SaleOrderHoldEventRepository,
+	customerRepo *repository.CustomerRepository,
+	accountRepo *repository.CustomerAccountRepository,
+	loyalty *LoyaltyService,
+	giftCards *GiftCardService,
+	vouchers *VoucherService,
+	events *EventService,
+) *SaleOrderService {
 	return &SaleOrderService{
+		transactor:    transactor,
 		saleOrderRepo: saleOrderRepo,
+		productRepo:   productRepo,
+		promotionRepo: promotionRepo,
+		userRepo:      userRepo,
+		storeRepo:     storeRepo,
+		taxRateRepo:   taxRateRepo,
+		orderNumRepo:  orderNumRepo,
+		revisionRepo:  revisionRepo,
+		holdEventRepo: holdEventRepo,
+		customerRepo:  customerRepo,
+		accountRepo:   accountRepo,
+		loyalty:       loyalty,
+		giftCards:     giftCards,
+		vouchers:      vouchers,
+		events:        events,
 	}
 }
 
-func (s *SaleOrderService) CreateSaleOrder(ctx context.Context, req *dto.CreateSaleOrderRequest, createdBy uuid.UUID) error {
-	orderNumber := fmt.Sprintf("SO-%d", time.Now().Unix())
+func newSaleOrderResponse(so *models.SaleOrder) dto.SaleOrderResponse {
+	response := dto.SaleOrderResponse{
+		ID:                    so.ID,
+		OrderNumber:           so.OrderNumber,
+		CustomerName:          so.CustomerName,
+		CustomerID:            storeIDPtr(so.CustomerID),
+		StoreID:               storeIDPtr(so.StoreID),
+		Currency:              so.Currency,
+		PriceIncludesTax:      so.PriceIncludesTax,
+		TaxExempt:             so.TaxExempt,
+		TaxExemptionReference: so.TaxExemptionReference.String,
+		SubtotalAmount:        so.SubtotalAmount,
+		DiscountAmount:        so.DiscountAmount,
+		TaxAmount:             so.TaxAmount,
+		TotalAmount:           so.TotalAmount,
+		CashTotalAmount:       so.Currency.CashRound(so.TotalAmount),
+		Status:                so.Status,
+		CreatedBy:             so.CreatedBy,
+		CreatedAt:             so.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:             so.UpdatedAt.Format(time.RFC3339),
+		Version:               so.Version,
+		DeviceID:              so.DeviceID.String,
+		SyncedAt:              formatNullTime(so.SyncedAt),
+		HoldLabel:             so.HoldLabel.String,
+		RegisterID:            so.RegisterID.String,
+		HeldAt:                formatNullTime(so.HeldAt),
+		QuotationID:           storeIDPtr(so.QuotationID),
+	}
+
+	if so.CashTendered.Valid {
+		response.CashTendered = &so.CashTendered.Amount
+	}
+
+	return response
+}
+
+// newSaleOrderDetailResponse is newSaleOrderResponse with lines, tax
+// breakdown, applied promotions and voucher.
+func newSaleOrderDetailResponse(so *models.SaleOrder, items []models.SaleOrderItem, promotions []models.SaleOrderPromotion, voucher *models.SaleOrderVoucher) dto.SaleOrderResponse {
+	response := newSaleOrderResponse(so)
+
+	for _, item := range items {
+		response.Items = append(response.Items, dto.SaleOrderItemResponse{
+			ID:             item.ID,
+			ProductID:      item.ProductID,
+			ProductName:    item.ProductName,
+			Category:       item.Category,
+			Quantity:       item.Quantity,
+			UnitPrice:      item.UnitPrice,
+			SubtotalAmount: item.SubtotalAmount,
+			DiscountAmount: item.DiscountAmount,
+			TotalAmount:    item.TotalAmount,
+			TaxRate:        item.TaxRate,
+			TaxableAmount:  item.TaxableAmount,
+			TaxAmount:      item.TaxAmount,
+		})
+	}
+
+	response.Taxes = newTaxBreakdown(items)
+
+	for _, p := range promotions {
+		response.Promotions = append(response.Promotions, dto.AppliedPromotionResponse{
+			PromotionID:    p.PromotionID,
+			PromotionName:  p.PromotionName,
+			DiscountAmount: p.DiscountAmount,
+		})
+	}
+
+	if voucher != nil {
+		response.Voucher = &dto.AppliedVoucherResponse{
+			Code:           voucher.Code,
+			DiscountAmount: voucher.DiscountAmount,
+		}
+	}
+
+	return response
+}
+
+// resolveOrderStore uses the requested store, falling back to the store the
+// cashier is assigned to. Orders without a store are not taxed and it returns
+// nil for them.
+func (s *SaleOrderService) resolveOrderStore(ctx context.Context, storeID *uuid.UUID, createdBy uuid.UUID) (*models.Store, error) {
+	if storeID == nil {
+		user, err := s.userRepo.GetUserByID(ctx, createdBy.String())
+		if err != nil {
+			if errors.Is(err, apperror.ErrNotFound) {
+				return nil, nil
+			}
+			return nil, err
+		}
+
+		if !user.StoreID.Valid {
+			return nil, nil
+		}
+		storeID = &user.StoreID.UUID
+	}
+
+	store, err := s.storeRepo.GetStoreByID(ctx, *storeID)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil, apperror.ErrInvalidStore
+		}
+		return nil, err
+	}
+
+	return store, nil
+}
+
+// resolveOrderCustomer links an order to a customer record. An order without
+// a customer name takes the name of the customer it is linked to.
+func (s *SaleOrderService) resolveOrderCustomer(ctx context.Context, saleOrder *models.SaleOrder, customerID *uuid.UUID) error {
+	if customerID == nil {
+		saleOrder.CustomerID = uuid.NullUUID{}
+		return nil
+	}
+
+	customer, err := s.customerRepo.GetCustomerByID(ctx, *customerID)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return apperror.ErrInvalidCustomer
+		}
+		return err
+	}
+
+	saleOrder.CustomerID = uuid.NullUUID{UUID: customer.ID, Valid: true}
+	if strings.TrimSpace(saleOrder.CustomerName) == "" {
+		saleOrder.CustomerName = customer.Name
+	}
+
+	return nil
+}
+
+// nextOrderNumber takes the next value of the counter for the store and the
+// period implied by the format. The counter row stays locked until the
+// surrounding transaction ends, so numbers are handed out without gaps.
+func (s *SaleOrderService) nextOrderNumber(ctx context.Context, store *models.Store, at time.Time) (string, error) {
+	format := utils.GetEnvOrDefault("ORDER_NUMBER_FORMAT", defaultOrderNumberFormat)
+	storeCode := defaultOrderNumberStoreCode
+	if store != nil {
+		storeCode = store.Code
+		if store.OrderNumberFormat.Valid {
+			format = store.OrderNumberFormat.String
+		}
+	}
+
+	if err := validateOrderNumberFormat(format); err != nil {
+		return "", err
+	}
+
+	period := orderNumberPeriod(format, at)
+	seq, err := s.orderNumRepo.NextValue(ctx, storeCode, period)
+	if err != nil {
+		return "", err
+	}
+
+	return formatOrderNumber(format, storeCode, at, seq), nil
+}
+
+// insertWithOrderNumber assigns an order number and inserts the order. An
+// order number can already be taken when the format of a store changes, so
+// the insert runs in a savepoint and is retried with the next counter value.
+func (s *SaleOrderService) insertWithOrderNumber(ctx context.Context, saleOrder *models.SaleOrder, store *models.Store) error {
+	for attempt := 0; attempt < maxOrderNumberAttempts; attempt++ {
+		orderNumber, err := s.nextOrderNumber(ctx, store, saleOrder.CreatedAt)
+		if err != nil {
+			return err
+		}
+		saleOrder.OrderNumber = orderNumber
+
+		err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+			return s.saleOrderRepo.InsertSaleOrder(ctx, saleOrder)
+		})
+		if !errors.Is(err, apperror.ErrOrderNumberConflict) {
+			return err
+		}
+	}
+
+	return apperror.ErrOrderNumberConflict
+}
+
+func (s *SaleOrderService) applyOrderTaxes(ctx context.Context, saleOrder *models.SaleOrder, items []models.SaleOrderItem) error {
+	var store *models.Store
+	var rates []models.TaxRate
+
+	if saleOrder.StoreID.Valid {
+		var err error
+		store, err = s.storeRepo.GetStoreByID(ctx, saleOrder.StoreID.UUID)
+		if err != nil {
+			return err
+		}
+
+		rates, err = s.taxRateRepo.GetTaxRatesForStore(ctx, store.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	saleOrder.Currency = money.DefaultCurrency
+	if store != nil {
+		saleOrder.Currency = store.Currency
+	}
+
+	saleOrder.PriceIncludesTax = store != nil && store.PriceIncludesTax
+	saleOrder.TaxAmount, saleOrder.TotalAmount = applyTaxes(items, store, rates, saleOrder.TaxExempt)
+
+	return nil
+}
+
+func formatNullTime(t sql.NullTime) string {
+	if !t.Valid {
+		return ""
+	}
+	return t.Time.Format(time.RFC3339)
+}
+
+func taxExemptionReference(exempt bool, reference string) sql.NullString {
+	reference = strings.TrimSpace(reference)
+	if !exempt || reference == "" {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: reference, Valid: true}
+}
+
+// priceItems resolves the requested lines against the product catalog and
+// runs the promotion engine over them, then takes off the voucher, if any.
+// A voucher that gives no discount, say because the minimum spend is no
+// longer met, is dropped from the result.
+func (s *SaleOrderService) priceItems(ctx context.Context, saleOrderID uuid.UUID, reqItems []dto.SaleOrderItemRequest, now time.Time, voucher *models.Voucher) (*pricingResult, error) {
+	productIDs := make([]uuid.UUID, 0, len(reqItems))
+	for _, item := range reqItems {
+		if item.ProductID == uuid.Nil || item.Quantity <= 0 {
+			return nil, apperror.ErrInvalidSaleOrderItem
+		}
+		productIDs = append(productIDs, item.ProductID)
+	}
+
+	products, err := s.productRepo.GetProductsByIDs(ctx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]models.SaleOrderItem, 0, len(reqItems))
+	for i, reqItem := range reqItems {
+		product, ok := products[reqItem.ProductID]
+		if !ok {
+			return nil, apperror.ErrInvalidSaleOrderItem
+		}
+
+		items = append(items, models.SaleOrderItem{
+			ID:          uuid.New(),
+			SaleOrderID: saleOrderID,
+			LineNumber:  i + 1,
+			ProductID:   product.ID,
+			ProductName: product.Name,
+			Category:    product.Category,
+			Quantity:    reqItem.Quantity,
+			UnitPrice:   product.UnitPrice,
+			CreatedAt:   now,
+		})
+	}
+
+	promotions, err := s.promotionRepo.GetApplicablePromotions(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+
+	result := applyPromotions(items, promotions, now)
+	for i := range result.Promotions {
+		result.Promotions[i].SaleOrderID = saleOrderID
+		result.Promotions[i].CreatedAt = now
+	}
+
+	if voucher != nil {
+		if discount := applyVoucher(result.Items, &voucher.Campaign); discount > 0 {
+			result.Voucher = &models.SaleOrderVoucher{
+				ID:             uuid.New(),
+				SaleOrderID:    saleOrderID,
+				VoucherID:      voucher.ID,
+				Code:           voucher.Code,
+				DiscountAmount: discount,
+				CreatedAt:      now,
+			}
+			result.sumLines()
+		}
+	}
+
+	return &result, nil
+}
+
+func (s *SaleOrderService) savePricing(ctx context.Context, priced *pricingResult) error {
+	if err := s.saleOrderRepo.InsertSaleOrderItems(ctx, priced.Items); err != nil {
+		return err
+	}
+
+	for _, applied := range priced.Promotions {
+		if err := s.promotionRepo.IncrementUsage(ctx, applied.PromotionID); err != nil {
+			return err
+		}
+	}
 
-	saleOrder := &models.SaleOrder{
-		ID:           uuid.New(),
-		OrderNumber:  orderNumber,
+	if err := s.saleOrderRepo.InsertSaleOrderPromotions(ctx, priced.Promotions); err != nil {
+		return err
+	}
+
+	if priced.Voucher == nil {
+		return nil
+	}
+
+	return s.vouchers.voucherRepo.InsertSaleOrderVoucher(ctx, priced.Voucher)
+}
+
+func (s *SaleOrderService) releasePricing(ctx context.Context, saleOrderID uuid.UUID) error {
+	applied, err := s.saleOrderRepo.GetSaleOrderPromotions(ctx, saleOrderID)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range applied {
+		if err := s.promotionRepo.DecrementUsage(ctx, p.PromotionID); err != nil {
+			return err
+		}
+	}
+
+	if err := s.saleOrderRepo.DeleteSaleOrderPromotions(ctx, saleOrderID); err != nil {
+		return err
+	}
+
+	if err := s.vouchers.voucherRepo.DeleteSaleOrderVoucher(ctx, saleOrderID); err != nil {
+		return err
+	}
+
+	return s.saleOrderRepo.DeleteSaleOrderItems(ctx, saleOrderID)
+}
+
+// checkRequestedStatus allows the statuses an order can be given when it is
+// created or updated. Orders are only held through HoldSaleOrder, which
+// records the register and when they were held.
+func checkRequestedStatus(status string) error {
+	switch status {
+	case constants.SaleOrderStatusDraft, constants.SaleOrderStatusCompleted, constants.SaleOrderStatusCancelled:
+		return nil
+	default:
+		return apperror.ErrInvalidSaleOrderStatus
+	}
+}
+
+func (s *SaleOrderService) CreateSaleOrder(ctx context.Context, caller dto.Caller, req *dto.CreateSaleOrderRequest) error {
+	now := time.Now()
+
+	return s.createSaleOrder(ctx, caller, req, &models.SaleOrder{
+		ID:        uuid.New(),
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+}
+
+// createSaleOrder fills in and stores saleOrder, whose ID and timestamps are
+// set by the caller. Promotions are evaluated as of saleOrder.CreatedAt.
+func (s *SaleOrderService) createSaleOrder(ctx context.Context, caller dto.Caller, req *dto.CreateSaleOrderRequest, saleOrder *models.SaleOrder) error {
+	if err := s.checkOrderStore(ctx, caller, req.StoreID); err != nil {
+		return err
+	}
+
+	createdBy := caller.ID
+
+	status := req.Status
+	if status == "" {
+		status = constants.SaleOrderStatusDraft
+	}
+	if err := checkRequestedStatus(status); err != nil {
+		return err
+	}
+
+	saleOrder.CustomerName = req.CustomerName
+	saleOrder.Currency = money.DefaultCurrency
+	saleOrder.TaxExempt = req.TaxExempt
+	saleOrder.TaxExemptionReference = taxExemptionReference(req.TaxExempt, req.TaxExemptionReference)
+	saleOrder.SubtotalAmount = req.TotalAmount
+	saleOrder.TotalAmount = req.TotalAmount
+	saleOrder.Status = status
+	saleOrder.CreatedBy = createdBy
+	saleOrder.Version = 1
+	saleOrder.DeletedAt = sql.NullTime{}
+
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		store, err := s.resolveOrderStore(ctx, req.StoreID, createdBy)
+		if err != nil {
+			return err
+		}
+		if store != nil {
+			saleOrder.StoreID = uuid.NullUUID{UUID: store.ID, Valid: true}
+			saleOrder.Currency = store.Currency
+		}
+
+		if err := s.resolveOrderCustomer(ctx, saleOrder, req.CustomerID); err != nil {
+			return err
+		}
+
+		var priced *pricingResult
+		if len(req.Items) > 0 {
+			priced, err = s.priceItems(ctx, saleOrder.ID, req.Items, saleOrder.CreatedAt, nil)
+			if err != nil {
+				return err
+			}
+
+			saleOrder.SubtotalAmount = priced.SubtotalAmount
+			saleOrder.DiscountAmount = priced.DiscountAmount
+
+			if err := s.applyOrderTaxes(ctx, saleOrder, priced.Items); err != nil {
+				return err
+			}
+		}
+
+		if req.CashTendered != nil {
+			if err := setCashTendered(saleOrder, *req.CashTendered, saleOrder.TotalAmount); err != nil {
+				return err
+			}
+		}
+
+		if err := s.insertWithOrderNumber(ctx, saleOrder, store); err != nil {
+			return err
+		}
+
+		if priced != nil {
+			if err := s.savePricing(ctx, priced); err != nil {
+				return err
+			}
+		}
+
+		if err := s.recordSaleOrderEvent(ctx, constants.EventSaleOrderCreated, saleOrder); err != nil {
+			return err
+		}
+
+		return s.settleSaleOrder(ctx, saleOrder, constants.SaleOrderStatusDraft, saleOrder.Status)
+	})
+}
+
+func newSaleOrderFilter(req *dto.SaleOrderFilterRequest) models.SaleOrderFilter {
+	filter := models.SaleOrderFilter{
+		Statuses:     req.Statuses,
+		CreatedFrom:  req.CreatedFrom,
+		CreatedTo:    req.CreatedTo,
 		CustomerName: req.CustomerName,
-		TotalAmount:  req.TotalAmount,
-		Status:       req.Status,
-		CreatedBy:    createdBy,
-		CreatedAt:    time.Now(),
-		UpdatedAt:    time.Now(),
-		DeletedAt:    sql.NullTime{},
+		CustomerID:   req.CustomerID,
+		CreatedBy:    req.CreatedBy,
+		StoreID:      req.StoreID,
+		MinAmount:    req.MinAmount,
+		MaxAmount:    req.MaxAmount,
+		Query:        req.Query,
 	}
 
-	return s.saleOrderRepo.InsertSaleOrder(ctx, saleOrder)
+	for _, sf := range req.Sort {
+		filter.Sort = append(filter.Sort, models.SortField{Field: sf.Field, Descending: sf.Descending})
+	}
+
+	return filter
 }
 
-func (s *SaleOrderService) GetSaleOrders(ctx context.Context, limit, offset int) ([]dto.SaleOrderResponse, int64, error) {
-	saleOrders, totalCount, err := s.saleOrderRepo.GetSaleOrders(ctx, limit, offset)
+func (s *SaleOrderService) GetSaleOrders(ctx context.Context, caller dto.Caller, req *dto.SaleOrderFilterRequest, limit, offset int) ([]dto.SaleOrderResponse, int64, error) {
+	filter := newSaleOrderFilter(req)
+	if err := s.scopeSaleOrderFilter(ctx, caller, &filter); err != nil {
+		return nil, 0, err
+	}
+
+	saleOrders, totalCount, err := s.saleOrderRepo.GetSaleOrders(ctx, filter, limit, offset)
 	if err != nil {
 		return nil, 0, err
 	}
 
 	responses := make([]dto.SaleOrderResponse, 0, len(saleOrders))
-	for _, so := range saleOrders {
-		responses = append(responses, dto.SaleOrderResponse{
-			ID:           so.ID,
-			OrderNumber:  so.OrderNumber,
-			CustomerName: so.CustomerName,
-			TotalAmount:  so.TotalAmount,
-			Status:       so.Status,
-			CreatedBy:    so.CreatedBy,
-			CreatedAt:    so.CreatedAt.Format(time.RFC3339),
-			UpdatedAt:    so.UpdatedAt.Format(time.RFC3339),
-		})
+	for i := range saleOrders {
+		responses = append(responses, newSaleOrderResponse(&saleOrders[i]))
 	}
 
 	return responses, totalCount, nil
 }
 
-func (s *SaleOrderService) GetSaleOrderByID(ctx context.Context, id uuid.UUID) (*dto.SaleOrderResponse, error) {
-	saleOrder, err := s.saleOrderRepo.GetSaleOrderByID(ctx, id)
+// GetSaleOrdersPage is the cursor based variant of GetSaleOrders. It always
+// lists newest first and counts only when includeTotal is set.
+func (s *SaleOrderService) GetSaleOrdersPage(ctx context.Context, caller dto.Caller, req *dto.SaleOrderFilterRequest, cursor *dto.Cursor, limit int, includeTotal bool) ([]dto.SaleOrderResponse, dto.CursorPageInfo, error) {
+	filter := newSaleOrderFilter(req)
+	if err := s.scopeSaleOrderFilter(ctx, caller, &filter); err != nil {
+		return nil, dto.CursorPageInfo{}, err
+	}
+	page := newKeysetPage(cursor, limit)
+
+	saleOrders, err := s.saleOrderRepo.GetSaleOrdersKeyset(ctx, filter, page)
 	if err != nil {
-		return nil, err
+		return nil, dto.CursorPageInfo{}, err
 	}
 
-	return &dto.SaleOrderResponse{
-		ID:           saleOrder.ID,
-		OrderNumber:  saleOrder.OrderNumber,
-		CustomerName: saleOrder.CustomerName,
-		TotalAmount:  saleOrder.TotalAmount,
-		Status:       saleOrder.Status,
-		CreatedBy:    saleOrder.CreatedBy,
-		CreatedAt:    saleOrder.CreatedAt.Format(time.RFC3339),
-		UpdatedAt:    saleOrder.UpdatedAt.Format(time.RFC3339),
-	}, nil
+	saleOrders, info := trimKeysetPage(saleOrders, page, func(so *models.SaleOrder) models.Keyset {
+		return models.Keyset{CreatedAt: so.CreatedAt, ID: so.ID}
+	})
+
+	if includeTotal {
+		totalCount, err := s.saleOrderRepo.CountSaleOrders(ctx, filter)
+		if err != nil {
+			return nil, dto.CursorPageInfo{}, err
+		}
+		info.TotalItems = &totalCount
+	}
+
+	responses := make([]dto.SaleOrderResponse, 0, len(saleOrders))
+	for i := range saleOrders {
+		responses = append(responses, newSaleOrderResponse(&saleOrders[i]))
+	}
+
+	return responses, info, nil
 }
 
-func (s *SaleOrderService) UpdateSaleOrder(ctx context.Context, id uuid.UUID, req *dto.UpdateSaleOrderRequest) error {
-	existingSaleOrder, err := s.saleOrderRepo.GetSaleOrderByID(ctx, id)
+func (s *SaleOrderService) GetSaleOrderByID(ctx context.Context, caller dto.Caller, id uuid.UUID) (*dto.SaleOrderResponse, error) {
+	saleOrder, err := s.getSaleOrderForCaller(ctx, caller, id)
 	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.saleOrderRepo.GetSaleOrderItems(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	promotions, err := s.saleOrderRepo.GetSaleOrderPromotions(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	voucher, err := s.vouchers.appliedVoucher(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := newSaleOrderDetailResponse(saleOrder, items, promotions, voucher)
+	return &response, nil
+}
+
+func (s *SaleOrderService) UpdateSaleOrder(ctx context.Context, caller dto.Caller, id uuid.UUID, version int64, req *dto.UpdateSaleOrderRequest) error {
+	if err := checkRequestedStatus(req.Status); err != nil {
 		return err
 	}
 
-	existingSaleOrder.CustomerName = req.CustomerName
-	existingSaleOrder.TotalAmount = req.TotalAmount
-	existingSaleOrder.Status = req.Status
-	existingSaleOrder.UpdatedAt = time.Now()
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		existingSaleOrder, err := s.getSaleOrderForChange(ctx, caller, id)
+		if err != nil {
+			return err
+		}
+
+		if err := checkVersion(version, existingSaleOrder.Version); err != nil {
+			return err
+		}
+
+		if existingSaleOrder.Status == constants.SaleOrderStatusHeld {
+			return apperror.ErrSaleOrderHeld
+		}
+
+		if err := s.saveRevision(ctx, caller, existingSaleOrder); err != nil {
+			return err
+		}
+
+		previousStatus := existingSaleOrder.Status
+
+		existingSaleOrder.CustomerName = req.CustomerName
+		existingSaleOrder.Status = req.Status
+		existingSaleOrder.TaxExempt = req.TaxExempt
+		existingSaleOrder.TaxExemptionReference = taxExemptionReference(req.TaxExempt, req.TaxExemptionReference)
+		existingSaleOrder.UpdatedAt = time.Now()
+
+		if err := s.resolveOrderCustomer(ctx, existingSaleOrder, req.CustomerID); err != nil {
+			return err
+		}
+
+		if len(req.Items) > 0 {
+			voucher, err := s.vouchers.orderVoucher(ctx, id)
+			if err != nil {
+				return err
+			}
+
+			if err := s.releasePricing(ctx, id); err != nil {
+				return err
+			}
+
+			priced, err := s.priceItems(ctx, id, req.Items, existingSaleOrder.UpdatedAt, voucher)
+			if err != nil {
+				return err
+			}
+
+			if err := s.applyOrderTaxes(ctx, existingSaleOrder, priced.Items); err != nil {
+				return err
+			}
+
+			if err := s.savePricing(ctx, priced); err != nil {
+				return err
+			}
+
+			existingSaleOrder.SubtotalAmount = priced.SubtotalAmount
+			existingSaleOrder.DiscountAmount = priced.DiscountAmount
+
+			return s.saveUpdatedSaleOrder(ctx, existingSaleOrder, previousStatus, req.CashTendered)
+		}
+
+		items, err := s.saleOrderRepo.GetSaleOrderItems(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		// Orders without line items keep the legacy manually entered total.
+		if len(items) == 0 {
+			existingSaleOrder.SubtotalAmount = req.TotalAmount
+			existingSaleOrder.DiscountAmount = 0
+			existingSaleOrder.TaxAmount = 0
+			existingSaleOrder.TotalAmount = req.TotalAmount
+
+			return s.saveUpdatedSaleOrder(ctx, existingSaleOrder, previousStatus, req.CashTendered)
+		}
+
+		// The exemption may have changed, so recompute tax on the existing lines.
+		if err := s.applyOrderTaxes(ctx, existingSaleOrder, items); err != nil {
+			return err
+		}
+
+		if err := s.saleOrderRepo.DeleteSaleOrderItems(ctx, id); err != nil {
+			return err
+		}
+
+		if err := s.saleOrderRepo.InsertSaleOrderItems(ctx, items); err != nil {
+			return err
+		}
 
-	return s.saleOrderRepo.UpdateSaleOrder(ctx, existingSaleOrder)
+		return s.saveUpdatedSaleOrder(ctx, existingSaleOrder, previousStatus, req.CashTendered)
+	})
 }
 
-func (s *SaleOrderService) DeleteSaleOrder(ctx context.Context, id uuid.UUID) error {
-	return s.saleOrderRepo.DeleteSaleOrder(ctx, id)
+// saveUpdatedSaleOrder records the cash handed over, when given, against what
+// is left to pay on the order and saves it.
+func (s *SaleOrderService) saveUpdatedSaleOrder(ctx context.Context, saleOrder *models.SaleOrder, previousStatus string, tendered *money.Amount) error {
+	if tendered != nil {
+		due, err := s.amountDue(ctx, saleOrder)
+		if err != nil {
+			return err
+		}
+
+		if err := setCashTendered(saleOrder, *tendered, due); err != nil {
+			return err
+		}
+	}
+
+	return s.saveSaleOrder(ctx, saleOrder, previousStatus)
+}
+
+// setCashTendered records the cash handed over for an order. Once the order is
+// completed it has to cover due rounded to what can be paid in cash; what is
+// over is given back as change.
+func setCashTendered(saleOrder *models.SaleOrder, tendered, due money.Amount) error {
+	if tendered < 0 {
+		return apperror.ErrInvalidCashTendered
+	}
+
+	if saleOrder.Status == constants.SaleOrderStatusCompleted && tendered < saleOrder.Currency.CashRound(due) {
+		return apperror.ErrInvalidCashTendered
+	}
+
+	saleOrder.CashTendered = money.NullAmount{Amount: tendered, Valid: true}
+	return nil
+}
+
+// saveSaleOrder stores a changed order and settles its tenders and loyalty
+// points for the move away from previousStatus. A total below what points and
+// gift cards already pay is refused with ErrRedemptionsExceedTotal.
+func (s *SaleOrderService) saveSaleOrder(ctx context.Context, saleOrder *models.SaleOrder, previousStatus string) error {
+	if err := s.checkRedemptions(ctx, saleOrder); err != nil {
+		return err
+	}
+
+	if err := s.saleOrderRepo.UpdateSaleOrder(ctx, saleOrder); err != nil {
+		return err
+	}
+
+	if err := s.recordSaleOrderEvent(ctx, constants.EventSaleOrderUpdated, saleOrder); err != nil {
+		return err
+	}
+
+	return s.settleSaleOrder(ctx, saleOrder, previousStatus, saleOrder.Status)
+}
+
+// DeleteSaleOrder soft deletes an order. Its tenders and loyalty points are
+// settled as if it was cancelled.
+func (s *SaleOrderService) DeleteSaleOrder(ctx context.Context, caller dto.Caller, id uuid.UUID, version int64) error {
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		saleOrder, err := s.getSaleOrderForChange(ctx, caller, id)
+		if err != nil {
+			return err
+		}
+
+		if err := checkVersion(version, saleOrder.Version); err != nil {
+			return err
+		}
+
+		if err := s.saleOrderRepo.DeleteSaleOrder(ctx, id, saleOrder.Version); err != nil {
+			return err
+		}
+
+		if err := s.recordSaleOrderEvent(ctx, constants.EventSaleOrderDeleted, saleOrder); err != nil {
+			return err
+		}
+
+		return s.settleSaleOrder(ctx, saleOrder, saleOrder.Status, constants.SaleOrderStatusCancelled)
+	})
 }
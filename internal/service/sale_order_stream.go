@@ -0,0 +1,293 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	apperror "github.com/hafiztri123/kki-be/internal/app_error"
+	"github.com/hafiztri123/kki-be/internal/constants"
+	"github.com/hafiztri123/kki-be/internal/dto"
+	"github.com/hafiztri123/kki-be/internal/events"
+	"github.com/hafiztri123/kki-be/internal/models"
+	"github.com/hafiztri123/kki-be/internal/repository"
+)
+
+const (
+	saleOrderStreamChannel = "sale_order_stream"
+	// saleOrderStreamBuffer is how many events a client may fall behind
+	// before it is cut off, to resume from where it got to.
+	saleOrderStreamBuffer = 64
+	// maxSaleOrderStreamReplay caps the events sent to a client resuming
+	// after Last-Event-ID. A client that missed more is sent a reset.
+	maxSaleOrderStreamReplay  = 500
+	saleOrderStreamRetryDelay = 5 * time.Second
+)
+
+// saleOrderStreamEvents are the events pushed to the live order feed.
+var saleOrderStreamEvents = []string{
+	constants.EventSaleOrderCreated,
+	constants.EventSaleOrderUpdated,
+	constants.EventSaleOrderStatusChanged,
+}
+
+// SaleOrderStreamService feeds sale order changes to connected clients. When
+// an event is dispatched its sequence is sent with NOTIFY, and every server
+// LISTENs and hands the event to its own clients, so a client sees changes
+// made through any server.
+type SaleOrderStreamService struct {
+	eventRepo        *repository.EventRepository
+	saleOrderService *SaleOrderService
+
+	mu          sync.Mutex
+	subscribers map[*saleOrderSubscriber]struct{}
+}
+
+func NewSaleOrderStreamService(eventRepo *repository.EventRepository, saleOrderService *SaleOrderService) *SaleOrderStreamService {
+	return &SaleOrderStreamService{
+		eventRepo:        eventRepo,
+		saleOrderService: saleOrderService,
+		subscribers:      make(map[*saleOrderSubscriber]struct{}),
+	}
+}
+
+type saleOrderSubscriber struct {
+	canRead func(createdBy uuid.UUID, storeID *uuid.UUID) bool
+	events  chan dto.StreamEvent
+}
+
+// SaleOrderStream is the feed of one client. Replay holds the events missed
+// since Last-Event-ID, or a single reset event when there were too many to
+// replay. Events is closed when the client falls too far behind.
+type SaleOrderStream struct {
+	Replay []dto.StreamEvent
+	Events <-chan dto.StreamEvent
+
+	service    *SaleOrderStreamService
+	subscriber *saleOrderSubscriber
+}
+
+// Close stops the feed.
+func (st *SaleOrderStream) Close() {
+	st.service.mu.Lock()
+	defer st.service.mu.Unlock()
+
+	if _, ok := st.service.subscribers[st.subscriber]; ok {
+		delete(st.service.subscribers, st.subscriber)
+		close(st.subscriber.events)
+	}
+}
+
+// subscribe registers the handler that announces dispatched order events to
+// every server.
+func (s *SaleOrderStreamService) subscribe(bus *events.Bus) {
+	bus.Subscribe("sale_order_stream", s.notify, saleOrderStreamEvents...)
+}
+
+func (s *SaleOrderStreamService) notify(ctx context.Context, event *models.DomainEvent) error {
+	return s.eventRepo.NotifyEvent(ctx, saleOrderStreamChannel, event.Sequence)
+}
+
+// Listen receives the announced events and pushes them to the clients of
+// this server until ctx is done. A lost connection is opened again; clients
+// miss what was announced in between unless they reconnect.
+func (s *SaleOrderStreamService) Listen(ctx context.Context) {
+	for {
+		err := s.eventRepo.Listen(ctx, saleOrderStreamChannel, func(payload string) {
+			s.broadcast(ctx, payload)
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		slog.ErrorContext(ctx, "sale order stream listener failed", "error", err.Error())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(saleOrderStreamRetryDelay):
+		}
+	}
+}
+
+func (s *SaleOrderStreamService) broadcast(ctx context.Context, payload string) {
+	sequence, err := strconv.ParseInt(payload, 10, 64)
+	if err != nil {
+		return
+	}
+
+	s.mu.Lock()
+	listening := len(s.subscribers) > 0
+	s.mu.Unlock()
+	if !listening {
+		return
+	}
+
+	event, err := s.eventRepo.GetEventBySequence(ctx, sequence)
+	if err != nil {
+		if !errors.Is(err, apperror.ErrNotFound) {
+			slog.ErrorContext(ctx, "failed to load sale order stream event", "sequence", sequence, "error", err.Error())
+		}
+		return
+	}
+
+	saleOrder, err := streamedSaleOrder(event)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to decode sale order stream event", "sequence", sequence, "error", err.Error())
+		return
+	}
+
+	streamEvent := newStreamEvent(event)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for subscriber := range s.subscribers {
+		if !subscriber.canRead(saleOrder.CreatedBy, saleOrder.StoreID) {
+			continue
+		}
+
+		select {
+		case subscriber.events <- streamEvent:
+		default:
+			// The client is not keeping up. Cutting it off lets it
+			// reconnect and catch up from its Last-Event-ID.
+			delete(s.subscribers, subscriber)
+			close(subscriber.events)
+		}
+	}
+}
+
+// streamedSaleOrder is the order an event is about.
+func streamedSaleOrder(event *models.DomainEvent) (*dto.SaleOrderResponse, error) {
+	if event.EventType == constants.EventSaleOrderStatusChanged {
+		var change dto.SaleOrderStatusChangedData
+		if err := json.Unmarshal(event.Payload, &change); err != nil {
+			return nil, err
+		}
+		return &change.SaleOrder, nil
+	}
+
+	var saleOrder dto.SaleOrderResponse
+	if err := json.Unmarshal(event.Payload, &saleOrder); err != nil {
+		return nil, err
+	}
+	return &saleOrder, nil
+}
+
+func newStreamEvent(event *models.DomainEvent) dto.StreamEvent {
+	return dto.StreamEvent{
+		ID:   event.Sequence,
+		Type: event.EventType,
+		Data: event.Payload,
+	}
+}
+
+// resetEvent tells a client to refetch, for reason. Its ID is the newest
+// sequence, so the client resumes after what the refetch already shows;
+// events recorded later reach it live.
+func (s *SaleOrderStreamService) resetEvent(ctx context.Context, reason string) (*dto.StreamEvent, error) {
+	sequence, err := s.eventRepo.GetLatestEventSequence(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(dto.StreamResetData{Reason: reason})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.StreamEvent{
+		ID:   sequence,
+		Type: constants.StreamEventReset,
+		Data: data,
+	}, nil
+}
+
+// SubscribeSaleOrders opens a feed of changes to the orders the caller may
+// read. With lastEventID set, the events after it are replayed first; events
+// retried by the dispatcher can come out of sequence and are then not
+// replayed. When more than maxSaleOrderStreamReplay events were missed, or
+// events after lastEventID were already purged, the replay is a reset event
+// instead, carrying the newest sequence so the client refetches the orders and
+// resumes from there.
+func (s *SaleOrderStreamService) SubscribeSaleOrders(ctx context.Context, caller dto.Caller, lastEventID *int64) (*SaleOrderStream, error) {
+	canRead, err := s.saleOrderService.saleOrderReader(ctx, caller)
+	if err != nil {
+		return nil, err
+	}
+
+	subscriber := &saleOrderSubscriber{
+		canRead: canRead,
+		events:  make(chan dto.StreamEvent, saleOrderStreamBuffer),
+	}
+
+	// Subscribe before reading the replay, so nothing dispatched in between
+	// is lost. Such an event is both in Replay and on Events.
+	s.mu.Lock()
+	s.subscribers[subscriber] = struct{}{}
+	s.mu.Unlock()
+
+	stream := &SaleOrderStream{
+		Events:     subscriber.events,
+		service:    s,
+		subscriber: subscriber,
+	}
+
+	if lastEventID == nil {
+		return stream, nil
+	}
+
+	// Purging removes dispatched events from the oldest on, so when the oldest
+	// one kept comes after the next the client expects, it may have missed
+	// events that are gone.
+	oldest, err := s.eventRepo.GetOldestDispatchedEventSequence(ctx)
+	if err != nil {
+		stream.Close()
+		return nil, err
+	}
+
+	if oldest > *lastEventID+1 {
+		return s.resetStream(ctx, stream, constants.StreamResetPurged)
+	}
+
+	missed, err := s.eventRepo.GetDispatchedEventsAfter(ctx, saleOrderStreamEvents, *lastEventID, maxSaleOrderStreamReplay+1)
+	if err != nil {
+		stream.Close()
+		return nil, err
+	}
+
+	if len(missed) > maxSaleOrderStreamReplay {
+		return s.resetStream(ctx, stream, constants.StreamResetReplayLimit)
+	}
+
+	for i := range missed {
+		saleOrder, err := streamedSaleOrder(&missed[i])
+		if err != nil {
+			stream.Close()
+			return nil, err
+		}
+
+		if canRead(saleOrder.CreatedBy, saleOrder.StoreID) {
+			stream.Replay = append(stream.Replay, newStreamEvent(&missed[i]))
+		}
+	}
+
+	return stream, nil
+}
+
+// resetStream makes a reset event, for reason, the whole replay of stream.
+func (s *SaleOrderStreamService) resetStream(ctx context.Context, stream *SaleOrderStream, reason string) (*SaleOrderStream, error) {
+	reset, err := s.resetEvent(ctx, reason)
+	if err != nil {
+		stream.Close()
+		return nil, err
+	}
+
+	stream.Replay = []dto.StreamEvent{*reset}
+	return stream, nil
+}
@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	apperror "github.com/hafiztri123/kki-be/internal/app_error"
+	"github.com/hafiztri123/kki-be/internal/constants"
+	"github.com/hafiztri123/kki-be/internal/dto"
+	"github.com/hafiztri123/kki-be/internal/models"
+	"github.com/hafiztri123/kki-be/internal/money"
+)
+
+// settleSaleOrder keeps the voucher, tenders, account charge and loyalty
+// points of an order in step with a status change and records the change for
+// the features that react to it. It runs inside the transaction that changes
+// the order.
+func (s *SaleOrderService) settleSaleOrder(ctx context.Context, saleOrder *models.SaleOrder, from, to string) error {
+	if err := s.vouchers.settleSaleOrder(ctx, saleOrder, from, to); err != nil {
+		return err
+	}
+
+	if err := s.giftCards.settleSaleOrder(ctx, saleOrder, from, to); err != nil {
+		return err
+	}
+
+	if err := s.loyalty.settleSaleOrder(ctx, saleOrder, from, to); err != nil {
+		return err
+	}
+
+	if from == constants.SaleOrderStatusCompleted && to != constants.SaleOrderStatusCompleted {
+		if err := s.reverseCharge(ctx, saleOrder); err != nil {
+			return err
+		}
+	}
+
+	return s.recordStatusChange(ctx, saleOrder, from, to)
+}
+
+// reverseCharge takes what an order put on a customer account off the
+// balance again once the order is no longer completed. A charge is reversed
+// once, and the order may be charged again if it is completed again; the
+// reversal is recorded by the system.
+func (s *SaleOrderService) reverseCharge(ctx context.Context, saleOrder *models.SaleOrder) error {
+	charge, err := s.accountRepo.GetUnreversedCharge(ctx, saleOrder.ID)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	account, err := s.accountRepo.GetCustomerAccountByIDForUpdate(ctx, charge.AccountID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if err := s.accountRepo.MarkChargeReversed(ctx, charge.ID, now); err != nil {
+		return err
+	}
+
+	account.Balance -= charge.Amount
+	account.UpdatedAt = now
+	if err := s.accountRepo.UpdateCustomerAccount(ctx, account); err != nil {
+		return err
+	}
+
+	return s.accountRepo.InsertEntry(ctx, &models.CustomerAccountEntry{
+		ID:           uuid.New(),
+		AccountID:    account.ID,
+		EntryType:    constants.CustomerAccountEntryReversal,
+		Amount:       charge.Amount,
+		BalanceAfter: account.Balance,
+		SaleOrderID:  uuid.NullUUID{UUID: saleOrder.ID, Valid: true},
+		CreatedAt:    now,
+	})
+}
+
+// amountDue is what is left to pay on an order after points and gift cards.
+func (s *SaleOrderService) amountDue(ctx context.Context, saleOrder *models.SaleOrder) (money.Amount, error) {
+	points, err := s.loyalty.redeemedAmount(ctx, saleOrder.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	cards, err := s.giftCards.redeemedAmount(ctx, saleOrder.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	return money.Max(saleOrder.TotalAmount-points-cards, 0), nil
+}
+
+// checkRedemptions makes sure points and gift cards do not pay more than the
+// total of an order, which repricing or a manual total can bring down. A
+// cancelled order releases them, so it is not checked.
+func (s *SaleOrderService) checkRedemptions(ctx context.Context, saleOrder *models.SaleOrder) error {
+	if saleOrder.Status == constants.SaleOrderStatusCancelled {
+		return nil
+	}
+
+	points, err := s.loyalty.redeemedAmount(ctx, saleOrder.ID)
+	if err != nil {
+		return err
+	}
+
+	cards, err := s.giftCards.redeemedAmount(ctx, saleOrder.ID)
+	if err != nil {
+		return err
+	}
+
+	return checkRedeemedAmount(saleOrder.TotalAmount, points, cards)
+}
+
+func checkRedeemedAmount(total, points, cards money.Amount) error {
+	if points+cards > total {
+		return apperror.ErrRedemptionsExceedTotal
+	}
+	return nil
+}
+
+// getDraftForRedemption loads a draft the caller may change at the expected
+// version.
+func (s *SaleOrderService) getDraftForRedemption(ctx context.Context, caller dto.Caller, id uuid.UUID, version int64) (*models.SaleOrder, error) {
+	saleOrder, err := s.getSaleOrderForChange(ctx, caller, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkVersion(version, saleOrder.Version); err != nil {
+		return nil, err
+	}
+
+	if saleOrder.Status != constants.SaleOrderStatusDraft {
+		return nil, apperror.ErrSaleOrderNotDraft
+	}
+
+	return saleOrder, nil
+}
+
+// RedeemGiftCard pays part of a draft with a gift card or store credit. Several
+// cards can pay the same order. The order version is bumped so a stale client
+// cannot complete it unaware.
+func (s *SaleOrderService) RedeemGiftCard(ctx context.Context, caller dto.Caller, id uuid.UUID, version int64, req *dto.RedeemGiftCardRequest) (*dto.GiftCardRedemptionResponse, error) {
+	var response *dto.GiftCardRedemptionResponse
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		saleOrder, err := s.getDraftForRedemption(ctx, caller, id, version)
+		if err != nil {
+			return err
+		}
+
+		due, err := s.amountDue(ctx, saleOrder)
+		if err != nil {
+			return err
+		}
+
+		response, err = s.giftCards.redeem(ctx, caller, saleOrder, req, due)
+		if err != nil {
+			return err
+		}
+
+		saleOrder.UpdatedAt = time.Now()
+		return s.saveSaleOrder(ctx, saleOrder, saleOrder.Status)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// ReleaseGiftCardRedemptions refunds every card that pays on a draft.
+func (s *SaleOrderService) ReleaseGiftCardRedemptions(ctx context.Context, caller dto.Caller, id uuid.UUID, version int64) error {
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		saleOrder, err := s.getDraftForRedemption(ctx, caller, id, version)
+		if err != nil {
+			return err
+		}
+
+		if err := s.giftCards.refund(ctx, saleOrder.ID, &caller.ID); err != nil {
+			return err
+		}
+
+		saleOrder.UpdatedAt = time.Now()
+		return s.saveSaleOrder(ctx, saleOrder, saleOrder.Status)
+	})
+}
@@ -0,0 +1,34 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	apperror "github.com/hafiztri123/kki-be/internal/app_error"
+	"github.com/hafiztri123/kki-be/internal/money"
+)
+
+func TestCheckRedeemedAmount(t *testing.T) {
+	tests := []struct {
+		name                 string
+		total, points, cards money.Amount
+		wantErr              bool
+	}{
+		{name: "nothing redeemed", total: 10000},
+		{name: "partly paid", total: 10000, points: 2500, cards: 5000},
+		{name: "fully paid", total: 10000, points: 2500, cards: 7500},
+		{name: "repriced below points", total: 2000, points: 2500, wantErr: true},
+		{name: "repriced below points and cards", total: 9999, points: 2500, cards: 7500, wantErr: true},
+		{name: "zero total with a card", total: 0, cards: 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		err := checkRedeemedAmount(tt.total, tt.points, tt.cards)
+		if tt.wantErr && !errors.Is(err, apperror.ErrRedemptionsExceedTotal) {
+			t.Errorf("%s: checkRedeemedAmount(%d, %d, %d) = %v, want ErrRedemptionsExceedTotal", tt.name, tt.total, tt.points, tt.cards, err)
+		}
+		if !tt.wantErr && err != nil {
+			t.Errorf("%s: checkRedeemedAmount(%d, %d, %d) = %v, want nil", tt.name, tt.total, tt.points, tt.cards, err)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	apperror "github.com/hafiztri123/kki-be/internal/app_error"
+	"github.com/hafiztri123/kki-be/internal/constants"
+	"github.com/hafiztri123/kki-be/internal/dto"
+	"github.com/hafiztri123/kki-be/internal/models"
+)
+
+// ApplyVoucher applies a code to a draft and reprices its lines with it,
+// replacing any code applied before. A code that cannot be used fails with
+// ErrVoucherNotApplicable and the reason why, and one that brings the total
+// below what points and gift cards already pay with ErrRedemptionsExceedTotal.
+// The code is only used up once the order is completed.
+func (s *SaleOrderService) ApplyVoucher(ctx context.Context, caller dto.Caller, id uuid.UUID, version int64, req *dto.ApplyVoucherRequest) (*dto.SaleOrderResponse, *dto.VoucherRejectionResponse, error) {
+	code := normalizeVoucherCode(req.Code)
+
+	var rejection *dto.VoucherRejectionResponse
+	reject := func(reason string) error {
+		rejection = &dto.VoucherRejectionResponse{Code: code, Reason: reason}
+		return apperror.ErrVoucherNotApplicable
+	}
+
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		saleOrder, err := s.getDraftForRedemption(ctx, caller, id, version)
+		if err != nil {
+			return err
+		}
+
+		voucher, err := s.vouchers.voucherRepo.GetVoucherByCode(ctx, code)
+		if err != nil {
+			if errors.Is(err, apperror.ErrNotFound) {
+				return reject(constants.VoucherRejectNotFound)
+			}
+			return err
+		}
+
+		reason, err := s.vouchers.rejectVoucher(ctx, voucher, saleOrder.Currency, saleOrder.CustomerID, time.Now())
+		if err != nil {
+			return err
+		}
+
+		if reason != "" {
+			return reject(reason)
+		}
+
+		priced, err := s.repriceSaleOrder(ctx, caller, saleOrder, voucher)
+		if err != nil {
+			return err
+		}
+
+		if priced == nil {
+			return reject(constants.VoucherRejectNoItems)
+		}
+
+		if priced.Voucher == nil {
+			return reject(constants.VoucherRejectMinSpend)
+		}
+
+		return s.saveSaleOrder(ctx, saleOrder, saleOrder.Status)
+	})
+	if err != nil {
+		return nil, rejection, err
+	}
+
+	response, err := s.GetSaleOrderByID(ctx, caller, id)
+	return response, nil, err
+}
+
+// RemoveVoucher takes the code off a draft and reprices its lines without it.
+func (s *SaleOrderService) RemoveVoucher(ctx context.Context, caller dto.Caller, id uuid.UUID, version int64) (*dto.SaleOrderResponse, error) {
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		saleOrder, err := s.getDraftForRedemption(ctx, caller, id, version)
+		if err != nil {
+			return err
+		}
+
+		applied, err := s.vouchers.appliedVoucher(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if applied == nil {
+			return apperror.ErrNotFound
+		}
+
+		if _, err := s.repriceSaleOrder(ctx, caller, saleOrder, nil); err != nil {
+			return err
+		}
+
+		return s.saveSaleOrder(ctx, saleOrder, saleOrder.Status)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetSaleOrderByID(ctx, caller, id)
+}
+
+// repriceSaleOrder prices the lines of an order again with voucher, or with
+// none when it is nil, and updates its totals. The state before is kept as a
+// revision. It returns nil for orders without lines, which are left alone.
+func (s *SaleOrderService) repriceSaleOrder(ctx context.Context, caller dto.Caller, saleOrder *models.SaleOrder, voucher *models.Voucher) (*pricingResult, error) {
+	items, err := s.saleOrderRepo.GetSaleOrderItems(ctx, saleOrder.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(items) == 0 {
+		return nil, nil
+	}
+
+	if err := s.saveRevision(ctx, caller, saleOrder); err != nil {
+		return nil, err
+	}
+
+	reqItems := make([]dto.SaleOrderItemRequest, 0, len(items))
+	for _, item := range items {
+		reqItems = append(reqItems, dto.SaleOrderItemRequest{
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+		})
+	}
+
+	if err := s.releasePricing(ctx, saleOrder.ID); err != nil {
+		return nil, err
+	}
+
+	saleOrder.UpdatedAt = time.Now()
+	priced, err := s.priceItems(ctx, saleOrder.ID, reqItems, saleOrder.UpdatedAt, voucher)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.applyOrderTaxes(ctx, saleOrder, priced.Items); err != nil {
+		return nil, err
+	}
+
+	if err := s.savePricing(ctx, priced); err != nil {
+		return nil, err
+	}
+
+	saleOrder.SubtotalAmount = priced.SubtotalAmount
+	saleOrder.DiscountAmount = priced.DiscountAmount
+
+	return priced, nil
+}
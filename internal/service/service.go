@@ -1,15 +1,120 @@
 package service
 
-import "github.com/hafiztri123/kki-be/internal/repository"
+import (
+	"github.com/hafiztri123/kki-be/internal/events"
+	"github.com/hafiztri123/kki-be/internal/repository"
+)
 
 type Services struct {
-	UserService      *UserService
-	SaleOrderService *SaleOrderService
+	UserService            *UserService
+	SaleOrderService       *SaleOrderService
+	ProductService         *ProductService
+	PromotionService       *PromotionService
+	StoreService           *StoreService
+	IdempotencyService     *IdempotencyService
+	SyncService            *SyncService
+	QuotationService       *QuotationService
+	InvoiceService         *InvoiceService
+	CustomerAccountService *CustomerAccountService
+	CustomerService        *CustomerService
+	LoyaltyService         *LoyaltyService
+	GiftCardService        *GiftCardService
+	VoucherService         *VoucherService
+	ReceiptService         *ReceiptService
+	EmailService           *EmailService
+	WebhookService         *WebhookService
+	EventService           *EventService
+	SaleOrderStreamService *SaleOrderStreamService
 }
 
 func NewServices(repositories *repository.Repositories) *Services {
+	bus := events.NewBus()
+	eventService := NewEventService(repositories.Transactor, repositories.EventRepository, bus)
+
+	loyaltyService := NewLoyaltyService(
+		repositories.Transactor,
+		repositories.LoyaltyRepository,
+		repositories.CustomerRepository,
+		repositories.SaleOrderRepository,
+	)
+
+	giftCardService := NewGiftCardService(repositories.Transactor, repositories.GiftCardRepository, repositories.CustomerRepository)
+
+	voucherService := NewVoucherService(repositories.Transactor, repositories.VoucherRepository)
+
+	saleOrderService := NewSaleOrderService(
+		repositories.Transactor,
+		repositories.SaleOrderRepository,
+		repositories.ProductRepository,
+		repositories.PromotionRepository,
+		repositories.UserRepository,
+		repositories.StoreRepository,
+		repositories.TaxRateRepository,
+		repositories.OrderNumberRepository,
+		repositories.SaleOrderRevisionRepository,
+		repositories.SaleOrderHoldEventRepository,
+		repositories.CustomerRepository,
+		repositories.CustomerAccountRepository,
+		loyaltyService,
+		giftCardService,
+		voucherService,
+		eventService,
+	)
+
+	receiptService := NewReceiptService(saleOrderService, repositories.GiftCardRepository, repositories.CustomerAccountRepository)
+
+	emailService := NewEmailService(repositories.Transactor, repositories.EmailRepository, receiptService, newMailTransport(), mailFrom())
+	emailService.subscribe(bus)
+
+	webhookService := NewWebhookService(repositories.WebhookRepository, newWebhookClient())
+	webhookService.subscribe(bus)
+
+	saleOrderStreamService := NewSaleOrderStreamService(repositories.EventRepository, saleOrderService)
+	saleOrderStreamService.subscribe(bus)
+
 	return &Services{
-		UserService:      NewUserService(repositories.UserRepository),
-		SaleOrderService: NewSaleOrderService(repositories.SaleOrderRepository),
+		UserService:        NewUserService(repositories.Transactor, repositories.UserRepository, repositories.StoreRepository, eventService),
+		SaleOrderService:   saleOrderService,
+		ProductService:     NewProductService(repositories.ProductRepository),
+		PromotionService:   NewPromotionService(repositories.PromotionRepository, repositories.ProductRepository),
+		StoreService:       NewStoreService(repositories.StoreRepository, repositories.TaxRateRepository),
+		IdempotencyService: NewIdempotencyService(repositories.Transactor, repositories.IdempotencyKeyRepository),
+		SyncService: NewSyncService(
+			saleOrderService,
+			repositories.SaleOrderRepository,
+			repositories.ProductRepository,
+			repositories.PromotionRepository,
+			repositories.TaxRateRepository,
+		),
+		QuotationService: NewQuotationService(
+			repositories.Transactor,
+			repositories.QuotationRepository,
+			repositories.ProductRepository,
+			saleOrderService,
+		),
+		InvoiceService: NewInvoiceService(repositories.Transactor, repositories.InvoiceRepository, saleOrderService),
+		CustomerAccountService: NewCustomerAccountService(
+			repositories.Transactor,
+			repositories.CustomerAccountRepository,
+			repositories.CustomerRepository,
+			saleOrderService,
+		),
+		CustomerService: NewCustomerService(
+			repositories.Transactor,
+			repositories.CustomerRepository,
+			repositories.LoyaltyRepository,
+			repositories.GiftCardRepository,
+			repositories.VoucherRepository,
+			repositories.CustomerAccountRepository,
+			saleOrderService,
+		),
+		LoyaltyService:         loyaltyService,
+		GiftCardService:        giftCardService,
+		VoucherService:         voucherService,
+		ReceiptService:         receiptService,
+		EmailService:           emailService,
+		WebhookService:         webhookService,
+		EventService:           eventService,
+		SaleOrderStreamService: saleOrderStreamService,
 	}
 }
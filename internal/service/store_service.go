@@ -0,0 +1,300 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	apperror "github.com/hafiztri123/kki-be/internal/app_error"
+	"github.com/hafiztri123/kki-be/internal/dto"
+	"github.com/hafiztri123/kki-be/internal/models"
+	"github.com/hafiztri123/kki-be/internal/money"
+	"github.com/hafiztri123/kki-be/internal/repository"
+)
+
+const (
+	maxReceiptTextLength = 500
+	maxReceiptTextLines  = 8
+)
+
+type StoreService struct {
+	storeRepo   *repository.StoreRepository
+	taxRateRepo *repository.TaxRateRepository
+}
+
+func NewStoreService(storeRepo *repository.StoreRepository, taxRateRepo *repository.TaxRateRepository) *StoreService {
+	return &StoreService{
+		storeRepo:   storeRepo,
+		taxRateRepo: taxRateRepo,
+	}
+}
+
+func newStoreResponse(s *models.Store) dto.StoreResponse {
+	return dto.StoreResponse{
+		ID:                s.ID,
+		Code:              s.Code,
+		Name:              s.Name,
+		Address:           s.Address,
+		Currency:          s.Currency.String(),
+		PriceIncludesTax:  s.PriceIncludesTax,
+		DefaultTaxRate:    s.DefaultTaxRate,
+		OrderNumberFormat: s.OrderNumberFormat.String,
+		ReceiptHeader:     s.ReceiptHeader.String,
+		ReceiptFooter:     s.ReceiptFooter.String,
+		CreatedAt:         s.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:         s.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+func newTaxRateResponse(t *models.TaxRate) dto.TaxRateResponse {
+	return dto.TaxRateResponse{
+		ID:        t.ID,
+		StoreID:   storeIDPtr(t.StoreID),
+		Category:  t.Category,
+		Name:      t.Name,
+		Rate:      t.Rate,
+		CreatedAt: t.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: t.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+func validTaxRate(rate float64) bool {
+	return rate >= 0 && rate < 100
+}
+
+func storeCurrency(code string) (money.Currency, error) {
+	if strings.TrimSpace(code) == "" {
+		return money.DefaultCurrency, nil
+	}
+
+	currency, ok := money.ParseCurrency(code)
+	if !ok {
+		return "", apperror.ErrInvalidStore
+	}
+
+	return currency, nil
+}
+
+// storeOrderNumberFormat validates an optional per-store override of the
+// ORDER_NUMBER_FORMAT setting. An empty format keeps the global default.
+func storeOrderNumberFormat(format string) (sql.NullString, error) {
+	format = strings.TrimSpace(format)
+	if format == "" {
+		return sql.NullString{}, nil
+	}
+
+	if err := validateOrderNumberFormat(format); err != nil {
+		return sql.NullString{}, err
+	}
+
+	return sql.NullString{String: format, Valid: true}, nil
+}
+
+// storeReceiptText validates an optional receipt header or footer. Long text
+// would run for metres of paper, so it is capped.
+func storeReceiptText(text string) (sql.NullString, error) {
+	text = strings.TrimSpace(text)
+	if len(text) > maxReceiptTextLength || strings.Count(text, "\n") >= maxReceiptTextLines {
+		return sql.NullString{}, apperror.ErrInvalidStore
+	}
+
+	return nullString(&text), nil
+}
+
+func (s *StoreService) CreateStore(ctx context.Context, req *dto.CreateStoreRequest) error {
+	if strings.TrimSpace(req.Code) == "" || strings.TrimSpace(req.Name) == "" || !validTaxRate(req.DefaultTaxRate) {
+		return apperror.ErrInvalidStore
+	}
+
+	currency, err := storeCurrency(req.Currency)
+	if err != nil {
+		return err
+	}
+
+	orderNumberFormat, err := storeOrderNumberFormat(req.OrderNumberFormat)
+	if err != nil {
+		return err
+	}
+
+	receiptHeader, err := storeReceiptText(req.ReceiptHeader)
+	if err != nil {
+		return err
+	}
+
+	receiptFooter, err := storeReceiptText(req.ReceiptFooter)
+	if err != nil {
+		return err
+	}
+
+	return s.storeRepo.InsertStore(ctx, &models.Store{
+		ID:                uuid.New(),
+		Code:              strings.ToUpper(strings.TrimSpace(req.Code)),
+		Name:              strings.TrimSpace(req.Name),
+		Address:           strings.TrimSpace(req.Address),
+		Currency:          currency,
+		PriceIncludesTax:  req.PriceIncludesTax,
+		DefaultTaxRate:    req.DefaultTaxRate,
+		OrderNumberFormat: orderNumberFormat,
+		ReceiptHeader:     receiptHeader,
+		ReceiptFooter:     receiptFooter,
+		CreatedAt:         time.Now(),
+		UpdatedAt:         time.Now(),
+		DeletedAt:         sql.NullTime{},
+	})
+}
+
+func (s *StoreService) GetStores(ctx context.Context, limit, offset int) ([]dto.StoreResponse, int64, error) {
+	stores, totalCount, err := s.storeRepo.GetStores(ctx, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	responses := make([]dto.StoreResponse, 0, len(stores))
+	for i := range stores {
+		responses = append(responses, newStoreResponse(&stores[i]))
+	}
+
+	return responses, totalCount, nil
+}
+
+func (s *StoreService) GetStoreByID(ctx context.Context, id uuid.UUID) (*dto.StoreResponse, error) {
+	store, err := s.storeRepo.GetStoreByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := newStoreResponse(store)
+	return &response, nil
+}
+
+func (s *StoreService) UpdateStore(ctx context.Context, id uuid.UUID, req *dto.UpdateStoreRequest) error {
+	if strings.TrimSpace(req.Code) == "" || strings.TrimSpace(req.Name) == "" || !validTaxRate(req.DefaultTaxRate) {
+		return apperror.ErrInvalidStore
+	}
+
+	currency, err := storeCurrency(req.Currency)
+	if err != nil {
+		return err
+	}
+
+	orderNumberFormat, err := storeOrderNumberFormat(req.OrderNumberFormat)
+	if err != nil {
+		return err
+	}
+
+	receiptHeader, err := storeReceiptText(req.ReceiptHeader)
+	if err != nil {
+		return err
+	}
+
+	receiptFooter, err := storeReceiptText(req.ReceiptFooter)
+	if err != nil {
+		return err
+	}
+
+	store, err := s.storeRepo.GetStoreByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	store.Code = strings.ToUpper(strings.TrimSpace(req.Code))
+	store.Name = strings.TrimSpace(req.Name)
+	store.Address = strings.TrimSpace(req.Address)
+	store.Currency = currency
+	store.PriceIncludesTax = req.PriceIncludesTax
+	store.DefaultTaxRate = req.DefaultTaxRate
+	store.OrderNumberFormat = orderNumberFormat
+	store.ReceiptHeader = receiptHeader
+	store.ReceiptFooter = receiptFooter
+	store.UpdatedAt = time.Now()
+
+	return s.storeRepo.UpdateStore(ctx, store)
+}
+
+func (s *StoreService) DeleteStore(ctx context.Context, id uuid.UUID) error {
+	return s.storeRepo.DeleteStore(ctx, id)
+}
+
+func (s *StoreService) validateTaxRateRequest(ctx context.Context, req *dto.CreateTaxRateRequest) error {
+	if strings.TrimSpace(req.Category) == "" || strings.TrimSpace(req.Name) == "" || !validTaxRate(req.Rate) {
+		return apperror.ErrInvalidTaxRate
+	}
+
+	if req.StoreID != nil {
+		if _, err := s.storeRepo.GetStoreByID(ctx, *req.StoreID); err != nil {
+			if errors.Is(err, apperror.ErrNotFound) {
+				return apperror.ErrInvalidStore
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *StoreService) CreateTaxRate(ctx context.Context, req *dto.CreateTaxRateRequest) error {
+	if err := s.validateTaxRateRequest(ctx, req); err != nil {
+		return err
+	}
+
+	return s.taxRateRepo.InsertTaxRate(ctx, &models.TaxRate{
+		ID:        uuid.New(),
+		StoreID:   nullUUID(req.StoreID),
+		Category:  strings.TrimSpace(req.Category),
+		Name:      strings.TrimSpace(req.Name),
+		Rate:      req.Rate,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		DeletedAt: sql.NullTime{},
+	})
+}
+
+func (s *StoreService) GetTaxRates(ctx context.Context, limit, offset int) ([]dto.TaxRateResponse, int64, error) {
+	taxRates, totalCount, err := s.taxRateRepo.GetTaxRates(ctx, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	responses := make([]dto.TaxRateResponse, 0, len(taxRates))
+	for i := range taxRates {
+		responses = append(responses, newTaxRateResponse(&taxRates[i]))
+	}
+
+	return responses, totalCount, nil
+}
+
+func (s *StoreService) GetTaxRateByID(ctx context.Context, id uuid.UUID) (*dto.TaxRateResponse, error) {
+	taxRate, err := s.taxRateRepo.GetTaxRateByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := newTaxRateResponse(taxRate)
+	return &response, nil
+}
+
+func (s *StoreService) UpdateTaxRate(ctx context.Context, id uuid.UUID, req *dto.UpdateTaxRateRequest) error {
+	if err := s.validateTaxRateRequest(ctx, req); err != nil {
+		return err
+	}
+
+	taxRate, err := s.taxRateRepo.GetTaxRateByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	taxRate.StoreID = nullUUID(req.StoreID)
+	taxRate.Category = strings.TrimSpace(req.Category)
+	taxRate.Name = strings.TrimSpace(req.Name)
+	taxRate.Rate = req.Rate
+	taxRate.UpdatedAt = time.Now()
+
+	return s.taxRateRepo.UpdateTaxRate(ctx, taxRate)
+}
+
+func (s *StoreService) DeleteTaxRate(ctx context.Context, id uuid.UUID) error {
+	return s.taxRateRepo.DeleteTaxRate(ctx, id)
+}
@@ -0,0 +1,247 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	apperror "github.com/hafiztri123/kki-be/internal/app_error"
+	"github.com/hafiztri123/kki-be/internal/constants"
+	"github.com/hafiztri123/kki-be/internal/dto"
+	"github.com/hafiztri123/kki-be/internal/models"
+	"github.com/hafiztri123/kki-be/internal/repository"
+)
+
+const (
+	maxSyncBatchSize      = 100
+	maxSyncDeviceIDLength = 100
+
+	// syncChangesOverlap widens every changes window backwards so rows written
+	// by transactions that committed after the previous call, but carry an
+	// earlier timestamp, are not missed. Clients upsert, so repeats are harmless.
+	syncChangesOverlap = time.Minute
+)
+
+type SyncService struct {
+	saleOrderService *SaleOrderService
+	saleOrderRepo    *repository.SaleOrderRepository
+	productRepo      *repository.ProductRepository
+	promotionRepo    *repository.PromotionRepository
+	taxRateRepo      *repository.TaxRateRepository
+}
+
+func NewSyncService(
+	saleOrderService *SaleOrderService,
+	saleOrderRepo *repository.SaleOrderRepository,
+	productRepo *repository.ProductRepository,
+	promotionRepo *repository.PromotionRepository,
+	taxRateRepo *repository.TaxRateRepository,
+) *SyncService {
+	return &SyncService{
+		saleOrderService: saleOrderService,
+		saleOrderRepo:    saleOrderRepo,
+		productRepo:      productRepo,
+		promotionRepo:    promotionRepo,
+		taxRateRepo:      taxRateRepo,
+	}
+}
+
+// PushOrders applies orders created offline. Each order is applied on its
+// own, so one rejected order does not hold back the rest of the batch.
+func (s *SyncService) PushOrders(ctx context.Context, caller dto.Caller, req *dto.SyncOrdersRequest) (*dto.SyncOrdersResponse, error) {
+	deviceID := strings.TrimSpace(req.DeviceID)
+	if deviceID == "" || len(deviceID) > maxSyncDeviceIDLength || len(req.Orders) == 0 || len(req.Orders) > maxSyncBatchSize {
+		return nil, apperror.ErrInvalidSyncBatch
+	}
+
+	now := time.Now()
+	response := &dto.SyncOrdersResponse{
+		ServerTime: now.Format(time.RFC3339Nano),
+		Results:    make([]dto.SyncOrderResult, 0, len(req.Orders)),
+	}
+
+	for i := range req.Orders {
+		result, err := s.pushOrder(ctx, caller, deviceID, &req.Orders[i], now)
+		if err != nil {
+			return nil, err
+		}
+		response.Results = append(response.Results, result)
+	}
+
+	return response, nil
+}
+
+func (s *SyncService) pushOrder(ctx context.Context, caller dto.Caller, deviceID string, order *dto.SyncOrderRequest, now time.Time) (dto.SyncOrderResult, error) {
+	result := dto.SyncOrderResult{ClientID: order.ClientID}
+
+	if order.ClientID == uuid.Nil {
+		result.Status = constants.SyncResultRejected
+		result.Message = "client_id is required"
+		return result, nil
+	}
+
+	existing, err := s.saleOrderRepo.GetSaleOrderByID(ctx, order.ClientID)
+	if err == nil {
+		return existingSyncResult(result, existing, caller, deviceID), nil
+	}
+	if !errors.Is(err, apperror.ErrNotFound) {
+		return result, err
+	}
+
+	// The device clock is trusted for the sale time, but never into the future.
+	createdAt := order.CreatedAt.In(time.Local)
+	if order.CreatedAt.IsZero() || createdAt.After(now) {
+		createdAt = now
+	}
+
+	saleOrder := &models.SaleOrder{
+		ID:        order.ClientID,
+		DeviceID:  sql.NullString{String: deviceID, Valid: true},
+		SyncedAt:  sql.NullTime{Time: now, Valid: true},
+		CreatedAt: createdAt,
+		UpdatedAt: now,
+	}
+
+	err = s.saleOrderService.createSaleOrder(ctx, caller, &order.CreateSaleOrderRequest, saleOrder)
+	switch {
+	case errors.Is(err, apperror.ErrSaleOrderAlreadyExists):
+		// Another upload of the same order got in first; answer as for a
+		// re-upload.
+		existing, err := s.saleOrderRepo.GetSaleOrderByID(ctx, order.ClientID)
+		if err != nil {
+			if !errors.Is(err, apperror.ErrNotFound) {
+				return result, err
+			}
+			result.Status = constants.SyncResultConflict
+			result.Message = "client_id belongs to another order"
+			return result, nil
+		}
+		return existingSyncResult(result, existing, caller, deviceID), nil
+	case errors.Is(err, apperror.ErrInvalidSaleOrderItem),
+		errors.Is(err, apperror.ErrInvalidStore),
+		errors.Is(err, apperror.ErrInvalidCustomer),
+		errors.Is(err, apperror.ErrForbidden),
+		errors.Is(err, apperror.ErrPromotionUsageLimitReached),
+		errors.Is(err, apperror.ErrInvalidCashTendered),
+		errors.Is(err, apperror.ErrInvalidSaleOrderStatus),
+		errors.Is(err, apperror.ErrOrderNumberConflict),
+		errors.Is(err, apperror.ErrInvalidOrderNumberFormat):
+		result.Status = constants.SyncResultRejected
+		result.Message = err.Error()
+		return result, nil
+	case err != nil:
+		return result, err
+	}
+
+	result.Status = constants.SyncResultCreated
+	result.OrderNumber = saleOrder.OrderNumber
+	result.TotalAmount = &saleOrder.TotalAmount
+
+	// Itemised orders are repriced on the server; tell the device when its
+	// own total differs so it can correct the local copy.
+	if len(order.Items) > 0 && !order.TotalAmount.IsZero() && order.TotalAmount != saleOrder.TotalAmount {
+		result.Conflicts = append(result.Conflicts, dto.SyncConflict{
+			Field:       "total_amount",
+			ClientValue: order.TotalAmount.String(),
+			ServerValue: saleOrder.TotalAmount.String(),
+		})
+	}
+
+	return result, nil
+}
+
+// existingSyncResult answers a re-upload. The same device and cashier get
+// the stored result back; anyone else reusing the ID is a conflict.
+func existingSyncResult(result dto.SyncOrderResult, existing *models.SaleOrder, caller dto.Caller, deviceID string) dto.SyncOrderResult {
+	if existing.CreatedBy != caller.ID || existing.DeviceID.String != deviceID {
+		result.Status = constants.SyncResultConflict
+		result.Message = "client_id belongs to another order"
+		return result
+	}
+
+	result.Status = constants.SyncResultDuplicate
+	result.OrderNumber = existing.OrderNumber
+	result.TotalAmount = &existing.TotalAmount
+	return result
+}
+
+// GetChanges returns catalog and order changes after since. A nil since asks
+// for everything. Orders are limited to those the caller may read.
+func (s *SyncService) GetChanges(ctx context.Context, caller dto.Caller, since *time.Time) (*dto.SyncChangesResponse, error) {
+	serverTime := time.Now()
+
+	var from time.Time
+	if since != nil {
+		from = since.Add(-syncChangesOverlap)
+	}
+
+	response := &dto.SyncChangesResponse{
+		ServerTime:          serverTime.Format(time.RFC3339Nano),
+		Products:            []dto.ProductResponse{},
+		DeletedProductIDs:   []uuid.UUID{},
+		Promotions:          []dto.PromotionResponse{},
+		DeletedPromotionIDs: []uuid.UUID{},
+		TaxRates:            []dto.TaxRateResponse{},
+		DeletedTaxRateIDs:   []uuid.UUID{},
+		SaleOrders:          []dto.SaleOrderResponse{},
+		DeletedSaleOrderIDs: []uuid.UUID{},
+	}
+
+	products, err := s.productRepo.GetProductsChangedSince(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+	for i := range products {
+		if products[i].DeletedAt.Valid {
+			response.DeletedProductIDs = append(response.DeletedProductIDs, products[i].ID)
+			continue
+		}
+		response.Products = append(response.Products, newProductResponse(&products[i]))
+	}
+
+	promotions, err := s.promotionRepo.GetPromotionsChangedSince(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+	for i := range promotions {
+		if promotions[i].DeletedAt.Valid {
+			response.DeletedPromotionIDs = append(response.DeletedPromotionIDs, promotions[i].ID)
+			continue
+		}
+		response.Promotions = append(response.Promotions, newPromotionResponse(&promotions[i]))
+	}
+
+	taxRates, err := s.taxRateRepo.GetTaxRatesChangedSince(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+	for i := range taxRates {
+		if taxRates[i].DeletedAt.Valid {
+			response.DeletedTaxRateIDs = append(response.DeletedTaxRateIDs, taxRates[i].ID)
+			continue
+		}
+		response.TaxRates = append(response.TaxRates, newTaxRateResponse(&taxRates[i]))
+	}
+
+	var filter models.SaleOrderFilter
+	if err := s.saleOrderService.scopeSaleOrderFilter(ctx, caller, &filter); err != nil {
+		return nil, err
+	}
+
+	saleOrders, err := s.saleOrderRepo.GetSaleOrdersChangedSince(ctx, filter, from)
+	if err != nil {
+		return nil, err
+	}
+	for i := range saleOrders {
+		if saleOrders[i].DeletedAt.Valid {
+			response.DeletedSaleOrderIDs = append(response.DeletedSaleOrderIDs, saleOrders[i].ID)
+			continue
+		}
+		response.SaleOrders = append(response.SaleOrders, newSaleOrderResponse(&saleOrders[i]))
+	}
+
+	return response, nil
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"github.com/hafiztri123/kki-be/internal/dto"
+	"github.com/hafiztri123/kki-be/internal/models"
+	"github.com/hafiztri123/kki-be/internal/money"
+)
+
+// resolveTaxRate picks the most specific rate for a category: a store specific
+// rate, then a rate shared by all stores, then the store default.
+func resolveTaxRate(store *models.Store, rates []models.TaxRate, category string) float64 {
+	rate := store.DefaultTaxRate
+	for _, r := range rates {
+		if r.Category != category {
+			continue
+		}
+
+		if r.StoreID.Valid {
+			return r.Rate
+		}
+
+		rate = r.Rate
+	}
+
+	return rate
+}
+
+// applyTaxes fills in the tax columns of each line and returns the order tax
+// and grand total. Tax is rounded once per rate on the summed line amounts and
+// then allocated back over the lines, so line taxes always add up to the rate
+// total. Without a store no tax is charged; tax exempt orders pay the amount
+// excluding tax.
+func applyTaxes(items []models.SaleOrderItem, store *models.Store, rates []models.TaxRate, exempt bool) (money.Amount, money.Amount) {
+	groups := map[float64][]int{}
+	var order []float64
+	for i := range items {
+		rate := 0.0
+		if store != nil {
+			rate = resolveTaxRate(store, rates, items[i].Category)
+		}
+
+		if _, ok := groups[rate]; !ok {
+			order = append(order, rate)
+		}
+		groups[rate] = append(groups[rate], i)
+	}
+
+	inclusive := store != nil && store.PriceIncludesTax
+
+	var taxAmount, totalAmount money.Amount
+	for _, rate := range order {
+		indexes := groups[rate]
+
+		lineAmounts := make([]money.Amount, len(indexes))
+		for j, i := range indexes {
+			lineAmounts[j] = items[i].TotalAmount
+		}
+
+		net := money.Sum(lineAmounts...)
+		groupTax := net.Percent(rate, money.RoundHalfUp)
+		if inclusive {
+			groupTax = net.IncludedPercent(rate, money.RoundHalfUp)
+		}
+
+		lineTax := money.Allocate(groupTax, lineAmounts)
+
+		for j, i := range indexes {
+			taxable := lineAmounts[j]
+			if inclusive {
+				taxable -= lineTax[j]
+			}
+
+			items[i].TaxRate = rate
+			items[i].TaxableAmount = taxable
+			items[i].TaxAmount = lineTax[j]
+
+			if exempt {
+				items[i].TaxRate = 0
+				items[i].TaxAmount = 0
+			}
+
+			taxAmount += items[i].TaxAmount
+			totalAmount += taxable + items[i].TaxAmount
+		}
+	}
+
+	return taxAmount, totalAmount
+}
+
+func newTaxBreakdown(items []models.SaleOrderItem) []dto.TaxBreakdownResponse {
+	var breakdown []dto.TaxBreakdownResponse
+	index := map[float64]int{}
+	for _, item := range items {
+		i, ok := index[item.TaxRate]
+		if !ok {
+			i = len(breakdown)
+			index[item.TaxRate] = i
+			breakdown = append(breakdown, dto.TaxBreakdownResponse{Rate: item.TaxRate})
+		}
+
+		breakdown[i].TaxableAmount += item.TaxableAmount
+		breakdown[i].TaxAmount += item.TaxAmount
+	}
+
+	return breakdown
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
@@ -17,12 +18,55 @@ import (
 
 
 type UserService struct {
-	userRepo *repository.UserRepository
+	transactor *repository.Transactor
+	userRepo   *repository.UserRepository
+	storeRepo  *repository.StoreRepository
+	events     *EventService
 }
 
-func NewUserService(userRepo *repository.UserRepository) *UserService {
+func NewUserService(transactor *repository.Transactor, userRepo *repository.UserRepository, storeRepo *repository.StoreRepository, events *EventService) *UserService {
 	return &UserService{
-		userRepo: userRepo,
+		transactor: transactor,
+		userRepo:   userRepo,
+		storeRepo:  storeRepo,
+		events:     events,
+	}
+}
+
+func (s *UserService) resolveStoreID(ctx context.Context, storeID *uuid.UUID) (uuid.NullUUID, error) {
+	if storeID == nil {
+		return uuid.NullUUID{}, nil
+	}
+
+	if _, err := s.storeRepo.GetStoreByID(ctx, *storeID); err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return uuid.NullUUID{}, apperror.ErrInvalidStore
+		}
+		return uuid.NullUUID{}, err
+	}
+
+	return uuid.NullUUID{UUID: *storeID, Valid: true}, nil
+}
+
+func storeIDPtr(storeID uuid.NullUUID) *uuid.UUID {
+	if !storeID.Valid {
+		return nil
+	}
+	return &storeID.UUID
+}
+
+// newCashierEvent is the payload of the cashier domain events.
+func newCashierEvent(user *models.User) dto.UserResponse {
+	return dto.UserResponse{
+		ID:        user.ID,
+		Username:  user.Username,
+		Email:     user.Email,
+		Role:      user.Role,
+		Name:      user.Name,
+		StoreID:   storeIDPtr(user.StoreID),
+		CreatedAt: user.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: user.UpdatedAt.Format(time.RFC3339),
+		Version:   user.Version,
 	}
 }
 
@@ -74,25 +118,37 @@ func (s *UserService) Login(ctx context.Context, req *dto.LoginRequest) (*dto.Lo
 }
 
 func (s *UserService) CreateCashier(ctx context.Context, req *dto.CreateCashierRequest) error {
+	storeID, err := s.resolveStoreID(ctx, req.StoreID)
+	if err != nil {
+		return err
+	}
+
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
 		return err
 	}
 
-	return s.userRepo.InsertUser(
-		ctx,
-		&models.User{
-			ID:        uuid.New(),
-			Username:  req.Username,
-			Password:  string(hashedPassword),
-			Email:     req.Email,
-			Role:      constants.RoleCashier,
-			Name:      req.Name,
-			CreatedAt: time.Now(),
-			UpdatedAt: time.Now(),
-			DeletedAt: sql.NullTime{},
-		},
-	)
+	user := &models.User{
+		ID:        uuid.New(),
+		Username:  req.Username,
+		Password:  string(hashedPassword),
+		Email:     req.Email,
+		Role:      constants.RoleCashier,
+		Name:      req.Name,
+		StoreID:   storeID,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		DeletedAt: sql.NullTime{},
+		Version:   1,
+	}
+
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.userRepo.InsertUser(ctx, user); err != nil {
+			return err
+		}
+
+		return s.events.record(ctx, constants.EventCashierCreated, user.ID, newCashierEvent(user))
+	})
 }
 
 func (s *UserService) GetCashiers(ctx context.Context, limit, offset int) ([]dto.UserResponse, int64, error) {
@@ -109,14 +165,55 @@ func (s *UserService) GetCashiers(ctx context.Context, limit, offset int) ([]dto
 			Email:     user.Email,
 			Role:      user.Role,
 			Name:      user.Name,
+			StoreID:   storeIDPtr(user.StoreID),
 			CreatedAt: user.CreatedAt.Format(time.RFC3339),
 			UpdatedAt: user.UpdatedAt.Format(time.RFC3339),
+			Version:   user.Version,
 		})
 	}
 
 	return responses, totalCount, nil
 }
 
+// GetCashiersPage is the cursor based variant of GetCashiers.
+func (s *UserService) GetCashiersPage(ctx context.Context, cursor *dto.Cursor, limit int, includeTotal bool) ([]dto.UserResponse, dto.CursorPageInfo, error) {
+	page := newKeysetPage(cursor, limit)
+
+	users, err := s.userRepo.GetUsersByRoleKeyset(ctx, "cashier", page)
+	if err != nil {
+		return nil, dto.CursorPageInfo{}, err
+	}
+
+	users, info := trimKeysetPage(users, page, func(u *models.User) models.Keyset {
+		return models.Keyset{CreatedAt: u.CreatedAt, ID: u.ID}
+	})
+
+	if includeTotal {
+		totalCount, err := s.userRepo.CountUsersByRole(ctx, "cashier")
+		if err != nil {
+			return nil, dto.CursorPageInfo{}, err
+		}
+		info.TotalItems = &totalCount
+	}
+
+	responses := make([]dto.UserResponse, 0, len(users))
+	for _, user := range users {
+		responses = append(responses, dto.UserResponse{
+			ID:        user.ID,
+			Username:  user.Username,
+			Email:     user.Email,
+			Role:      user.Role,
+			Name:      user.Name,
+			StoreID:   storeIDPtr(user.StoreID),
+			CreatedAt: user.CreatedAt.Format(time.RFC3339),
+			UpdatedAt: user.UpdatedAt.Format(time.RFC3339),
+			Version:   user.Version,
+		})
+	}
+
+	return responses, info, nil
+}
+
 func (s *UserService) GetCashierByID(ctx context.Context, id string) (*dto.UserResponse, error) {
 	user, err := s.userRepo.GetUserByID(ctx, id)
 	if err != nil {
@@ -133,12 +230,14 @@ func (s *UserService) GetCashierByID(ctx context.Context, id string) (*dto.UserR
 		Email:     user.Email,
 		Role:      user.Role,
 		Name:      user.Name,
+		StoreID:   storeIDPtr(user.StoreID),
 		CreatedAt: user.CreatedAt.Format(time.RFC3339),
 		UpdatedAt: user.UpdatedAt.Format(time.RFC3339),
+		Version:   user.Version,
 	}, nil
 }
 
-func (s *UserService) UpdateCashier(ctx context.Context, id string, req *dto.UpdateCashierRequest) error {
+func (s *UserService) UpdateCashier(ctx context.Context, id string, version int64, req *dto.UpdateCashierRequest) error {
 	user, err := s.userRepo.GetUserByID(ctx, id)
 	if err != nil {
 		return err
@@ -148,6 +247,15 @@ func (s *UserService) UpdateCashier(ctx context.Context, id string, req *dto.Upd
 		return apperror.ErrNotFound
 	}
 
+	if err := checkVersion(version, user.Version); err != nil {
+		return err
+	}
+
+	storeID, err := s.resolveStoreID(ctx, req.StoreID)
+	if err != nil {
+		return err
+	}
+
 	newHashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 
 	if err != nil {
@@ -157,14 +265,21 @@ func (s *UserService) UpdateCashier(ctx context.Context, id string, req *dto.Upd
 	user.Username = req.Username
 	user.Email = req.Email
 	user.Name = req.Name
+	user.StoreID = storeID
 	user.Password = string(newHashedPassword)
 
 	user.UpdatedAt = time.Now()
 
-	return s.userRepo.UpdateUser(ctx, user)
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.userRepo.UpdateUser(ctx, user); err != nil {
+			return err
+		}
+
+		return s.events.record(ctx, constants.EventCashierUpdated, user.ID, newCashierEvent(user))
+	})
 }
 
-func (s *UserService) DeleteCashier(ctx context.Context, id string) error {
+func (s *UserService) DeleteCashier(ctx context.Context, id string, version int64) error {
 	user, err := s.userRepo.GetUserByID(ctx, id)
 	if err != nil {
 		return err
@@ -174,7 +289,17 @@ func (s *UserService) DeleteCashier(ctx context.Context, id string) error {
 		return apperror.ErrNotFound
 	}
 
-	return s.userRepo.DeleteUser(ctx, id)
+	if err := checkVersion(version, user.Version); err != nil {
+		return err
+	}
+
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.userRepo.DeleteUser(ctx, id, user.Version); err != nil {
+			return err
+		}
+
+		return s.events.record(ctx, constants.EventCashierDeleted, user.ID, newCashierEvent(user))
+	})
 }
 
 
@@ -0,0 +1,12 @@
+package service
+
+import apperror "github.com/hafiztri123/kki-be/internal/app_error"
+
+// checkVersion compares the version from If-Match with the stored one. A
+// zero version stands for "If-Match: *" and matches any version.
+func checkVersion(expected, current int64) error {
+	if expected != 0 && expected != current {
+		return apperror.ErrVersionMismatch
+	}
+	return nil
+}
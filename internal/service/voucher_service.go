@@ -0,0 +1,504 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	apperror "github.com/hafiztri123/kki-be/internal/app_error"
+	"github.com/hafiztri123/kki-be/internal/constants"
+	"github.com/hafiztri123/kki-be/internal/dto"
+	"github.com/hafiztri123/kki-be/internal/models"
+	"github.com/hafiztri123/kki-be/internal/money"
+	"github.com/hafiztri123/kki-be/internal/repository"
+)
+
+const (
+	maxVoucherCodesPerCampaign = 10000
+	maxVoucherPrefixLength     = 10
+	maxVoucherCodeLength       = 50
+	voucherCodeGroups          = 2
+	maxVoucherCodeAttempts     = 5
+)
+
+// VoucherService keeps voucher campaigns and their codes. Unlike promotions a
+// voucher only applies to an order when its code is entered. A code is used
+// when its order is completed and given back when the order leaves that
+// status.
+type VoucherService struct {
+	transactor  *repository.Transactor
+	voucherRepo *repository.VoucherRepository
+}
+
+func NewVoucherService(transactor *repository.Transactor, voucherRepo *repository.VoucherRepository) *VoucherService {
+	return &VoucherService{
+		transactor:  transactor,
+		voucherRepo: voucherRepo,
+	}
+}
+
+func newVoucherCampaignResponse(c *models.VoucherCampaign) dto.VoucherCampaignResponse {
+	response := dto.VoucherCampaignResponse{
+		ID:           c.ID,
+		Name:         c.Name,
+		DiscountType: c.DiscountType,
+		MinSpend:     c.MinSpend,
+		Currency:     c.Currency,
+		StartsAt:     c.StartsAt.Format(time.RFC3339),
+		ExpiresAt:    formatNullTime(c.ExpiresAt),
+		CreatedBy:    c.CreatedBy,
+		CreatedAt:    c.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:    c.UpdatedAt.Format(time.RFC3339),
+	}
+
+	if c.Percentage.Valid {
+		response.Percentage = &c.Percentage.Float64
+	}
+	if c.Amount.Valid {
+		response.Amount = &c.Amount.Amount
+	}
+	if c.MaxDiscount.Valid {
+		response.MaxDiscount = &c.MaxDiscount.Amount
+	}
+	if c.MaxUsesPerCode.Valid {
+		v := int(c.MaxUsesPerCode.Int32)
+		response.MaxUsesPerCode = &v
+	}
+	if c.MaxUsesPerCustomer.Valid {
+		v := int(c.MaxUsesPerCustomer.Int32)
+		response.MaxUsesPerCustomer = &v
+	}
+
+	return response
+}
+
+// normalizeVoucherCode accepts codes typed in lower case or with stray spaces
+// around them.
+func normalizeVoucherCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// generateVoucherCode returns a random code such as PREFIX-ABCD-EFGH. It uses
+// the gift card alphabet, so codes are as hard to misread.
+func generateVoucherCode(prefix string) string {
+	code := generateGiftCardCode()[:voucherCodeGroups*(giftCardCodeGroupLength+1)-1]
+	if prefix == "" {
+		return code
+	}
+
+	return prefix + "-" + code
+}
+
+func validVoucherCodeChars(code string) bool {
+	for _, c := range code {
+		if (c < 'A' || c > 'Z') && (c < '0' || c > '9') && c != '-' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func validateVoucherCampaignRequest(req *dto.CreateVoucherCampaignRequest, now time.Time) error {
+	if strings.TrimSpace(req.Name) == "" || req.MinSpend < 0 {
+		return apperror.ErrInvalidVoucherCampaign
+	}
+
+	switch req.DiscountType {
+	case constants.VoucherDiscountPercentage:
+		if req.Percentage == nil || *req.Percentage <= 0 || *req.Percentage > 100 {
+			return apperror.ErrInvalidVoucherCampaign
+		}
+		if req.MaxDiscount != nil && *req.MaxDiscount <= 0 {
+			return apperror.ErrInvalidVoucherCampaign
+		}
+	case constants.VoucherDiscountFixedAmount:
+		if req.Amount == nil || *req.Amount <= 0 || req.MaxDiscount != nil {
+			return apperror.ErrInvalidVoucherCampaign
+		}
+	default:
+		return apperror.ErrInvalidVoucherCampaign
+	}
+
+	if (req.MaxUsesPerCode != nil && *req.MaxUsesPerCode <= 0) || (req.MaxUsesPerCustomer != nil && *req.MaxUsesPerCustomer <= 0) {
+		return apperror.ErrInvalidVoucherCampaign
+	}
+
+	if req.ExpiresAt != nil {
+		startsAt := now
+		if req.StartsAt != nil {
+			startsAt = *req.StartsAt
+		}
+		if !req.ExpiresAt.After(startsAt) || !req.ExpiresAt.After(now) {
+			return apperror.ErrInvalidVoucherCampaign
+		}
+	}
+
+	code := normalizeVoucherCode(req.Code)
+	if code != "" {
+		if req.Quantity > 1 || len(code) > maxVoucherCodeLength || !validVoucherCodeChars(code) {
+			return apperror.ErrInvalidVoucherCampaign
+		}
+		return nil
+	}
+
+	prefix := normalizeVoucherCode(req.Prefix)
+	if req.Quantity <= 0 || req.Quantity > maxVoucherCodesPerCampaign || len(prefix) > maxVoucherPrefixLength || !validVoucherCodeChars(prefix) {
+		return apperror.ErrInvalidVoucherCampaign
+	}
+
+	return nil
+}
+
+// CreateVoucherCampaign creates a campaign with either the one code the owner
+// chose or a batch of generated codes.
+func (s *VoucherService) CreateVoucherCampaign(ctx context.Context, caller dto.Caller, req *dto.CreateVoucherCampaignRequest) (*dto.VoucherCampaignResponse, error) {
+	now := time.Now()
+	if err := validateVoucherCampaignRequest(req, now); err != nil {
+		return nil, err
+	}
+
+	currency := money.DefaultCurrency
+	if code := strings.TrimSpace(req.Currency); code != "" {
+		var ok bool
+		if currency, ok = money.ParseCurrency(code); !ok {
+			return nil, apperror.ErrInvalidVoucherCampaign
+		}
+	}
+
+	campaign := &models.VoucherCampaign{
+		ID:                 uuid.New(),
+		Name:               strings.TrimSpace(req.Name),
+		DiscountType:       req.DiscountType,
+		MinSpend:           req.MinSpend,
+		Currency:           currency,
+		MaxUsesPerCode:     nullInt32(req.MaxUsesPerCode),
+		MaxUsesPerCustomer: nullInt32(req.MaxUsesPerCustomer),
+		StartsAt:           now,
+		CreatedBy:          caller.ID,
+		CreatedAt:          now,
+		UpdatedAt:          now,
+	}
+
+	switch req.DiscountType {
+	case constants.VoucherDiscountPercentage:
+		campaign.Percentage = nullFloat64(req.Percentage)
+		if req.MaxDiscount != nil {
+			campaign.MaxDiscount = money.NullAmount{Amount: *req.MaxDiscount, Valid: true}
+		}
+	case constants.VoucherDiscountFixedAmount:
+		campaign.Amount = money.NullAmount{Amount: *req.Amount, Valid: true}
+	}
+
+	if req.StartsAt != nil {
+		campaign.StartsAt = req.StartsAt.In(time.Local)
+	}
+	if req.ExpiresAt != nil {
+		campaign.ExpiresAt = sql.NullTime{Time: req.ExpiresAt.In(time.Local), Valid: true}
+	}
+
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.voucherRepo.InsertCampaign(ctx, campaign); err != nil {
+			return err
+		}
+
+		if code := normalizeVoucherCode(req.Code); code != "" {
+			return s.voucherRepo.InsertCode(ctx, campaign.ID, code, now)
+		}
+
+		return s.insertCodes(ctx, campaign.ID, normalizeVoucherCode(req.Prefix), req.Quantity, now)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetVoucherCampaign(ctx, campaign.ID)
+}
+
+// insertCodes generates quantity codes for a campaign. Codes that collide
+// with existing ones are skipped by the insert and drawn again.
+func (s *VoucherService) insertCodes(ctx context.Context, campaignID uuid.UUID, prefix string, quantity int, now time.Time) error {
+	for attempt := 0; attempt < maxVoucherCodeAttempts && quantity > 0; attempt++ {
+		seen := make(map[string]struct{}, quantity)
+		codes := make([]string, 0, quantity)
+		for len(codes) < quantity {
+			code := generateVoucherCode(prefix)
+			if _, ok := seen[code]; ok {
+				continue
+			}
+			seen[code] = struct{}{}
+			codes = append(codes, code)
+		}
+
+		inserted, err := s.voucherRepo.InsertCodes(ctx, campaignID, codes, now)
+		if err != nil {
+			return err
+		}
+		quantity -= int(inserted)
+	}
+
+	if quantity > 0 {
+		return apperror.ErrVoucherCodeConflict
+	}
+
+	return nil
+}
+
+func (s *VoucherService) GetVoucherCampaigns(ctx context.Context, limit, offset int) ([]dto.VoucherCampaignResponse, int64, error) {
+	campaigns, totalCount, err := s.voucherRepo.GetCampaigns(ctx, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	responses := make([]dto.VoucherCampaignResponse, 0, len(campaigns))
+	for i := range campaigns {
+		responses = append(responses, newVoucherCampaignResponse(&campaigns[i]))
+	}
+
+	return responses, totalCount, nil
+}
+
+// GetVoucherCampaign returns a campaign with counts of how its codes were
+// used.
+func (s *VoucherService) GetVoucherCampaign(ctx context.Context, id uuid.UUID) (*dto.VoucherCampaignResponse, error) {
+	campaign, err := s.voucherRepo.GetCampaignByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := s.voucherRepo.GetCampaignStats(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := newVoucherCampaignResponse(campaign)
+	response.Stats = &dto.VoucherStats{
+		Codes:          stats.Codes,
+		CodesUsed:      stats.CodesUsed,
+		Redemptions:    stats.Redemptions,
+		DiscountAmount: stats.DiscountAmount,
+	}
+
+	return &response, nil
+}
+
+// WriteVoucherCodesCSV writes the codes of a campaign to w, one row per code
+// with how often it was used, for printing.
+func (s *VoucherService) WriteVoucherCodesCSV(ctx context.Context, id uuid.UUID, w io.Writer) error {
+	out := csv.NewWriter(w)
+	if err := out.Write([]string{"code", "use_count", "max_uses", "expires_at"}); err != nil {
+		return err
+	}
+
+	err := s.voucherRepo.EachCode(ctx, id, func(v *models.Voucher) error {
+		maxUses := ""
+		if v.Campaign.MaxUsesPerCode.Valid {
+			maxUses = strconv.Itoa(int(v.Campaign.MaxUsesPerCode.Int32))
+		}
+
+		return out.Write([]string{v.Code, strconv.Itoa(v.UseCount), maxUses, formatNullTime(v.Campaign.ExpiresAt)})
+	})
+	if err != nil {
+		return err
+	}
+
+	out.Flush()
+	return out.Error()
+}
+
+// rejectVoucher returns why v cannot be used at now on an order in currency
+// by customerID, or an empty reason when it can. The minimum spend is left to
+// the caller, which knows what the order is worth.
+func (s *VoucherService) rejectVoucher(ctx context.Context, v *models.Voucher, currency money.Currency, customerID uuid.NullUUID, now time.Time) (string, error) {
+	c := &v.Campaign
+
+	if now.Before(c.StartsAt) {
+		return constants.VoucherRejectNotStarted, nil
+	}
+
+	if c.ExpiresAt.Valid && !now.Before(c.ExpiresAt.Time) {
+		return constants.VoucherRejectExpired, nil
+	}
+
+	if c.Currency != currency {
+		return constants.VoucherRejectCurrency, nil
+	}
+
+	if c.MaxUsesPerCode.Valid && v.UseCount >= int(c.MaxUsesPerCode.Int32) {
+		return constants.VoucherRejectUsedUp, nil
+	}
+
+	if !c.MaxUsesPerCustomer.Valid {
+		return "", nil
+	}
+
+	if !customerID.Valid {
+		return constants.VoucherRejectCustomerRequired, nil
+	}
+
+	used, err := s.voucherRepo.CountCustomerRedemptions(ctx, c.ID, customerID.UUID)
+	if err != nil {
+		return "", err
+	}
+
+	if used >= int(c.MaxUsesPerCustomer.Int32) {
+		return constants.VoucherRejectCustomerLimit, nil
+	}
+
+	return "", nil
+}
+
+// ValidateVoucher is asked by the till before a code is applied. A code that
+// cannot be used is not an error; the response says why.
+func (s *VoucherService) ValidateVoucher(ctx context.Context, req *dto.ValidateVoucherRequest) (*dto.ValidateVoucherResponse, error) {
+	if req.Amount < 0 {
+		return nil, apperror.ErrVoucherNotApplicable
+	}
+
+	currency := money.DefaultCurrency
+	if code := strings.TrimSpace(req.Currency); code != "" {
+		var ok bool
+		if currency, ok = money.ParseCurrency(code); !ok {
+			return nil, apperror.ErrVoucherNotApplicable
+		}
+	}
+
+	response := &dto.ValidateVoucherResponse{Code: normalizeVoucherCode(req.Code)}
+
+	voucher, err := s.voucherRepo.GetVoucherByCode(ctx, response.Code)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			response.Reason = constants.VoucherRejectNotFound
+			return response, nil
+		}
+		return nil, err
+	}
+
+	response.Reason, err = s.rejectVoucher(ctx, voucher, currency, nullUUID(req.CustomerID), time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if response.Reason == "" && req.Amount < voucher.Campaign.MinSpend {
+		response.Reason = constants.VoucherRejectMinSpend
+	}
+
+	if response.Reason == "" {
+		response.Valid = true
+		response.DiscountAmount = voucherDiscount(&voucher.Campaign, req.Amount)
+	}
+
+	return response, nil
+}
+
+// appliedVoucher returns the code applied to a sale order, or nil.
+func (s *VoucherService) appliedVoucher(ctx context.Context, saleOrderID uuid.UUID) (*models.SaleOrderVoucher, error) {
+	applied, err := s.voucherRepo.GetSaleOrderVoucher(ctx, saleOrderID)
+	if errors.Is(err, apperror.ErrNotFound) {
+		return nil, nil
+	}
+
+	return applied, err
+}
+
+// orderVoucher loads the code applied to a sale order with its campaign, so
+// the order can be repriced with it. It returns nil when there is none.
+func (s *VoucherService) orderVoucher(ctx context.Context, saleOrderID uuid.UUID) (*models.Voucher, error) {
+	applied, err := s.appliedVoucher(ctx, saleOrderID)
+	if err != nil || applied == nil {
+		return nil, err
+	}
+
+	return s.voucherRepo.GetVoucherByID(ctx, applied.VoucherID)
+}
+
+// settleSaleOrder uses the code applied to an order once it is completed and
+// gives the use back when it leaves that status. The code is locked and its
+// rules checked again, so two tills cannot use it past its limits; when they
+// no longer allow it the completion fails with ErrVoucherNotApplicable.
+func (s *VoucherService) settleSaleOrder(ctx context.Context, saleOrder *models.SaleOrder, from, to string) error {
+	if from == to {
+		return nil
+	}
+
+	if to == constants.SaleOrderStatusCompleted {
+		return s.redeem(ctx, saleOrder)
+	}
+
+	if from == constants.SaleOrderStatusCompleted {
+		return s.reverse(ctx, saleOrder.ID)
+	}
+
+	return nil
+}
+
+func (s *VoucherService) redeem(ctx context.Context, saleOrder *models.SaleOrder) error {
+	applied, err := s.appliedVoucher(ctx, saleOrder.ID)
+	if err != nil || applied == nil {
+		return err
+	}
+
+	voucher, err := s.voucherRepo.GetVoucherByIDForUpdate(ctx, applied.VoucherID)
+	if err != nil {
+		return err
+	}
+
+	// Orders of different codes of the campaign count towards the same
+	// customer limit, so they queue on the campaign.
+	if voucher.Campaign.MaxUsesPerCustomer.Valid {
+		if _, err := s.voucherRepo.GetCampaignByIDForUpdate(ctx, voucher.CampaignID); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now()
+	reason, err := s.rejectVoucher(ctx, voucher, saleOrder.Currency, saleOrder.CustomerID, now)
+	if err != nil {
+		return err
+	}
+
+	if reason != "" {
+		return apperror.ErrVoucherNotApplicable
+	}
+
+	if err := s.voucherRepo.AddUseCount(ctx, voucher.ID, 1); err != nil {
+		return err
+	}
+
+	return s.voucherRepo.InsertRedemption(ctx, &models.VoucherRedemption{
+		ID:             uuid.New(),
+		VoucherID:      voucher.ID,
+		CampaignID:     voucher.CampaignID,
+		SaleOrderID:    saleOrder.ID,
+		CustomerID:     saleOrder.CustomerID,
+		DiscountAmount: applied.DiscountAmount,
+		RedeemedAt:     now,
+	})
+}
+
+// reverse gives back the use of a code by an order that was refunded.
+func (s *VoucherService) reverse(ctx context.Context, saleOrderID uuid.UUID) error {
+	redemption, err := s.voucherRepo.GetActiveRedemption(ctx, saleOrderID)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	if _, err := s.voucherRepo.GetVoucherByIDForUpdate(ctx, redemption.VoucherID); err != nil {
+		return err
+	}
+
+	if err := s.voucherRepo.AddUseCount(ctx, redemption.VoucherID, -1); err != nil {
+		return err
+	}
+
+	return s.voucherRepo.ReverseRedemption(ctx, redemption.ID, time.Now())
+}
@@ -0,0 +1,467 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	apperror "github.com/hafiztri123/kki-be/internal/app_error"
+	"github.com/hafiztri123/kki-be/internal/constants"
+	"github.com/hafiztri123/kki-be/internal/dto"
+	"github.com/hafiztri123/kki-be/internal/events"
+	"github.com/hafiztri123/kki-be/internal/models"
+	"github.com/hafiztri123/kki-be/internal/repository"
+)
+
+const (
+	webhookBatchSize = 10
+	// maxWebhookAttempts is how often a delivery is tried before it is dead.
+	// The waits between attempts double from webhookRetryDelay, about two
+	// hours in all.
+	maxWebhookAttempts = 8
+	webhookRetryDelay  = time.Minute
+	webhookTimeout     = 10 * time.Second
+	// webhookLease is how long claimed deliveries are kept from other
+	// workers. It has to outlast sending a whole batch.
+	webhookLease                = 5 * time.Minute
+	maxWebhookURLLength         = 2048
+	maxWebhookDescriptionLength = 255
+	maxWebhookResponseLength    = 200
+)
+
+// webhookEvents are the events endpoints can subscribe to.
+var webhookEvents = []string{
+	constants.EventSaleOrderCreated,
+	constants.EventSaleOrderUpdated,
+	constants.EventSaleOrderStatusChanged,
+	constants.EventSaleOrderDeleted,
+	constants.EventCashierCreated,
+	constants.EventCashierUpdated,
+	constants.EventCashierDeleted,
+}
+
+// WebhookService tells registered endpoints about domain events. Events are
+// written as deliveries when they are dispatched and a background worker
+// posts them, signed with the endpoint's secret, retrying failures until the
+// delivery is dead.
+type WebhookService struct {
+	webhookRepo *repository.WebhookRepository
+	client      *http.Client
+}
+
+func NewWebhookService(webhookRepo *repository.WebhookRepository, client *http.Client) *WebhookService {
+	return &WebhookService{
+		webhookRepo: webhookRepo,
+		client:      client,
+	}
+}
+
+func newWebhookClient() *http.Client {
+	return &http.Client{
+		Timeout: webhookTimeout,
+		// A redirect counts as a failure like any other status that is not
+		// 2xx, so an endpoint cannot send deliveries somewhere else.
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+func newWebhookEndpointResponse(e *models.WebhookEndpoint) dto.WebhookEndpointResponse {
+	return dto.WebhookEndpointResponse{
+		ID:          e.ID,
+		URL:         e.URL,
+		Description: e.Description.String,
+		Events:      e.Events,
+		Active:      e.Active,
+		CreatedBy:   e.CreatedBy,
+		CreatedAt:   e.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:   e.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+func newWebhookDeliveryResponse(d *models.WebhookDelivery) dto.WebhookDeliveryResponse {
+	response := dto.WebhookDeliveryResponse{
+		ID:          d.ID,
+		EndpointID:  d.EndpointID,
+		EventID:     d.EventID,
+		EventType:   d.EventType,
+		Payload:     d.Payload,
+		Status:      d.Status,
+		Attempts:    d.Attempts,
+		LastError:   d.LastError.String,
+		DeliveredAt: formatNullTime(d.DeliveredAt),
+		CreatedAt:   d.CreatedAt.Format(time.RFC3339),
+	}
+
+	if d.Status == constants.WebhookDeliveryPending {
+		response.NextAttemptAt = d.NextAttemptAt.Format(time.RFC3339)
+	}
+	if d.LastStatusCode.Valid {
+		response.LastStatusCode = &d.LastStatusCode.Int32
+	}
+
+	return response
+}
+
+func generateWebhookSecret() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return "whsec_" + hex.EncodeToString(b)
+}
+
+// signWebhook signs a delivery body sent at timestamp. Receivers recompute
+// the HMAC over "<timestamp>.<body>" and reject old timestamps to stop
+// replays.
+func signWebhook(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(body)
+	return fmt.Sprintf("t=%d,v1=%s", timestamp, hex.EncodeToString(mac.Sum(nil)))
+}
+
+// applyWebhookEndpointRequest validates req and copies it onto e. Events are
+// kept in the order given, without repeats.
+func applyWebhookEndpointRequest(e *models.WebhookEndpoint, req *dto.WebhookEndpointRequest) error {
+	rawURL := strings.TrimSpace(req.URL)
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || len(rawURL) > maxWebhookURLLength {
+		return apperror.ErrInvalidWebhookEndpoint
+	}
+
+	description := strings.TrimSpace(req.Description)
+	if len(description) > maxWebhookDescriptionLength {
+		return apperror.ErrInvalidWebhookEndpoint
+	}
+
+	events := make([]string, 0, len(req.Events))
+	for _, event := range req.Events {
+		if !slices.Contains(webhookEvents, event) {
+			return apperror.ErrInvalidWebhookEndpoint
+		}
+		if !slices.Contains(events, event) {
+			events = append(events, event)
+		}
+	}
+	if len(events) == 0 {
+		return apperror.ErrInvalidWebhookEndpoint
+	}
+
+	e.URL = rawURL
+	e.Description = sql.NullString{String: description, Valid: description != ""}
+	e.Events = events
+	if req.Active != nil {
+		e.Active = *req.Active
+	}
+
+	return nil
+}
+
+// CreateWebhookEndpoint registers an endpoint with a new secret. The secret
+// is only returned here, for the owner to configure the receiver with.
+func (s *WebhookService) CreateWebhookEndpoint(ctx context.Context, caller dto.Caller, req *dto.WebhookEndpointRequest) (*dto.WebhookEndpointResponse, error) {
+	now := time.Now()
+	endpoint := &models.WebhookEndpoint{
+		ID:        uuid.New(),
+		Secret:    generateWebhookSecret(),
+		Active:    true,
+		CreatedBy: caller.ID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := applyWebhookEndpointRequest(endpoint, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.webhookRepo.InsertEndpoint(ctx, endpoint); err != nil {
+		return nil, err
+	}
+
+	response := newWebhookEndpointResponse(endpoint)
+	response.Secret = endpoint.Secret
+	return &response, nil
+}
+
+func (s *WebhookService) GetWebhookEndpoints(ctx context.Context, limit, offset int) ([]dto.WebhookEndpointResponse, int64, error) {
+	endpoints, totalCount, err := s.webhookRepo.GetEndpoints(ctx, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	responses := make([]dto.WebhookEndpointResponse, len(endpoints))
+	for i := range endpoints {
+		responses[i] = newWebhookEndpointResponse(&endpoints[i])
+	}
+
+	return responses, totalCount, nil
+}
+
+func (s *WebhookService) GetWebhookEndpoint(ctx context.Context, id uuid.UUID) (*dto.WebhookEndpointResponse, error) {
+	endpoint, err := s.webhookRepo.GetEndpointByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := newWebhookEndpointResponse(endpoint)
+	return &response, nil
+}
+
+// UpdateWebhookEndpoint changes the URL, events or state of an endpoint.
+// Deliveries already queued go to the new URL.
+func (s *WebhookService) UpdateWebhookEndpoint(ctx context.Context, id uuid.UUID, req *dto.WebhookEndpointRequest) (*dto.WebhookEndpointResponse, error) {
+	endpoint, err := s.webhookRepo.GetEndpointByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := applyWebhookEndpointRequest(endpoint, req); err != nil {
+		return nil, err
+	}
+	endpoint.UpdatedAt = time.Now()
+
+	if err := s.webhookRepo.UpdateEndpoint(ctx, endpoint); err != nil {
+		return nil, err
+	}
+
+	response := newWebhookEndpointResponse(endpoint)
+	return &response, nil
+}
+
+// DeleteWebhookEndpoint soft deletes an endpoint. Its pending deliveries die
+// when the worker next picks them up.
+func (s *WebhookService) DeleteWebhookEndpoint(ctx context.Context, id uuid.UUID) error {
+	return s.webhookRepo.DeleteEndpoint(ctx, id)
+}
+
+// GetWebhookDeliveries is the delivery log of an endpoint.
+func (s *WebhookService) GetWebhookDeliveries(ctx context.Context, endpointID uuid.UUID, status, eventType string, limit, offset int) ([]dto.WebhookDeliveryResponse, int64, error) {
+	if _, err := s.webhookRepo.GetEndpointByID(ctx, endpointID); err != nil {
+		return nil, 0, err
+	}
+
+	deliveries, totalCount, err := s.webhookRepo.GetDeliveries(ctx, endpointID, status, eventType, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	responses := make([]dto.WebhookDeliveryResponse, len(deliveries))
+	for i := range deliveries {
+		responses[i] = newWebhookDeliveryResponse(&deliveries[i])
+	}
+
+	return responses, totalCount, nil
+}
+
+// RedeliverWebhookDelivery queues the event of a delivery to its endpoint
+// again as a new delivery, whatever became of the first. The event keeps its
+// id and payload.
+func (s *WebhookService) RedeliverWebhookDelivery(ctx context.Context, id uuid.UUID) (*dto.WebhookDeliveryResponse, error) {
+	delivery, err := s.webhookRepo.GetDeliveryByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint, err := s.webhookRepo.GetEndpointByID(ctx, delivery.EndpointID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !endpoint.Active {
+		return nil, apperror.ErrWebhookEndpointInactive
+	}
+
+	redelivery := newWebhookDelivery(endpoint.ID, delivery.EventID, delivery.EventType, delivery.Payload, time.Now())
+	if err := s.webhookRepo.InsertDelivery(ctx, redelivery); err != nil {
+		return nil, err
+	}
+
+	response := newWebhookDeliveryResponse(redelivery)
+	return &response, nil
+}
+
+func newWebhookDelivery(endpointID, eventID uuid.UUID, eventType string, payload json.RawMessage, now time.Time) *models.WebhookDelivery {
+	return &models.WebhookDelivery{
+		ID:            uuid.New(),
+		EndpointID:    endpointID,
+		EventID:       eventID,
+		EventType:     eventType,
+		Payload:       payload,
+		Status:        constants.WebhookDeliveryPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+}
+
+// subscribe registers the handler that queues deliveries on the bus.
+func (s *WebhookService) subscribe(bus *events.Bus) {
+	bus.Subscribe("webhooks", s.queueDeliveries, webhookEvents...)
+}
+
+// queueDeliveries queues a domain event for every active endpoint subscribed
+// to it. The webhook event takes the id of the domain event, so receivers
+// can drop duplicates.
+func (s *WebhookService) queueDeliveries(ctx context.Context, event *models.DomainEvent) error {
+	endpoints, err := s.webhookRepo.GetSubscribedEndpoints(ctx, event.EventType)
+	if err != nil || len(endpoints) == 0 {
+		return err
+	}
+
+	payload, err := json.Marshal(dto.WebhookEvent{
+		ID:        event.ID,
+		Type:      event.EventType,
+		CreatedAt: event.CreatedAt.Format(time.RFC3339),
+		Data:      event.Payload,
+	})
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, endpoint := range endpoints {
+		if err := s.webhookRepo.InsertDelivery(ctx, newWebhookDelivery(endpoint.ID, event.ID, event.EventType, payload, now)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DeliverWebhooks posts the deliveries that are due. Each batch is claimed
+// for a lease before it is sent, so workers running side by side never post
+// the same delivery and no transaction is held open over the network.
+func (s *WebhookService) DeliverWebhooks(ctx context.Context) error {
+	var delivered, dead int
+	for {
+		now := time.Now()
+		deliveries, err := s.webhookRepo.ClaimDueDeliveries(ctx, now, now.Add(webhookLease), webhookBatchSize)
+		if err != nil {
+			return err
+		}
+
+		endpoints := make(map[uuid.UUID]*models.WebhookEndpoint)
+		for i := range deliveries {
+			delivery := &deliveries[i]
+
+			endpoint, ok := endpoints[delivery.EndpointID]
+			if !ok {
+				endpoint, err = s.webhookRepo.GetEndpointByID(ctx, delivery.EndpointID)
+				if err != nil && !errors.Is(err, apperror.ErrNotFound) {
+					return err
+				}
+				endpoints[delivery.EndpointID] = endpoint
+			}
+
+			if err := s.attempt(ctx, endpoint, delivery); err != nil {
+				return err
+			}
+
+			switch delivery.Status {
+			case constants.WebhookDeliveryDelivered:
+				delivered++
+			case constants.WebhookDeliveryDead:
+				dead++
+			}
+		}
+
+		if len(deliveries) < webhookBatchSize {
+			break
+		}
+	}
+
+	if delivered > 0 || dead > 0 {
+		slog.InfoContext(ctx, "webhooks delivered", "delivered", delivered, "dead", dead)
+	}
+
+	return nil
+}
+
+// attempt sends a delivery once and records the outcome. Deliveries to
+// endpoints that were deleted or switched off die straight away; they can be
+// redelivered once the endpoint is active again.
+func (s *WebhookService) attempt(ctx context.Context, endpoint *models.WebhookEndpoint, delivery *models.WebhookDelivery) error {
+	now := time.Now()
+	delivery.UpdatedAt = now
+
+	var sendErr error
+	switch {
+	case endpoint == nil:
+		sendErr = errors.New("endpoint was deleted")
+		delivery.Status = constants.WebhookDeliveryDead
+	case !endpoint.Active:
+		sendErr = errors.New("endpoint is inactive")
+		delivery.Status = constants.WebhookDeliveryDead
+	default:
+		delivery.Attempts++
+
+		var statusCode int
+		statusCode, sendErr = s.send(ctx, endpoint, delivery)
+		delivery.LastStatusCode = sql.NullInt32{Int32: int32(statusCode), Valid: statusCode != 0}
+		delivery.UpdatedAt = time.Now()
+
+		if sendErr == nil {
+			delivery.Status = constants.WebhookDeliveryDelivered
+			delivery.DeliveredAt = sql.NullTime{Time: delivery.UpdatedAt, Valid: true}
+			delivery.LastError = sql.NullString{}
+		} else if delivery.Attempts >= maxWebhookAttempts {
+			delivery.Status = constants.WebhookDeliveryDead
+		} else {
+			delivery.NextAttemptAt = delivery.UpdatedAt.Add(webhookRetryDelay << (delivery.Attempts - 1))
+		}
+	}
+
+	if sendErr != nil {
+		delivery.LastError = sql.NullString{String: truncateError(sendErr), Valid: true}
+	}
+
+	return s.webhookRepo.UpdateDeliveryAttempt(ctx, delivery)
+}
+
+// send posts a delivery to its endpoint and returns the status code it
+// answered with, zero when there was no answer. Only a 2xx status counts as
+// delivered.
+func (s *WebhookService) send(ctx context.Context, endpoint *models.WebhookEndpoint, delivery *models.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "kki-be-webhooks")
+	req.Header.Set("X-Webhook-ID", delivery.EventID.String())
+	req.Header.Set("X-Webhook-Delivery", delivery.ID.String())
+	req.Header.Set("X-Webhook-Event", delivery.EventType)
+	req.Header.Set("X-Webhook-Timestamp", strconv.FormatInt(timestamp, 10))
+	req.Header.Set("X-Webhook-Signature", signWebhook(endpoint.Secret, timestamp, delivery.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxWebhookResponseLength))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("endpoint answered %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,25 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// RunEvery calls fn every interval until ctx is cancelled. Failures are logged
+// and the next tick tries again.
+func RunEvery(ctx context.Context, interval time.Duration, name string, fn func(ctx context.Context) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := fn(ctx); err != nil {
+				slog.ErrorContext(ctx, "background job failed", "job", name, "error", err.Error())
+			}
+		}
+	}
+}
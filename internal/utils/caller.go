@@ -0,0 +1,24 @@
+package utils
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/hafiztri123/kki-be/internal/constants"
+	"github.com/hafiztri123/kki-be/internal/dto"
+)
+
+// GetCaller reads the caller set by the JWT middleware from the context.
+func GetCaller(ctx context.Context) (dto.Caller, bool) {
+	id, ok := ctx.Value(constants.ClaimsKeyID).(uuid.UUID)
+	if !ok {
+		return dto.Caller{}, false
+	}
+
+	role, ok := ctx.Value(constants.ClaimsKeyRole).(string)
+	if !ok {
+		return dto.Caller{}, false
+	}
+
+	return dto.Caller{ID: id, Role: role}, true
+}
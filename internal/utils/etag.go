@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	apperror "github.com/hafiztri123/kki-be/internal/app_error"
+)
+
+// SetETag exposes the row version of a resource as a strong ETag.
+func SetETag(w http.ResponseWriter, version int64) {
+	w.Header().Set("ETag", `"`+strconv.FormatInt(version, 10)+`"`)
+}
+
+// ParseIfMatch returns the version a client expects to change. "If-Match: *"
+// returns 0, which matches any version. Weak or malformed tags never match.
+func ParseIfMatch(r *http.Request) (int64, error) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" {
+		return 0, apperror.ErrPreconditionRequired
+	}
+
+	if header == "*" {
+		return 0, nil
+	}
+
+	tag, ok := strings.CutPrefix(header, `"`)
+	if !ok {
+		return 0, apperror.ErrVersionMismatch
+	}
+	tag, ok = strings.CutSuffix(tag, `"`)
+	if !ok {
+		return 0, apperror.ErrVersionMismatch
+	}
+
+	version, err := strconv.ParseInt(tag, 10, 64)
+	if err != nil || version <= 0 {
+		return 0, apperror.ErrVersionMismatch
+	}
+
+	return version, nil
+}
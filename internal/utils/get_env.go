@@ -17,4 +17,12 @@ func GetEnv(key string) string {
 
 	slog.Error(constants.MsgEnvNotFound, "env", key)
 	panic(constants.MsgEnvNotFound)
-}
\ No newline at end of file
+}
+
+func GetEnvOrDefault(key string, defaultValue string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+
+	return defaultValue
+}
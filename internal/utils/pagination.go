@@ -1,20 +1,36 @@
 package utils
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
 	"math"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/google/uuid"
+	apperror "github.com/hafiztri123/kki-be/internal/app_error"
 	"github.com/hafiztri123/kki-be/internal/dto"
 )
 
+const (
+	DefaultPageLimit = 10
+	MaxPageLimit     = 100
+)
+
 func ParsePaginationParams(r *http.Request) dto.PaginationRequest {
-	limitStr := r.URL.Query().Get("limit")
-	pageStr := r.URL.Query().Get("page")
+	query := r.URL.Query()
+	limitStr := query.Get("limit")
+	pageStr := query.Get("page")
 
 	limit, err := strconv.Atoi(limitStr)
 	if err != nil || limit <= 0 {
-		limit = 10 
+		limit = DefaultPageLimit
+	}
+	if limit > MaxPageLimit {
+		limit = MaxPageLimit
 	}
 
 	page, err := strconv.Atoi(pageStr)
@@ -22,9 +38,14 @@ func ParsePaginationParams(r *http.Request) dto.PaginationRequest {
 		page = 1
 	}
 
+	includeTotal, _ := strconv.ParseBool(query.Get("include_total"))
+
 	return dto.PaginationRequest{
-		Limit: limit,
-		Page:  page,
+		Limit:        limit,
+		Page:         page,
+		UseCursor:    query.Has("cursor"),
+		Cursor:       query.Get("cursor"),
+		IncludeTotal: includeTotal,
 	}
 }
 
@@ -37,11 +58,111 @@ func CalculateTotalPages(totalItems int64, limit int) int {
 }
 
 func NewPaginatedResponse(data interface{}, totalItems int64, page, limit int) dto.PaginatedResponse {
+	totalPages := CalculateTotalPages(totalItems, limit)
+
+	return dto.PaginatedResponse{
+		Data:       data,
+		TotalItems: &totalItems,
+		TotalPages: &totalPages,
+		Page:       &page,
+		Limit:      limit,
+	}
+}
+
+func NewCursorPaginatedResponse(data interface{}, info dto.CursorPageInfo, limit int) dto.PaginatedResponse {
 	return dto.PaginatedResponse{
 		Data:       data,
-		TotalItems: totalItems,
-		TotalPages: CalculateTotalPages(totalItems, limit),
-		Page:       page,
+		TotalItems: info.TotalItems,
 		Limit:      limit,
+		NextCursor: info.NextCursor,
+		PrevCursor: info.PrevCursor,
+	}
+}
+
+type cursorPayload struct {
+	CreatedAt string    `json:"t"`
+	ID        uuid.UUID `json:"id"`
+	Backward  bool      `json:"b,omitempty"`
+}
+
+// EncodeCursor turns a cursor into the opaque string handed to clients.
+func EncodeCursor(c dto.Cursor) string {
+	payload, _ := json.Marshal(cursorPayload{
+		CreatedAt: c.CreatedAt.Format(time.RFC3339Nano),
+		ID:        c.ID,
+		Backward:  c.Backward,
+	})
+	return base64.RawURLEncoding.EncodeToString(payload)
+}
+
+// DecodeCursor parses a cursor produced by EncodeCursor. An empty string is
+// the first page and decodes to nil.
+func DecodeCursor(s string) (*dto.Cursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, apperror.ErrInvalidCursor
+	}
+
+	var payload cursorPayload
+	if err := json.Unmarshal(raw, &payload); err != nil || payload.ID == uuid.Nil {
+		return nil, apperror.ErrInvalidCursor
+	}
+
+	createdAt, err := time.Parse(time.RFC3339Nano, payload.CreatedAt)
+	if err != nil {
+		return nil, apperror.ErrInvalidCursor
 	}
+
+	return &dto.Cursor{CreatedAt: createdAt, ID: payload.ID, Backward: payload.Backward}, nil
+}
+
+// pageLink returns an RFC 8288 link to the current URL with the given query
+// parameters replaced.
+func pageLink(r *http.Request, rel string, params map[string]string) string {
+	u := *r.URL
+	query := u.Query()
+	for k, v := range params {
+		query.Set(k, v)
+	}
+	u.RawQuery = query.Encode()
+
+	return fmt.Sprintf(`<%s>; rel="%s"`, u.RequestURI(), rel)
+}
+
+// SetPageLinks sets the Link header for a page based list.
+func SetPageLinks(w http.ResponseWriter, r *http.Request, pagination dto.PaginationRequest, totalItems int64) {
+	totalPages := max(CalculateTotalPages(totalItems, pagination.Limit), 1)
+	limit := strconv.Itoa(pagination.Limit)
+
+	links := []string{
+		pageLink(r, "first", map[string]string{"page": "1", "limit": limit}),
+		pageLink(r, "last", map[string]string{"page": strconv.Itoa(totalPages), "limit": limit}),
+	}
+	if pagination.Page > 1 {
+		links = append(links, pageLink(r, "prev", map[string]string{"page": strconv.Itoa(pagination.Page - 1), "limit": limit}))
+	}
+	if pagination.Page < totalPages {
+		links = append(links, pageLink(r, "next", map[string]string{"page": strconv.Itoa(pagination.Page + 1), "limit": limit}))
+	}
+
+	w.Header().Set("Link", strings.Join(links, ", "))
+}
+
+// SetCursorLinks sets the Link header for a cursor based list.
+func SetCursorLinks(w http.ResponseWriter, r *http.Request, pagination dto.PaginationRequest, info dto.CursorPageInfo) {
+	limit := strconv.Itoa(pagination.Limit)
+
+	links := []string{pageLink(r, "first", map[string]string{"cursor": "", "limit": limit})}
+	if info.PrevCursor != "" {
+		links = append(links, pageLink(r, "prev", map[string]string{"cursor": info.PrevCursor, "limit": limit}))
+	}
+	if info.NextCursor != "" {
+		links = append(links, pageLink(r, "next", map[string]string{"cursor": info.NextCursor, "limit": limit}))
+	}
+
+	w.Header().Set("Link", strings.Join(links, ", "))
 }
@@ -0,0 +1,118 @@
+package utils
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	apperror "github.com/hafiztri123/kki-be/internal/app_error"
+	"github.com/hafiztri123/kki-be/internal/dto"
+	"github.com/hafiztri123/kki-be/internal/money"
+)
+
+const maxSortFields = 3
+
+// ParseSortParams parses a sort parameter such as "sort=-created_at,total_amount",
+// where a leading "-" sorts descending. Only fields in allowed are accepted.
+func ParseSortParams(r *http.Request, allowed ...string) ([]dto.SortField, error) {
+	raw := strings.TrimSpace(r.URL.Query().Get("sort"))
+	if raw == "" {
+		return nil, nil
+	}
+
+	parts := strings.Split(raw, ",")
+	if len(parts) > maxSortFields {
+		return nil, apperror.ErrInvalidFilter
+	}
+
+	seen := make(map[string]bool, len(parts))
+	fields := make([]dto.SortField, 0, len(parts))
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		descending := strings.HasPrefix(part, "-")
+		name := strings.TrimPrefix(part, "-")
+
+		valid := false
+		for _, a := range allowed {
+			if name == a {
+				valid = true
+				break
+			}
+		}
+		if !valid || seen[name] {
+			return nil, apperror.ErrInvalidFilter
+		}
+
+		seen[name] = true
+		fields = append(fields, dto.SortField{Field: name, Descending: descending})
+	}
+
+	return fields, nil
+}
+
+// ParseListParam splits a comma separated parameter, dropping empty entries.
+func ParseListParam(r *http.Request, key string) []string {
+	var values []string
+	for _, v := range strings.Split(r.URL.Query().Get(key), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// ParseTimeParam accepts RFC 3339 timestamps or plain dates (YYYY-MM-DD). When
+// endOfDay is set a plain date is moved to the start of the following day, so
+// it can be used as an exclusive upper bound that still covers the whole day.
+func ParseTimeParam(r *http.Request, key string, endOfDay bool) (*time.Time, error) {
+	raw := strings.TrimSpace(r.URL.Query().Get(key))
+	if raw == "" {
+		return nil, nil
+	}
+
+	// Timestamps are stored as server local wall time.
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		t = t.In(time.Local)
+		return &t, nil
+	}
+
+	t, err := time.ParseInLocation(time.DateOnly, raw, time.Local)
+	if err != nil {
+		return nil, apperror.ErrInvalidFilter
+	}
+
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+
+	return &t, nil
+}
+
+func ParseUUIDParam(r *http.Request, key string) (*uuid.UUID, error) {
+	raw := strings.TrimSpace(r.URL.Query().Get(key))
+	if raw == "" {
+		return nil, nil
+	}
+
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		return nil, apperror.ErrInvalidFilter
+	}
+
+	return &id, nil
+}
+
+func ParseAmountParam(r *http.Request, key string) (*money.Amount, error) {
+	raw := strings.TrimSpace(r.URL.Query().Get(key))
+	if raw == "" {
+		return nil, nil
+	}
+
+	amount, err := money.Parse(raw)
+	if err != nil {
+		return nil, apperror.ErrInvalidFilter
+	}
+
+	return &amount, nil
+}
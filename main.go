@@ -64,7 +64,18 @@ func main() {
 	services := service.NewServices(repositories)
 	handlers := handler.NewHandlers(services)
 
-	router := config.NewRouter(handlers)
+	router := config.NewRouter(handlers, services)
+
+	go service.RunEvery(context.Background(), time.Hour, "purge_idempotency_keys", services.IdempotencyService.PurgeExpired)
+	go service.RunEvery(context.Background(), 5*time.Minute, "expire_held_orders", services.SaleOrderService.ExpireHeldOrders)
+	go service.RunEvery(context.Background(), time.Hour, "expire_quotations", services.QuotationService.ExpireQuotations)
+	go service.RunEvery(context.Background(), time.Hour, "mark_overdue_invoices", services.InvoiceService.MarkOverdueInvoices)
+	go service.RunEvery(context.Background(), time.Hour, "expire_loyalty_points", services.LoyaltyService.ExpirePoints)
+	go service.RunEvery(context.Background(), 30*time.Second, "deliver_emails", services.EmailService.DeliverEmails)
+	go service.RunEvery(context.Background(), 2*time.Second, "dispatch_events", services.EventService.DispatchEvents)
+	go service.RunEvery(context.Background(), time.Hour, "purge_dispatched_events", services.EventService.PurgeDispatchedEvents)
+	go service.RunEvery(context.Background(), 15*time.Second, "deliver_webhooks", services.WebhookService.DeliverWebhooks)
+	go services.SaleOrderStreamService.Listen(context.Background())
 
 	port := utils.GetEnv("PORT")
 	if port == "" {
@@ -0,0 +1,15 @@
+-- Create products table
+CREATE TABLE IF NOT EXISTS products (
+    id UUID PRIMARY KEY,
+    sku VARCHAR(100) NOT NULL UNIQUE,
+    name VARCHAR(255) NOT NULL,
+    category VARCHAR(100) NOT NULL,
+    unit_price DECIMAL(15, 2) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_products_category ON products(category);
+
+CREATE INDEX IF NOT EXISTS idx_products_deleted_at ON products(deleted_at);
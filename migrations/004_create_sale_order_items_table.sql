@@ -0,0 +1,25 @@
+-- Add pricing breakdown to sale_orders
+ALTER TABLE sale_orders ADD COLUMN IF NOT EXISTS subtotal_amount DECIMAL(15, 2) NOT NULL DEFAULT 0;
+ALTER TABLE sale_orders ADD COLUMN IF NOT EXISTS discount_amount DECIMAL(15, 2) NOT NULL DEFAULT 0;
+
+UPDATE sale_orders SET subtotal_amount = total_amount WHERE subtotal_amount = 0;
+
+-- Create sale_order_items table
+CREATE TABLE IF NOT EXISTS sale_order_items (
+    id UUID PRIMARY KEY,
+    sale_order_id UUID NOT NULL,
+    line_number INT NOT NULL,
+    product_id UUID NOT NULL,
+    product_name VARCHAR(255) NOT NULL,
+    category VARCHAR(100) NOT NULL,
+    quantity INT NOT NULL CHECK (quantity > 0),
+    unit_price DECIMAL(15, 2) NOT NULL,
+    subtotal_amount DECIMAL(15, 2) NOT NULL,
+    discount_amount DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    total_amount DECIMAL(15, 2) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (sale_order_id) REFERENCES sale_orders(id) ON DELETE CASCADE,
+    FOREIGN KEY (product_id) REFERENCES products(id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_sale_order_items_sale_order_id_line_number ON sale_order_items(sale_order_id, line_number);
@@ -0,0 +1,40 @@
+-- Create promotions table
+CREATE TABLE IF NOT EXISTS promotions (
+    id UUID PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    type VARCHAR(50) NOT NULL,
+    product_id UUID NULL,
+    category VARCHAR(100) NULL,
+    buy_quantity INT NULL,
+    get_quantity INT NULL,
+    percentage DECIMAL(5, 2) NULL,
+    amount DECIMAL(15, 2) NULL,
+    min_spend DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    starts_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP NOT NULL,
+    priority INT NOT NULL DEFAULT 0,
+    stackable BOOLEAN NOT NULL DEFAULT TRUE,
+    usage_limit INT NULL,
+    usage_count INT NOT NULL DEFAULT 0,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP NULL,
+    FOREIGN KEY (product_id) REFERENCES products(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_promotions_window ON promotions(starts_at, ends_at) WHERE deleted_at IS NULL AND is_active;
+
+-- Create sale_order_promotions table
+CREATE TABLE IF NOT EXISTS sale_order_promotions (
+    id UUID PRIMARY KEY,
+    sale_order_id UUID NOT NULL,
+    promotion_id UUID NOT NULL,
+    promotion_name VARCHAR(255) NOT NULL,
+    discount_amount DECIMAL(15, 2) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (sale_order_id) REFERENCES sale_orders(id) ON DELETE CASCADE,
+    FOREIGN KEY (promotion_id) REFERENCES promotions(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_sale_order_promotions_sale_order_id ON sale_order_promotions(sale_order_id);
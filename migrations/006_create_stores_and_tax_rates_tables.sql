@@ -0,0 +1,45 @@
+-- Create stores table
+CREATE TABLE IF NOT EXISTS stores (
+    id UUID PRIMARY KEY,
+    code VARCHAR(50) NOT NULL UNIQUE,
+    name VARCHAR(255) NOT NULL,
+    address TEXT NOT NULL DEFAULT '',
+    price_includes_tax BOOLEAN NOT NULL DEFAULT TRUE,
+    default_tax_rate DECIMAL(5, 2) NOT NULL DEFAULT 11.00,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_stores_deleted_at ON stores(deleted_at);
+
+-- Create tax_rates table, a NULL store_id applies to every store
+CREATE TABLE IF NOT EXISTS tax_rates (
+    id UUID PRIMARY KEY,
+    store_id UUID NULL,
+    category VARCHAR(100) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    rate DECIMAL(5, 2) NOT NULL CHECK (rate >= 0),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP NULL,
+    FOREIGN KEY (store_id) REFERENCES stores(id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tax_rates_store_category ON tax_rates(COALESCE(store_id, '00000000-0000-0000-0000-000000000000'::uuid), category) WHERE deleted_at IS NULL;
+
+-- Assign cashiers to a store
+ALTER TABLE users ADD COLUMN IF NOT EXISTS store_id UUID NULL REFERENCES stores(id);
+
+-- Add tax information to sale orders
+ALTER TABLE sale_orders ADD COLUMN IF NOT EXISTS store_id UUID NULL REFERENCES stores(id);
+ALTER TABLE sale_orders ADD COLUMN IF NOT EXISTS price_includes_tax BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE sale_orders ADD COLUMN IF NOT EXISTS tax_exempt BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE sale_orders ADD COLUMN IF NOT EXISTS tax_exemption_reference VARCHAR(100) NULL;
+ALTER TABLE sale_orders ADD COLUMN IF NOT EXISTS tax_amount DECIMAL(15, 2) NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_sale_orders_store_id ON sale_orders(store_id);
+
+ALTER TABLE sale_order_items ADD COLUMN IF NOT EXISTS tax_rate DECIMAL(5, 2) NOT NULL DEFAULT 0;
+ALTER TABLE sale_order_items ADD COLUMN IF NOT EXISTS taxable_amount DECIMAL(15, 2) NOT NULL DEFAULT 0;
+ALTER TABLE sale_order_items ADD COLUMN IF NOT EXISTS tax_amount DECIMAL(15, 2) NOT NULL DEFAULT 0;
@@ -0,0 +1,4 @@
+-- Record the currency every amount is expressed in
+ALTER TABLE stores ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'IDR';
+
+ALTER TABLE sale_orders ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'IDR';
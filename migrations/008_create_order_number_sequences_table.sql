@@ -0,0 +1,11 @@
+-- Create order_number_sequences table, one counter row per scope (store) and period
+CREATE TABLE IF NOT EXISTS order_number_sequences (
+    scope VARCHAR(100) NOT NULL,
+    period VARCHAR(20) NOT NULL,
+    last_value BIGINT NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (scope, period)
+);
+
+-- Allow a store to override the order number format
+ALTER TABLE stores ADD COLUMN IF NOT EXISTS order_number_format VARCHAR(100) NULL;
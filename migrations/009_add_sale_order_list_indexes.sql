@@ -0,0 +1,17 @@
+-- Indexes backing the filters and sort orders of the sale order list
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_sale_orders_created_at ON sale_orders(created_at DESC, id DESC) WHERE deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_sale_orders_status_created_at ON sale_orders(status, created_at DESC) WHERE deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_sale_orders_created_by_created_at ON sale_orders(created_by, created_at DESC) WHERE deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_sale_orders_store_id_created_at ON sale_orders(store_id, created_at DESC) WHERE deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_sale_orders_total_amount ON sale_orders(total_amount) WHERE deleted_at IS NULL;
+
+-- Trigram indexes for partial, case-insensitive matches on customer name and order number
+CREATE INDEX IF NOT EXISTS idx_sale_orders_customer_name_trgm ON sale_orders USING GIN (customer_name gin_trgm_ops);
+
+CREATE INDEX IF NOT EXISTS idx_sale_orders_order_number_trgm ON sale_orders USING GIN (order_number gin_trgm_ops);
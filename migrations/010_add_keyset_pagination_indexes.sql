@@ -0,0 +1,3 @@
+-- Index backing keyset pagination of users by role on (created_at, id).
+-- Sale orders are covered by idx_sale_orders_created_at from 009.
+CREATE INDEX IF NOT EXISTS idx_users_role_created_at_id ON users(role, created_at DESC, id DESC) WHERE deleted_at IS NULL;
@@ -0,0 +1,4 @@
+-- Row versions for optimistic concurrency control (ETag / If-Match)
+ALTER TABLE sale_orders ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
@@ -0,0 +1,13 @@
+-- Track orders uploaded by terminals that were offline
+ALTER TABLE sale_orders ADD COLUMN IF NOT EXISTS device_id VARCHAR(100) NULL;
+
+ALTER TABLE sale_orders ADD COLUMN IF NOT EXISTS synced_at TIMESTAMP NULL;
+
+-- Indexes backing GET /sync/changes
+CREATE INDEX IF NOT EXISTS idx_sale_orders_updated_at ON sale_orders(updated_at);
+
+CREATE INDEX IF NOT EXISTS idx_products_updated_at ON products(updated_at);
+
+CREATE INDEX IF NOT EXISTS idx_promotions_updated_at ON promotions(updated_at);
+
+CREATE INDEX IF NOT EXISTS idx_tax_rates_updated_at ON tax_rates(updated_at);
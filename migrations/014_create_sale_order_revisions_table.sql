@@ -0,0 +1,13 @@
+-- Create sale_order_revisions table, the state of an order before each update
+CREATE TABLE IF NOT EXISTS sale_order_revisions (
+    id UUID PRIMARY KEY,
+    sale_order_id UUID NOT NULL,
+    revision_number BIGINT NOT NULL,
+    snapshot JSONB NOT NULL,
+    changed_by UUID NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (sale_order_id) REFERENCES sale_orders(id),
+    FOREIGN KEY (changed_by) REFERENCES users(id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_sale_order_revisions_order_revision ON sale_order_revisions(sale_order_id, revision_number);
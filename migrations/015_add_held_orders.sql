@@ -0,0 +1,25 @@
+-- Park (hold) sale orders at a register and resume them later
+ALTER TABLE sale_orders ADD COLUMN IF NOT EXISTS hold_label VARCHAR(100) NULL;
+
+ALTER TABLE sale_orders ADD COLUMN IF NOT EXISTS register_id VARCHAR(100) NULL;
+
+ALTER TABLE sale_orders ADD COLUMN IF NOT EXISTS held_at TIMESTAMP NULL;
+
+-- Backs GET /sale-orders/held and the expiry job
+CREATE INDEX IF NOT EXISTS idx_sale_orders_held ON sale_orders(store_id, register_id, held_at) WHERE status = 'held' AND deleted_at IS NULL;
+
+-- Audit trail of holds, resumes, take-overs and expiries
+CREATE TABLE IF NOT EXISTS sale_order_hold_events (
+    id UUID PRIMARY KEY,
+    sale_order_id UUID NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    actor_id UUID NULL,
+    previous_cashier_id UUID NULL,
+    register_id VARCHAR(100) NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (sale_order_id) REFERENCES sale_orders(id),
+    FOREIGN KEY (actor_id) REFERENCES users(id),
+    FOREIGN KEY (previous_cashier_id) REFERENCES users(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_sale_order_hold_events_sale_order_id ON sale_order_hold_events(sale_order_id, created_at);
@@ -0,0 +1,54 @@
+-- Create quotations table, price quotes for B2B customers
+CREATE TABLE IF NOT EXISTS quotations (
+    id UUID PRIMARY KEY,
+    customer_name VARCHAR(255) NOT NULL,
+    store_id UUID NULL,
+    currency CHAR(3) NOT NULL DEFAULT 'IDR',
+    price_includes_tax BOOLEAN NOT NULL DEFAULT FALSE,
+    tax_exempt BOOLEAN NOT NULL DEFAULT FALSE,
+    tax_exemption_reference VARCHAR(100) NULL,
+    subtotal_amount DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    discount_amount DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    tax_amount DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    total_amount DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    status VARCHAR(50) NOT NULL,
+    valid_until TIMESTAMP NOT NULL,
+    sale_order_id UUID NULL UNIQUE,
+    created_by UUID NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP NULL,
+    FOREIGN KEY (store_id) REFERENCES stores(id),
+    FOREIGN KEY (sale_order_id) REFERENCES sale_orders(id),
+    FOREIGN KEY (created_by) REFERENCES users(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_quotations_created_by ON quotations(created_by);
+
+CREATE INDEX IF NOT EXISTS idx_quotations_status_valid_until ON quotations(status, valid_until) WHERE deleted_at IS NULL;
+
+-- Create quotation_items table
+CREATE TABLE IF NOT EXISTS quotation_items (
+    id UUID PRIMARY KEY,
+    quotation_id UUID NOT NULL,
+    line_number INT NOT NULL,
+    product_id UUID NOT NULL,
+    product_name VARCHAR(255) NOT NULL,
+    category VARCHAR(100) NOT NULL,
+    quantity INT NOT NULL CHECK (quantity > 0),
+    unit_price DECIMAL(15, 2) NOT NULL,
+    subtotal_amount DECIMAL(15, 2) NOT NULL,
+    discount_amount DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    total_amount DECIMAL(15, 2) NOT NULL,
+    tax_rate DECIMAL(5, 2) NOT NULL DEFAULT 0,
+    taxable_amount DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    tax_amount DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (quotation_id) REFERENCES quotations(id) ON DELETE CASCADE,
+    FOREIGN KEY (product_id) REFERENCES products(id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_quotation_items_quotation_id_line_number ON quotation_items(quotation_id, line_number);
+
+-- Link orders back to the quotation they were converted from
+ALTER TABLE sale_orders ADD COLUMN IF NOT EXISTS quotation_id UUID NULL REFERENCES quotations(id);
@@ -0,0 +1,42 @@
+-- Create invoices table, sale orders billed on payment terms
+CREATE TABLE IF NOT EXISTS invoices (
+    id UUID PRIMARY KEY,
+    invoice_number VARCHAR(255) NOT NULL UNIQUE,
+    sale_order_id UUID NOT NULL,
+    customer_name VARCHAR(255) NOT NULL,
+    store_id UUID NULL,
+    currency CHAR(3) NOT NULL DEFAULT 'IDR',
+    total_amount DECIMAL(15, 2) NOT NULL,
+    paid_amount DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    payment_term_days INT NOT NULL CHECK (payment_term_days >= 0),
+    issued_at TIMESTAMP NOT NULL,
+    due_at TIMESTAMP NOT NULL,
+    status VARCHAR(50) NOT NULL,
+    created_by UUID NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT invoices_sale_order_id_key UNIQUE (sale_order_id),
+    FOREIGN KEY (sale_order_id) REFERENCES sale_orders(id),
+    FOREIGN KEY (store_id) REFERENCES stores(id),
+    FOREIGN KEY (created_by) REFERENCES users(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_invoices_status_due_at ON invoices(status, due_at);
+
+CREATE INDEX IF NOT EXISTS idx_invoices_customer_name ON invoices(customer_name);
+
+-- Create invoice_payments table, partial or full payments of an invoice
+CREATE TABLE IF NOT EXISTS invoice_payments (
+    id UUID PRIMARY KEY,
+    invoice_id UUID NOT NULL,
+    amount DECIMAL(15, 2) NOT NULL CHECK (amount > 0),
+    method VARCHAR(50) NOT NULL,
+    reference VARCHAR(255) NULL,
+    paid_at TIMESTAMP NOT NULL,
+    recorded_by UUID NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (invoice_id) REFERENCES invoices(id),
+    FOREIGN KEY (recorded_by) REFERENCES users(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_invoice_payments_invoice_id ON invoice_payments(invoice_id, paid_at);
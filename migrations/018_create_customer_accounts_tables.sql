@@ -0,0 +1,39 @@
+-- Create customer_accounts table, store credit (kasbon) extended to regular customers
+CREATE TABLE IF NOT EXISTS customer_accounts (
+    id UUID PRIMARY KEY,
+    customer_name VARCHAR(255) NOT NULL,
+    phone VARCHAR(50) NULL,
+    currency CHAR(3) NOT NULL DEFAULT 'IDR',
+    credit_limit DECIMAL(15, 2) NOT NULL CHECK (credit_limit >= 0),
+    balance DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    created_by UUID NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (created_by) REFERENCES users(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_customer_accounts_customer_name ON customer_accounts(customer_name);
+
+-- Create customer_account_entries table, the ledger of charges and repayments
+CREATE TABLE IF NOT EXISTS customer_account_entries (
+    id UUID PRIMARY KEY,
+    account_id UUID NOT NULL,
+    entry_type VARCHAR(50) NOT NULL,
+    amount DECIMAL(15, 2) NOT NULL CHECK (amount > 0),
+    balance_after DECIMAL(15, 2) NOT NULL,
+    sale_order_id UUID NULL,
+    method VARCHAR(50) NULL,
+    reference VARCHAR(255) NULL,
+    approved_by UUID NULL,
+    recorded_by UUID NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (account_id) REFERENCES customer_accounts(id),
+    FOREIGN KEY (sale_order_id) REFERENCES sale_orders(id),
+    FOREIGN KEY (approved_by) REFERENCES users(id),
+    FOREIGN KEY (recorded_by) REFERENCES users(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_customer_account_entries_account_id ON customer_account_entries(account_id, created_at);
+
+-- A sale order is charged to an account at most once
+CREATE UNIQUE INDEX IF NOT EXISTS customer_account_entries_sale_order_id_key ON customer_account_entries(sale_order_id) WHERE entry_type = 'charge';
@@ -0,0 +1,28 @@
+-- Create customers table, customer master data linked from sale orders
+CREATE TABLE IF NOT EXISTS customers (
+    id UUID PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    phone VARCHAR(50) NULL,
+    email VARCHAR(255) NULL,
+    address TEXT NULL,
+    tags TEXT[] NOT NULL DEFAULT '{}',
+    notes TEXT NULL,
+    merged_into_id UUID NULL,
+    created_by UUID NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP NULL,
+    FOREIGN KEY (merged_into_id) REFERENCES customers(id),
+    FOREIGN KEY (created_by) REFERENCES users(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_customers_name ON customers(name) WHERE deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_customers_phone ON customers(phone) WHERE deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_customers_tags ON customers USING GIN (tags);
+
+-- Link sale orders to a customer; customer_name stays as entered on the order
+ALTER TABLE sale_orders ADD COLUMN IF NOT EXISTS customer_id UUID NULL REFERENCES customers(id);
+
+CREATE INDEX IF NOT EXISTS idx_sale_orders_customer_id ON sale_orders(customer_id, created_at) WHERE customer_id IS NOT NULL AND deleted_at IS NULL;
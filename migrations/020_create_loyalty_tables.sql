@@ -0,0 +1,47 @@
+-- Create loyalty_settings table, the single row earn and redeem rule
+CREATE TABLE IF NOT EXISTS loyalty_settings (
+    id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    spend_per_point DECIMAL(15, 2) NOT NULL CHECK (spend_per_point > 0),
+    point_value DECIMAL(15, 2) NOT NULL CHECK (point_value > 0),
+    expiry_days INT NOT NULL DEFAULT 0 CHECK (expiry_days >= 0),
+    min_redeem_points BIGINT NOT NULL DEFAULT 0 CHECK (min_redeem_points >= 0),
+    updated_by UUID NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (updated_by) REFERENCES users(id)
+);
+
+INSERT INTO loyalty_settings (id, enabled, spend_per_point, point_value, expiry_days, min_redeem_points)
+VALUES (TRUE, FALSE, 10000, 1, 365, 0)
+ON CONFLICT (id) DO NOTHING;
+
+-- Create loyalty_category_multipliers table, extra points for some product categories
+CREATE TABLE IF NOT EXISTS loyalty_category_multipliers (
+    category VARCHAR(100) PRIMARY KEY,
+    multiplier DECIMAL(6, 2) NOT NULL CHECK (multiplier >= 0)
+);
+
+-- Create loyalty_point_entries table, the points ledger of each customer.
+-- Earned and restored points form lots that redemptions consume, soonest to
+-- expire first; remaining_points is what is left of a lot.
+CREATE TABLE IF NOT EXISTS loyalty_point_entries (
+    id UUID PRIMARY KEY,
+    customer_id UUID NOT NULL,
+    entry_type VARCHAR(50) NOT NULL,
+    points BIGINT NOT NULL,
+    remaining_points BIGINT NOT NULL DEFAULT 0 CHECK (remaining_points >= 0),
+    expires_at TIMESTAMP NULL,
+    sale_order_id UUID NULL,
+    amount DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    created_by UUID NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (customer_id) REFERENCES customers(id),
+    FOREIGN KEY (sale_order_id) REFERENCES sale_orders(id),
+    FOREIGN KEY (created_by) REFERENCES users(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_loyalty_point_entries_customer_id ON loyalty_point_entries(customer_id, created_at);
+
+CREATE INDEX IF NOT EXISTS idx_loyalty_point_entries_sale_order_id ON loyalty_point_entries(sale_order_id) WHERE sale_order_id IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_loyalty_point_entries_open_lots ON loyalty_point_entries(customer_id, expires_at) WHERE remaining_points > 0;
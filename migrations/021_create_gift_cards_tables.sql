@@ -0,0 +1,54 @@
+-- Create gift_cards table, prepaid gift cards and store credit identified by code
+CREATE TABLE IF NOT EXISTS gift_cards (
+    id UUID PRIMARY KEY,
+    code VARCHAR(50) NOT NULL,
+    card_type VARCHAR(50) NOT NULL,
+    currency CHAR(3) NOT NULL DEFAULT 'IDR',
+    initial_amount DECIMAL(15, 2) NOT NULL CHECK (initial_amount > 0),
+    balance DECIMAL(15, 2) NOT NULL CHECK (balance >= 0),
+    expires_at TIMESTAMP NULL,
+    customer_id UUID NULL,
+    issued_by UUID NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT gift_cards_code_key UNIQUE (code),
+    FOREIGN KEY (customer_id) REFERENCES customers(id),
+    FOREIGN KEY (issued_by) REFERENCES users(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_gift_cards_customer_id ON gift_cards(customer_id) WHERE customer_id IS NOT NULL;
+
+-- Create gift_card_transactions table, the append-only ledger of each card.
+-- recorded_by is NULL for refunds made when a sale order is cancelled.
+CREATE TABLE IF NOT EXISTS gift_card_transactions (
+    id UUID PRIMARY KEY,
+    gift_card_id UUID NOT NULL,
+    transaction_type VARCHAR(50) NOT NULL,
+    amount DECIMAL(15, 2) NOT NULL CHECK (amount > 0),
+    balance_after DECIMAL(15, 2) NOT NULL CHECK (balance_after >= 0),
+    sale_order_id UUID NULL,
+    method VARCHAR(50) NULL,
+    reference VARCHAR(255) NULL,
+    recorded_by UUID NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (gift_card_id) REFERENCES gift_cards(id),
+    FOREIGN KEY (sale_order_id) REFERENCES sale_orders(id),
+    FOREIGN KEY (recorded_by) REFERENCES users(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_gift_card_transactions_gift_card_id ON gift_card_transactions(gift_card_id, created_at);
+
+CREATE INDEX IF NOT EXISTS idx_gift_card_transactions_sale_order_id ON gift_card_transactions(sale_order_id) WHERE sale_order_id IS NOT NULL;
+
+-- Ledger rows are never changed or removed; corrections are new rows
+CREATE OR REPLACE FUNCTION reject_gift_card_transaction_change() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'gift_card_transactions is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS gift_card_transactions_append_only ON gift_card_transactions;
+
+CREATE TRIGGER gift_card_transactions_append_only
+    BEFORE UPDATE OR DELETE ON gift_card_transactions
+    FOR EACH ROW EXECUTE FUNCTION reject_gift_card_transaction_change();
@@ -0,0 +1,67 @@
+-- Create voucher_campaigns table, the rules shared by a batch of printed voucher codes
+CREATE TABLE IF NOT EXISTS voucher_campaigns (
+    id UUID PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    discount_type VARCHAR(50) NOT NULL,
+    percentage DECIMAL(5, 2) NULL,
+    amount DECIMAL(15, 2) NULL,
+    max_discount DECIMAL(15, 2) NULL,
+    min_spend DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    currency CHAR(3) NOT NULL DEFAULT 'IDR',
+    max_uses_per_code INT NULL CHECK (max_uses_per_code > 0),
+    max_uses_per_customer INT NULL CHECK (max_uses_per_customer > 0),
+    starts_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NULL,
+    created_by UUID NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (created_by) REFERENCES users(id)
+);
+
+-- Create vouchers table, one row per code
+CREATE TABLE IF NOT EXISTS vouchers (
+    id UUID PRIMARY KEY,
+    campaign_id UUID NOT NULL,
+    code VARCHAR(50) NOT NULL,
+    use_count INT NOT NULL DEFAULT 0 CHECK (use_count >= 0),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT vouchers_code_key UNIQUE (code),
+    FOREIGN KEY (campaign_id) REFERENCES voucher_campaigns(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_vouchers_campaign_id ON vouchers(campaign_id, code);
+
+-- Create sale_order_vouchers table, the code applied to a sale order and its discount
+CREATE TABLE IF NOT EXISTS sale_order_vouchers (
+    id UUID PRIMARY KEY,
+    sale_order_id UUID NOT NULL,
+    voucher_id UUID NOT NULL,
+    code VARCHAR(50) NOT NULL,
+    discount_amount DECIMAL(15, 2) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT sale_order_vouchers_sale_order_id_key UNIQUE (sale_order_id),
+    FOREIGN KEY (sale_order_id) REFERENCES sale_orders(id) ON DELETE CASCADE,
+    FOREIGN KEY (voucher_id) REFERENCES vouchers(id)
+);
+
+-- Create voucher_redemptions table, codes used by paid sale orders. A
+-- redemption is reversed, not removed, when its order is refunded.
+CREATE TABLE IF NOT EXISTS voucher_redemptions (
+    id UUID PRIMARY KEY,
+    voucher_id UUID NOT NULL,
+    campaign_id UUID NOT NULL,
+    sale_order_id UUID NOT NULL,
+    customer_id UUID NULL,
+    discount_amount DECIMAL(15, 2) NOT NULL,
+    redeemed_at TIMESTAMP NOT NULL,
+    reversed_at TIMESTAMP NULL,
+    FOREIGN KEY (voucher_id) REFERENCES vouchers(id),
+    FOREIGN KEY (campaign_id) REFERENCES voucher_campaigns(id),
+    FOREIGN KEY (sale_order_id) REFERENCES sale_orders(id),
+    FOREIGN KEY (customer_id) REFERENCES customers(id)
+);
+
+-- A sale order redeems at most one code at a time
+CREATE UNIQUE INDEX IF NOT EXISTS voucher_redemptions_sale_order_id_key ON voucher_redemptions(sale_order_id) WHERE reversed_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_voucher_redemptions_customer ON voucher_redemptions(campaign_id, customer_id) WHERE reversed_at IS NULL;
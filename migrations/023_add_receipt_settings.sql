@@ -0,0 +1,7 @@
+-- Lines printed above and below the receipts of a store
+ALTER TABLE stores ADD COLUMN IF NOT EXISTS receipt_header TEXT NULL;
+
+ALTER TABLE stores ADD COLUMN IF NOT EXISTS receipt_footer TEXT NULL;
+
+-- Cash handed over for a sale order, so receipts can show the change given
+ALTER TABLE sale_orders ADD COLUMN IF NOT EXISTS cash_tendered DECIMAL(15, 2) NULL;
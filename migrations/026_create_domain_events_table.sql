@@ -0,0 +1,22 @@
+-- Create domain_events table, the outbox of changes other features react to.
+-- Events are written in the transaction that makes the change and dispatched
+-- to the registered handlers by a background poller. sequence orders events
+-- in the order they were raised.
+CREATE TABLE IF NOT EXISTS domain_events (
+    id UUID PRIMARY KEY,
+    sequence BIGSERIAL NOT NULL UNIQUE,
+    event_type VARCHAR(100) NOT NULL,
+    aggregate_id UUID NOT NULL,
+    payload JSONB NOT NULL,
+    status VARCHAR(50) NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL,
+    last_error TEXT NULL,
+    dispatched_at TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_domain_events_pending ON domain_events(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_domain_events_aggregate_id ON domain_events(aggregate_id);
+CREATE INDEX IF NOT EXISTS idx_domain_events_dispatched_at ON domain_events(dispatched_at) WHERE status = 'dispatched';
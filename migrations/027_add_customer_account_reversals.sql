@@ -0,0 +1,6 @@
+-- Reversals of charges are recorded by the system when a charged sale order is
+-- cancelled or deleted, without a user
+ALTER TABLE customer_account_entries ALTER COLUMN recorded_by DROP NOT NULL;
+
+-- A charge is reversed at most once
+CREATE UNIQUE INDEX IF NOT EXISTS customer_account_entries_reversal_sale_order_id_key ON customer_account_entries(sale_order_id) WHERE entry_type = 'reversal';
@@ -0,0 +1,15 @@
+-- A reversed charge is marked, so a sale order completed again after a
+-- reversal can be charged again
+ALTER TABLE customer_account_entries ADD COLUMN IF NOT EXISTS reversed_at TIMESTAMP NULL;
+
+UPDATE customer_account_entries c
+SET reversed_at = r.created_at
+FROM customer_account_entries r
+WHERE c.entry_type = 'charge' AND c.reversed_at IS NULL
+  AND r.entry_type = 'reversal' AND r.sale_order_id = c.sale_order_id;
+
+-- A sale order has at most one live charge; a charge is reversed once as it
+-- is marked under a row lock
+DROP INDEX IF EXISTS customer_account_entries_reversal_sale_order_id_key;
+DROP INDEX IF EXISTS customer_account_entries_sale_order_id_key;
+CREATE UNIQUE INDEX IF NOT EXISTS customer_account_entries_sale_order_id_key ON customer_account_entries(sale_order_id) WHERE entry_type = 'charge' AND reversed_at IS NULL;
@@ -0,0 +1,5 @@
+-- Link credit accounts to customer records. Accounts opened before stay
+-- unlinked until they are updated with a customer
+ALTER TABLE customer_accounts ADD COLUMN IF NOT EXISTS customer_id UUID NULL REFERENCES customers(id);
+
+CREATE INDEX IF NOT EXISTS idx_customer_accounts_customer_id ON customer_accounts(customer_id);